@@ -1,7 +1,7 @@
 #!/usr/bin/env python3
 """
 Minimal LlamaIndex Flask server for Slack AI Assistant.
-Supports: /v1/answer, /v1/elaborate, /v1/inject
+Supports: /v1/answer, /v1/elaborate, /v1/inject, /v1/projects, /v1/documents
 """
 import os
 import sys
@@ -44,8 +44,17 @@ MIN_HITS = int(os.environ.get("MIN_HITS", "1"))
 SIMILARITY_CUTOFF = float(os.environ.get("SIMILARITY_CUTOFF", "0.5"))
 CONFIDENCE_THRESHOLD = float(os.environ.get("CONFIDENCE_THRESHOLD", "0.1"))
 TOP_K = int(os.environ.get("TOP_K", "5"))
+# Bounds of the top_k sent with a question, matching the topk command of the bot
+MIN_TOP_K = 1
+MAX_TOP_K = 20
+# Bounds of the temperature sent with a question, matching the temperature command of the bot
+MIN_TEMPERATURE = 0.0
+MAX_TEMPERATURE = 1.0
 TEMPERATURE = float(os.environ.get("TEMPERATURE", "0.0"))
 
+# Answer of the questions the knowledge base has no relevant document for, outside of strict mode
+I_DONT_KNOW = "I don't know."
+
 # Gemini model for chat (initialize once)
 gemini_model = None
 
@@ -159,22 +168,23 @@ def load_or_create_delta_index(slug: str) -> Optional[VectorStoreIndex]:
 
 def retrieve_with_confidence(base_index: VectorStoreIndex, 
                               delta_index: Optional[VectorStoreIndex],
-                              query: str) -> tuple[List[NodeWithScore], bool]:
+                              query: str,
+                              top_k: int = TOP_K) -> tuple[List[NodeWithScore], bool]:
     """
-    Retrieve nodes and determine if we have sufficient confidence to answer.
+    Retrieve up to top_k nodes from each index and determine if we have sufficient confidence to answer.
     Returns (nodes, should_answer).
     """
     all_nodes = []
     
     # Retrieve from base index
-    base_retriever = base_index.as_retriever(similarity_top_k=TOP_K)
+    base_retriever = base_index.as_retriever(similarity_top_k=top_k)
     base_nodes = base_retriever.retrieve(query)
     all_nodes.extend(base_nodes)
     print(f"Retrieved {len(base_nodes)} nodes from base index")
     
     # Retrieve from delta index if available
     if delta_index:
-        delta_retriever = delta_index.as_retriever(similarity_top_k=TOP_K)
+        delta_retriever = delta_index.as_retriever(similarity_top_k=top_k)
         delta_nodes = delta_retriever.retrieve(query)
         all_nodes.extend(delta_nodes)
         print(f"Retrieved {len(delta_nodes)} nodes from delta index")
@@ -208,18 +218,36 @@ def retrieve_with_confidence(base_index: VectorStoreIndex,
     return filtered_nodes, True
 
 
-def generate_with_gemini(prompt: str) -> str:
-    """Generate response using Gemini model."""
+def generate_with_gemini(prompt: str, temperature: Optional[float] = None) -> str:
+    """Generate response using Gemini model, with TEMPERATURE when no temperature is given."""
+    if temperature is None:
+        temperature = TEMPERATURE
     model = get_gemini_model()
     response = model.generate_content(
         prompt,
         generation_config=genai.types.GenerationConfig(
-            temperature=TEMPERATURE,
+            temperature=temperature,
         )
     )
     return response.text
 
 
+def request_temperature(data: Dict) -> tuple[Optional[float], bool]:
+    """
+    Read the optional temperature of a request.
+    Returns (temperature, valid), the temperature is None when the request has none.
+    """
+    temperature = data.get('temperature')
+    if temperature is None:
+        return None, True
+    # bool is an int in Python, true isn't a valid temperature
+    if isinstance(temperature, bool) or not isinstance(temperature, (int, float)):
+        return None, False
+    if not MIN_TEMPERATURE <= temperature <= MAX_TEMPERATURE:
+        return None, False
+    return float(temperature), True
+
+
 def build_indexes_if_needed():
     """Build indexes from rag-data if they don't exist yet."""
     storage_path = Path(STORAGE_ROOT)
@@ -300,21 +328,58 @@ def health():
     return jsonify({"status": "ok", "base_indexes": len(indexes), "delta_indexes": len(delta_indexes)})
 
 
+@app.route('/v1/projects', methods=['GET'])
+def projects():
+    """
+    List the project/version slugs that have a base index.
+    Returns: { projects }
+    """
+    return jsonify({"projects": sorted(indexes.keys())})
+
+
+@app.route('/v1/documents', methods=['GET'])
+def documents():
+    """
+    List the documents injected into a project/version.
+    Query: project, version
+    Returns: { documents: [{ id, title }] }
+    """
+    project = request.args.get('project')
+    version = request.args.get('version')
+
+    if not all([project, version]):
+        return jsonify({"error": "Missing required fields"}), 400
+
+    return jsonify({"documents": [
+        {"id": doc.doc_id, "title": doc.metadata.get("title", "")}
+        for doc in load_injected_documents(project, version)
+    ]})
+
+
 @app.route('/v1/answer', methods=['POST'])
 def answer():
     """
     Answer a question using RAG over base + delta indexes.
-    Body: { project, version, thread_slug, message }
-    Returns: { textResponse }
+    Body: { project, version, thread_slug, message, strict?, top_k?, temperature? }
+    Returns: { textResponse, sources, abstained }
+    A strict question is answered only from the retrieved documents, the textResponse is empty when it abstains.
     """
     data = request.json
     project = data.get('project')
     version = data.get('version')
     thread_slug = data.get('thread_slug')
     message = data.get('message')
+    strict = data.get('strict') is True
+    top_k = data.get('top_k', TOP_K)
     
     if not all([project, version, thread_slug, message]):
         return jsonify({"error": "Missing required fields"}), 400
+    # bool is an int in Python, true isn't a valid top_k
+    if isinstance(top_k, bool) or not isinstance(top_k, int) or not MIN_TOP_K <= top_k <= MAX_TOP_K:
+        return jsonify({"error": f"top_k must be an integer between {MIN_TOP_K} and {MAX_TOP_K}"}), 400
+    temperature, valid = request_temperature(data)
+    if not valid:
+        return jsonify({"error": f"temperature must be a number between {MIN_TEMPERATURE} and {MAX_TEMPERATURE}"}), 400
     
     slug = get_slug(project, version)
     
@@ -326,24 +391,39 @@ def answer():
     delta_index = delta_indexes.get(slug)
     
     # Retrieve with confidence gating
-    nodes, should_answer = retrieve_with_confidence(base_index, delta_index, message)
+    nodes, should_answer = retrieve_with_confidence(base_index, delta_index, message, top_k)
     
-    if not should_answer:
-        response_text = "I don't know."
+    sources = []
+    abstained = not should_answer
+    if abstained:
+        response_text = "" if strict else I_DONT_KNOW
     else:
+        # The retrieved chunks, for the admin context command of the bot
+        sources = [
+            {
+                "title": node.node.metadata.get("title") or node.node.metadata.get("file_name", ""),
+                "text": node.node.get_content(),
+                "score": node.score or 0.0,
+            }
+            for node in nodes
+        ]
         # Build context from nodes
         context = "\n\n".join([node.node.get_content() for node in nodes])
         
         # Generate response with Gemini directly
-        prompt = f"""You are a helpful technical assistant with expertise in Kubernetes and cloud-native technologies.
-
-Use the provided context as your PRIMARY source of information. When the user asks for examples or configurations:
+        if strict:
+            instructions = """Answer ONLY from the provided context, do not add anything from your own knowledge."""
+        else:
+            instructions = """Use the provided context as your PRIMARY source of information. When the user asks for examples or configurations:
 1. Start with what's provided in the context
 2. Use your knowledge to complete and enhance the example to make it fully functional
 3. Ensure all parts of your example are consistent (matching labels, IPs, names, etc.)
-4. Provide complete, working configurations that the user can directly use
+4. Provide complete, working configurations that the user can directly use"""
+        prompt = f"""You are a helpful technical assistant with expertise in Kubernetes and cloud-native technologies.
 
-If the context doesn't contain relevant information to answer the question at all, respond with: "I don't know."
+{instructions}
+
+If the context doesn't contain relevant information to answer the question at all, respond with: "{I_DONT_KNOW}"
 
 Context:
 {context}
@@ -352,7 +432,12 @@ Question: {message}
 
 Answer:"""
         
-        response_text = generate_with_gemini(prompt)
+        response_text = generate_with_gemini(prompt, temperature)
+        # The model refusing to answer from the context is an abstention too
+        if response_text.strip() == I_DONT_KNOW:
+            abstained = True
+            if strict:
+                response_text = ""
     
     # Update thread memory
     thread_messages = load_thread_memory(thread_slug)
@@ -361,7 +446,7 @@ Answer:"""
     save_thread_memory(thread_slug, thread_messages)
     threads[thread_slug] = thread_messages
     
-    return jsonify({"textResponse": response_text})
+    return jsonify({"textResponse": response_text, "sources": sources, "abstained": abstained})
 
 
 @app.route('/v1/elaborate', methods=['POST'])
@@ -369,7 +454,7 @@ def elaborate():
     """
     Elaborate/summarize a message in a more readable fashion using pure chat (no retrieval/RAG).
     This is used when a human provides detailed information and wants it reformatted.
-    Body: { thread_slug, message }
+    Body: { thread_slug, message, temperature? }
     Returns: { textResponse }
     """
     data = request.json
@@ -378,6 +463,9 @@ def elaborate():
     
     if not all([thread_slug, message]):
         return jsonify({"error": "Missing required fields"}), 400
+    temperature, valid = request_temperature(data)
+    if not valid:
+        return jsonify({"error": f"temperature must be a number between {MIN_TEMPERATURE} and {MAX_TEMPERATURE}"}), 400
     
     # Load thread memory
     thread_messages = load_thread_memory(thread_slug)
@@ -404,7 +492,7 @@ def elaborate():
             f"Reformatted version:"
         )
     
-    response_text = generate_with_gemini(prompt)
+    response_text = generate_with_gemini(prompt, temperature)
     
     # Update thread memory
     thread_messages.append({"role": "user", "content": message})
@@ -64,6 +64,34 @@ def test_health_endpoint(client):
     assert 'delta_indexes' in data
 
 
+def test_projects_endpoint(client):
+    """Test /v1/projects lists the loaded indexes."""
+    response = client.get('/v1/projects')
+    assert response.status_code == 200
+    data = json.loads(response.data)
+    assert isinstance(data['projects'], list)
+
+
+def test_documents_endpoint(client, tmp_path, monkeypatch):
+    """Test /v1/documents lists the injected documents."""
+    import app as server
+    monkeypatch.setattr(server, 'INJECT_ROOT', str(tmp_path))
+    server.append_to_jsonl('metallb', '4.18', 'BGP peers', {'title': 'MetalLB BGP'})
+
+    response = client.get('/v1/documents?project=metallb&version=4.18')
+    assert response.status_code == 200
+    data = json.loads(response.data)
+    assert len(data['documents']) == 1
+    assert data['documents'][0]['title'] == 'MetalLB BGP'
+    assert data['documents'][0]['id']
+
+
+def test_documents_missing_fields(client):
+    """Test /v1/documents without project and version."""
+    response = client.get('/v1/documents')
+    assert response.status_code == 400
+
+
 def test_answer_missing_fields(client):
     """Test /v1/answer with missing fields."""
     response = client.post('/v1/answer',
@@ -89,6 +117,190 @@ def test_answer_unknown_project(client):
     assert 'error' in data
 
 
+class FakeNode:
+    """A retrieved chunk with the attributes read by /v1/answer."""
+
+    def __init__(self, text, title='', score=0.9):
+        self.metadata = {'title': title}
+        self.text = text
+        self.score = score
+        self.node = self
+
+    def get_content(self):
+        return self.text
+
+
+@pytest.fixture
+def answer_server(tmp_path, monkeypatch):
+    """Serve /v1/answer for metallb 4.18 with stubbed retrieval and generation, recording their calls."""
+    import app as server
+    calls = {'retrieve': [], 'generate': []}
+    retrieval = {'nodes': [FakeNode('Create a BGPPeer', 'MetalLB BGP')], 'should_answer': True}
+    answer_text = {'value': 'Create a BGPPeer resource.'}
+
+    def retrieve(base_index, delta_index, query, *args, **kwargs):
+        calls['retrieve'].append({'query': query, 'args': args, 'kwargs': kwargs})
+        return retrieval['nodes'], retrieval['should_answer']
+
+    def generate(prompt, *args, **kwargs):
+        calls['generate'].append({'prompt': prompt, 'args': args, 'kwargs': kwargs})
+        return answer_text['value']
+
+    monkeypatch.setattr(server, 'STATE_ROOT', str(tmp_path))
+    monkeypatch.setitem(server.indexes, 'metallb-4-dot-18', object())
+    monkeypatch.setattr(server, 'retrieve_with_confidence', retrieve)
+    monkeypatch.setattr(server, 'generate_with_gemini', generate)
+    return {'calls': calls, 'retrieval': retrieval, 'answer': answer_text}
+
+
+def post_answer(client, **fields):
+    """Ask the metallb 4.18 question with the extra fields and return the decoded response."""
+    body = {'project': 'metallb', 'version': '4.18', 'thread_slug': 'test-thread', 'message': 'How do I configure BGP?'}
+    body.update(fields)
+    response = client.post('/v1/answer', json=body, content_type='application/json')
+    assert response.status_code == 200
+    return json.loads(response.data)
+
+
+def test_answer_abstains_with_i_dont_know(client, answer_server):
+    """Test /v1/answer answers "I don't know." without a confident retrieval."""
+    answer_server['retrieval'].update(nodes=[], should_answer=False)
+
+    data = post_answer(client)
+    assert data['textResponse'] == "I don't know."
+    assert data['abstained'] is True
+    assert answer_server['calls']['generate'] == []
+
+
+def test_answer_strict_abstains_with_empty_text(client, answer_server):
+    """Test a strict /v1/answer answers an empty text without a confident retrieval."""
+    answer_server['retrieval'].update(nodes=[], should_answer=False)
+
+    data = post_answer(client, strict=True)
+    assert data['textResponse'] == ''
+    assert data['abstained'] is True
+    assert data['sources'] == []
+
+
+def test_answer_strict_abstains_when_the_model_refuses(client, answer_server):
+    """Test a strict /v1/answer abstains when the model finds nothing relevant in the context."""
+    answer_server['answer']['value'] = "I don't know."
+
+    data = post_answer(client, strict=True)
+    assert data['textResponse'] == ''
+    assert data['abstained'] is True
+    assert 'Answer ONLY from the provided context' in answer_server['calls']['generate'][0]['prompt']
+
+
+def test_answer_strict_with_relevant_documents(client, answer_server):
+    """Test a strict /v1/answer answers from the retrieved documents."""
+    data = post_answer(client, strict=True)
+    assert data['textResponse'] == 'Create a BGPPeer resource.'
+    assert data['abstained'] is False
+    assert data['sources'][0]['title'] == 'MetalLB BGP'
+
+
+def test_answer_passes_top_k_to_the_retrieval(client, answer_server):
+    """Test /v1/answer retrieves the top_k of the request, TOP_K without it."""
+    import app as server
+
+    post_answer(client, top_k=8)
+    post_answer(client)
+    assert answer_server['calls']['retrieve'][0]['args'] == (8,)
+    assert answer_server['calls']['retrieve'][1]['args'] == (server.TOP_K,)
+
+
+@pytest.mark.parametrize('top_k', [0, 21, 'many', 2.5, True])
+def test_answer_rejects_an_invalid_top_k(client, answer_server, top_k):
+    """Test /v1/answer rejects a top_k outside of the topk command bounds."""
+    response = client.post('/v1/answer',
+                           json={
+                               'project': 'metallb',
+                               'version': '4.18',
+                               'thread_slug': 'test-thread',
+                               'message': 'How do I configure BGP?',
+                               'top_k': top_k
+                           },
+                           content_type='application/json')
+    assert response.status_code == 400
+    assert answer_server['calls']['retrieve'] == []
+
+
+def test_answer_passes_temperature_to_the_generation(client, answer_server):
+    """Test /v1/answer generates with the temperature of the request, the default without it."""
+    post_answer(client, temperature=0.7)
+    post_answer(client)
+    assert answer_server['calls']['generate'][0]['args'] == (0.7,)
+    assert answer_server['calls']['generate'][1]['args'] == (None,)
+
+
+def test_elaborate_passes_temperature_to_the_generation(client, answer_server):
+    """Test /v1/elaborate generates with the temperature of the request."""
+    response = client.post('/v1/elaborate',
+                           json={'thread_slug': 'test-thread', 'message': 'BGP notes', 'temperature': 0.2},
+                           content_type='application/json')
+    assert response.status_code == 200
+    assert answer_server['calls']['generate'][0]['args'] == (0.2,)
+
+
+@pytest.mark.parametrize('temperature', [-0.1, 1.5, 'hot', True])
+def test_rejects_an_invalid_temperature(client, answer_server, temperature):
+    """Test /v1/answer and /v1/elaborate reject a temperature outside of the temperature command bounds."""
+    for path, body in [
+        ('/v1/answer', {'project': 'metallb', 'version': '4.18', 'thread_slug': 'test-thread', 'message': 'BGP?'}),
+        ('/v1/elaborate', {'thread_slug': 'test-thread', 'message': 'BGP notes'}),
+    ]:
+        body['temperature'] = temperature
+        response = client.post(path, json=body, content_type='application/json')
+        assert response.status_code == 400
+    assert answer_server['calls']['generate'] == []
+
+
+def test_generate_with_gemini_temperature(monkeypatch):
+    """Test generate_with_gemini uses the given temperature and falls back to TEMPERATURE."""
+    import app as server
+    configs = []
+
+    class FakeModel:
+        def generate_content(self, prompt, generation_config):
+            configs.append(generation_config)
+            return type('Response', (), {'text': 'answer'})()
+
+    monkeypatch.setattr(server, 'get_gemini_model', lambda: FakeModel())
+    monkeypatch.setattr(server.genai.types, 'GenerationConfig', lambda **kwargs: kwargs)
+    monkeypatch.setattr(server, 'TEMPERATURE', 0.3)
+
+    assert server.generate_with_gemini('prompt', 0.9) == 'answer'
+    server.generate_with_gemini('prompt')
+    assert configs == [{'temperature': 0.9}, {'temperature': 0.3}]
+
+
+class FakeIndex:
+    """An index recording the similarity_top_k of its retrievers, which find nothing."""
+
+    def __init__(self):
+        self.top_ks = []
+
+    def as_retriever(self, similarity_top_k):
+        self.top_ks.append(similarity_top_k)
+        return self
+
+    def retrieve(self, query):
+        return []
+
+
+def test_retrieve_with_confidence_uses_top_k():
+    """Test the base and delta retrievers both retrieve top_k nodes."""
+    import app as server
+    base_index, delta_index = FakeIndex(), FakeIndex()
+
+    nodes, should_answer = server.retrieve_with_confidence(base_index, delta_index, 'How do I configure BGP?', 8)
+    assert nodes == []
+    assert should_answer is False
+    assert base_index.top_ks == [8]
+    assert delta_index.top_ks == [8]
+
+
 def test_elaborate_missing_fields(client):
     """Test /v1/elaborate with missing fields."""
     response = client.post('/v1/elaborate',
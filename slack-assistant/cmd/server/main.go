@@ -2,11 +2,17 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
@@ -14,32 +20,382 @@ import (
 
 	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
 	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
 	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
 	slackbot "github.com/SchSeba/slack-ai-assistant/pkg/slack-bot"
 )
 
+// Build metadata, set at build time via ldflags
+var (
+	version   = "dev"
+	commit    = "unknown"
+	buildTime = "unknown"
+)
+
 var (
 	slackBotToken string
 	slackAppToken string
+	// teamBotTokens are the bot tokens of the additional workspaces the app is installed in
+	teamBotTokens []string
 	debug         bool
 	workers       int
+	queueSize     int
+	durableQueue  bool
+	// dbDriver selects the database, sqlite or memory
+	dbDriver string
+	// sqlitePragmas are the name=value pragmas of the sqlite connections on top of the defaults
+	sqlitePragmas []string
+	// deletePlaceholder deletes the searching message once the answer is posted
+	deletePlaceholder bool
+	// elaborateWorkspace is the AnythingLLM workspace used by the elaborate command
+	elaborateWorkspace string
+	maxThreadContext   int
+	keepFirstMessage   bool
+	// injectConfirmTimeout enables the inject confirmation when it is set
+	injectConfirmTimeout time.Duration
+	// llmFailureThreshold enables the LLM circuit breaker when it is set
+	llmFailureThreshold int
+	llmCoolDown         time.Duration
+	// llmConcurrency bounds the LLM calls in flight across the workers when it is set
+	llmConcurrency int
+	// maxConcurrentInjects bounds the injects in flight with their own slots when it is set
+	maxConcurrentInjects int
+	maxMessageLength     int
+	// slackAuthRetry is the retry budget of the Slack authentication at startup
+	slackAuthRetry = slackbot.DefaultAuthRetry()
+	// botUserIDs are the additional user IDs the bot is mentioned with, e.g. on enterprise grid
+	botUserIDs []string
+	// adminUsers are the user IDs allowed to run the admin commands, e.g. debug
+	adminUsers []string
+	// triggerPrefix starts the messages of the triggerChannels handled without mentioning the bot
+	triggerPrefix   string
+	triggerChannels []string
+	// branding holds the emoji and prefixes of the status messages
+	branding = agent.DefaultBranding()
+	// projectPrompts are the project=prompt system prompts prepended to the questions of each project
+	projectPrompts []string
+	// commandTemperatures are the command=temperature LLM temperatures overriding the defaults of the commands
+	commandTemperatures []string
+	// readOnlyProjects are the project/version workspaces whose curated knowledge base rejects the injects
+	readOnlyProjects []string
+	// requiredProjects are the project/version workspaces checked in the LLM backend at startup, with the read-only ones
+	requiredProjects []string
+	// workspaceAliases are the project/version=slug workspaces not named after the project and version
+	workspaceAliases []string
+	// failOnMissingProject stops the startup when a required workspace is missing instead of warning
+	failOnMissingProject bool
+	// locale selects the language of the messages posted to Slack
+	locale string
+	// snippetThreshold uploads the answers longer than it as a snippet when it is set
+	snippetThreshold int
+	// coalesceWindow runs the same command repeated in a thread within it only once when it is set
+	coalesceWindow time.Duration
+	// maxQueueWait skips the mentions that waited longer in the queue when it is set
+	maxQueueWait  time.Duration
+	notifyExpired bool
+	// vision sends the images attached to the questions to the LLM
+	vision bool
+	// postAttempts is how many times an answer is posted, waiting postRetryBackoff doubled between the attempts
+	postAttempts     int
+	postRetryBackoff time.Duration
+	// answerChannel also gets the answers when it is set, only it gets them with redirectAnswers
+	answerChannel   string
+	redirectAnswers bool
+	// replayChannel gets the replays of the admins, e.g. a scratch channel, when it is set
+	replayChannel string
+	// metricsAddr serves the command latency histograms on /metrics and the readiness probe on /readyz when it is set
+	metricsAddr string
+	// shutdownTimeout bounds how long the workers may take to finish once a signal was received
+	shutdownTimeout time.Duration
 )
 
 func init() {
-	rootCmd.PersistentFlags().StringVarP(&slackBotToken, "bot-token", "b", "", "Slack Bot Token (required)")
-	rootCmd.PersistentFlags().StringVarP(&slackAppToken, "app-token", "a", "", "Slack App Token (required)")
+	rootCmd.Flags().StringVarP(&slackBotToken, "bot-token", "b", "", "Slack Bot Token (required)")
+	rootCmd.Flags().StringVarP(&slackAppToken, "app-token", "a", "", "Slack App Token (required)")
+	rootCmd.Flags().StringSliceVar(&teamBotTokens, "team-bot-token", nil, "Bot Token of an additional Slack workspace to serve, can be repeated")
+	rootCmd.Flags().StringVar(&elaborateWorkspace, "elaborate-workspace", defaultElaborateWorkspace(), "AnythingLLM workspace used by the elaborate command (env ELABORATE_WORKSPACE)")
 	rootCmd.PersistentFlags().BoolVarP(&debug, "debug", "d", false, "Enable debug mode")
 	rootCmd.PersistentFlags().IntVarP(&workers, "workers", "w", 10, "Number of workers for the agent")
+	rootCmd.PersistentFlags().IntVar(&queueSize, "queue-size", 200, "Number of events that can wait for a worker before new ones are dropped")
+	rootCmd.PersistentFlags().IntVar(&maxThreadContext, "max-thread-context", agent.DefaultMaxThreadContext, "Maximum number of thread characters sent to the LLM, older messages are dropped first (0 disables the limit)")
+	rootCmd.PersistentFlags().BoolVar(&keepFirstMessage, "keep-first-message", true, "Always send the first message of a thread when it exceeds max-thread-context")
+	rootCmd.PersistentFlags().DurationVar(&injectConfirmTimeout, "inject-confirm-timeout", 0, "Ask for a confirmation before inject, cancelled when not confirmed within this duration (0 disables the confirmation)")
+	rootCmd.PersistentFlags().IntVar(&llmFailureThreshold, "llm-failure-threshold", llm.DefaultBreakerFailureThreshold, "Consecutive LLM failures before calls are short-circuited (0 disables the circuit breaker)")
+	rootCmd.PersistentFlags().DurationVar(&llmCoolDown, "llm-cool-down", llm.DefaultBreakerCoolDown, "How long LLM calls are short-circuited before the backend is probed again")
+	rootCmd.PersistentFlags().IntVar(&llmConcurrency, "llm-concurrency", 0, "Maximum number of LLM calls in flight across the workers (0 disables the limit)")
+	rootCmd.PersistentFlags().IntVar(&maxConcurrentInjects, "max-concurrent-injects", 0, "Maximum number of injects in flight, so bulk injects don't overwhelm the embedding pipeline or hold back the answers (0 disables the limit)")
+	rootCmd.PersistentFlags().IntVar(&slackAuthRetry.MaxAttempts, "slack-auth-attempts", slackbot.DefaultAuthAttempts, "How many times the Slack authentication is tried at startup when it fails transiently, e.g. rate limited while many replicas start")
+	rootCmd.PersistentFlags().DurationVar(&slackAuthRetry.Backoff, "slack-auth-backoff", slackbot.DefaultAuthBackoff, "Wait before retrying the Slack authentication, doubled on every attempt and jittered so the replicas don't retry together")
+	rootCmd.PersistentFlags().IntVar(&maxMessageLength, "max-message-length", slackbot.DefaultMaxMessageLength, "Maximum number of characters of a Slack message, longer messages are truncated")
+	rootCmd.PersistentFlags().StringSliceVar(&botUserIDs, "bot-user-id", nil, "Additional user ID the bot is mentioned with, e.g. its enterprise grid user ID, can be repeated")
+	rootCmd.PersistentFlags().StringVar(&triggerPrefix, "trigger-prefix", "", "Prefix of the messages run as a command without mentioning the bot, e.g. ?ask, only in the trigger channels")
+	rootCmd.PersistentFlags().StringSliceVar(&triggerChannels, "trigger-channel", nil, "Channel ID where the messages starting with the trigger prefix run a command, can be repeated")
+	rootCmd.PersistentFlags().StringSliceVar(&adminUsers, "admin-user", nil, "User ID allowed to run the admin commands, e.g. debug, can be repeated")
+	rootCmd.PersistentFlags().StringVar(&branding.ProcessingEmoji, "processing-emoji", branding.ProcessingEmoji, "Emoji of the busy and temporarily unavailable messages, e.g. :hourglass: for a workspace emoji")
+	rootCmd.PersistentFlags().StringVar(&branding.SuccessEmoji, "success-emoji", branding.SuccessEmoji, "Emoji of the successful commands in the App Home tab")
+	rootCmd.PersistentFlags().StringVar(&branding.ErrorEmoji, "error-emoji", branding.ErrorEmoji, "Emoji of the error messages and the failed commands in the App Home tab")
+	rootCmd.PersistentFlags().StringVar(&branding.ErrorPrefix, "error-prefix", branding.ErrorPrefix, "Prefix of the error detail posted when the LLM fails")
+	rootCmd.PersistentFlags().StringVar(&branding.SearchingMessage, "searching-message", branding.SearchingMessage, "Message posted while an answer is generated")
+	rootCmd.PersistentFlags().StringVar(&branding.ElaboratingMessage, "elaborating-message", branding.ElaboratingMessage, "Message posted while a message is elaborated")
+	rootCmd.PersistentFlags().StringVar(&branding.AnswerIntro, "answer-intro", branding.AnswerIntro, "Sentence introducing the answers, empty to post the answers without introduction")
+	rootCmd.PersistentFlags().StringVar(&branding.AnswerFooter, "answer-footer", branding.AnswerFooter, "Footer appended to every answer, e.g. a disclaimer like \"AI-generated, verify before acting\", empty to post the answers without footer")
+	rootCmd.PersistentFlags().StringVar(&locale, "locale", i18n.DefaultLocale, fmt.Sprintf("Language of the messages posted to Slack (%s)", strings.Join(i18n.Locales(), ", ")))
+	rootCmd.PersistentFlags().StringArrayVar(&projectPrompts, "project-prompt", nil, "System prompt of a project as project=prompt, prepended to the questions sent to its workspaces, can be repeated")
+	rootCmd.PersistentFlags().StringArrayVar(&commandTemperatures, "command-temperature", nil, "LLM temperature of a command as command=temperature, from 0 (grounded) to 1 (creative), e.g. elaborate=0.9 or follow-up=0.3, can be repeated. The commands without one keep the temperature of the backend")
+	rootCmd.PersistentFlags().StringSliceVar(&readOnlyProjects, "read-only-project", nil, "Project/version workspace whose knowledge base is read-only, e.g. sriov/4.16, inject and export are rejected for it, can be repeated")
+	rootCmd.PersistentFlags().StringSliceVar(&workspaceAliases, "workspace-alias", nil, "Project/version=slug AnythingLLM workspace of a project whose slug isn't derived from its project and version, e.g. sriov/4.16=team-sriov, can be repeated")
+	rootCmd.PersistentFlags().StringSliceVar(&requiredProjects, "required-project", nil, "Project/version workspace checked in the LLM backend at startup, e.g. sriov/4.16, the read-only projects are checked too, can be repeated")
+	rootCmd.PersistentFlags().BoolVar(&failOnMissingProject, "fail-on-missing-project", false, "Stop at startup when a required or read-only project workspace is missing instead of only warning")
+	rootCmd.PersistentFlags().DurationVar(&shutdownTimeout, "shutdown-timeout", defaultShutdownTimeout, "How long the shutdown waits for the running work before the process exits anyway (0 waits forever)")
+	rootCmd.PersistentFlags().BoolVar(&durableQueue, "durable-queue", false, "Persist queued work in the database so it survives restarts")
+	rootCmd.PersistentFlags().StringVar(&dbDriver, "db-driver", "sqlite", "Database of the threads and audits, sqlite or memory (nothing is kept across restarts)")
+	rootCmd.PersistentFlags().StringArrayVar(&sqlitePragmas, "sqlite-pragma", nil, fmt.Sprintf("SQLite pragma as name=value overriding the defaults (%s), e.g. synchronous=NORMAL, can be repeated", strings.Join(database.DefaultPragmas, ", ")))
+	rootCmd.PersistentFlags().IntVar(&snippetThreshold, "snippet-threshold", 0, "Answers longer than this number of characters are uploaded as a text snippet instead of a message (0 disables the snippets)")
+	rootCmd.PersistentFlags().DurationVar(&maxQueueWait, "max-queue-wait", 0, "Skip the mentions that waited longer than this in the queue, e.g. 2m under sustained overload (0 answers every mention)")
+	rootCmd.PersistentFlags().BoolVar(&notifyExpired, "notify-expired", true, "Tell the thread when its mention is skipped for waiting longer than --max-queue-wait")
+	rootCmd.PersistentFlags().BoolVar(&vision, "vision", false, "Send the images attached to the questions to the LLM, the AnythingLLM model must support vision (without it the thread is told the images aren't read)")
+	rootCmd.PersistentFlags().IntVar(&postAttempts, "post-attempts", agent.DefaultPostAttempts, "How many times an answer is posted to Slack before giving up, the answer is logged in full when every attempt failed")
+	rootCmd.PersistentFlags().DurationVar(&postRetryBackoff, "post-retry-backoff", agent.DefaultPostRetryBackoff, "Wait before posting an answer again, doubled on every attempt")
+	rootCmd.PersistentFlags().StringVar(&answerChannel, "answer-channel", "", "Channel ID the answers are also posted to with a link back to their thread, e.g. to review them in one place (empty disables it)")
+	rootCmd.PersistentFlags().BoolVar(&redirectAnswers, "redirect-answers", false, "Post the answers only to --answer-channel, their thread is told where to find them")
+	rootCmd.PersistentFlags().StringVar(&replayChannel, "replay-channel", "", "Channel ID the admin replay command answers in, e.g. a scratch channel (empty replays in the channel of the command)")
+	rootCmd.PersistentFlags().DurationVar(&coalesceWindow, "coalesce-window", 0, "Run the same command repeated in a thread within this window only once, e.g. 5s (0 runs every command)")
+	rootCmd.PersistentFlags().StringVar(&metricsAddr, "metrics-addr", "", "Address serving the command latency histograms on /metrics in the Prometheus format and the readiness probe on /readyz, e.g. :9090 (empty disables it)")
+	rootCmd.PersistentFlags().BoolVar(&deletePlaceholder, "delete-placeholder", false, "Delete the searching message once the answer is posted")
 
-	// Mark required flags
-	if err := rootCmd.MarkPersistentFlagRequired("bot-token"); err != nil {
+	// Mark required flags, they are local so subcommands like version don't require them
+	if err := rootCmd.MarkFlagRequired("bot-token"); err != nil {
 		log.Fatalf("Failed to mark bot-token as required: %v", err)
 	}
-	if err := rootCmd.MarkPersistentFlagRequired("app-token"); err != nil {
+	if err := rootCmd.MarkFlagRequired("app-token"); err != nil {
 		log.Fatalf("Failed to mark app-token as required: %v", err)
 	}
 }
 
+// defaultElaborateWorkspace returns the ELABORATE_WORKSPACE environment variable or the agent default
+func defaultElaborateWorkspace() string {
+	if workspace := os.Getenv("ELABORATE_WORKSPACE"); workspace != "" {
+		return workspace
+	}
+	return agent.DefaultElaborateWorkspace
+}
+
+// validateElaborateWorkspace checks that the elaborate workspace exists in the LLM backend,
+// a backend that can't be reached is only reported so the bot can still start
+func validateElaborateWorkspace(ctx context.Context, llmClient llm.Interface, workspace string) error {
+	projects, err := llmClient.ListProjects(ctx)
+	if err != nil {
+		fmt.Printf("⚠️ Failed to check the elaborate workspace %s: %v\n", workspace, err)
+		return nil
+	}
+
+	if !slices.Contains(projects, workspace) {
+		return fmt.Errorf("elaborate workspace %q doesn't exist, create it in AnythingLLM or set --elaborate-workspace", workspace)
+	}
+	return nil
+}
+
+// parseProjectPrompts parses the project=prompt values of the project-prompt flag
+func parseProjectPrompts(values []string) (map[string]string, error) {
+	prompts := make(map[string]string, len(values))
+	for _, value := range values {
+		project, prompt, found := strings.Cut(value, "=")
+		project = strings.TrimSpace(project)
+		if !found || project == "" {
+			return nil, fmt.Errorf("invalid project prompt %q, expected project=prompt", value)
+		}
+		prompts[project] = strings.TrimSpace(prompt)
+	}
+	return prompts, nil
+}
+
+// parseCommandTemperatures parses the command=temperature values of the command-temperature flag,
+// the range of the temperatures is checked by the agent
+func parseCommandTemperatures(values []string) (map[string]float64, error) {
+	temperatures := make(map[string]float64, len(values))
+	for _, value := range values {
+		command, temperature, found := strings.Cut(value, "=")
+		command = strings.TrimSpace(command)
+		if !found || command == "" {
+			return nil, fmt.Errorf("invalid command temperature %q, expected command=temperature", value)
+		}
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(temperature), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid temperature in %q: %w", value, err)
+		}
+		temperatures[command] = parsed
+	}
+	return temperatures, nil
+}
+
+// llmBackendNames are the LLM backends AI_BACKEND and AI_BACKENDS accept
+var llmBackendNames = []string{"anythingllm", "llamaindex"}
+
+// parseBackends returns the LLM backend answering at startup, AnythingLLM when active is empty, and the backends to
+// configure: the active one followed by the comma-separated list, which the admins can switch between at runtime
+func parseBackends(active, list string) (string, []string, error) {
+	if active == "" {
+		active = "anythingllm"
+	}
+	names := []string{active}
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		if !slices.Contains(llmBackendNames, name) {
+			return "", nil, fmt.Errorf("unknown LLM backend %q, expected one of %s", name, strings.Join(llmBackendNames, ", "))
+		}
+	}
+	return active, names, nil
+}
+
+// newBackend creates the client of the named LLM backend, the AnythingLLM elaborate workspace is checked
+func newBackend(ctx context.Context, name, elaborateWorkspace string, aliases *llm.WorkspaceAliases) (llm.Backend, error) {
+	if name == "llamaindex" {
+		client, err := llm.NewLlamaIndexClient()
+		if err != nil {
+			return llm.Backend{}, fmt.Errorf("failed to create LlamaIndex client: %w", err)
+		}
+		host := os.Getenv("LLAMAINDEX_HOST")
+		if host == "" {
+			host = llm.DefaultLlamaIndexHost
+		}
+		return llm.Backend{Name: name, Provider: "LlamaIndex", Host: host, Client: client}, nil
+	}
+
+	client := llm.NewLLMClient()
+	// The LlamaIndex server names its indexes itself, only the AnythingLLM workspaces can be aliased
+	if aliased, ok := client.(*llm.LLMClient); ok {
+		aliased.SetWorkspaceAliases(aliases)
+	}
+	// LlamaIndex has a dedicated elaborate endpoint, only AnythingLLM needs the workspace
+	if err := validateElaborateWorkspace(ctx, client, elaborateWorkspace); err != nil {
+		return llm.Backend{}, err
+	}
+	return llm.Backend{Name: name, Provider: "AnythingLLM", Host: os.Getenv("ANYTHINGLLM_HOST"), Client: client}, nil
+}
+
+// validateProjects checks that the workspace of every project and version exists in the LLM backend, the missing ones
+// are an error when failOnMissing is set and only reported otherwise, like a backend that can't be reached
+func validateProjects(ctx context.Context, llmClient llm.Interface, projects []agent.ProjectVersion, failOnMissing bool) error {
+	if len(projects) == 0 {
+		return nil
+	}
+	workspaces, err := llmClient.ListProjects(ctx)
+	if err != nil {
+		fmt.Printf("⚠️ Failed to check the configured projects: %v\n", err)
+		return nil
+	}
+
+	var missing []string
+	for _, project := range projects {
+		if !slices.Contains(workspaces, llm.WorkspaceSlug(project.Project, project.Version)) {
+			missing = append(missing, project.Project+"/"+project.Version)
+		}
+	}
+	if len(missing) == 0 {
+		fmt.Printf("✅ The %d configured projects have a workspace\n", len(projects))
+		return nil
+	}
+
+	err = fmt.Errorf("the workspace of the projects %s doesn't exist, create it in the LLM backend or fix the configuration", strings.Join(missing, ", "))
+	if failOnMissing {
+		return err
+	}
+	fmt.Printf("⚠️ %v\n", err)
+	return nil
+}
+
+// parseProjectVersions parses the project/version values of the flag
+func parseProjectVersions(flag string, values []string) ([]agent.ProjectVersion, error) {
+	projects := make([]agent.ProjectVersion, 0, len(values))
+	for _, value := range values {
+		project, version, found := strings.Cut(strings.TrimSpace(value), "/")
+		if !found || project == "" || version == "" {
+			return nil, fmt.Errorf("invalid %s %q, expected project/version", flag, value)
+		}
+		projects = append(projects, agent.ProjectVersion{Project: project, Version: version})
+	}
+	return projects, nil
+}
+
+// openDatabase opens and migrates the database of the driver, the memory database keeps nothing across restarts
+func openDatabase(driver string, pragmas ...string) (database.Interface, error) {
+	switch driver {
+	case "sqlite":
+		db, err := database.NewDatabase("slack-ai-assistant.db", pragmas...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create database: %w", err)
+		}
+		if err := db.AutoMigrate(); err != nil {
+			//nolint:errcheck // the migration error is reported
+			_ = db.Close()
+			return nil, fmt.Errorf("failed to migrate database: %w", err)
+		}
+		if version, err := db.SchemaVersion(); err == nil {
+			fmt.Printf("🗄️ Database schema version %s\n", version)
+		}
+		if mode, err := db.Pragma("journal_mode"); err == nil {
+			fmt.Printf("🗄️ Database journal mode %s\n", mode)
+		}
+		return db, nil
+	case "memory":
+		fmt.Println("🗄️ Using the in-memory database, threads and audits are lost on restart")
+		return database.NewMemoryDatabase(), nil
+	default:
+		return nil, fmt.Errorf("unknown database driver %q, expected sqlite or memory", driver)
+	}
+}
+
+// defaultShutdownTimeout is how long the shutdown waits for the running work unless configured otherwise
+const defaultShutdownTimeout = 30 * time.Second
+
+// awaitShutdown cancels the bot on the first signal and exits the process with exit when it didn't stop within the timeout,
+// e.g. a worker stuck in an LLM call, so the process never outlives the grace period of the orchestrator.
+// It returns once done is closed, a zero timeout waits for done forever.
+func awaitShutdown(signals <-chan os.Signal, cancel context.CancelFunc, done <-chan struct{}, timeout time.Duration, exit func(code int)) {
+	select {
+	case sig := <-signals:
+		fmt.Printf("🛑 Received signal %v, shutting down gracefully...\n", sig)
+		cancel()
+	case <-done:
+		return
+	}
+
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	select {
+	case <-done:
+	case <-deadline:
+		fmt.Printf("⚠️ Shutdown didn't finish within %s, exiting without waiting for the running work\n", timeout)
+		exit(1)
+	}
+}
+
+// localizeBranding returns the branding with the messages that were not set with a flag in the active locale
+func localizeBranding(branding agent.Branding, changed func(flag string) bool) agent.Branding {
+	localized := agent.DefaultBranding()
+	if !changed("error-prefix") {
+		branding.ErrorPrefix = localized.ErrorPrefix
+	}
+	if !changed("searching-message") {
+		branding.SearchingMessage = localized.SearchingMessage
+	}
+	if !changed("elaborating-message") {
+		branding.ElaboratingMessage = localized.ElaboratingMessage
+	}
+	if !changed("answer-intro") {
+		branding.AnswerIntro = localized.AnswerIntro
+	}
+	return branding
+}
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "slack-ai-assistant",
@@ -47,7 +403,7 @@ var rootCmd = &cobra.Command{
 	Long: `A Slack AI Assistant Bot that can respond to messages and interact with users.
 This bot uses socket mode for real-time communication with Slack.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		startSlackBot()
+		startSlackBot(cmd)
 	},
 }
 
@@ -58,13 +414,26 @@ func Execute() {
 	}
 }
 
-func startSlackBot() {
-	fmt.Printf("🚀 Starting Slack AI Assistant Bot with %d workers...\n", workers)
+func startSlackBot(cmd *cobra.Command) {
+	fmt.Printf("🚀 Starting Slack AI Assistant Bot with %d workers and queue size %d...\n", workers, queueSize)
 
 	if slackBotToken == "" || slackAppToken == "" {
 		log.Fatal("❌ Both bot-token and app-token are required")
 	}
 
+	if queueSize <= 0 {
+		log.Fatalf("❌ queue-size must be positive, got %d", queueSize)
+	}
+
+	if maxThreadContext < 0 {
+		log.Fatalf("❌ max-thread-context can't be negative, got %d", maxThreadContext)
+	}
+
+	if err := i18n.SetLocale(locale); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	branding = localizeBranding(branding, cmd.Flags().Changed)
+
 	// Create a context that can be canceled
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -73,17 +442,15 @@ func startSlackBot() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	// Start a goroutine to handle shutdown signals
-	go func() {
-		sig := <-sigChan
-		fmt.Printf("🛑 Received signal %v, shutting down gracefully...\n", sig)
-		cancel()
-	}()
+	// Start a goroutine to handle shutdown signals, it forces the exit when the workers don't stop in time
+	stopped := make(chan struct{})
+	defer close(stopped)
+	go awaitShutdown(sigChan, cancel, stopped, shutdownTimeout, os.Exit)
 
-	db, err := database.NewDatabase("slack-ai-assistant.db")
+	db, err := openDatabase(dbDriver, sqlitePragmas...)
 	if err != nil {
 		//nolint:gocritic // this is a critical error, so we should log it and exit
-		log.Fatalf("❌ Failed to create database: %v", err)
+		log.Fatalf("❌ %v", err)
 	}
 	defer func() {
 		if err := db.Close(); err != nil {
@@ -91,39 +458,222 @@ func startSlackBot() {
 		}
 	}()
 
-	// Auto migrate the database
-	err = db.AutoMigrate()
-	if err != nil {
-		//nolint:gocritic // this is a critical error, so we should log it and exit
-		log.Fatalf("❌ Failed to migrate database: %v", err)
-	}
-
 	appMentionChannel := make(chan *slackevents.AppMentionEvent, 100)
 	slashCommandChannel := make(chan *slack.SlashCommand, 100)
+	interactionChannel := make(chan *slack.InteractionCallback, 100)
+	appHomeChannel := make(chan *slackevents.AppHomeOpenedEvent, 100)
 
-	slackBot, err := slackbot.NewSlackBot(slackBotToken, slackAppToken, appMentionChannel, slashCommandChannel, debug)
+	slackBot, err := slackbot.NewSlackBot(slackBotToken, slackAppToken, appMentionChannel, slashCommandChannel, interactionChannel, appHomeChannel, slackAuthRetry, debug)
 	if err != nil {
 		//nolint:gocritic // this is a critical error, so we should log it and exit
 		log.Fatalf("❌ Failed to create Slack bot: %v", err)
 	}
+	slackBot.SetMaxMessageLength(maxMessageLength)
+	slackBot.SetTrigger(triggerPrefix, triggerChannels)
+	for _, token := range teamBotTokens {
+		if _, err := slackBot.AddTeam(token); err != nil {
+			log.Fatalf("❌ Failed to add Slack team: %v", err)
+		}
+	}
+
+	// Select the AI backends based on environment variables, AI_BACKEND answers at startup
+	aiBackend, backendNames, err := parseBackends(os.Getenv("AI_BACKEND"), os.Getenv("AI_BACKENDS"))
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	aliases, err := llm.NewWorkspaceAliases(workspaceAliases...)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	for _, alias := range aliases.List() {
+		fmt.Printf("🏷️ Workspace alias %s\n", alias)
+	}
+	backends := make([]llm.Backend, 0, len(backendNames))
+	for _, name := range backendNames {
+		backend, err := newBackend(ctx, name, elaborateWorkspace, aliases)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		backends = append(backends, backend)
+	}
+	fmt.Printf("🧠 Using %s backend\n", backends[0].Provider)
+	llmClient := backends[0].Client
+	statusInfo := agent.StatusInfo{Version: buildInfo(), LLMProvider: backends[0].Provider, LLMHost: backends[0].Host}
 
-	// Select AI backend based on environment variable
-	aiBackend := os.Getenv("AI_BACKEND")
-	var llmClient llm.Interface
-	if aiBackend == "llamaindex" {
-		fmt.Println("🧠 Using LlamaIndex backend")
-		llmClient = llm.NewLlamaIndexClient()
+	readOnly, err := parseProjectVersions("read-only project", readOnlyProjects)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	required, err := parseProjectVersions("required project", requiredProjects)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if err := validateProjects(ctx, llmClient, slices.Concat(required, readOnly), failOnMissingProject); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	pingCtx, cancelPing := context.WithTimeout(ctx, readinessTimeout)
+	if err := llmClient.Ping(pingCtx); err != nil {
+		fmt.Printf("⚠️ The %s backend at %s isn't reachable yet: %v\n", statusInfo.LLMProvider, statusInfo.LLMHost, err)
 	} else {
-		fmt.Println("🧠 Using AnythingLLM backend")
-		llmClient = llm.NewLLMClient()
+		fmt.Printf("✅ The %s backend is reachable\n", statusInfo.LLMProvider)
+	}
+	cancelPing()
+
+	// The limiters are inside the circuit breaker so short-circuited calls don't wait for a slot, an inject waits for
+	// an inject slot before taking an LLM call slot so the injects never hold more than their share of them
+	if llmConcurrency > 0 {
+		fmt.Printf("🚦 At most %d LLM calls in flight\n", llmConcurrency)
+	}
+	if maxConcurrentInjects > 0 {
+		fmt.Printf("🚦 At most %d injects in flight\n", maxConcurrentInjects)
+		if llmConcurrency > 0 && maxConcurrentInjects >= llmConcurrency {
+			fmt.Println("⚠️ --max-concurrent-injects isn't below --llm-concurrency, the injects may still hold back the answers")
+		}
+	}
+	if llmFailureThreshold > 0 {
+		fmt.Printf("🔌 LLM circuit breaker opens after %d consecutive failures for %s\n", llmFailureThreshold, llmCoolDown)
+	}
+	// Each backend has its own breaker, so switching away from a failing backend answers right away
+	for i := range backends {
+		if llmConcurrency > 0 {
+			backends[i].Client = llm.NewConcurrencyLimiter(backends[i].Client, llmConcurrency)
+		}
+		if maxConcurrentInjects > 0 {
+			backends[i].Client = llm.NewInjectLimiter(backends[i].Client, maxConcurrentInjects)
+		}
+		if llmFailureThreshold > 0 {
+			backends[i].Client = llm.NewCircuitBreaker(backends[i].Client, llmFailureThreshold, llmCoolDown)
+		}
+	}
+	var registry *llm.Registry
+	llmClient = backends[0].Client
+	if len(backends) > 1 {
+		registry, err = llm.NewRegistry(aiBackend, backends...)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		fmt.Printf("🔀 The admins can switch between the %s backends\n", strings.Join(backendNames, ", "))
+		llmClient = registry
 	}
 
-	agentProcess := agent.NewAgent(db, slackBot, llmClient, appMentionChannel, slashCommandChannel, workers)
+	agentProcess := agent.NewAgent(db, slackBot, llmClient, appMentionChannel, slashCommandChannel, interactionChannel, appHomeChannel, workers, queueSize)
+	agentProcess.SetStatusInfo(statusInfo)
+	if registry != nil {
+		agentProcess.SetBackends(registry)
+	}
+	agentProcess.SetElaborateWorkspace(elaborateWorkspace)
+	agentProcess.SetMaxThreadContext(maxThreadContext, keepFirstMessage)
+	agentProcess.AddBotUserIDs(botUserIDs...)
+	agentProcess.AddAdmins(adminUsers...)
+	if slices.ContainsFunc(backends, func(backend llm.Backend) bool { return backend.Provider == "AnythingLLM" }) {
+		agentProcess.SetWorkspaceAliases(aliases)
+	}
+	agentProcess.SetBranding(branding)
+	prompts, err := parseProjectPrompts(projectPrompts)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	agentProcess.SetProjectPrompts(prompts)
+	temperatures, err := parseCommandTemperatures(commandTemperatures)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if err := agentProcess.SetCommandTemperatures(temperatures); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if len(readOnly) > 0 {
+		fmt.Printf("🔒 %d read-only projects reject inject and export\n", len(readOnly))
+		agentProcess.SetReadOnlyProjects(readOnly...)
+	}
+	if count, err := agentProcess.RefreshProjects(ctx); err != nil {
+		fmt.Printf("⚠️ Failed to load the project list, every project will be accepted until a refresh succeeds: %v\n", err)
+	} else {
+		fmt.Printf("📚 Loaded %d projects\n", count)
+	}
+	if injectConfirmTimeout > 0 {
+		fmt.Printf("✋ Inject requires a confirmation within %s\n", injectConfirmTimeout)
+		agentProcess.EnableInjectConfirmation(injectConfirmTimeout)
+	}
+	if deletePlaceholder {
+		agentProcess.EnablePlaceholderCleanup()
+	}
+	if snippetThreshold > 0 {
+		fmt.Printf("📎 Answers longer than %d characters are uploaded as snippets\n", snippetThreshold)
+		agentProcess.SetSnippetThreshold(snippetThreshold)
+	}
+	if coalesceWindow > 0 {
+		fmt.Printf("🧲 Commands repeated in a thread within %s run only once\n", coalesceWindow)
+		agentProcess.SetCoalesceWindow(coalesceWindow)
+	}
+	if maxQueueWait > 0 {
+		fmt.Printf("⌛ Mentions waiting longer than %s in the queue are skipped\n", maxQueueWait)
+		agentProcess.SetMaxQueueWait(maxQueueWait, notifyExpired)
+	}
+	agentProcess.SetPostRetry(postAttempts, postRetryBackoff)
+	if answerChannel != "" {
+		if redirectAnswers {
+			fmt.Printf("📬 Answers are posted to channel %s instead of their thread\n", answerChannel)
+		} else {
+			fmt.Printf("📬 Answers are also posted to channel %s\n", answerChannel)
+		}
+		agentProcess.SetAnswerChannel(answerChannel, redirectAnswers)
+	}
+	if replayChannel != "" {
+		fmt.Printf("🔁 Replays are posted to channel %s\n", replayChannel)
+		agentProcess.SetReplayChannel(replayChannel)
+	}
+	if vision {
+		if aiBackend == "llamaindex" {
+			fmt.Println("⚠️ The LlamaIndex backend has no vision, the images of the questions aren't sent")
+		} else {
+			fmt.Println("🖼️ Sending the images of the questions to the LLM")
+			agentProcess.SetVision(true)
+		}
+	}
+	if durableQueue {
+		fmt.Println("💾 Using durable work queue")
+		agentProcess.EnableDurableQueue()
+	}
+	if metricsAddr != "" {
+		metricsServer := newMetricsServer(metricsAddr, agentProcess.Latencies(), llmClient.Ping)
+		go func() {
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				fmt.Printf("❌ Metrics server failed: %v\n", err)
+			}
+		}()
+		defer func() {
+			if err := metricsServer.Close(); err != nil {
+				log.Printf("Error closing metrics server: %v", err)
+			}
+		}()
+		fmt.Printf("📈 Serving the command latencies on %s/metrics and the readiness on %s/readyz\n", metricsAddr, metricsAddr)
+	}
 	fmt.Println("👋 Starting Slack AI Assistant Bot...")
 	agentProcess.Start(ctx)
 	fmt.Println("👋 Shutting down Slack AI Assistant Bot...")
 }
 
+// readinessTimeout bounds the LLM backend ping of the startup check and of the readiness probe
+const readinessTimeout = 5 * time.Second
+
+// newMetricsServer returns the server exposing the metrics handler on /metrics and the readiness probe on /readyz
+func newMetricsServer(addr string, metrics http.Handler, ping func(ctx context.Context) error) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics)
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+		defer cancel()
+		// The bot can't answer anything while the LLM backend is down
+		if err := ping(ctx); err != nil {
+			http.Error(w, fmt.Sprintf("LLM backend not reachable: %v", err), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+	return &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+}
+
 func main() {
 	Execute()
 }
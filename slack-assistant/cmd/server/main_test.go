@@ -0,0 +1,323 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+	"github.com/SchSeba/slack-ai-assistant/pkg/metrics"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+)
+
+func TestValidateElaborateWorkspace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockLLM := llmMock.NewMockInterface(ctrl)
+	mockLLM.EXPECT().ListProjects(gomock.Any()).Return([]string{"sriov-4-dot-16", "summaries"}, nil).Times(2)
+
+	if err := validateElaborateWorkspace(context.Background(), mockLLM, "summaries"); err != nil {
+		t.Errorf("Expected the configured workspace to be valid, got %v", err)
+	}
+
+	err := validateElaborateWorkspace(context.Background(), mockLLM, "elaborate")
+	if err == nil || !strings.Contains(err.Error(), `elaborate workspace "elaborate" doesn't exist`) {
+		t.Errorf("Expected a missing workspace error, got %v", err)
+	}
+}
+
+func TestValidateElaborateWorkspace_BackendUnavailable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockLLM := llmMock.NewMockInterface(ctrl)
+	mockLLM.EXPECT().ListProjects(gomock.Any()).Return(nil, errors.New("connection refused"))
+
+	if err := validateElaborateWorkspace(context.Background(), mockLLM, "elaborate"); err != nil {
+		t.Errorf("Expected an unreachable backend to be tolerated, got %v", err)
+	}
+}
+
+func TestValidateProjects(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockLLM := llmMock.NewMockInterface(ctrl)
+	mockLLM.EXPECT().ListProjects(gomock.Any()).Return([]string{"sriov-4-dot-16", "metallb-4-dot-18"}, nil).Times(3)
+	present := []agent.ProjectVersion{{Project: "sriov", Version: "4.16"}, {Project: "metallb", Version: "4.18"}}
+	missing := append(slices.Clone(present), agent.ProjectVersion{Project: "sriov", Version: "4.20"})
+
+	if err := validateProjects(context.Background(), mockLLM, present, true); err != nil {
+		t.Errorf("Expected the existing workspaces to be valid, got %v", err)
+	}
+
+	err := validateProjects(context.Background(), mockLLM, missing, true)
+	if err == nil || !strings.Contains(err.Error(), "the workspace of the projects sriov/4.20 doesn't exist") {
+		t.Errorf("Expected a missing workspace error, got %v", err)
+	}
+
+	if err := validateProjects(context.Background(), mockLLM, missing, false); err != nil {
+		t.Errorf("Expected a missing workspace to only be reported without fail fast, got %v", err)
+	}
+}
+
+func TestValidateProjects_NothingConfigured(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockLLM := llmMock.NewMockInterface(ctrl)
+	mockLLM.EXPECT().ListProjects(gomock.Any()).Times(0)
+
+	if err := validateProjects(context.Background(), mockLLM, nil, true); err != nil {
+		t.Errorf("Expected no error without configured projects, got %v", err)
+	}
+}
+
+func TestValidateProjects_BackendUnavailable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockLLM := llmMock.NewMockInterface(ctrl)
+	mockLLM.EXPECT().ListProjects(gomock.Any()).Return(nil, errors.New("connection refused"))
+
+	projects := []agent.ProjectVersion{{Project: "sriov", Version: "4.16"}}
+	if err := validateProjects(context.Background(), mockLLM, projects, true); err != nil {
+		t.Errorf("Expected an unreachable backend to be tolerated, got %v", err)
+	}
+}
+
+func TestDefaultElaborateWorkspace(t *testing.T) {
+	t.Setenv("ELABORATE_WORKSPACE", "")
+	if workspace := defaultElaborateWorkspace(); workspace != "elaborate" {
+		t.Errorf("Expected 'elaborate', got '%s'", workspace)
+	}
+
+	t.Setenv("ELABORATE_WORKSPACE", "summaries")
+	if workspace := defaultElaborateWorkspace(); workspace != "summaries" {
+		t.Errorf("Expected 'summaries', got '%s'", workspace)
+	}
+}
+
+func TestParseProjectPrompts(t *testing.T) {
+	prompts, err := parseProjectPrompts([]string{"sriov=Answer for cluster admins, with YAML examples", "metallb = Keep it short"})
+	if err != nil {
+		t.Fatalf("Expected the prompts to be valid, got %v", err)
+	}
+	if prompts["sriov"] != "Answer for cluster admins, with YAML examples" {
+		t.Errorf("Expected the sriov prompt to keep its commas, got '%s'", prompts["sriov"])
+	}
+	if prompts["metallb"] != "Keep it short" {
+		t.Errorf("Expected 'Keep it short', got '%s'", prompts["metallb"])
+	}
+
+	for _, value := range []string{"no prompt", "=orphan prompt"} {
+		if _, err := parseProjectPrompts([]string{value}); err == nil {
+			t.Errorf("Expected %q to be rejected", value)
+		}
+	}
+}
+
+func TestParseCommandTemperatures(t *testing.T) {
+	temperatures, err := parseCommandTemperatures([]string{"elaborate=0.9", " follow-up = 0.3 "})
+	if err != nil {
+		t.Fatalf("Expected the temperatures to be valid, got %v", err)
+	}
+	if temperatures["elaborate"] != 0.9 {
+		t.Errorf("Expected 0.9 for elaborate, got %v", temperatures["elaborate"])
+	}
+	if temperatures["follow-up"] != 0.3 {
+		t.Errorf("Expected 0.3 for follow-up, got %v", temperatures["follow-up"])
+	}
+
+	for _, value := range []string{"elaborate", "=0.5", "answer=hot"} {
+		if _, err := parseCommandTemperatures([]string{value}); err == nil {
+			t.Errorf("Expected %q to be rejected", value)
+		}
+	}
+}
+
+func TestParseBackends(t *testing.T) {
+	active, names, err := parseBackends("", "")
+	if err != nil || active != "anythingllm" || !reflect.DeepEqual(names, []string{"anythingllm"}) {
+		t.Errorf("Expected AnythingLLM alone by default, got %s %v %v", active, names, err)
+	}
+
+	active, names, err = parseBackends("llamaindex", " anythingllm, llamaindex ")
+	if err != nil || active != "llamaindex" || !reflect.DeepEqual(names, []string{"llamaindex", "anythingllm"}) {
+		t.Errorf("Expected LlamaIndex then AnythingLLM, got %s %v %v", active, names, err)
+	}
+
+	for _, backends := range [][2]string{{"openai", ""}, {"anythingllm", "llamaindex,openai"}} {
+		if _, _, err := parseBackends(backends[0], backends[1]); err == nil || !strings.Contains(err.Error(), "unknown LLM backend") {
+			t.Errorf("Expected %v to be rejected, got %v", backends, err)
+		}
+	}
+}
+
+func TestParseProjectVersions(t *testing.T) {
+	projects, err := parseProjectVersions("read-only project", []string{"sriov/4.16", " metallb/4.18 "})
+	if err != nil {
+		t.Fatalf("Expected the read-only projects to parse, got %v", err)
+	}
+	expected := []agent.ProjectVersion{{Project: "sriov", Version: "4.16"}, {Project: "metallb", Version: "4.18"}}
+	if !reflect.DeepEqual(projects, expected) {
+		t.Errorf("Expected %v, got %v", expected, projects)
+	}
+
+	for _, value := range []string{"sriov", "sriov/", "/4.16"} {
+		_, err := parseProjectVersions("read-only project", []string{value})
+		if err == nil || !strings.Contains(err.Error(), "invalid read-only project") {
+			t.Errorf("Expected %q to be rejected, got %v", value, err)
+		}
+	}
+}
+
+func TestOpenDatabase(t *testing.T) {
+	db, err := openDatabase("memory")
+	if err != nil {
+		t.Fatalf("Expected the memory database to open, got %v", err)
+	}
+	if _, ok := db.(*database.MemoryDatabase); !ok {
+		t.Errorf("Expected a memory database, got %T", db)
+	}
+
+	if _, err := openDatabase("postgres"); err == nil || !strings.Contains(err.Error(), `unknown database driver "postgres"`) {
+		t.Errorf("Expected an unknown driver error, got %v", err)
+	}
+}
+
+func TestNewMetricsServer(t *testing.T) {
+	latencies := metrics.NewLatencyRecorder()
+	latencies.Observe("answer", "sriov", time.Second)
+	server := newMetricsServer(":0", latencies, func(context.Context) error { return nil })
+
+	response := httptest.NewRecorder()
+	server.Handler.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+	if response.Code != http.StatusOK || !strings.Contains(response.Body.String(), `command="answer",project="sriov"`) {
+		t.Errorf("Expected the answer histogram, got %d: %s", response.Code, response.Body.String())
+	}
+
+	response = httptest.NewRecorder()
+	server.Handler.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	if response.Code != http.StatusNotFound {
+		t.Errorf("Expected only /metrics to be served, got %d", response.Code)
+	}
+}
+
+func TestNewMetricsServer_Readyz(t *testing.T) {
+	tests := []struct {
+		name string
+		ping error
+		code int
+	}{
+		{name: "healthy backend", code: http.StatusOK},
+		{name: "unreachable backend", ping: errors.New("connection refused"), code: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newMetricsServer(":0", metrics.NewLatencyRecorder(), func(context.Context) error { return tt.ping })
+
+			response := httptest.NewRecorder()
+			server.Handler.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/readyz", http.NoBody))
+			if response.Code != tt.code {
+				t.Errorf("Expected status %d, got %d: %s", tt.code, response.Code, response.Body.String())
+			}
+		})
+	}
+}
+
+func TestLocalizeBranding(t *testing.T) {
+	if err := i18n.SetLocale("es"); err != nil {
+		t.Fatalf("Expected the es locale to exist, got %v", err)
+	}
+	t.Cleanup(func() {
+		if err := i18n.SetLocale(i18n.DefaultLocale); err != nil {
+			t.Errorf("Failed to restore the default locale: %v", err)
+		}
+	})
+
+	branding := agent.Branding{SearchingMessage: "Searching for answer...", AnswerIntro: "Found it:", ElaboratingMessage: "Elaborating..."}
+	branding = localizeBranding(branding, func(flag string) bool { return flag == "answer-intro" })
+	if branding.SearchingMessage != "Buscando una respuesta..." {
+		t.Errorf("Expected the searching message in Spanish, got '%s'", branding.SearchingMessage)
+	}
+	if branding.AnswerIntro != "Found it:" {
+		t.Errorf("Expected the answer intro set with its flag to be kept, got '%s'", branding.AnswerIntro)
+	}
+}
+
+// slowWorkItem is a work item ignoring the cancellation, like a worker stuck in an LLM call
+type slowWorkItem struct {
+	started  chan struct{}
+	duration time.Duration
+}
+
+func (w slowWorkItem) Process(context.Context, *agent.Agent) error {
+	close(w.started)
+	time.Sleep(w.duration)
+	return nil
+}
+
+func (w slowWorkItem) String() string {
+	return "SlowWorkItem"
+}
+
+// shutdownWithSlowWorker runs awaitShutdown while a worker takes workDuration to finish its work item after
+// the signal, it returns the exit code awaitShutdown forced or -1 when the pool stopped in time
+func shutdownWithSlowWorker(t *testing.T, workDuration, timeout time.Duration) int {
+	t.Helper()
+	pool := agent.NewWorkerPool(1, 1)
+	pool.Start(nil)
+	item := slowWorkItem{started: make(chan struct{}), duration: workDuration}
+	if err := pool.Submit(item); err != nil {
+		t.Fatalf("Failed to submit the work item: %v", err)
+	}
+	<-item.started
+
+	signals := make(chan os.Signal, 1)
+	signals <- syscall.SIGTERM
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	exitCode := make(chan int, 1)
+	go func() {
+		<-ctx.Done()
+		pool.Stop()
+		close(stopped)
+	}()
+
+	awaitShutdown(signals, cancel, stopped, timeout, func(code int) { exitCode <- code })
+	select {
+	case code := <-exitCode:
+		return code
+	default:
+		return -1
+	}
+}
+
+func TestAwaitShutdown_WorkersStopInTime(t *testing.T) {
+	if code := shutdownWithSlowWorker(t, 10*time.Millisecond, time.Second); code != -1 {
+		t.Errorf("Expected the shutdown to wait for the worker, got exit code %d", code)
+	}
+}
+
+func TestAwaitShutdown_ForcesExitAfterTimeout(t *testing.T) {
+	start := time.Now()
+	if code := shutdownWithSlowWorker(t, time.Second, 50*time.Millisecond); code != 1 {
+		t.Errorf("Expected a forced exit with code 1, got %d", code)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected the exit once the timeout elapsed, took %s", elapsed)
+	}
+}
+
+func TestAwaitShutdown_ReturnsWhenStoppedWithoutSignal(t *testing.T) {
+	stopped := make(chan struct{})
+	close(stopped)
+	awaitShutdown(make(chan os.Signal), func() { t.Error("Expected no cancellation without a signal") }, stopped, time.Second, func(int) {
+		t.Error("Expected no exit without a signal")
+	})
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+
+	// Enable the --version flag on the root command
+	rootCmd.Version = buildInfo()
+	rootCmd.SetVersionTemplate("{{.Name}} {{.Version}}\n")
+}
+
+// versionCmd prints the build metadata injected via ldflags
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version information",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", rootCmd.Name(), buildInfo())
+	},
+}
+
+// buildInfo returns the version, git commit and build date of the running binary
+func buildInfo() string {
+	return fmt.Sprintf("%s (commit %s, built %s)", version, commit, buildTime)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestVersionCommand(t *testing.T) {
+	oldVersion, oldCommit, oldBuildTime := version, commit, buildTime
+	defer func() {
+		version, commit, buildTime = oldVersion, oldCommit, oldBuildTime
+	}()
+	version = "v1.2.3"
+	commit = "abc1234"
+	buildTime = "2025-07-29T10:30:00Z"
+
+	out := &bytes.Buffer{}
+	rootCmd.SetOut(out)
+	rootCmd.SetArgs([]string{"version"})
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+	}()
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("version command failed: %v", err)
+	}
+
+	expected := "slack-ai-assistant v1.2.3 (commit abc1234, built 2025-07-29T10:30:00Z)\n"
+	if out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestVersionFlag(t *testing.T) {
+	out := &bytes.Buffer{}
+	rootCmd.SetOut(out)
+	rootCmd.SetArgs([]string{"--version"})
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+	}()
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("--version flag failed: %v", err)
+	}
+
+	if !strings.HasPrefix(out.String(), "slack-ai-assistant dev (commit unknown") {
+		t.Errorf("Unexpected version output %q", out.String())
+	}
+}
@@ -11,7 +11,10 @@ require (
 	github.com/onsi/gomega v1.38.0
 	github.com/slack-go/slack v0.17.3
 	github.com/spf13/cobra v1.9.1
+	go.uber.org/goleak v1.3.0
 	go.uber.org/mock v0.5.2
+	golang.org/x/net v0.42.0
+	golang.org/x/sync v0.16.0
 	gorm.io/driver/sqlite v1.6.0
 	gorm.io/gorm v1.30.1
 )
@@ -28,7 +31,6 @@ require (
 	github.com/mattn/go-sqlite3 v1.14.22 // indirect
 	github.com/spf13/pflag v1.0.6 // indirect
 	go.uber.org/automaxprocs v1.6.0 // indirect
-	golang.org/x/net v0.42.0 // indirect
 	golang.org/x/sys v0.34.0 // indirect
 	golang.org/x/text v0.27.0 // indirect
 	golang.org/x/tools v0.35.0 // indirect
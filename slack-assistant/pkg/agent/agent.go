@@ -3,30 +3,103 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
 
 	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
 	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+	"github.com/SchSeba/slack-ai-assistant/pkg/metrics"
 	slackbot "github.com/SchSeba/slack-ai-assistant/pkg/slack-bot"
+	"github.com/SchSeba/slack-ai-assistant/pkg/webpage"
 )
 
+// DefaultElaborateWorkspace is the LLM workspace used by the elaborate command unless configured otherwise
+const DefaultElaborateWorkspace = "elaborate"
+
+// StatusInfo describes the running build and configuration reported by the status command
+type StatusInfo struct {
+	Version     string
+	LLMProvider string
+	LLMHost     string
+}
+
 type Agent struct {
 	db                  database.Interface
 	appMentionChannel   chan *slackevents.AppMentionEvent
 	slashCommandChannel chan *slack.SlashCommand
+	interactionChannel  chan *slack.InteractionCallback
+	appHomeChannel      chan *slackevents.AppHomeOpenedEvent
 	slackBot            slackbot.Interface
 	llmClient           llm.Interface
 	workerPool          *WorkerPool
+	durableQueue        bool
+	statusInfo          StatusInfo
+	elaborateWorkspace  string
+	maxThreadContext    int
+	keepFirstMessage    bool
+	// injectConfirmTimeout enables the inject confirmation when it is set
+	injectConfirmTimeout time.Duration
+	pendingMu            sync.Mutex
+	pendingInjects       map[string]*pendingInject
+	nextPendingID        uint64
+	startTime            time.Time
+	projectsMu           sync.RWMutex
+	knownProjects        map[string]struct{}
+	// botUserIDs are the additional user IDs the bot is mentioned with
+	botUserIDs []string
+	branding   Branding
+	// projectPrompts are the system prompts prepended to the questions, by project
+	projectPrompts map[string]string
+	// slugLocks make the workers of the same thread create its LLM threads only once
+	slugLocks threadLocks
+	// inFlight holds the cancel functions of the commands running in each thread
+	inFlight inFlightWork
+	// coalescer drops the commands repeated in a thread within the coalesce window
+	coalescer commandCoalescer
+	// deletePlaceholders deletes the searching message once the answer is posted
+	deletePlaceholders bool
+	// admins are the user IDs allowed to run the admin commands
+	admins []string
+	// latencies records how long the commands took by command and project
+	latencies *metrics.LatencyRecorder
+	// snippetThreshold uploads the longer answers as a snippet when it is set
+	snippetThreshold int
+	// pages fetches the pages injected from a URL
+	pages *webpage.Fetcher
+	// maxQueueWait skips the mentions queued for longer when it is set, telling their thread when notifyExpired is set
+	maxQueueWait  time.Duration
+	notifyExpired bool
+	// readOnlyProjects are the workspace slugs whose knowledge base can't be changed
+	readOnlyProjects map[string]struct{}
+	// vision sends the images attached to the questions to the LLM
+	vision bool
+	// postAttempts is how many times an answer is posted, waiting postRetryBackoff doubled between the attempts
+	postAttempts     int
+	postRetryBackoff time.Duration
+	// commandTemperatures are the LLM temperatures of the commands, overridden by the temperature of the thread
+	commandTemperatures map[string]float64
+	// answerChannel also gets the answers when it is set, only it gets them when redirectAnswers is set
+	answerChannel   string
+	redirectAnswers bool
+	// backends switches the LLM backend the llmClient answers with, nil when a single backend is configured
+	backends *llm.Registry
+	// replayChannel gets the replays of the admins when it is set, the channel of the command otherwise
+	replayChannel string
+	// aliases are the workspace aliases of the LLM client changed by the alias command, nil when it has none
+	aliases *llm.WorkspaceAliases
 }
 
-func NewAgent(db database.Interface, slackBot slackbot.Interface, llmClient llm.Interface, appMentionChannel chan *slackevents.AppMentionEvent, slashCommandChannel chan *slack.SlashCommand, workerCount int) *Agent {
+func NewAgent(db database.Interface, slackBot slackbot.Interface, llmClient llm.Interface, appMentionChannel chan *slackevents.AppMentionEvent, slashCommandChannel chan *slack.SlashCommand, interactionChannel chan *slack.InteractionCallback, appHomeChannel chan *slackevents.AppHomeOpenedEvent, workerCount, queueSize int) *Agent {
 	// Create worker pool with configurable size
-	// Queue size is set to 200 to handle bursts of events
-	workerPool := NewWorkerPool(workerCount, 200)
+	// Queue size allows to handle bursts of events
+	workerPool := NewWorkerPool(workerCount, queueSize)
 
 	return &Agent{
 		db:                  db,
@@ -34,22 +107,76 @@ func NewAgent(db database.Interface, slackBot slackbot.Interface, llmClient llm.
 		llmClient:           llmClient,
 		appMentionChannel:   appMentionChannel,
 		slashCommandChannel: slashCommandChannel,
+		interactionChannel:  interactionChannel,
+		appHomeChannel:      appHomeChannel,
 		workerPool:          workerPool,
+		elaborateWorkspace:  DefaultElaborateWorkspace,
+		maxThreadContext:    DefaultMaxThreadContext,
+		keepFirstMessage:    true,
+		startTime:           time.Now(),
+		branding:            DefaultBranding(),
+		latencies:           metrics.NewLatencyRecorder(),
+		pages:               newPageFetcher(),
+		postAttempts:        DefaultPostAttempts,
+		postRetryBackoff:    DefaultPostRetryBackoff,
+		commandTemperatures: make(map[string]float64),
 	}
 }
 
+// SetStatusInfo sets the build and configuration details reported by the status command
+func (a *Agent) SetStatusInfo(info StatusInfo) {
+	a.statusInfo = info
+}
+
+// SetElaborateWorkspace sets the LLM workspace used to elaborate messages
+func (a *Agent) SetElaborateWorkspace(workspace string) {
+	a.elaborateWorkspace = workspace
+}
+
+// WorkerPoolStats returns a snapshot of the agent worker pool
+func (a *Agent) WorkerPoolStats() WorkerPoolStats {
+	return a.workerPool.Stats()
+}
+
+// Start runs the agent until the context is canceled, it returns once the dispatcher and the workers exited
 func (a *Agent) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	// Start the worker pool
 	a.workerPool.Start(a)
+	if a.durableQueue {
+		a.recoverQueuedWork()
+	}
 
 	// Start the dispatcher goroutine that reads from channels and submits work
+	var wg sync.WaitGroup
+	wg.Add(1)
 	go func() {
+		defer wg.Done()
 		defer a.workerPool.Stop()
 		for {
 			select {
 			case event := <-a.appMentionChannel:
-				workItem := AppMentionWorkItem{Event: event}
-				a.workerPool.Submit(workItem)
+				workItem := AppMentionWorkItem{Event: event, EnqueuedAt: time.Now()}
+				if err := a.submit(workItem); errors.Is(err, ErrQueueFull) {
+					a.postBusy(event.Channel, threadTimestamp(event))
+				}
+			case callback := <-a.interactionChannel:
+				workItem := InteractionWorkItem{Callback: callback}
+				if err := a.submit(workItem); errors.Is(err, ErrQueueFull) {
+					a.postEphemeralBusy(callback.Channel.ID, callback.User.ID)
+				}
+			case command := <-a.slashCommandChannel:
+				workItem := SlashCommandWorkItem{Command: command}
+				// Response URLs expire after 30 minutes, so slash commands are never persisted by the durable queue
+				if err := a.workerPool.Submit(workItem); errors.Is(err, ErrQueueFull) {
+					a.respondBusy(command.ResponseURL)
+				}
+			case event := <-a.appHomeChannel:
+				workItem := AppHomeOpenedWorkItem{Event: event}
+				//nolint:errcheck // the home view is refreshed the next time the user opens the tab
+				_ = a.workerPool.Submit(workItem)
 			case <-ctx.Done():
 				fmt.Println("🛑 Agent dispatcher shutting down...")
 				return
@@ -58,105 +185,486 @@ func (a *Agent) Start(ctx context.Context) {
 	}()
 
 	a.slackBot.Start(ctx)
+
+	// Stop the dispatcher even if the bot returned on its own and wait for the workers to drain
+	cancel()
+	wg.Wait()
+	a.dropPendingInjects()
 }
 
-// handleAppMentionEvent is the internal implementation called by worker pool
-func (a *Agent) handleAppMentionEvent(event *slackevents.AppMentionEvent) error {
-	botUser := a.slackBot.GetBotUser()
-	fmt.Printf("🏷️ Bot mentioned: %s from user %s in channel %s\n",
-		event.Text, event.User, event.Channel)
+// postBusy tells the thread that the request was dropped because the bot is overloaded
+func (a *Agent) postBusy(channel, threadTS string) {
+	if err := a.slackBot.PostMessage(channel, threadTS, a.branding.busyMessage()); err != nil {
+		fmt.Printf("❌ Failed to post busy message: %v\n", err)
+	}
+}
+
+// postEphemeralBusy tells the user that the request was dropped because the bot is overloaded
+func (a *Agent) postEphemeralBusy(channel, userID string) {
+	if err := a.slackBot.PostEphemeral(channel, userID, a.branding.busyMessage()); err != nil {
+		fmt.Printf("❌ Failed to post busy message: %v\n", err)
+	}
+}
+
+// respondBusy tells the user of a slash command that the request was dropped because the bot is overloaded
+func (a *Agent) respondBusy(responseURL string) {
+	if err := a.slackBot.RespondViaResponseURL(responseURL, a.branding.busyMessage(), true); err != nil {
+		fmt.Printf("❌ Failed to post busy message: %v\n", err)
+	}
+}
 
-	// Extract bot's username and ID
-	botUsername := "slack-ai-assistant"
-	botUserID := ""
-	if botUser != nil {
-		botUsername = botUser.User
-		botUserID = botUser.UserID
+// threadTimestamp returns the thread of the event, or the event itself when it starts a new thread. A mention at the
+// top level of the channel has no ThreadTimeStamp, so its answer starts a thread on it and the mentions replying in
+// that thread carry its TimeStamp as ThreadTimeStamp, keeping the same LLM thread.
+func threadTimestamp(event *slackevents.AppMentionEvent) string {
+	if event.ThreadTimeStamp != "" {
+		return event.ThreadTimeStamp
 	}
-	fmt.Printf("🤖 Bot info - Username: %s, ID: %s\n", botUsername, botUserID)
+	return event.TimeStamp
+}
+
+// handleAppMentionEvent is the internal implementation called by worker pool, it returns how the command was handled
+func (a *Agent) handleAppMentionEvent(ctx context.Context, event *slackevents.AppMentionEvent) (*CommandResult, error) {
+	bot := a.botIdentity()
+	fmt.Printf("🏷️ Bot mentioned: %s from user %s in channel %s\n",
+		event.Text, a.userLabel(event.User), event.Channel)
+	fmt.Printf("🤖 Bot info - Names: %v, IDs: %v, Bot ID: %s\n", bot.Names, bot.UserIDs, bot.BotID)
 
 	// Determine the thread timestamp
-	var threadTS string
+	threadTS := threadTimestamp(event)
 	if event.ThreadTimeStamp != "" {
-		// This is already in a thread, use the existing thread timestamp
-		threadTS = event.ThreadTimeStamp
 		fmt.Printf("📎 Message is in an existing thread: %s\n", threadTS)
 	} else {
-		// This is a new message, use its timestamp to create a new thread
-		threadTS = event.TimeStamp
 		fmt.Printf("🆕 Creating new thread with timestamp: %s\n", threadTS)
 	}
 
 	// Check if we have parameters in the message
-	parameters := strings.Split(event.Text, " ")
+	parameters := stripSpecialMentions(strings.Split(event.Text, " "))
+	// The command follows the bot mention, which isn't always the first word
+	if index := bot.mentionIndex(parameters); index > 0 {
+		parameters = parameters[index:]
+	}
 	command := ""
 	if len(parameters) > 1 {
 		fmt.Printf("🔍 Parameters: %v\n", parameters)
 		command = parameters[1]
 	}
 
+	// A cancel must always run, even right after another one
+	if command != "cancel" && a.coalescer.duplicate(threadTS, parameters) {
+		fmt.Printf("🧲 Coalesced the repeated command %s in thread %s\n", command, threadTS)
+		return &CommandResult{Command: command, Outcome: CommandSkipped}, nil
+	}
+
+	ctx = a.mentionQuestion(ctx, event, command, parameters)
+
+	// Every command but cancel itself can be stopped by a cancel in the same thread
+	if command != "cancel" {
+		var done func()
+		ctx, done = a.inFlight.track(ctx, threadTS)
+		defer done()
+	}
+
+	start := time.Now()
+	outcome, err := a.runCommand(ctx, event.Channel, threadTS, event.User, command, parameters)
+	result := &CommandResult{Command: command, Outcome: outcome, Duration: time.Since(start)}
+	if err != nil {
+		result.Outcome = CommandFailed
+	}
+	a.observeLatency(command, parameters, result.Duration)
+	a.recordCommand(event, threadTS, command, parameters, err)
+	return result, err
+}
+
+// runCommand dispatches the parsed command to its handler and returns how it was handled
+func (a *Agent) runCommand(ctx context.Context, channel, threadTS, user, command string, parameters []string) (CommandOutcome, error) {
+	// answer! and answer-all! also broadcast the answer to the channel
+	broadcast := false
+	if base, ok := strings.CutSuffix(command, "!"); ok && (base == "answer" || base == "answer-all") {
+		command, broadcast = base, true
+	}
+	ctx = a.withCommandTemperature(ctx, command)
+
 	switch command {
 	case "answer":
 		if len(parameters) > 2 && len(parameters) < 4 {
-			return a.slackBot.PostMessage(event.Channel, threadTS, "To answer the question please provide the project name (example: sriov,metallb) and the openshift version (4.16,4.18, etc..)")
+			return a.usage(channel, user, "usage.answer")
+		}
+		if !a.isKnownProject(parameters[2], parameters[3]) {
+			return rejected(a.unknownProject(channel, user, parameters[2], parameters[3]))
 		}
-		return a.AnswerQuestion(event.Channel, threadTS, parameters[2], parameters[3], false)
+		return handled(a.AnswerQuestion(ctx, channel, threadTS, parameters[2], parameters[3], false, broadcast))
 	case "answer-all":
 		if len(parameters) > 2 && len(parameters) < 4 {
-			return a.slackBot.PostMessage(event.Channel, threadTS, "To answer the question please provide the project name (example: sriov,metallb) and the openshift version (4.16,4.18, etc..)")
+			return a.usage(channel, user, "usage.answer")
+		}
+		if !a.isKnownProject(parameters[2], parameters[3]) {
+			return rejected(a.unknownProject(channel, user, parameters[2], parameters[3]))
+		}
+		return handled(a.AnswerQuestion(ctx, channel, threadTS, parameters[2], parameters[3], true, broadcast))
+	case "answer-multi":
+		projects, ok := parseProjectVersions(parameters[2:])
+		if !ok {
+			return a.usage(channel, user, "usage.answerMulti")
 		}
-		return a.AnswerQuestion(event.Channel, threadTS, parameters[2], parameters[3], true)
+		for _, project := range projects {
+			if !a.isKnownProject(project.Project, project.Version) {
+				return rejected(a.unknownProject(channel, user, project.Project, project.Version))
+			}
+		}
+		return handled(a.AnswerMulti(ctx, channel, threadTS, projects))
 	case "inject":
 		if len(parameters) > 2 && len(parameters) < 4 {
-			return a.slackBot.PostMessage(event.Channel, threadTS, "To inject the last message in the thread please provide the project name (example: sriov,metallb) and the openshift version (4.16,4.18, etc..)")
+			return a.usage(channel, user, "usage.inject")
+		}
+		if !a.isKnownProject(parameters[2], parameters[3]) {
+			return rejected(a.unknownProject(channel, user, parameters[2], parameters[3]))
+		}
+		if a.isReadOnlyProject(parameters[2], parameters[3]) {
+			return rejected(a.readOnlyProject(channel, user, parameters[2], parameters[3]))
+		}
+		if pageURL := injectURL(parameters[4:]); pageURL != "" {
+			return handled(a.InjectURL(ctx, channel, threadTS, user, parameters[2], parameters[3], pageURL))
+		}
+		split := hasModifier(parameters[4:], "split")
+		if a.injectConfirmTimeout > 0 {
+			return handled(a.RequestInjectConfirmation(channel, threadTS, user, parameters[2], parameters[3], split))
+		}
+		return handled(a.Inject(ctx, channel, threadTS, parameters[2], parameters[3], split))
+	case "export":
+		if len(parameters) < 4 {
+			return a.usage(channel, user, "usage.export")
+		}
+		if !a.isKnownProject(parameters[2], parameters[3]) {
+			return rejected(a.unknownProject(channel, user, parameters[2], parameters[3]))
 		}
-		return a.Inject(event.Channel, threadTS, parameters[2], parameters[3])
+		if a.isReadOnlyProject(parameters[2], parameters[3]) {
+			return rejected(a.readOnlyProject(channel, user, parameters[2], parameters[3]))
+		}
+		return handled(a.Export(ctx, channel, threadTS, parameters[2], parameters[3]))
+	case "docs":
+		if len(parameters) < 4 {
+			return a.usage(channel, user, "usage.docs")
+		}
+		if !a.isKnownProject(parameters[2], parameters[3]) {
+			return rejected(a.unknownProject(channel, user, parameters[2], parameters[3]))
+		}
+		return handled(a.Docs(ctx, channel, threadTS, parameters[2], parameters[3]))
+	case "debug", "whoami":
+		return handled(a.Debug(channel, threadTS, user))
+	case "context":
+		return handled(a.RetrievedContext(channel, threadTS, user))
+	case "alias":
+		return handled(a.Alias(ctx, channel, user, parameters[2:]))
+	case "replay":
+		target := ""
+		if len(parameters) > 2 {
+			target = parameters[2]
+		}
+		return handled(a.Replay(ctx, channel, user, target))
+	case "backends":
+		return handled(a.Backends(channel, user))
+	case "backend":
+		name := ""
+		if len(parameters) > 2 {
+			name = parameters[2]
+		}
+		return handled(a.Backend(channel, user, name))
 	case "elaborate":
-		return a.Elaborate(event.Channel, threadTS)
+		return handled(a.Elaborate(ctx, channel, threadTS))
+	case "mode":
+		mode := ""
+		if len(parameters) > 2 {
+			mode = parameters[2]
+		}
+		return handled(a.Mode(channel, threadTS, user, mode))
+	case "topk":
+		value := ""
+		if len(parameters) > 2 {
+			value = parameters[2]
+		}
+		return handled(a.TopK(channel, threadTS, user, value))
+	case "temperature":
+		value := ""
+		if len(parameters) > 2 {
+			value = parameters[2]
+		}
+		return handled(a.Temperature(channel, threadTS, user, value))
+	case "strict":
+		value := ""
+		if len(parameters) > 2 {
+			value = parameters[2]
+		}
+		return handled(a.Strict(channel, threadTS, user, value))
+	case "memory":
+		value := ""
+		if len(parameters) > 2 {
+			value = parameters[2]
+		}
+		return handled(a.Memory(channel, threadTS, user, value))
+	case "cancel":
+		return handled(a.Cancel(channel, threadTS, user))
+	case "pin":
+		return handled(a.Pin(channel, threadTS, user))
+	case "unpin":
+		return handled(a.Unpin(channel, threadTS, user))
+	case "retry":
+		return handled(a.Retry(ctx, channel, threadTS, user))
+	case "continue":
+		return handled(a.Continue(ctx, channel, threadTS, user))
+	case "status":
+		return handled(a.Status(channel, threadTS))
+	case "refresh":
+		return handled(a.Refresh(ctx, channel, user))
+	case "threads":
+		page, ok := threadsPage(parameters)
+		if !ok {
+			return a.usage(channel, user, "usage.threads")
+		}
+		return handled(a.Threads(channel, user, page))
+	case "correct":
+		correction := ""
+		if len(parameters) > 2 {
+			correction = strings.TrimSpace(a.sanitize(strings.Join(parameters[2:], " ")))
+		}
+		if correction == "" {
+			return a.usage(channel, user, "usage.correct")
+		}
+		threadContext := a.getThreadContext(threadTS)
+		if threadContext == nil {
+			return a.usage(channel, user, "correct.noProject")
+		}
+		if a.isReadOnlyProject(threadContext.Project, threadContext.Version) {
+			return rejected(a.readOnlyProject(channel, user, threadContext.Project, threadContext.Version))
+		}
+		return handled(a.Correct(ctx, channel, threadTS, user, threadContext, correction))
+	case "history":
+		count, page, ok := historyArguments(parameters)
+		if !ok {
+			return a.usage(channel, user, "usage.history")
+		}
+		return handled(a.History(channel, user, count, page))
+	case "switch":
+		if len(parameters) < 4 {
+			return a.usage(channel, user, "usage.switch")
+		}
+		if !a.isKnownProject(parameters[2], parameters[3]) {
+			return rejected(a.unknownProject(channel, user, parameters[2], parameters[3]))
+		}
+		return handled(a.Switch(ctx, channel, threadTS, user, parameters[2], parameters[3]))
+	default:
+		// A bare mention in a thread that already has a project and version is a follow-up question
+		if threadContext := a.getThreadContext(threadTS); threadContext != nil && len(parameters) > 1 {
+			return handled(a.FollowUp(a.withCommandTemperature(ctx, followUpCommand), channel, threadTS, threadContext, strings.Join(parameters[1:], " ")))
+		}
 	}
 
-	return a.slackBot.PostMessage(event.Channel, threadTS, "Please use one of the following commands (answer,elaborate,inject)")
+	return a.usage(channel, user, "usage.commands")
 }
 
-func (a *Agent) AnswerQuestion(channel, threadTS, project, version string, fullThread bool) error {
-	if err := a.slackBot.PostMessage(channel, threadTS, "Searching for answer..."); err != nil {
-		return fmt.Errorf("failed to post initial message: %w", err)
+// hasModifier reports whether the command parameters contain the --name modifier,
+// dashes are optional because Slack clients may turn "--" into an em dash
+func hasModifier(parameters []string, name string) bool {
+	for _, parameter := range parameters {
+		if strings.TrimLeft(parameter, "-—") == name {
+			return true
+		}
+	}
+	return false
+}
+
+// recordCommand stores an audit entry for the command, failures are logged but never returned
+func (a *Agent) recordCommand(event *slackevents.AppMentionEvent, threadTS, command string, parameters []string, cmdErr error) {
+	args := ""
+	if len(parameters) > 2 {
+		args = strings.Join(parameters[2:], " ")
+	}
+
+	audit := &database.CommandAudit{
+		User:        event.User,
+		Channel:     event.Channel,
+		SlackThread: threadTS,
+		Command:     command,
+		Args:        args,
+		Success:     cmdErr == nil,
+	}
+	if cmdErr != nil {
+		audit.Error = cmdErr.Error()
+	}
+
+	if err := a.db.RecordCommand(audit); err != nil {
+		fmt.Printf("❌ Failed to record command audit: %v\n", err)
+	}
+}
+
+// interactionThread returns the channel and thread of the message holding the clicked button
+func interactionThread(callback *slack.InteractionCallback) (channel, threadTS string) {
+	channel = callback.Channel.ID
+	if channel == "" {
+		channel = callback.Container.ChannelID
+	}
+	threadTS = callback.Message.ThreadTimestamp
+	if threadTS == "" {
+		threadTS = callback.Message.Timestamp
+	}
+	return channel, threadTS
+}
+
+// threadGone ends a work item whose thread was deleted while it was processed: nothing more can be posted,
+// so the thread mapping is dropped and the work item is not reported as failed
+func (a *Agent) threadGone(threadTS string, err error) error {
+	if !errors.Is(err, slackbot.ErrThreadGone) {
+		return err
+	}
+
+	fmt.Printf("🗑️ Thread %s was deleted while processing it: %v\n", threadTS, err)
+	if err := a.db.DeleteThread(threadTS); err != nil {
+		fmt.Printf("❌ Failed to delete thread from database: %v\n", err)
+	}
+	return nil
+}
+
+// handleInteraction is the internal implementation for answer button clicks and modal submissions called by worker pool
+func (a *Agent) handleInteraction(ctx context.Context, callback *slack.InteractionCallback) error {
+	if callback.Type == slack.InteractionTypeViewSubmission {
+		return a.handleModalSubmission(ctx, callback)
+	}
+	channel, threadTS := interactionThread(callback)
+
+	for _, action := range callback.ActionCallback.BlockActions {
+		fmt.Printf("🖱️ Button %s clicked by user %s in channel %s\n", action.ActionID, a.userLabel(callback.User.ID), channel)
+		switch action.ActionID {
+		case slackbot.ActionElaborate:
+			return a.ElaborateMessage(a.withCommandTemperature(ctx, "elaborate"), channel, threadTS, slackbot.AnswerText(callback.Message.Blocks))
+		case slackbot.ActionSources:
+			return a.Sources(channel, callback.User.ID, slackbot.AnswerSources(callback.Message.Blocks))
+		case slackbot.ActionInjectConfirm:
+			return a.ConfirmInject(ctx, channel, callback.User.ID, action.Value)
+		case slackbot.ActionInjectCancel:
+			return a.CancelInject(channel, callback.User.ID, action.Value)
+		}
+	}
+
+	return fmt.Errorf("unsupported interaction in channel %s", channel)
+}
+
+// AnswerQuestion answers the last message, or the whole thread with fullThread,
+// the answer is also shown in the channel when broadcast is set
+func (a *Agent) AnswerQuestion(ctx context.Context, channel, threadTS, project, version string, fullThread, broadcast bool) error {
+	placeholder, err := a.postPlaceholder(channel, threadTS)
+	if err != nil {
+		return err
+	}
+
+	messages, images, err := a.getMessages(ctx, channel, threadTS, fullThread)
+	if err != nil {
+		return err
+	}
+
+	slug, err := a.getOrCreateSlug(ctx, threadTS, project, version)
+	if err != nil {
+		a.postUnavailable(channel, threadTS, err)
+		return err
+	}
+	// Remember the question so retry can ask it again
+	if err := a.db.SetLastQuestion(threadTS, messages); err != nil {
+		fmt.Printf("⚠️ Failed to store the last question of thread %s: %v\n", threadTS, err)
+	}
+	threadContext := a.getThreadContext(threadTS)
+	if slug, err = a.questionSlug(ctx, threadTS, threadContext, slug); err != nil {
+		a.postUnavailable(channel, threadTS, err)
+		return err
+	}
+	ctx = withThreadSettings(ctx, threadContext)
+	if len(images) > 0 {
+		ctx = llm.WithImages(ctx, images)
 	}
 
-	messages, err := a.getMessages(channel, threadTS, fullThread)
+	return a.deletePlaceholder(channel, placeholder, a.generateAndPostResponse(ctx, channel, threadTS, project, version, slug, messages, broadcast))
+}
+
+// FollowUp answers a question asked in a thread using the project, version and slug stored for the thread
+func (a *Agent) FollowUp(ctx context.Context, channel, threadTS string, threadContext *database.SlackThreadToSlug, question string) error {
+	placeholder, err := a.postPlaceholder(channel, threadTS)
 	if err != nil {
 		return err
 	}
 
-	slug, err := a.getOrCreateSlug(threadTS, project, version)
+	slug, err := a.questionSlug(ctx, threadTS, threadContext, threadContext.ThreadSlug)
 	if err != nil {
+		a.postUnavailable(channel, threadTS, err)
 		return err
 	}
+	return a.deletePlaceholder(channel, placeholder, a.generateAndPostResponse(withThreadSettings(ctx, threadContext), channel, threadTS, threadContext.Project, threadContext.Version, slug, question, false))
+}
+
+// Switch moves the thread to another project and version, with a new LLM thread in the matching workspace
+func (a *Agent) Switch(ctx context.Context, channel, threadTS, user, project, version string) error {
+	if a.getThreadContext(threadTS) == nil {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("switch.noProject"))
+	}
+
+	slug, err := a.llmClient.CreateThread(ctx, project, version)
+	if err != nil {
+		fmt.Printf("❌ Failed to create thread: %v\n", err)
+		return fmt.Errorf("failed to create thread: %w", err)
+	}
+
+	if err := a.db.UpdateThreadContext(threadTS, slug, project, version); err != nil {
+		fmt.Printf("❌ Failed to update thread context in database: %v\n", err)
+		return fmt.Errorf("failed to update thread context in database: %w", err)
+	}
 
-	return a.generateAndPostResponse(channel, threadTS, project, version, slug, messages)
+	if err := a.slackBot.PostMessage(channel, threadTS, i18n.T("switch.done", project, version)); err != nil {
+		return fmt.Errorf("failed to send switch confirmation: %w", err)
+	}
+	return nil
+}
+
+// getThreadContext returns the context stored for the thread, or nil if there is none
+func (a *Agent) getThreadContext(threadTS string) *database.SlackThreadToSlug {
+	threadContext, exist, err := a.db.GetThreadContext(threadTS)
+	if err != nil {
+		fmt.Printf("❌ Failed to get thread context from database: %v\n", err)
+		return nil
+	}
+	if !exist || threadContext.Project == "" {
+		return nil
+	}
+	return threadContext
 }
 
 // getMessages retrieves messages from the thread based on fullThread flag
-func (a *Agent) getMessages(channel, threadTS string, fullThread bool) (string, error) {
+func (a *Agent) getMessages(ctx context.Context, channel, threadTS string, fullThread bool) (string, []llm.Image, error) {
+	if question := inlineQuestionFrom(ctx); question != "" {
+		return question, nil, nil
+	}
 	if fullThread {
-		messages, err := a.getThreadMessages(channel, threadTS)
+		messages, err := a.getThreadMessages(ctx, channel, threadTS)
 		if err != nil {
 			fmt.Printf("❌ Failed to get thread messages: %v\n", err)
-			return "", fmt.Errorf("failed to get thread messages: %w", err)
+			return "", nil, fmt.Errorf("failed to get thread messages: %w", err)
 		}
-		return messages, nil
+		return messages, nil, nil
 	}
 
-	messages, err := a.getLastMessageInThread(channel, threadTS)
+	message, err := a.getLastMessageInThread(channel, threadTS)
+	if errors.Is(err, ErrNoQuestion) {
+		return "", nil, a.noQuestion(channel, threadTS)
+	}
 	if err != nil {
 		fmt.Printf("❌ Failed to get last message in thread: %v\n", err)
-		return "", fmt.Errorf("failed to get last message in thread: %w", err)
+		return "", nil, fmt.Errorf("failed to get last message in thread: %w", err)
 	}
-	return messages, nil
+	return a.sanitize(message.Text), a.questionImages(channel, threadTS, message), nil
 }
 
 // getOrCreateSlug retrieves an existing slug or creates a new one
-func (a *Agent) getOrCreateSlug(threadTS, project, version string) (string, error) {
+func (a *Agent) getOrCreateSlug(ctx context.Context, threadTS, project, version string) (string, error) {
+	// Two mentions in a new thread would otherwise both create a thread and one would fail to store it
+	unlock := a.slugLocks.lock(threadTS)
+	defer unlock()
+
 	slug, exist, err := a.db.GetSlugForThread(threadTS)
 	if err != nil {
 		fmt.Printf("❌ Failed to get slug for thread from database: %v\n", err)
@@ -167,13 +675,13 @@ func (a *Agent) getOrCreateSlug(threadTS, project, version string) (string, erro
 		return slug, nil
 	}
 
-	slug, err = a.llmClient.CreateThread(project, version)
+	slug, err = a.llmClient.CreateThread(ctx, project, version)
 	if err != nil {
 		fmt.Printf("❌ Failed to create thread: %v\n", err)
 		return "", fmt.Errorf("failed to create thread: %w", err)
 	}
 
-	if err = a.db.CreateSlackThreadWithSlug(threadTS, slug); err != nil {
+	if err = a.db.CreateSlackThreadWithSlug(threadTS, slug, project, version); err != nil {
 		fmt.Printf("❌ Failed to create slack thread in database: %v\n", err)
 		return "", fmt.Errorf("failed to create slack thread in database: %w", err)
 	}
@@ -181,25 +689,91 @@ func (a *Agent) getOrCreateSlug(threadTS, project, version string) (string, erro
 	return slug, nil
 }
 
+// getOrCreateElaborateSlug retrieves the elaborate thread of the Slack thread or creates it on first use,
+// so every elaboration in a thread shares the same conversation
+func (a *Agent) getOrCreateElaborateSlug(ctx context.Context, threadTS string) (string, error) {
+	unlock := a.slugLocks.lock(threadTS)
+	defer unlock()
+
+	slug, exist, err := a.db.GetElaborateSlugForThread(threadTS)
+	if err != nil {
+		fmt.Printf("❌ Failed to get elaborate slug for thread from database: %v\n", err)
+		return "", fmt.Errorf("failed to get elaborate slug for thread from database: %w", err)
+	}
+
+	if exist {
+		return slug, nil
+	}
+
+	slug, err = a.llmClient.CreateThread(ctx, a.elaborateWorkspace, "")
+	if err != nil {
+		fmt.Printf("❌ Failed to create thread: %v\n", err)
+		return "", fmt.Errorf("failed to create thread: %w", err)
+	}
+
+	// The thread still works without being stored, it just won't be reused
+	if err = a.db.SetElaborateSlugForThread(threadTS, slug); err != nil {
+		fmt.Printf("❌ Failed to store elaborate slug in database: %v\n", err)
+	}
+
+	return slug, nil
+}
+
 // generateAndPostResponse generates a response from LLM and posts it to Slack
-func (a *Agent) generateAndPostResponse(channel, threadTS, project, version, slug, messages string) error {
-	response, err := a.llmClient.SendMessageToChat(project, version, slug, messages)
+func (a *Agent) generateAndPostResponse(ctx context.Context, channel, threadTS, project, version, slug, messages string, broadcast bool) error {
+	prompt := a.withProjectPrompt(project, messages)
+	response, answeredSlug, err := a.askInThread(ctx, threadTS, project, version, slug, prompt)
+	if errors.Is(err, llm.ErrNoInformation) {
+		fmt.Printf("🔍 No relevant document in %s %s to answer thread %s strictly\n", project, version, threadTS)
+		return a.postNoInformation(channel, threadTS, project, version)
+	}
 	if err != nil {
-		fmt.Printf("❌ Failed to generate response: %v\n", err)
-		if postErr := a.slackBot.PostMessage(channel, threadTS, fmt.Sprintf("❌ Error: %v", err)); postErr != nil {
-			fmt.Printf("❌ Failed to post error message: %v\n", postErr)
-		}
+		a.postFailure(channel, threadTS, "generate response", err)
 		return fmt.Errorf("failed to generate response: %w", err)
 	}
 
-	if err = a.slackBot.PostMessage(channel, threadTS, fmt.Sprintf("Here is the information I was able to find\n%s", response)); err != nil {
+	if err := a.postThreadAnswer(ctx, channel, threadTS, a.branding.AnswerIntro, response, a.answerSources(answeredSlug), broadcast); err != nil {
+		return err
+	}
+	a.rememberTruncation(channel, threadTS, response)
+	return nil
+}
+
+// postAnswer posts the answer as Block Kit blocks with its sources for the Sources button, falling back to plain
+// text if the blocks are rejected, broadcast also shows the answer in the channel. Answers over the snippet threshold
+// are uploaded as a snippet, which can't be broadcast.
+func (a *Agent) postAnswer(channel, threadTS, intro, answer string, sources []string, broadcast bool) error {
+	if posted, err := a.postSnippet(channel, threadTS, intro, answer); posted || err != nil {
+		return err
+	}
+
+	var options []slackbot.MessageOption
+	if broadcast {
+		options = append(options, slackbot.OptionBroadcast)
+	}
+
+	blocks := slackbot.BuildAnswerBlocks(intro, answer, sources, a.branding.AnswerFooter)
+	err := a.slackBot.PostBlocks(channel, threadTS, blocks, options...)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, slackbot.ErrThreadGone) {
+		return fmt.Errorf("failed to send response: %w", err)
+	}
+
+	fmt.Println("⚠️ Falling back to plain text answer")
+	text := answer
+	if intro != "" {
+		text = fmt.Sprintf("%s\n%s", intro, answer)
+	}
+	if err := a.slackBot.PostMessage(channel, threadTS, a.branding.withFooter(text), options...); err != nil {
 		return fmt.Errorf("failed to send response: %w", err)
 	}
 	return nil
 }
 
-func (a *Agent) Elaborate(channel, threadTS string) error {
-	err := a.slackBot.PostMessage(channel, threadTS, "Elaborating...")
+func (a *Agent) Elaborate(ctx context.Context, channel, threadTS string) error {
+	err := a.slackBot.PostMessage(channel, threadTS, a.branding.ElaboratingMessage)
 	if err != nil {
 		return fmt.Errorf("failed to post initial message: %w", err)
 	}
@@ -209,23 +783,37 @@ func (a *Agent) Elaborate(channel, threadTS string) error {
 		fmt.Printf("❌ Failed to get last message in thread: %v\n", err)
 		return fmt.Errorf("failed to get last message in thread: %w", err)
 	}
-	slug, err := a.llmClient.CreateThread("elaborate", "")
+
+	return a.elaborate(ctx, channel, threadTS, a.sanitize(lastMessage.Text))
+}
+
+// ElaborateMessage expands the given message, used when the answer "Elaborate" button is clicked
+func (a *Agent) ElaborateMessage(ctx context.Context, channel, threadTS, message string) error {
+	if err := a.slackBot.PostMessage(channel, threadTS, a.branding.ElaboratingMessage); err != nil {
+		return fmt.Errorf("failed to post initial message: %w", err)
+	}
+
+	return a.elaborate(ctx, channel, threadTS, message)
+}
+
+// elaborate sends the message to the elaborate workspace and posts the response
+func (a *Agent) elaborate(ctx context.Context, channel, threadTS, message string) error {
+	slug, err := a.getOrCreateElaborateSlug(ctx, threadTS)
 	if err != nil {
-		fmt.Printf("❌ Failed to create thread: %v\n", err)
-		return fmt.Errorf("failed to create thread: %w", err)
+		a.postUnavailable(channel, threadTS, err)
+		return err
 	}
 
-	response, err := a.llmClient.Elaborate(slug, lastMessage)
+	response, err := chatRecovering(slug, func(slug string) (string, error) {
+		return a.llmClient.Elaborate(ctx, a.elaborateWorkspace, slug, message)
+	}, func(expiredSlug string) (string, error) {
+		return a.replaceElaborateSlug(ctx, threadTS, expiredSlug)
+	})
 	if err != nil {
-		fmt.Printf("❌ Failed to generate response: %v\n", err)
-		// Send error message to user
-		postErr := a.slackBot.PostMessage(channel, threadTS, fmt.Sprintf("❌ Error: %v", err))
-		if postErr != nil {
-			fmt.Printf("❌ Failed to post error message: %v\n", postErr)
-		}
+		a.postFailure(channel, threadTS, "generate response", err)
 		return fmt.Errorf("failed to generate response: %w", err)
 	}
-	err = a.slackBot.PostMessage(channel, threadTS, response)
+	err = a.slackBot.PostMessage(channel, threadTS, a.branding.withFooter(response))
 	if err != nil {
 		return fmt.Errorf("failed to send response: %w", err)
 	}
@@ -233,33 +821,200 @@ func (a *Agent) Elaborate(channel, threadTS string) error {
 	return nil
 }
 
-func (a *Agent) Inject(channel, threadTS, project, version string) error {
-	messages, err := a.getLastMessagesFromTheSameUser(channel, threadTS)
+// postUnavailable tells the user the LLM backend is down when the error is a temporary outage,
+// other errors are only returned as before
+func (a *Agent) postUnavailable(channel, threadTS string, err error) {
+	if ClassifyError(err) != UnavailableError {
+		return
+	}
+	if postErr := a.slackBot.PostMessage(channel, threadTS, a.branding.errorMessage(err)); postErr != nil {
+		fmt.Printf("❌ Failed to post error message: %v\n", postErr)
+	}
+}
+
+// Status posts the bot uptime, version, LLM backend and worker pool state
+func (a *Agent) Status(channel, threadTS string) error {
+	if err := a.slackBot.PostMessage(channel, threadTS, a.statusMessage()); err != nil {
+		return fmt.Errorf("failed to send status: %w", err)
+	}
+	return nil
+}
+
+// statusMessage formats the bot uptime, version, LLM backend and worker pool state
+func (a *Agent) statusMessage() string {
+	stats := a.WorkerPoolStats()
+	provider, host := a.llmBackend()
+	return i18n.T("status.message",
+		a.statusInfo.Version,
+		time.Since(a.startTime).Round(time.Second),
+		provider, host,
+		stats.Workers,
+		stats.QueueDepth, stats.QueueCapacity)
+}
+
+// Sources posts the sources of an answer to the user that clicked the answer "Sources" button
+func (a *Agent) Sources(channel, userID string, sources []string) error {
+	message := i18n.T("sources.none")
+	if len(sources) > 0 {
+		message = i18n.T("sources.list", strings.Join(sources, "\n• "))
+	}
+
+	if err := a.slackBot.PostEphemeral(channel, userID, message); err != nil {
+		return fmt.Errorf("failed to send sources: %w", err)
+	}
+	return nil
+}
+
+// answerSources returns the distinct titles of the documents the last answer of the LLM thread was retrieved from
+func (a *Agent) answerSources(slug string) []string {
+	var titles []string
+	seen := make(map[string]bool)
+	for _, chunk := range a.llmClient.LastContext(slug) {
+		if chunk.Title == "" || seen[chunk.Title] {
+			continue
+		}
+		seen[chunk.Title] = true
+		titles = append(titles, chunk.Title)
+	}
+	return titles
+}
+
+// Inject adds the last messages of the user to the project and version knowledge base,
+// as a single document or as one document per message when split is set
+func (a *Agent) Inject(ctx context.Context, channel, threadTS, project, version string, split bool) error {
+	var (
+		messages []string
+		source   string
+	)
+	err := lookupConcurrently(ctx,
+		func() error {
+			var err error
+			messages, err = a.getLastMessagesFromTheSameUser(channel, threadTS)
+			return err
+		},
+		func() error {
+			source = a.threadPermalink(channel, threadTS)
+			return nil
+		},
+	)
+	// An empty document would still be sent to the LLM, the thread is told there is nothing to inject instead
+	if errors.Is(err, ErrNoQuestion) || (err == nil && strings.TrimSpace(strings.Join(messages, "")) == "") {
+		return a.slackBot.PostMessage(channel, threadTS, i18n.T("inject.nothing"))
+	}
 	if err != nil {
 		fmt.Printf("❌ Failed to get thread messages: %v\n", err)
 		return fmt.Errorf("failed to get thread messages: %w", err)
 	}
 
-	err = a.llmClient.Inject(project, version, messages)
+	return a.injectMessages(ctx, channel, threadTS, project, version, source, messages, split)
+}
+
+// injectMessages adds the messages to the project and version knowledge base with the thread permalink as source
+// and reports the result in the thread
+func (a *Agent) injectMessages(ctx context.Context, channel, threadTS, project, version, source string, messages []string, split bool) error {
+	metadata := llm.DocumentMetadata{Source: source}
+	docs := make([]llm.Document, 0, len(messages))
+	if split {
+		for _, message := range messages {
+			if strings.TrimSpace(message) != "" {
+				docs = append(docs, llm.Document{Content: message, Metadata: metadata})
+			}
+		}
+	} else {
+		docs = append(docs, llm.Document{Content: strings.Join(messages, ""), Metadata: metadata})
+	}
+
+	// The inject is retried for a while on transient failures, the thread is told so it doesn't look stuck
+	ctx = llm.WithInjectRetryNotifier(ctx, func(attempt, maxAttempts int, wait time.Duration) {
+		if err := a.slackBot.PostMessage(channel, threadTS, withEmoji(a.branding.ProcessingEmoji, i18n.T("inject.retrying", wait, attempt, maxAttempts))); err != nil {
+			fmt.Printf("❌ Failed to post the inject retry: %v\n", err)
+		}
+	})
+
+	// The LLM injects first so the database isn't locked while it retries, a failed inject leaves no record.
+	// The LLM can't roll back, a record failing after it is reported on its own.
+	var err error
+	if split {
+		err = a.llmClient.InjectBatch(ctx, project, version, docs)
+	} else {
+		err = a.llmClient.Inject(ctx, project, version, docs[0].Content, metadata)
+	}
 	if err != nil {
-		fmt.Printf("❌ Failed to inject messages: %v\n", err)
-		// Send error message to user
-		postErr := a.slackBot.PostMessage(channel, threadTS, fmt.Sprintf("❌ Error: %v", err))
-		if postErr != nil {
+		a.postFailure(channel, threadTS, "inject messages", err)
+		return fmt.Errorf("failed to inject messages: %w", err)
+	}
+	if err := a.recordInjections(threadTS, project, version, docs); err != nil {
+		fmt.Printf("❌ Documents injected for project %s on version %s but not recorded: %v\n", project, version, err)
+		if postErr := a.slackBot.PostMessage(channel, threadTS, withEmoji(a.branding.ErrorEmoji, i18n.T("inject.notRecorded"))); postErr != nil {
 			fmt.Printf("❌ Failed to post error message: %v\n", postErr)
 		}
-		return fmt.Errorf("failed to inject messages: %w", err)
+		return fmt.Errorf("failed to record injected documents: %w", err)
 	}
 
-	err = a.slackBot.PostMessage(channel, threadTS, fmt.Sprintf("Document injected for project %s on version %s", project, version))
-	if err != nil {
+	result := i18n.T("inject.done", project, version)
+	if split {
+		result = i18n.T("inject.doneSplit", len(docs), project, version)
+	}
+	if err := a.slackBot.PostMessage(channel, threadTS, result); err != nil {
 		return fmt.Errorf("failed to send response: %w", err)
 	}
 	return nil
 }
 
+// recordInjections records the injected documents in a single transaction, so they are all recorded or none is
+func (a *Agent) recordInjections(threadTS, project, version string, docs []llm.Document) error {
+	return a.db.WithTransaction(func(tx database.Interface) error {
+		for _, doc := range docs {
+			record := &database.InjectedDocument{
+				SlackThread: threadTS,
+				Project:     project,
+				Version:     version,
+				Content:     doc.Content,
+				Source:      doc.Metadata.Source,
+			}
+			if err := tx.RecordInjection(record); err != nil {
+				return fmt.Errorf("failed to record injected document: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// threadPermalink returns the permalink of the thread, or an empty string when Slack doesn't return one
+func (a *Agent) threadPermalink(channel, threadTS string) string {
+	permalink, err := a.slackBot.GetPermalink(channel, threadTS)
+	if err != nil {
+		fmt.Printf("⚠️ Failed to get the permalink of thread %s: %v\n", threadTS, err)
+		return ""
+	}
+	return permalink
+}
+
+// sanitize turns the Slack markup of a message into plain text before it is sent to the LLM
+func (a *Agent) sanitize(text string) string {
+	return slackbot.SanitizeText(text, a.resolveUserName)
+}
+
+// resolveUserName returns the display name of the user, or an empty string when the lookup fails
+func (a *Agent) resolveUserName(userID string) string {
+	name, err := a.slackBot.GetUserName(userID)
+	if err != nil {
+		fmt.Printf("⚠️ Failed to resolve user %s: %v\n", userID, err)
+		return ""
+	}
+	return name
+}
+
+// userLabel renders a user for the logs as "name (ID)", or just the ID when the name is unknown
+func (a *Agent) userLabel(userID string) string {
+	if name := a.resolveUserName(userID); name != "" {
+		return fmt.Sprintf("%s (%s)", name, userID)
+	}
+	return userID
+}
+
 // getThreadMessages retrieves and returns all messages in a thread
-func (a *Agent) getThreadMessages(channel, threadTS string) (string, error) {
+func (a *Agent) getThreadMessages(ctx context.Context, channel, threadTS string) (string, error) {
 	fmt.Printf("🧵 Retrieving thread messages for thread: %s\n", threadTS)
 
 	// Get conversation replies (thread messages)
@@ -273,18 +1028,34 @@ func (a *Agent) getThreadMessages(channel, threadTS string) (string, error) {
 		fmt.Printf("❌ Failed to retrieve thread messages: %v\n", err)
 		return "", err
 	}
+	if len(replies) == 0 {
+		return "", a.emptyThread(channel, threadTS)
+	}
 
 	fmt.Printf("📋 Thread contains %d message(s):\n", len(replies))
-	messages := ""
-	//nolint:gocritic
+	texts := make([]string, 0, len(replies))
 	for _, msg := range replies {
-		messages += fmt.Sprintf("%s\n", msg.Text)
+		texts = append(texts, msg.Text)
+	}
+	texts, err = a.sanitizeMessages(ctx, texts)
+	if err != nil {
+		return "", err
 	}
+	for i := range texts {
+		texts[i] += "\n"
+	}
+
+	kept := limitThreadMessages(texts, a.maxThreadContext, a.keepFirstMessage)
+	if len(kept) < len(texts) {
+		fmt.Printf("✂️ Thread %s exceeds %d characters, sending %d of %d message(s)\n", threadTS, a.maxThreadContext, len(kept), len(texts))
+	}
+	messages := strings.Join(kept, "")
 	fmt.Printf("📋 messages in thread:\n%s", messages)
 	return messages, nil
 }
 
-func (a *Agent) getLastMessageInThread(channel, threadTS string) (string, error) {
+// getLastMessageInThread returns the message before the mention, the question to answer
+func (a *Agent) getLastMessageInThread(channel, threadTS string) (slack.Message, error) {
 	// Get conversation replies (thread messages)
 	replies, err := a.slackBot.GetConversationReplies(&slack.GetConversationRepliesParameters{
 		ChannelID: channel,
@@ -294,15 +1065,21 @@ func (a *Agent) getLastMessageInThread(channel, threadTS string) (string, error)
 
 	if err != nil {
 		fmt.Printf("❌ Failed to retrieve thread messages: %v\n", err)
-		return "", err
+		return slack.Message{}, err
 	}
+	if len(replies) == 0 {
+		return slack.Message{}, a.emptyThread(channel, threadTS)
+	}
+	// The last replies are the mention and the placeholder, a mention starting the thread has nothing before it
 	if len(replies) < 3 {
-		return "", fmt.Errorf("unexpected number of messages in thread")
+		return slack.Message{}, ErrNoQuestion
 	}
-	return replies[len(replies)-3].Text, nil
+	return replies[len(replies)-3], nil
 }
 
-func (a *Agent) getLastMessagesFromTheSameUser(channel, threadTS string) (string, error) {
+// getLastMessagesFromTheSameUser returns the last messages of the thread posted by the same user, oldest first, and
+// ErrNoQuestion when there is no message before the command
+func (a *Agent) getLastMessagesFromTheSameUser(channel, threadTS string) ([]string, error) {
 	replies, err := a.slackBot.GetConversationReplies(&slack.GetConversationRepliesParameters{
 		ChannelID: channel,
 		Timestamp: threadTS,
@@ -311,18 +1088,27 @@ func (a *Agent) getLastMessagesFromTheSameUser(channel, threadTS string) (string
 
 	if err != nil {
 		fmt.Printf("❌ Failed to retrieve thread messages: %v\n", err)
-		return "", err
+		return nil, err
+	}
+	if len(replies) == 0 {
+		return nil, a.emptyThread(channel, threadTS)
+	}
+	// The last reply is the inject command, there is nothing before it to inject
+	if len(replies) < 2 {
+		return nil, ErrNoQuestion
 	}
 
 	lastMessageUser := replies[len(replies)-2].User
-	messages := ""
+	messages := []string{}
 	for index := len(replies) - 2; index > 0; index-- {
 		if replies[index].User != lastMessageUser {
 			break
 		}
 
-		messages = fmt.Sprintf("%s%s", replies[index].Text, messages)
+		messages = append([]string{replies[index].Text}, messages...)
+	}
+	if len(messages) > 0 {
+		messages[0] = strings.TrimPrefix(messages[0], a.branding.ElaboratingMessage)
 	}
-	messages = strings.TrimPrefix(messages, "Elaborating...")
 	return messages, nil
 }
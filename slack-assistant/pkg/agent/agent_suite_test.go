@@ -1,13 +1,59 @@
 package agent_test
 
 import (
+	"context"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"go.uber.org/goleak"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
 )
 
 func TestAgent(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Agent Suite")
 }
+
+// Fail any spec that leaves goroutines running after its teardown
+var ignoreGoroutines goleak.Option
+
+var _ = BeforeEach(func() {
+	ignoreGoroutines = goleak.IgnoreCurrent()
+})
+
+var _ = AfterEach(func() {
+	goleak.VerifyNone(GinkgoT(), ignoreGoroutines)
+})
+
+// startAgent runs the agent in the background, the returned channel is closed once Start returned
+func startAgent(ctx context.Context, testAgent *agent.Agent) <-chan struct{} {
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		testAgent.Start(ctx)
+	}()
+	return stopped
+}
+
+// newTestAgent returns an agent with a single worker over new mocks, the bot user, the user names, the command
+// audit, the complete answers and their sources are stubbed since almost every command goes through them
+func newTestAgent() (*agent.Agent, *databaseMock.MockInterface, *slackbotMock.MockInterface, *llmMock.MockInterface) {
+	ctrl := gomock.NewController(GinkgoT())
+	mockDB := databaseMock.NewMockInterface(ctrl)
+	mockSlackBot := slackbotMock.NewMockInterface(ctrl)
+	mockLLM := llmMock.NewMockInterface(ctrl)
+
+	mockSlackBot.EXPECT().GetBotUser().Return(nil).AnyTimes()
+	mockSlackBot.EXPECT().GetUserName(gomock.Any()).Return("alice", nil).AnyTimes()
+	mockDB.EXPECT().RecordCommand(gomock.Any()).Return(nil).AnyTimes()
+	mockDB.EXPECT().SetThreadTruncated(gomock.Any(), false).Return(nil).AnyTimes()
+	mockLLM.EXPECT().LastContext(gomock.Any()).Return(nil).AnyTimes()
+
+	return agent.NewAgent(mockDB, mockSlackBot, mockLLM, nil, nil, nil, nil, 1, 10), mockDB, mockSlackBot, mockLLM
+}
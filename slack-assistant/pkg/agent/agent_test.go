@@ -3,6 +3,8 @@ package agent_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -12,9 +14,12 @@ import (
 	"go.uber.org/mock/gomock"
 
 	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
 	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
 	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
 	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+	slackbot "github.com/SchSeba/slack-ai-assistant/pkg/slack-bot"
 )
 
 var _ = Describe("Agent", func() {
@@ -25,6 +30,8 @@ var _ = Describe("Agent", func() {
 		mockLLM             *llmMock.MockInterface
 		appMentionChannel   chan *slackevents.AppMentionEvent
 		slashCommandChannel chan *slack.SlashCommand
+		interactionChannel  chan *slack.InteractionCallback
+		appHomeChannel      chan *slackevents.AppHomeOpenedEvent
 		testAgent           *agent.Agent
 	)
 
@@ -36,13 +43,20 @@ var _ = Describe("Agent", func() {
 
 		appMentionChannel = make(chan *slackevents.AppMentionEvent, 10)
 		slashCommandChannel = make(chan *slack.SlashCommand, 10)
+		interactionChannel = make(chan *slack.InteractionCallback, 10)
+		appHomeChannel = make(chan *slackevents.AppHomeOpenedEvent, 10)
 
-		testAgent = agent.NewAgent(mockDB, mockSlackBot, mockLLM, appMentionChannel, slashCommandChannel, 1)
+		testAgent = agent.NewAgent(mockDB, mockSlackBot, mockLLM, appMentionChannel, slashCommandChannel, interactionChannel, appHomeChannel, 1, 5)
+
+		mockDB.EXPECT().SetThreadTruncated(gomock.Any(), false).Return(nil).AnyTimes()
+		mockLLM.EXPECT().LastContext(gomock.Any()).Return(nil).AnyTimes()
 	})
 
 	AfterEach(func() {
 		close(appMentionChannel)
 		close(slashCommandChannel)
+		close(interactionChannel)
+		close(appHomeChannel)
 		ctrl.Finish()
 	})
 
@@ -50,6 +64,12 @@ var _ = Describe("Agent", func() {
 		It("should create a new agent with proper dependencies", func() {
 			Expect(testAgent).NotTo(BeNil())
 		})
+
+		It("should create the worker pool with the configured workers and queue size", func() {
+			stats := testAgent.WorkerPoolStats()
+			Expect(stats.Workers).To(Equal(1))
+			Expect(stats.QueueCapacity).To(Equal(5))
+		})
 	})
 
 	Describe("AnswerQuestion", func() {
@@ -70,16 +90,62 @@ var _ = Describe("Agent", func() {
 					{Msg: slack.Msg{Text: "User question"}},
 				}, nil)
 				mockDB.EXPECT().GetSlugForThread(threadTS).Return("", false, nil)
-				mockLLM.EXPECT().CreateThread(project, version).Return("test-thread-slug", nil)
-				mockDB.EXPECT().CreateSlackThreadWithSlug(threadTS, "test-thread-slug").Return(nil)
-				mockLLM.EXPECT().SendMessageToChat(project, version, "test-thread-slug", gomock.Any()).Return("AI response", nil)
-				mockSlackBot.EXPECT().PostMessage(channel, threadTS, gomock.Any()).Return(nil)
-
-				err := testAgent.AnswerQuestion(channel, threadTS, project, version, false)
+				mockDB.EXPECT().SetLastQuestion(threadTS, "User message 1").Return(nil)
+				mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil).AnyTimes()
+				mockLLM.EXPECT().CreateThread(gomock.Any(), project, version).Return("test-thread-slug", nil)
+				mockDB.EXPECT().CreateSlackThreadWithSlug(threadTS, "test-thread-slug", project, version).Return(nil)
+				mockLLM.EXPECT().SendMessageToChat(gomock.Any(), project, version, "test-thread-slug", gomock.Any()).Return("AI response", nil)
+				mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+
+				err := testAgent.AnswerQuestion(context.Background(), channel, threadTS, project, version, false, false)
 				Expect(err).NotTo(HaveOccurred())
 			})
 		})
 
+		Context("when the answer is broadcast", func() {
+			It("should broadcast the answer of the answer! command to the channel", func() {
+				mockSlackBot.EXPECT().GetBotUser().Return(nil).AnyTimes()
+				mockSlackBot.EXPECT().GetUserName(gomock.Any()).Return("alice", nil).AnyTimes()
+				mockDB.EXPECT().RecordCommand(gomock.Any()).Return(nil)
+				mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+				mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+					{Msg: slack.Msg{Text: "User message 1"}},
+					{Msg: slack.Msg{Text: "Bot response"}},
+					{Msg: slack.Msg{Text: "User question"}},
+				}, nil)
+				mockDB.EXPECT().GetSlugForThread(threadTS).Return("existing-slug", true, nil)
+				mockDB.EXPECT().SetLastQuestion(threadTS, gomock.Any()).Return(nil).AnyTimes()
+				mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil).AnyTimes()
+				mockLLM.EXPECT().SendMessageToChat(gomock.Any(), project, version, "existing-slug", gomock.Any()).Return("AI response", nil)
+				mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any(), slackbot.OptionBroadcast).Return(nil)
+
+				Expect(agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+					User:            "U123456",
+					Text:            "<@BOT123> answer! sriov 4.16",
+					Channel:         channel,
+					TimeStamp:       "1234567890.200000",
+					ThreadTimeStamp: threadTS,
+				}}.Process(context.Background(), testAgent)).To(Succeed())
+			})
+
+			It("should broadcast the plain text fallback too", func() {
+				mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+				mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+					{Msg: slack.Msg{Text: "User message 1"}},
+					{Msg: slack.Msg{Text: "Bot response"}},
+					{Msg: slack.Msg{Text: "User question"}},
+				}, nil)
+				mockDB.EXPECT().GetSlugForThread(threadTS).Return("existing-slug", true, nil)
+				mockDB.EXPECT().SetLastQuestion(threadTS, gomock.Any()).Return(nil).AnyTimes()
+				mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil).AnyTimes()
+				mockLLM.EXPECT().SendMessageToChat(gomock.Any(), project, version, "existing-slug", gomock.Any()).Return("AI response", nil)
+				mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any(), slackbot.OptionBroadcast).Return(errors.New("invalid_blocks"))
+				mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Here is the information I was able to find\nAI response", slackbot.OptionBroadcast).Return(nil)
+
+				Expect(testAgent.AnswerQuestion(context.Background(), channel, threadTS, project, version, false, true)).To(Succeed())
+			})
+		})
+
 		Context("when thread exists in database", func() {
 			It("should use existing thread slug", func() {
 				existingSlug := "existing-thread-slug"
@@ -91,10 +157,73 @@ var _ = Describe("Agent", func() {
 					{Msg: slack.Msg{Text: "User question"}},
 				}, nil)
 				mockDB.EXPECT().GetSlugForThread(threadTS).Return(existingSlug, true, nil)
-				mockLLM.EXPECT().SendMessageToChat(project, version, existingSlug, gomock.Any()).Return("AI response", nil)
-				mockSlackBot.EXPECT().PostMessage(channel, threadTS, gomock.Any()).Return(nil)
+				mockDB.EXPECT().SetLastQuestion(threadTS, gomock.Any()).Return(nil).AnyTimes()
+				mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil).AnyTimes()
+				mockLLM.EXPECT().SendMessageToChat(gomock.Any(), project, version, existingSlug, gomock.Any()).Return("AI response", nil)
+				mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+
+				err := testAgent.AnswerQuestion(context.Background(), channel, threadTS, project, version, false, false)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when the question contains Slack markup", func() {
+			It("should send plain text to the LLM", func() {
+				mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+				mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+					{Msg: slack.Msg{Text: "<@U123> check <https://docs.openshift.com|the docs> in <#C123|sriov>"}},
+					{Msg: slack.Msg{Text: "Bot response"}},
+					{Msg: slack.Msg{Text: "User question"}},
+				}, nil)
+				mockSlackBot.EXPECT().GetUserName("U123").Return("alice", nil)
+				mockDB.EXPECT().GetSlugForThread(threadTS).Return("existing-slug", true, nil)
+				mockDB.EXPECT().SetLastQuestion(threadTS, gomock.Any()).Return(nil).AnyTimes()
+				mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil).AnyTimes()
+				mockLLM.EXPECT().SendMessageToChat(gomock.Any(), project, version, "existing-slug",
+					"@alice check the docs (https://docs.openshift.com) in #sriov").Return("AI response", nil)
+				mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+
+				err := testAgent.AnswerQuestion(context.Background(), channel, threadTS, project, version, false, false)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when the mentioned user can't be resolved", func() {
+			It("should keep the user ID", func() {
+				mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+				mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+					{Msg: slack.Msg{Text: "<@U123> can you check?"}},
+					{Msg: slack.Msg{Text: "Bot response"}},
+					{Msg: slack.Msg{Text: "User question"}},
+				}, nil)
+				mockSlackBot.EXPECT().GetUserName("U123").Return("", errors.New("user_not_found"))
+				mockDB.EXPECT().GetSlugForThread(threadTS).Return("existing-slug", true, nil)
+				mockDB.EXPECT().SetLastQuestion(threadTS, gomock.Any()).Return(nil).AnyTimes()
+				mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil).AnyTimes()
+				mockLLM.EXPECT().SendMessageToChat(gomock.Any(), project, version, "existing-slug", "@U123 can you check?").Return("AI response", nil)
+				mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+
+				err := testAgent.AnswerQuestion(context.Background(), channel, threadTS, project, version, false, false)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when posting the answer blocks fails", func() {
+			It("should fall back to a plain text answer", func() {
+				mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+				mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+					{Msg: slack.Msg{Text: "User message 1"}},
+					{Msg: slack.Msg{Text: "Bot response"}},
+					{Msg: slack.Msg{Text: "User question"}},
+				}, nil)
+				mockDB.EXPECT().GetSlugForThread(threadTS).Return("existing-slug", true, nil)
+				mockDB.EXPECT().SetLastQuestion(threadTS, gomock.Any()).Return(nil).AnyTimes()
+				mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil).AnyTimes()
+				mockLLM.EXPECT().SendMessageToChat(gomock.Any(), project, version, "existing-slug", gomock.Any()).Return("AI response", nil)
+				mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(errors.New("invalid_blocks"))
+				mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Here is the information I was able to find\nAI response").Return(nil)
 
-				err := testAgent.AnswerQuestion(channel, threadTS, project, version, false)
+				err := testAgent.AnswerQuestion(context.Background(), channel, threadTS, project, version, false, false)
 				Expect(err).NotTo(HaveOccurred())
 			})
 		})
@@ -109,7 +238,7 @@ var _ = Describe("Agent", func() {
 				}, nil)
 				mockDB.EXPECT().GetSlugForThread(threadTS).Return("", false, errors.New("database error"))
 
-				err := testAgent.AnswerQuestion(channel, threadTS, project, version, false)
+				err := testAgent.AnswerQuestion(context.Background(), channel, threadTS, project, version, false, false)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("failed to get slug for thread from database"))
 			})
@@ -122,9 +251,11 @@ var _ = Describe("Agent", func() {
 					{Msg: slack.Msg{Text: "User question"}},
 				}, nil)
 				mockDB.EXPECT().GetSlugForThread(threadTS).Return("", false, nil)
-				mockLLM.EXPECT().CreateThread(project, version).Return("", errors.New("LLM error"))
+				mockDB.EXPECT().SetLastQuestion(threadTS, gomock.Any()).Return(nil).AnyTimes()
+				mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil).AnyTimes()
+				mockLLM.EXPECT().CreateThread(gomock.Any(), project, version).Return("", errors.New("LLM error"))
 
-				err := testAgent.AnswerQuestion(channel, threadTS, project, version, false)
+				err := testAgent.AnswerQuestion(context.Background(), channel, threadTS, project, version, false, false)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("failed to create thread"))
 			})
@@ -137,14 +268,49 @@ var _ = Describe("Agent", func() {
 					{Msg: slack.Msg{Text: "User question"}},
 				}, nil)
 				mockDB.EXPECT().GetSlugForThread(threadTS).Return("existing-slug", true, nil)
-				mockLLM.EXPECT().SendMessageToChat(project, version, "existing-slug", gomock.Any()).Return("", errors.New("no index found"))
-				mockSlackBot.EXPECT().PostMessage(channel, threadTS, "❌ Error: no index found").Return(nil)
+				mockDB.EXPECT().SetLastQuestion(threadTS, gomock.Any()).Return(nil).AnyTimes()
+				mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil).AnyTimes()
+				mockLLM.EXPECT().SendMessageToChat(gomock.Any(), project, version, "existing-slug", gomock.Any()).Return("", &llm.StatusError{StatusCode: 404, Body: "no index found"})
+				mockSlackBot.EXPECT().PostMessage(channel, threadTS, "❌ Error: server returned status 404: no index found").Return(nil)
 
-				err := testAgent.AnswerQuestion(channel, threadTS, project, version, false)
+				err := testAgent.AnswerQuestion(context.Background(), channel, threadTS, project, version, false, false)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("failed to generate response"))
 			})
 		})
+
+		Context("when the LLM backend is unavailable", func() {
+			BeforeEach(func() {
+				mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+				mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+					{Msg: slack.Msg{Text: "User message 1"}},
+					{Msg: slack.Msg{Text: "Bot response"}},
+					{Msg: slack.Msg{Text: "User question"}},
+				}, nil)
+			})
+
+			It("should tell the user when the thread can't be created", func() {
+				mockDB.EXPECT().GetSlugForThread(threadTS).Return("", false, nil)
+				mockDB.EXPECT().SetLastQuestion(threadTS, gomock.Any()).Return(nil).AnyTimes()
+				mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil).AnyTimes()
+				mockLLM.EXPECT().CreateThread(gomock.Any(), project, version).Return("", llm.ErrCircuitOpen)
+				mockSlackBot.EXPECT().PostMessage(channel, threadTS, "⏳ The assistant is temporarily unavailable, please try again in a few minutes").Return(nil)
+
+				err := testAgent.AnswerQuestion(context.Background(), channel, threadTS, project, version, false, false)
+				Expect(err).To(MatchError(llm.ErrCircuitOpen))
+			})
+
+			It("should tell the user when the answer can't be generated", func() {
+				mockDB.EXPECT().GetSlugForThread(threadTS).Return("existing-slug", true, nil)
+				mockDB.EXPECT().SetLastQuestion(threadTS, gomock.Any()).Return(nil).AnyTimes()
+				mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil).AnyTimes()
+				mockLLM.EXPECT().SendMessageToChat(gomock.Any(), project, version, "existing-slug", gomock.Any()).Return("", llm.ErrCircuitOpen)
+				mockSlackBot.EXPECT().PostMessage(channel, threadTS, "⏳ The assistant is temporarily unavailable, please try again in a few minutes").Return(nil)
+
+				err := testAgent.AnswerQuestion(context.Background(), channel, threadTS, project, version, false, false)
+				Expect(err).To(MatchError(llm.ErrCircuitOpen))
+			})
+		})
 	})
 
 	Describe("Elaborate", func() {
@@ -160,11 +326,38 @@ var _ = Describe("Agent", func() {
 				{Msg: slack.Msg{Text: "Bot response"}},
 				{Msg: slack.Msg{Text: "User question"}},
 			}, nil)
-			mockLLM.EXPECT().CreateThread("elaborate", "").Return("elaborate-thread-slug", nil)
-			mockLLM.EXPECT().Elaborate("elaborate-thread-slug", gomock.Any()).Return("Elaborated response", nil)
+			mockDB.EXPECT().GetElaborateSlugForThread(threadTS).Return("", false, nil)
+			mockLLM.EXPECT().CreateThread(gomock.Any(), "elaborate", "").Return("elaborate-thread-slug", nil)
+			mockDB.EXPECT().SetElaborateSlugForThread(threadTS, "elaborate-thread-slug").Return(nil)
+			mockLLM.EXPECT().Elaborate(gomock.Any(), "elaborate", "elaborate-thread-slug", gomock.Any()).Return("Elaborated response", nil)
+			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Elaborated response").Return(nil)
+
+			err := testAgent.Elaborate(context.Background(), channel, threadTS)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should use the configured elaborate workspace", func() {
+			testAgent.SetElaborateWorkspace("summaries")
+
+			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Elaborating...").Return(nil)
+			mockDB.EXPECT().GetElaborateSlugForThread(threadTS).Return("", false, nil)
+			mockLLM.EXPECT().CreateThread(gomock.Any(), "summaries", "").Return("summaries-thread-slug", nil)
+			mockDB.EXPECT().SetElaborateSlugForThread(threadTS, "summaries-thread-slug").Return(nil)
+			mockLLM.EXPECT().Elaborate(gomock.Any(), "summaries", "summaries-thread-slug", "Answer text").Return("Elaborated response", nil)
 			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Elaborated response").Return(nil)
 
-			err := testAgent.Elaborate(channel, threadTS)
+			err := testAgent.ElaborateMessage(context.Background(), channel, threadTS, "Answer text")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should reuse the elaborate thread stored for the Slack thread", func() {
+			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Elaborating...").Return(nil)
+			mockDB.EXPECT().GetElaborateSlugForThread(threadTS).Return("elaborate-thread-slug", true, nil)
+			mockLLM.EXPECT().CreateThread(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			mockLLM.EXPECT().Elaborate(gomock.Any(), "elaborate", "elaborate-thread-slug", "Answer text").Return("Elaborated response", nil)
+			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Elaborated response").Return(nil)
+
+			err := testAgent.ElaborateMessage(context.Background(), channel, threadTS, "Answer text")
 			Expect(err).NotTo(HaveOccurred())
 		})
 
@@ -175,9 +368,10 @@ var _ = Describe("Agent", func() {
 				{Msg: slack.Msg{Text: "Bot response"}},
 				{Msg: slack.Msg{Text: "User question"}},
 			}, nil)
-			mockLLM.EXPECT().CreateThread("elaborate", "").Return("", errors.New("LLM error"))
+			mockDB.EXPECT().GetElaborateSlugForThread(threadTS).Return("", false, nil)
+			mockLLM.EXPECT().CreateThread(gomock.Any(), "elaborate", "").Return("", errors.New("LLM error"))
 
-			err := testAgent.Elaborate(channel, threadTS)
+			err := testAgent.Elaborate(context.Background(), channel, threadTS)
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("failed to create thread"))
 		})
@@ -189,11 +383,13 @@ var _ = Describe("Agent", func() {
 				{Msg: slack.Msg{Text: "Bot response"}},
 				{Msg: slack.Msg{Text: "User question"}},
 			}, nil)
-			mockLLM.EXPECT().CreateThread("elaborate", "").Return("elaborate-thread-slug", nil)
-			mockLLM.EXPECT().Elaborate("elaborate-thread-slug", gomock.Any()).Return("", errors.New("elaboration failed"))
-			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "❌ Error: elaboration failed").Return(nil)
+			mockDB.EXPECT().GetElaborateSlugForThread(threadTS).Return("", false, nil)
+			mockLLM.EXPECT().CreateThread(gomock.Any(), "elaborate", "").Return("elaborate-thread-slug", nil)
+			mockDB.EXPECT().SetElaborateSlugForThread(threadTS, "elaborate-thread-slug").Return(nil)
+			mockLLM.EXPECT().Elaborate(gomock.Any(), "elaborate", "elaborate-thread-slug", gomock.Any()).Return("", errors.New("elaboration failed"))
+			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "❌ Error: something went wrong on our side, please try again later or contact the bot admins").Return(nil)
 
-			err := testAgent.Elaborate(channel, threadTS)
+			err := testAgent.Elaborate(context.Background(), channel, threadTS)
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("failed to generate response"))
 		})
@@ -207,32 +403,449 @@ var _ = Describe("Agent", func() {
 			version  = "4.16"
 		)
 
+		// inTransaction runs the transaction of the injection against the database mock
+		inTransaction := func() {
+			mockDB.EXPECT().WithTransaction(gomock.Any()).DoAndReturn(func(fn func(database.Interface) error) error {
+				return fn(mockDB)
+			})
+		}
+
 		It("should inject messages successfully", func() {
 			mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
 				{Msg: slack.Msg{Text: "User message 1", User: "U123"}},
 				{Msg: slack.Msg{Text: "Bot response", User: "BOT123"}},
 				{Msg: slack.Msg{Text: "User question", User: "U123"}},
 			}, nil)
-			mockLLM.EXPECT().Inject(project, version, gomock.Any()).Return(nil)
+			mockSlackBot.EXPECT().GetPermalink(channel, threadTS).Return("https://example.slack.com/archives/C1234567890/p1234567890123456", nil)
+			inTransaction()
+			mockDB.EXPECT().RecordInjection(&database.InjectedDocument{
+				SlackThread: threadTS,
+				Project:     project,
+				Version:     version,
+				Content:     "Bot response",
+				Source:      "https://example.slack.com/archives/C1234567890/p1234567890123456",
+			}).Return(nil)
+			mockLLM.EXPECT().Inject(gomock.Any(), project, version, "Bot response", llm.DocumentMetadata{
+				Source: "https://example.slack.com/archives/C1234567890/p1234567890123456",
+			}).Return(nil)
+			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Document injected for project sriov on version 4.16").Return(nil)
+
+			err := testAgent.Inject(context.Background(), channel, threadTS, project, version, false)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should tell the thread when the inject is retried", func() {
+			mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+				{Msg: slack.Msg{Text: "User message 1", User: "U123"}},
+				{Msg: slack.Msg{Text: "Bot response", User: "BOT123"}},
+				{Msg: slack.Msg{Text: "User question", User: "U123"}},
+			}, nil)
+			mockSlackBot.EXPECT().GetPermalink(channel, threadTS).Return("", errors.New("channel_not_found"))
+			inTransaction()
+			mockDB.EXPECT().RecordInjection(gomock.Any()).Return(nil)
+			mockLLM.EXPECT().Inject(gomock.Any(), project, version, "Bot response", llm.DocumentMetadata{}).DoAndReturn(
+				func(ctx context.Context, _, _, _ string, _ llm.DocumentMetadata) error {
+					// The client retries a transient failure before succeeding
+					notify := llm.InjectRetryNotifierFrom(ctx)
+					Expect(notify).NotTo(BeNil())
+					notify(1, 5, 2*time.Second)
+					return nil
+				})
+			gomock.InOrder(
+				mockSlackBot.EXPECT().PostMessage(channel, threadTS, "⏳ The knowledge base didn't accept the document yet, retrying in 2s (1/5)...").Return(nil),
+				mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Document injected for project sriov on version 4.16").Return(nil),
+			)
+
+			err := testAgent.Inject(context.Background(), channel, threadTS, project, version, false)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should inject without a source when the permalink is not available", func() {
+			mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+				{Msg: slack.Msg{Text: "User message 1", User: "U123"}},
+				{Msg: slack.Msg{Text: "Bot response", User: "BOT123"}},
+				{Msg: slack.Msg{Text: "User question", User: "U123"}},
+			}, nil)
+			mockSlackBot.EXPECT().GetPermalink(channel, threadTS).Return("", errors.New("channel_not_found"))
+			inTransaction()
+			mockDB.EXPECT().RecordInjection(gomock.Any()).Return(nil)
+			mockLLM.EXPECT().Inject(gomock.Any(), project, version, gomock.Any(), llm.DocumentMetadata{}).Return(nil)
 			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Document injected for project sriov on version 4.16").Return(nil)
 
-			err := testAgent.Inject(channel, threadTS, project, version)
+			err := testAgent.Inject(context.Background(), channel, threadTS, project, version, false)
 			Expect(err).NotTo(HaveOccurred())
 		})
 
+		It("should inject each message as its own document when split", func() {
+			mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+				{Msg: slack.Msg{Text: "Question", User: "U999"}},
+				{Msg: slack.Msg{Text: "First fact", User: "U123"}},
+				{Msg: slack.Msg{Text: "Second fact", User: "U123"}},
+				{Msg: slack.Msg{Text: "<@BOT123> inject sriov 4.16 --split", User: "U123"}},
+			}, nil)
+			mockSlackBot.EXPECT().GetPermalink(channel, threadTS).Return("https://example.slack.com/archives/C1234567890/p1234567890123456", nil)
+			source := llm.DocumentMetadata{Source: "https://example.slack.com/archives/C1234567890/p1234567890123456"}
+			inTransaction()
+			mockDB.EXPECT().RecordInjection(gomock.Any()).Return(nil).Times(2)
+			mockLLM.EXPECT().InjectBatch(gomock.Any(), project, version, []llm.Document{
+				{Content: "First fact", Metadata: source},
+				{Content: "Second fact", Metadata: source},
+			}).Return(nil)
+			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "2 document(s) injected for project sriov on version 4.16").Return(nil)
+
+			err := testAgent.Inject(context.Background(), channel, threadTS, project, version, true)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should parse the split modifier of the inject command", func() {
+			mockSlackBot.EXPECT().GetBotUser().Return(nil)
+			mockSlackBot.EXPECT().GetUserName("U123").Return("alice", nil)
+			mockDB.EXPECT().RecordCommand(gomock.Any()).Return(nil)
+			mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+				{Msg: slack.Msg{Text: "Question", User: "U999"}},
+				{Msg: slack.Msg{Text: "First fact", User: "U123"}},
+				{Msg: slack.Msg{Text: "<@BOT123> inject sriov 4.16 —split", User: "U123"}},
+			}, nil)
+			mockSlackBot.EXPECT().GetPermalink(channel, threadTS).Return("", nil)
+			inTransaction()
+			mockDB.EXPECT().RecordInjection(gomock.Any()).Return(nil)
+			mockLLM.EXPECT().InjectBatch(gomock.Any(), project, version, []llm.Document{{Content: "First fact"}}).Return(nil)
+			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "1 document(s) injected for project sriov on version 4.16").Return(nil)
+
+			workItem := agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+				User:      "U123",
+				Text:      "<@BOT123> inject sriov 4.16 —split",
+				Channel:   channel,
+				TimeStamp: threadTS,
+			}}
+			Expect(workItem.Process(context.Background(), testAgent)).To(Succeed())
+		})
+
+		It("should tell the thread there is nothing to inject when the command starts it", func() {
+			mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+				{Msg: slack.Msg{Text: "<@BOT123> inject sriov 4.16", User: "U123"}},
+			}, nil)
+			mockSlackBot.EXPECT().GetPermalink(channel, threadTS).Return("", nil)
+			mockLLM.EXPECT().Inject(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "There is nothing to inject in this thread").Return(nil)
+
+			Expect(testAgent.Inject(context.Background(), channel, threadTS, project, version, false)).To(Succeed())
+		})
+
 		It("should handle injection failure", func() {
 			mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
 				{Msg: slack.Msg{Text: "User message 1", User: "U123"}},
 				{Msg: slack.Msg{Text: "Bot response", User: "BOT123"}},
 				{Msg: slack.Msg{Text: "User question", User: "U123"}},
 			}, nil)
-			mockLLM.EXPECT().Inject(project, version, gomock.Any()).Return(errors.New("injection failed"))
-			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "❌ Error: injection failed").Return(nil)
+			mockSlackBot.EXPECT().GetPermalink(channel, threadTS).Return("", nil)
+			mockLLM.EXPECT().Inject(gomock.Any(), project, version, gomock.Any(), gomock.Any()).Return(errors.New("injection failed"))
+			// A failed inject leaves no record
+			mockDB.EXPECT().WithTransaction(gomock.Any()).Times(0)
+			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "❌ Error: something went wrong on our side, please try again later or contact the bot admins").Return(nil)
 
-			err := testAgent.Inject(channel, threadTS, project, version)
+			err := testAgent.Inject(context.Background(), channel, threadTS, project, version, false)
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("failed to inject messages"))
 		})
+
+		It("should report the documents injected but not recorded when a record fails", func() {
+			mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+				{Msg: slack.Msg{Text: "Question", User: "U123"}},
+				{Msg: slack.Msg{Text: "Bot response", User: "BOT123"}},
+				{Msg: slack.Msg{Text: "<@BOT123> inject sriov 4.16", User: "U123"}},
+			}, nil)
+			mockSlackBot.EXPECT().GetPermalink(channel, threadTS).Return("", nil)
+			mockLLM.EXPECT().Inject(gomock.Any(), project, version, "Bot response", gomock.Any()).Return(nil)
+			inTransaction()
+			mockDB.EXPECT().RecordInjection(gomock.Any()).Return(errors.New("database error"))
+			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "❌ The document was injected but it could not be recorded, please don't inject it again").Return(nil)
+
+			err := testAgent.Inject(context.Background(), channel, threadTS, project, version, false)
+			Expect(err).To(MatchError(ContainSubstring("failed to record injected document")))
+		})
+
+		It("should not hold the transaction while the LLM injects", func() {
+			mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+				{Msg: slack.Msg{Text: "Question", User: "U123"}},
+				{Msg: slack.Msg{Text: "Bot response", User: "BOT123"}},
+				{Msg: slack.Msg{Text: "<@BOT123> inject sriov 4.16", User: "U123"}},
+			}, nil)
+			mockSlackBot.EXPECT().GetPermalink(channel, threadTS).Return("", nil)
+			gomock.InOrder(
+				mockLLM.EXPECT().Inject(gomock.Any(), project, version, "Bot response", gomock.Any()).Return(nil),
+				mockDB.EXPECT().WithTransaction(gomock.Any()).DoAndReturn(func(fn func(database.Interface) error) error {
+					return fn(mockDB)
+				}),
+			)
+			mockDB.EXPECT().RecordInjection(gomock.Any()).Return(nil)
+			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Document injected for project sriov on version 4.16").Return(nil)
+
+			Expect(testAgent.Inject(context.Background(), channel, threadTS, project, version, false)).To(Succeed())
+		})
+
+		It("should report the documents injected but not recorded when the commit fails", func() {
+			mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+				{Msg: slack.Msg{Text: "Question", User: "U123"}},
+				{Msg: slack.Msg{Text: "Bot response", User: "BOT123"}},
+				{Msg: slack.Msg{Text: "<@BOT123> inject sriov 4.16", User: "U123"}},
+			}, nil)
+			mockSlackBot.EXPECT().GetPermalink(channel, threadTS).Return("", nil)
+			mockDB.EXPECT().WithTransaction(gomock.Any()).DoAndReturn(func(fn func(database.Interface) error) error {
+				Expect(fn(mockDB)).To(Succeed())
+				return errors.New("database is locked")
+			})
+			mockDB.EXPECT().RecordInjection(gomock.Any()).Return(nil)
+			mockLLM.EXPECT().Inject(gomock.Any(), project, version, "Bot response", gomock.Any()).Return(nil)
+			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "❌ The document was injected but it could not be recorded, please don't inject it again").Return(nil)
+
+			err := testAgent.Inject(context.Background(), channel, threadTS, project, version, false)
+			Expect(err).To(MatchError(ContainSubstring("failed to record injected documents")))
+		})
+	})
+
+	Describe("Status", func() {
+		It("should post the bot version, uptime, LLM backend and worker pool state", func() {
+			testAgent.SetStatusInfo(agent.StatusInfo{
+				Version:     "v1.2.3",
+				LLMProvider: "LlamaIndex",
+				LLMHost:     "http://llamaindex-server:5000",
+			})
+
+			var message string
+			mockSlackBot.EXPECT().PostMessage("C1234567890", "1234567890.123456", gomock.Any()).DoAndReturn(func(_, _, msg string, _ ...slackbot.MessageOption) error {
+				message = msg
+				return nil
+			})
+
+			Expect(testAgent.Status("C1234567890", "1234567890.123456")).To(Succeed())
+			Expect(message).To(ContainSubstring("Version: v1.2.3"))
+			Expect(message).To(ContainSubstring("Uptime: "))
+			Expect(message).To(ContainSubstring("LLM provider: LlamaIndex (http://llamaindex-server:5000)"))
+			Expect(message).To(ContainSubstring("Workers: 1"))
+			Expect(message).To(ContainSubstring("Queue depth: 0/5"))
+		})
+	})
+
+	Describe("Usage errors", func() {
+		var (
+			channel  = "C1234567890"
+			threadTS = "1234567890.123456"
+			user     = "U123456"
+		)
+
+		BeforeEach(func() {
+			mockSlackBot.EXPECT().GetBotUser().Return(nil).AnyTimes()
+			mockSlackBot.EXPECT().GetUserName(gomock.Any()).Return("alice", nil).AnyTimes()
+			mockDB.EXPECT().RecordCommand(gomock.Any()).Return(nil).AnyTimes()
+			mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil).AnyTimes()
+		})
+
+		It("should post the usage help as an ephemeral message to the requesting user", func() {
+			mockSlackBot.EXPECT().PostEphemeral(channel, user, "To answer the question please provide the project name (example: sriov,metallb) and the openshift version (4.16,4.18, etc..)").Return(nil)
+
+			workItem := agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+				User:      user,
+				Text:      "<@BOT123> answer sriov",
+				Channel:   channel,
+				TimeStamp: threadTS,
+			}}
+			Expect(workItem.Process(context.Background(), testAgent)).To(Succeed())
+		})
+
+		It("should post unknown command help as an ephemeral message", func() {
+			mockSlackBot.EXPECT().PostEphemeral(channel, user, "Please use one of the following commands (answer,answer-multi,cancel,continue,correct,docs,elaborate,export,history,inject,memory,mode,pin,retry,status,strict,switch,temperature,topk,unpin)").Return(nil)
+
+			workItem := agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+				User:      user,
+				Text:      "<@BOT123> unknown",
+				Channel:   channel,
+				TimeStamp: threadTS,
+			}}
+			Expect(workItem.Process(context.Background(), testAgent)).To(Succeed())
+		})
+	})
+
+	Describe("Follow-up questions", func() {
+		var (
+			channel  = "C1234567890"
+			threadTS = "1234567890.123456"
+		)
+
+		BeforeEach(func() {
+			mockSlackBot.EXPECT().GetBotUser().Return(nil).AnyTimes()
+			mockSlackBot.EXPECT().GetUserName(gomock.Any()).Return("alice", nil).AnyTimes()
+			mockDB.EXPECT().RecordCommand(gomock.Any()).Return(nil).AnyTimes()
+		})
+
+		newMention := func(text, ts string) agent.AppMentionWorkItem {
+			return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+				User:            "U123456",
+				Text:            text,
+				Channel:         channel,
+				TimeStamp:       ts,
+				ThreadTimeStamp: threadTS,
+			}}
+		}
+
+		It("should answer a bare mention using the context stored by the first command", func() {
+			// First full command creates the thread context
+			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil).Times(2)
+			mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+				{Msg: slack.Msg{Text: "User question"}},
+				{Msg: slack.Msg{Text: "<@BOT123> answer sriov 4.16"}},
+				{Msg: slack.Msg{Text: "Searching for answer..."}},
+			}, nil)
+			mockDB.EXPECT().GetSlugForThread(threadTS).Return("", false, nil)
+			mockDB.EXPECT().SetLastQuestion(threadTS, gomock.Any()).Return(nil).AnyTimes()
+			mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil)
+			mockLLM.EXPECT().CreateThread(gomock.Any(), "sriov", "4.16").Return("test-thread-slug", nil)
+			mockDB.EXPECT().CreateSlackThreadWithSlug(threadTS, "test-thread-slug", "sriov", "4.16").Return(nil)
+			mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "test-thread-slug", "User question").Return("AI response", nil)
+			mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil).Times(2)
+
+			Expect(newMention("<@BOT123> answer sriov 4.16", "1234567890.200000").Process(context.Background(), testAgent)).To(Succeed())
+
+			// Bare follow-up reuses the stored project, version and slug
+			mockDB.EXPECT().GetThreadContext(threadTS).Return(&database.SlackThreadToSlug{
+				SlackThread: threadTS,
+				ThreadSlug:  "test-thread-slug",
+				Project:     "sriov",
+				Version:     "4.16",
+			}, true, nil)
+			mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "test-thread-slug", "what about IPv6?").Return("IPv6 response", nil)
+
+			Expect(newMention("<@BOT123> what about IPv6?", "1234567890.300000").Process(context.Background(), testAgent)).To(Succeed())
+		})
+
+		It("should post the usage help when the thread has no stored context", func() {
+			mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil)
+			mockSlackBot.EXPECT().PostEphemeral(channel, "U123456", "Please use one of the following commands (answer,answer-multi,cancel,continue,correct,docs,elaborate,export,history,inject,memory,mode,pin,retry,status,strict,switch,temperature,topk,unpin)").Return(nil)
+
+			Expect(newMention("<@BOT123> what about IPv6?", "1234567890.300000").Process(context.Background(), testAgent)).To(Succeed())
+		})
+
+		It("should post the usage help when the thread context can't be loaded", func() {
+			mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, errors.New("database error"))
+			mockSlackBot.EXPECT().PostEphemeral(channel, "U123456", "Please use one of the following commands (answer,answer-multi,cancel,continue,correct,docs,elaborate,export,history,inject,memory,mode,pin,retry,status,strict,switch,temperature,topk,unpin)").Return(nil)
+
+			Expect(newMention("<@BOT123> what about IPv6?", "1234567890.300000").Process(context.Background(), testAgent)).To(Succeed())
+		})
+	})
+
+	Describe("Switch", func() {
+		var (
+			channel  = "C1234567890"
+			threadTS = "1234567890.123456"
+		)
+
+		BeforeEach(func() {
+			mockSlackBot.EXPECT().GetBotUser().Return(nil).AnyTimes()
+			mockSlackBot.EXPECT().GetUserName(gomock.Any()).Return("alice", nil).AnyTimes()
+			mockDB.EXPECT().RecordCommand(gomock.Any()).Return(nil).AnyTimes()
+			mockLLM.EXPECT().ListProjects(gomock.Any()).Return([]string{"sriov-4-dot-16", "sriov-4-dot-18"}, nil)
+			_, err := testAgent.RefreshProjects(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		newMention := func(text string) agent.AppMentionWorkItem {
+			return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+				User:            "U123456",
+				Text:            text,
+				Channel:         channel,
+				TimeStamp:       "1234567890.200000",
+				ThreadTimeStamp: threadTS,
+			}}
+		}
+
+		It("should move the thread to a new LLM thread of the valid project", func() {
+			mockDB.EXPECT().GetThreadContext(threadTS).Return(&database.SlackThreadToSlug{
+				SlackThread: threadTS,
+				ThreadSlug:  "sriov-4-16-thread",
+				Project:     "sriov",
+				Version:     "4.16",
+			}, true, nil)
+			mockLLM.EXPECT().CreateThread(gomock.Any(), "sriov", "4.18").Return("sriov-4-18-thread", nil)
+			mockDB.EXPECT().UpdateThreadContext(threadTS, "sriov-4-18-thread", "sriov", "4.18").Return(nil)
+			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "🔀 Switched this thread to project sriov on version 4.18").Return(nil)
+
+			Expect(newMention("<@BOT123> switch sriov 4.18").Process(context.Background(), testAgent)).To(Succeed())
+		})
+
+		It("should reject an invalid project without touching the thread", func() {
+			mockSlackBot.EXPECT().PostEphemeral(channel, "U123456", "Unknown project metallb on version 4.18, available projects: sriov-4-dot-16, sriov-4-dot-18").Return(nil)
+			mockLLM.EXPECT().CreateThread(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			mockDB.EXPECT().UpdateThreadContext(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+			Expect(newMention("<@BOT123> switch metallb 4.18").Process(context.Background(), testAgent)).To(Succeed())
+		})
+
+		It("should ask to answer first when the thread has no project", func() {
+			mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil)
+			mockSlackBot.EXPECT().PostEphemeral(channel, "U123456", "There is no project to switch from in this thread yet, please use answer first").Return(nil)
+
+			Expect(newMention("<@BOT123> switch sriov 4.18").Process(context.Background(), testAgent)).To(Succeed())
+		})
+
+		It("should post the usage when the version is missing", func() {
+			mockSlackBot.EXPECT().PostEphemeral(channel, "U123456", "To switch the thread please provide the project name (example: sriov,metallb) and the openshift version (4.16,4.18, etc..)").Return(nil)
+
+			Expect(newMention("<@BOT123> switch sriov").Process(context.Background(), testAgent)).To(Succeed())
+		})
+	})
+
+	Describe("Deleted threads", func() {
+		const (
+			channel  = "C1234567890"
+			threadTS = "1234567890.100000"
+		)
+
+		threadGone := fmt.Errorf("failed to post message: %w: %w", slackbot.ErrThreadGone, slack.SlackErrorResponse{Err: "thread_not_found"})
+
+		mention := agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            "U123456",
+			Text:            "<@BOT123> answer sriov 4.16",
+			Channel:         channel,
+			TimeStamp:       "1234567890.200000",
+			ThreadTimeStamp: threadTS,
+		}}
+
+		BeforeEach(func() {
+			mockSlackBot.EXPECT().GetBotUser().Return(nil).AnyTimes()
+			mockSlackBot.EXPECT().GetUserName(gomock.Any()).Return("alice", nil).AnyTimes()
+			mockDB.EXPECT().RecordCommand(gomock.Any()).Return(nil)
+		})
+
+		It("should stop and drop the thread mapping when the thread was deleted before the first post", func() {
+			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(threadGone)
+			mockDB.EXPECT().DeleteThread(threadTS).Return(nil)
+
+			Expect(mention.Process(context.Background(), testAgent)).To(Succeed())
+		})
+
+		It("should not fall back to plain text when the thread was deleted while answering", func() {
+			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+			mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+				{Msg: slack.Msg{Text: "User message 1"}},
+				{Msg: slack.Msg{Text: "Bot response"}},
+				{Msg: slack.Msg{Text: "User question"}},
+			}, nil)
+			mockDB.EXPECT().GetSlugForThread(threadTS).Return("existing-slug", true, nil)
+			mockDB.EXPECT().SetLastQuestion(threadTS, gomock.Any()).Return(nil).AnyTimes()
+			mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil).AnyTimes()
+			mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "existing-slug", gomock.Any()).Return("AI response", nil)
+			mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(
+				fmt.Errorf("failed to post blocks: %w: %w", slackbot.ErrThreadGone, slack.SlackErrorResponse{Err: "channel_not_found"}))
+			mockDB.EXPECT().DeleteThread(threadTS).Return(nil)
+
+			Expect(mention.Process(context.Background(), testAgent)).To(Succeed())
+		})
+
+		It("should still report other posting failures", func() {
+			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(errors.New("not_in_channel"))
+
+			Expect(mention.Process(context.Background(), testAgent)).To(MatchError(ContainSubstring("failed to post initial message")))
+		})
 	})
 
 	Describe("Start", func() {
@@ -255,7 +868,10 @@ var _ = Describe("Agent", func() {
 				UserID: "BOT123",
 			}
 			mockSlackBot.EXPECT().GetBotUser().Return(botUser).AnyTimes()
-			mockSlackBot.EXPECT().PostMessage(gomock.Any(), gomock.Any(), "Please use one of the following commands (answer,elaborate,inject)").Return(nil).AnyTimes()
+			mockSlackBot.EXPECT().GetUserName(gomock.Any()).Return("alice", nil).AnyTimes()
+			mockSlackBot.EXPECT().PostEphemeral(gomock.Any(), gomock.Any(), "Please use one of the following commands (answer,answer-multi,cancel,continue,correct,docs,elaborate,export,history,inject,memory,mode,pin,retry,status,strict,switch,temperature,topk,unpin)").Return(nil).AnyTimes()
+			mockDB.EXPECT().RecordCommand(gomock.Any()).Return(nil).AnyTimes()
+			mockDB.EXPECT().GetThreadContext(gomock.Any()).Return(nil, false, nil).AnyTimes()
 
 			// Mock the Start method to not block
 			mockSlackBot.EXPECT().Start(gomock.Any()).Do(func(ctx context.Context) {
@@ -263,13 +879,60 @@ var _ = Describe("Agent", func() {
 			})
 
 			// Start the agent in a goroutine
-			go testAgent.Start(ctx)
+			stopped := startAgent(ctx, testAgent)
 
 			// Send an event
 			appMentionChannel <- testEvent
 
-			// Wait for context to complete
-			<-ctx.Done()
+			// Start returns once the context is done and the workers exited
+			Eventually(stopped).Should(BeClosed())
+		})
+
+		It("should post a busy message when the work queue is full", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			// Block the single worker so the queue fills up
+			unblock := make(chan struct{})
+			mockSlackBot.EXPECT().GetBotUser().DoAndReturn(func() *slack.AuthTestResponse {
+				<-unblock
+				return nil
+			}).AnyTimes()
+			mockSlackBot.EXPECT().GetUserName(gomock.Any()).Return("alice", nil).AnyTimes()
+			mockDB.EXPECT().GetThreadContext(gomock.Any()).Return(nil, false, nil).AnyTimes()
+			mockDB.EXPECT().RecordCommand(gomock.Any()).Return(nil).AnyTimes()
+			mockSlackBot.EXPECT().PostEphemeral(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+			busy := make(chan struct{})
+			var busyOnce sync.Once
+			mockSlackBot.EXPECT().PostMessage("C1234567890", "1234567890.123456", "⏳ The system is busy, please retry shortly").DoAndReturn(func(_, _, _ string, _ ...slackbot.MessageOption) error {
+				busyOnce.Do(func() { close(busy) })
+				return nil
+			}).MinTimes(1)
+			mockSlackBot.EXPECT().Start(gomock.Any()).Do(func(ctx context.Context) {
+				<-ctx.Done()
+			})
+
+			stopped := startAgent(ctx, testAgent)
+
+			// One event is held by the worker, 5 fill the queue and the last one is dropped
+			go func() {
+				for i := 0; i < 7; i++ {
+					appMentionChannel <- &slackevents.AppMentionEvent{
+						User:      "U123456",
+						Text:      "<@BOT123> invalid",
+						Channel:   "C1234567890",
+						TimeStamp: "1234567890.123456",
+					}
+				}
+			}()
+
+			Eventually(busy).Should(BeClosed())
+
+			// Release the worker and wait for the agent to stop
+			close(unblock)
+			cancel()
+			Eventually(stopped).Should(BeClosed())
 		})
 	})
 })
@@ -0,0 +1,54 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+)
+
+// SetWorkspaceAliases lets the admins change the workspace aliases of the LLM client with the alias command
+func (a *Agent) SetWorkspaceAliases(aliases *llm.WorkspaceAliases) {
+	a.aliases = aliases
+}
+
+// Alias shows an admin the workspace aliases, or routes the project and version to the workspace slug of the
+// arguments. Without a slug the project and version go back to their derived workspace. The project allowlist is
+// reloaded so an aliased workspace is accepted right away, the aliases are lost when the bot restarts
+func (a *Agent) Alias(ctx context.Context, channel, user string, arguments []string) error {
+	if !a.isAdmin(user) {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("alias.notAdmin"))
+	}
+	if a.aliases == nil {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("alias.unavailable"))
+	}
+
+	switch len(arguments) {
+	case 0:
+		aliases := a.aliases.List()
+		if len(aliases) == 0 {
+			return a.slackBot.PostEphemeral(channel, user, i18n.T("alias.none"))
+		}
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("alias.list", strings.Join(aliases, "\n• ")))
+	case 2, 3:
+	default:
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("usage.alias"))
+	}
+
+	project, version, slug := arguments[0], arguments[1], ""
+	if len(arguments) == 3 {
+		slug = arguments[2]
+	}
+	a.aliases.Set(project, version, slug)
+	fmt.Printf("🏷️ %s routed %s/%s to workspace %s\n", user, project, version, a.aliases.Slug(project, version))
+	if _, err := a.RefreshProjects(ctx); err != nil {
+		fmt.Printf("⚠️ Failed to reload the project allowlist after the alias: %v\n", err)
+	}
+
+	if slug == "" {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("alias.removed", project, version, a.aliases.Slug(project, version)))
+	}
+	return a.slackBot.PostEphemeral(channel, user, i18n.T("alias.done", project, version, slug))
+}
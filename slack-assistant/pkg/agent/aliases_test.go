@@ -0,0 +1,96 @@
+package agent_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Alias", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.100000"
+		admin    = "UADMIN"
+	)
+
+	var (
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		aliases      *llm.WorkspaceAliases
+		testAgent    *agent.Agent
+	)
+
+	mention := func(user, command string) error {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            "<@BOT123> " + command,
+			Channel:         channel,
+			TimeStamp:       "1234567890.900000",
+			ThreadTimeStamp: threadTS,
+		}}.Process(context.Background(), testAgent)
+	}
+
+	BeforeEach(func() {
+		testAgent, _, mockSlackBot, mockLLM = newTestAgent()
+		testAgent.AddAdmins(admin)
+
+		var err error
+		aliases, err = llm.NewWorkspaceAliases("metallb/4.18=metallb-prod")
+		Expect(err).NotTo(HaveOccurred())
+		testAgent.SetWorkspaceAliases(aliases)
+	})
+
+	It("should list the aliases", func() {
+		mockSlackBot.EXPECT().PostEphemeral(channel, admin, "🏷️ Workspace aliases:\n• metallb/4.18=metallb-prod").Return(nil)
+
+		Expect(mention(admin, "alias")).To(Succeed())
+	})
+
+	It("should route the project to the workspace and reload the allowlist", func() {
+		mockLLM.EXPECT().ListProjects(gomock.Any()).Return([]string{"sriov-4-dot-16", "metallb-4-dot-18"}, nil)
+		mockSlackBot.EXPECT().PostEphemeral(channel, admin, "🏷️ sriov 4.16 now uses workspace `team-sriov`").Return(nil)
+
+		Expect(mention(admin, "alias sriov 4.16 team-sriov")).To(Succeed())
+		Expect(aliases.Slug("sriov", "4.16")).To(Equal("team-sriov"))
+		Expect(aliases.Slug("metallb", "4.18")).To(Equal("metallb-prod"))
+	})
+
+	It("should route the project back to its derived workspace without a slug", func() {
+		mockLLM.EXPECT().ListProjects(gomock.Any()).Return([]string{"metallb-4-dot-18"}, nil)
+		mockSlackBot.EXPECT().PostEphemeral(channel, admin, "🏷️ metallb 4.18 uses workspace `metallb-4-dot-18` again").Return(nil)
+
+		Expect(mention(admin, "alias metallb 4.18")).To(Succeed())
+		Expect(aliases.Slug("metallb", "4.18")).To(Equal("metallb-4-dot-18"))
+	})
+
+	It("should show the usage of a project without a version", func() {
+		mockSlackBot.EXPECT().PostEphemeral(channel, admin, gomock.Any()).DoAndReturn(func(_, _, message string) error {
+			Expect(message).To(HavePrefix("To route a project to a workspace please use"))
+			return nil
+		})
+
+		Expect(mention(admin, "alias sriov")).To(Succeed())
+	})
+
+	It("should tell when the LLM backend has no aliases", func() {
+		testAgent.SetWorkspaceAliases(nil)
+		mockSlackBot.EXPECT().PostEphemeral(channel, admin, "Workspace aliases aren't available with this LLM backend").Return(nil)
+
+		Expect(mention(admin, "alias sriov 4.16 team-sriov")).To(Succeed())
+	})
+
+	It("should only be available to the admins", func() {
+		mockSlackBot.EXPECT().PostEphemeral(channel, "U123456", "Only the bot admins can use alias").Return(nil)
+
+		Expect(mention("U123456", "alias sriov 4.16 team-sriov")).To(Succeed())
+		Expect(aliases.Slug("sriov", "4.16")).To(Equal("sriov-4-dot-16"))
+	})
+})
@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+)
+
+// SetAnswerChannel also posts the answers to the channel, e.g. to review them in one place, with a link back to
+// the thread of their question. redirect posts the answers only to the channel and tells the thread where they are
+func (a *Agent) SetAnswerChannel(channel string, redirect bool) {
+	a.answerChannel = channel
+	a.redirectAnswers = redirect
+}
+
+// postThreadAnswer posts the answer of the thread, to the answer channel too when one is set. A mirrored answer
+// that can't be posted to the answer channel is only logged, the thread got its answer
+func (a *Agent) postThreadAnswer(ctx context.Context, channel, threadTS, intro, answer string, sources []string, broadcast bool) error {
+	if a.answerChannel == "" || a.answerChannel == channel {
+		return a.postAnswerWithRetry(ctx, channel, threadTS, intro, answer, sources, broadcast)
+	}
+
+	answerIntro := i18n.T("answerChannel.intro", a.threadLink(channel, threadTS))
+	if a.redirectAnswers {
+		if err := a.postAnswerWithRetry(ctx, a.answerChannel, "", answerIntro, answer, sources, false); err != nil {
+			return err
+		}
+		if err := a.slackBot.PostMessage(channel, threadTS, i18n.T("answerChannel.redirected", a.answerChannel)); err != nil {
+			fmt.Printf("⚠️ Failed to tell thread %s where its answer was posted: %v\n", threadTS, err)
+		}
+		return nil
+	}
+
+	if err := a.postAnswerWithRetry(ctx, channel, threadTS, intro, answer, sources, broadcast); err != nil {
+		return err
+	}
+	if err := a.postAnswer(a.answerChannel, "", answerIntro, answer, sources, false); err != nil {
+		fmt.Printf("⚠️ Failed to mirror the answer of thread %s to channel %s: %v\n", threadTS, a.answerChannel, err)
+	}
+	return nil
+}
+
+// threadLink returns the permalink of the thread, or a link to its channel when the permalink can't be generated
+func (a *Agent) threadLink(channel, threadTS string) string {
+	permalink, err := a.slackBot.GetPermalink(channel, threadTS)
+	if err != nil {
+		fmt.Printf("⚠️ Failed to get the permalink of thread %s: %v\n", threadTS, err)
+		return fmt.Sprintf("<#%s>", channel)
+	}
+	return permalink
+}
@@ -0,0 +1,123 @@
+package agent_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+	slackbot "github.com/SchSeba/slack-ai-assistant/pkg/slack-bot"
+)
+
+var _ = Describe("Answer channel", func() {
+	const (
+		channel       = "C1234567890"
+		threadTS      = "1234567890.100000"
+		reviewChannel = "CREVIEW"
+		permalink     = "https://example.slack.com/archives/C1234567890/p1234567890100000"
+	)
+
+	var (
+		ctrl         *gomock.Controller
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	followUp := func() error {
+		return testAgent.FollowUp(context.Background(), channel, threadTS, &database.SlackThreadToSlug{
+			ThreadSlug: "stored-slug",
+			Project:    "metallb",
+			Version:    "4.18",
+		}, "How do I configure BGP?")
+	}
+
+	// expectAnswerText expects the answer blocks posted to the channel and thread to read text
+	expectAnswerText := func(channel, threadTS, text string) *gomock.Call {
+		return mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).DoAndReturn(func(_, _ string, blocks []slack.Block, _ ...slackbot.MessageOption) error {
+			Expect(slackbot.AnswerText(slack.Blocks{BlockSet: blocks})).To(Equal(text))
+			return nil
+		})
+	}
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockDB := databaseMock.NewMockInterface(ctrl)
+		mockSlackBot = slackbotMock.NewMockInterface(ctrl)
+		mockLLM = llmMock.NewMockInterface(ctrl)
+		testAgent = agent.NewAgent(mockDB, mockSlackBot, mockLLM, nil, nil, nil, nil, 1, 10)
+		testAgent.SetPostRetry(1, 0)
+
+		mockDB.EXPECT().SetThreadTruncated(gomock.Any(), false).Return(nil).AnyTimes()
+		mockLLM.EXPECT().LastContext(gomock.Any()).Return(nil).AnyTimes()
+
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "stored-slug", gomock.Any()).Return("Create a BGPPeer", nil)
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	It("should post the answer to the thread only without answer channel", func() {
+		expectAnswerText(channel, threadTS, "Here is the information I was able to find\nCreate a BGPPeer")
+
+		Expect(followUp()).To(Succeed())
+	})
+
+	It("should mirror the answer to the answer channel with a link to the thread", func() {
+		testAgent.SetAnswerChannel(reviewChannel, false)
+		mockSlackBot.EXPECT().GetPermalink(channel, threadTS).Return(permalink, nil)
+		gomock.InOrder(
+			expectAnswerText(channel, threadTS, "Here is the information I was able to find\nCreate a BGPPeer"),
+			expectAnswerText(reviewChannel, "", "💬 Answer to "+permalink+"\nCreate a BGPPeer"),
+		)
+
+		Expect(followUp()).To(Succeed())
+	})
+
+	It("should link the channel when the permalink of the thread can't be generated", func() {
+		testAgent.SetAnswerChannel(reviewChannel, false)
+		mockSlackBot.EXPECT().GetPermalink(channel, threadTS).Return("", errors.New("channel_not_found"))
+		expectAnswerText(channel, threadTS, "Here is the information I was able to find\nCreate a BGPPeer")
+		expectAnswerText(reviewChannel, "", "💬 Answer to <#C1234567890>\nCreate a BGPPeer")
+
+		Expect(followUp()).To(Succeed())
+	})
+
+	It("should keep the answer of the thread when it can't be mirrored", func() {
+		testAgent.SetAnswerChannel(reviewChannel, false)
+		mockSlackBot.EXPECT().GetPermalink(channel, threadTS).Return(permalink, nil)
+		expectAnswerText(channel, threadTS, "Here is the information I was able to find\nCreate a BGPPeer")
+		mockSlackBot.EXPECT().PostBlocks(reviewChannel, "", gomock.Any()).Return(errors.New("not_in_channel"))
+		mockSlackBot.EXPECT().PostMessage(reviewChannel, "", gomock.Any()).Return(errors.New("not_in_channel"))
+
+		Expect(followUp()).To(Succeed())
+	})
+
+	It("should redirect the answer to the answer channel and tell the thread", func() {
+		testAgent.SetAnswerChannel(reviewChannel, true)
+		mockSlackBot.EXPECT().GetPermalink(channel, threadTS).Return(permalink, nil)
+		expectAnswerText(reviewChannel, "", "💬 Answer to "+permalink+"\nCreate a BGPPeer")
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "📬 The answer was posted in <#CREVIEW>").Return(nil)
+
+		Expect(followUp()).To(Succeed())
+	})
+
+	It("should post the answer once when the question is asked in the answer channel", func() {
+		testAgent.SetAnswerChannel(channel, false)
+		mockSlackBot.EXPECT().GetPermalink(gomock.Any(), gomock.Any()).Times(0)
+		expectAnswerText(channel, threadTS, "Here is the information I was able to find\nCreate a BGPPeer")
+
+		Expect(followUp()).To(Succeed())
+	})
+})
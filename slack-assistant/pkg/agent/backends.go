@@ -0,0 +1,68 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+)
+
+// SetBackends lets the admins switch the LLM backend at runtime, the agent must answer with the registry
+func (a *Agent) SetBackends(registry *llm.Registry) {
+	a.backends = registry
+}
+
+// Backends shows an admin the configured LLM backends and the one answering
+func (a *Agent) Backends(channel, user string) error {
+	if !a.isAdmin(user) {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("backend.notAdmin"))
+	}
+	if a.backends == nil {
+		provider, host := a.llmBackend()
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("backend.single", provider, host))
+	}
+
+	active := a.backends.Active().Name
+	lines := []string{i18n.T("backends.header")}
+	for _, backend := range a.backends.Backends() {
+		line := i18n.T("backends.line", backend.Name, backend.Provider, backend.Host)
+		if backend.Name == active {
+			line += i18n.T("backends.active")
+		}
+		lines = append(lines, line)
+	}
+	return a.slackBot.PostEphemeral(channel, user, strings.Join(lines, "\n"))
+}
+
+// Backend switches the LLM backend answering every thread, the stored LLM threads of the previous backend are
+// replaced when the new one doesn't know them
+func (a *Agent) Backend(channel, user, name string) error {
+	if !a.isAdmin(user) {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("backend.notAdmin"))
+	}
+	if a.backends == nil {
+		provider, host := a.llmBackend()
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("backend.single", provider, host))
+	}
+
+	if err := a.backends.Use(name); errors.Is(err, llm.ErrUnknownBackend) {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("usage.backend", strings.Join(a.backends.Names(), ", ")))
+	} else if err != nil {
+		return fmt.Errorf("failed to switch the LLM backend: %w", err)
+	}
+
+	backend := a.backends.Active()
+	fmt.Printf("🧠 %s switched the LLM backend to %s (%s)\n", user, backend.Name, backend.Host)
+	return a.slackBot.PostEphemeral(channel, user, i18n.T("backend.done", backend.Name, backend.Provider))
+}
+
+// llmBackend returns the provider and host of the LLM backend answering
+func (a *Agent) llmBackend() (string, string) {
+	if a.backends == nil {
+		return a.statusInfo.LLMProvider, a.statusInfo.LLMHost
+	}
+	backend := a.backends.Active()
+	return backend.Provider, backend.Host
+}
@@ -0,0 +1,127 @@
+package agent_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Backends", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.100000"
+		admin    = "UADMIN"
+	)
+
+	var (
+		ctrl            *gomock.Controller
+		mockDB          *databaseMock.MockInterface
+		mockSlackBot    *slackbotMock.MockInterface
+		mockAnythingLLM *llmMock.MockInterface
+		mockLlamaIndex  *llmMock.MockInterface
+		registry        *llm.Registry
+		testAgent       *agent.Agent
+	)
+
+	mention := func(user, text string) error {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            text,
+			Channel:         channel,
+			TimeStamp:       "1234567890.200000",
+			ThreadTimeStamp: threadTS,
+		}}.Process(context.Background(), testAgent)
+	}
+
+	followUp := func() error {
+		return testAgent.FollowUp(context.Background(), channel, threadTS, &database.SlackThreadToSlug{
+			ThreadSlug: "stored-slug",
+			Project:    "metallb",
+			Version:    "4.18",
+		}, "How do I configure BGP?")
+	}
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockDB = databaseMock.NewMockInterface(ctrl)
+		mockSlackBot = slackbotMock.NewMockInterface(ctrl)
+		mockAnythingLLM = llmMock.NewMockInterface(ctrl)
+		mockLlamaIndex = llmMock.NewMockInterface(ctrl)
+
+		var err error
+		registry, err = llm.NewRegistry("anythingllm",
+			llm.Backend{Name: "anythingllm", Provider: "AnythingLLM", Host: "anythingllm:3001", Client: mockAnythingLLM},
+			llm.Backend{Name: "llamaindex", Provider: "LlamaIndex", Host: "http://llamaindex:5000", Client: mockLlamaIndex},
+		)
+		Expect(err).NotTo(HaveOccurred())
+		testAgent = agent.NewAgent(mockDB, mockSlackBot, registry, nil, nil, nil, nil, 1, 10)
+		testAgent.SetBackends(registry)
+		testAgent.AddAdmins(admin)
+
+		mockSlackBot.EXPECT().GetBotUser().Return(nil).AnyTimes()
+		mockSlackBot.EXPECT().GetUserName(gomock.Any()).Return("alice", nil).AnyTimes()
+		mockDB.EXPECT().RecordCommand(gomock.Any()).Return(nil).AnyTimes()
+		mockDB.EXPECT().SetThreadTruncated(gomock.Any(), false).Return(nil).AnyTimes()
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	It("should list the backends with the active one", func() {
+		mockSlackBot.EXPECT().PostEphemeral(channel, admin, "🧠 LLM backends:\n"+
+			"• `anythingllm` - AnythingLLM (anythingllm:3001) ✅ active\n"+
+			"• `llamaindex` - LlamaIndex (http://llamaindex:5000)").Return(nil)
+
+		Expect(mention(admin, "<@BOT123> backends")).To(Succeed())
+	})
+
+	It("should answer with the backend switched to", func() {
+		mockSlackBot.EXPECT().PostEphemeral(channel, admin, "🧠 Every thread now answers with the llamaindex backend (LlamaIndex)").Return(nil)
+		Expect(mention(admin, "<@BOT123> backend llamaindex")).To(Succeed())
+		Expect(registry.Active().Name).To(Equal("llamaindex"))
+
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockAnythingLLM.EXPECT().SendMessageToChat(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		mockLlamaIndex.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "stored-slug", gomock.Any()).Return("Create a BGPPeer", nil)
+		mockLlamaIndex.EXPECT().LastContext("stored-slug").Return(nil)
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+
+		Expect(followUp()).To(Succeed())
+	})
+
+	It("should reject an unknown backend and keep the active one", func() {
+		mockSlackBot.EXPECT().PostEphemeral(channel, admin, "To switch the LLM backend please use `backend <name>` with one of: anythingllm, llamaindex").Return(nil).Times(2)
+
+		Expect(mention(admin, "<@BOT123> backend openai")).To(Succeed())
+		Expect(mention(admin, "<@BOT123> backend")).To(Succeed())
+		Expect(registry.Active().Name).To(Equal("anythingllm"))
+	})
+
+	It("should only let the admins switch the backend", func() {
+		mockSlackBot.EXPECT().PostEphemeral(channel, "U123456", "Only the bot admins can list and switch the LLM backends").Return(nil).Times(2)
+
+		Expect(mention("U123456", "<@BOT123> backend llamaindex")).To(Succeed())
+		Expect(mention("U123456", "<@BOT123> backends")).To(Succeed())
+		Expect(registry.Active().Name).To(Equal("anythingllm"))
+	})
+
+	It("should tell the admin when a single backend is configured", func() {
+		testAgent = agent.NewAgent(mockDB, mockSlackBot, mockAnythingLLM, nil, nil, nil, nil, 1, 10)
+		testAgent.SetStatusInfo(agent.StatusInfo{LLMProvider: "AnythingLLM", LLMHost: "anythingllm:3001"})
+		testAgent.AddAdmins(admin)
+		mockSlackBot.EXPECT().PostEphemeral(channel, admin, "Only the AnythingLLM backend (anythingllm:3001) is configured, set AI_BACKENDS to switch between backends").Return(nil)
+
+		Expect(mention(admin, "<@BOT123> backend llamaindex")).To(Succeed())
+	})
+})
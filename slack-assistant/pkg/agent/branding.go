@@ -0,0 +1,93 @@
+package agent
+
+import (
+	"fmt"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+)
+
+// Branding holds the emoji and prefixes of the status messages posted by the bot,
+// custom workspace emoji can be used with their :name: syntax
+type Branding struct {
+	// ProcessingEmoji starts the busy and temporarily unavailable messages
+	ProcessingEmoji string
+	// SuccessEmoji marks the successful commands in the App Home tab
+	SuccessEmoji string
+	// ErrorEmoji starts the error messages and marks the failed commands in the App Home tab
+	ErrorEmoji string
+	// ErrorPrefix introduces the detail of a failed LLM call
+	ErrorPrefix string
+	// SearchingMessage is posted while an answer is generated
+	SearchingMessage string
+	// ElaboratingMessage is posted while a message is elaborated
+	ElaboratingMessage string
+	// AnswerIntro introduces the answers, answers are posted without introduction when it is empty
+	AnswerIntro string
+	// AnswerFooter is appended to the answers, e.g. a disclaimer, answers are posted without footer when it is empty
+	AnswerFooter string
+}
+
+// DefaultBranding returns the emoji and prefixes used unless configured otherwise
+func DefaultBranding() Branding {
+	return Branding{
+		ProcessingEmoji:    "⏳",
+		SuccessEmoji:       "✅",
+		ErrorEmoji:         "❌",
+		ErrorPrefix:        i18n.T("error.prefix"),
+		SearchingMessage:   i18n.T("progress.searching"),
+		ElaboratingMessage: i18n.T("progress.elaborating"),
+		AnswerIntro:        i18n.T("answer.intro"),
+	}
+}
+
+// SetBranding sets the emoji and prefixes of the status messages
+func (a *Agent) SetBranding(branding Branding) {
+	a.branding = branding
+}
+
+// withFooter returns the text of an answer followed by the answer footer, for the answers posted as text
+func (b Branding) withFooter(text string) string {
+	if b.AnswerFooter == "" {
+		return text
+	}
+	return fmt.Sprintf("%s\n\n%s", text, b.AnswerFooter)
+}
+
+// busyMessage is posted when a request is dropped because the worker pool is saturated
+func (b Branding) busyMessage() string {
+	return withEmoji(b.ProcessingEmoji, i18n.T("error.busy"))
+}
+
+// errorMessage returns the message posted to the user when an LLM call fails, worded after the error category.
+// Only user errors show the detail, it tells the user what to fix.
+func (b Branding) errorMessage(err error) string {
+	switch ClassifyError(err) {
+	case UnavailableError:
+		return withEmoji(b.ProcessingEmoji, i18n.T("error.unavailable"))
+	case UserError:
+		return withEmoji(b.ErrorEmoji, fmt.Sprintf("%s %v", b.ErrorPrefix, err))
+	default:
+		return withEmoji(b.ErrorEmoji, b.ErrorPrefix+" "+i18n.T("error.system"))
+	}
+}
+
+// statusEmoji returns the emoji marking a command as successful or failed
+func (b Branding) statusEmoji(success bool) string {
+	if success {
+		return b.SuccessEmoji
+	}
+	return b.ErrorEmoji
+}
+
+// isProgressMessage reports whether the text is one of the progress messages posted while a command runs
+func (b Branding) isProgressMessage(text string) bool {
+	return text == b.SearchingMessage || text == b.ElaboratingMessage
+}
+
+// withEmoji starts the text with the emoji, the text is returned as is when no emoji is configured
+func withEmoji(emoji, text string) string {
+	if emoji == "" {
+		return text
+	}
+	return emoji + " " + text
+}
@@ -0,0 +1,169 @@
+package agent_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+	slackbot "github.com/SchSeba/slack-ai-assistant/pkg/slack-bot"
+)
+
+var _ = Describe("Branding", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.100000"
+		user     = "U123456"
+	)
+
+	branding := agent.Branding{
+		ProcessingEmoji:    ":hourglass_flowing_sand:",
+		SuccessEmoji:       ":white_check_mark:",
+		ErrorEmoji:         ":rotating_light:",
+		ErrorPrefix:        "Oops:",
+		SearchingMessage:   ":mag: Looking into it...",
+		ElaboratingMessage: ":pencil: Writing more...",
+	}
+
+	var (
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	docs := func() error {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            "<@BOT123> docs metallb 4.18",
+			Channel:         channel,
+			TimeStamp:       "1234567890.200000",
+			ThreadTimeStamp: threadTS,
+		}}.Process(context.Background(), testAgent)
+	}
+
+	BeforeEach(func() {
+		testAgent, _, mockSlackBot, mockLLM = newTestAgent()
+		testAgent.SetBranding(branding)
+	})
+
+	It("should use the error emoji and prefix when the LLM fails", func() {
+		mockLLM.EXPECT().ListDocuments(gomock.Any(), "metallb", "4.18").Return(nil, errors.New("workspace not found"))
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, ":rotating_light: Oops: something went wrong on our side, please try again later or contact the bot admins").Return(nil)
+
+		Expect(docs()).To(HaveOccurred())
+	})
+
+	It("should use the processing emoji when the assistant is unavailable", func() {
+		mockLLM.EXPECT().ListDocuments(gomock.Any(), "metallb", "4.18").Return(nil, llm.ErrCircuitOpen)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS,
+			":hourglass_flowing_sand: The assistant is temporarily unavailable, please try again in a few minutes").Return(nil)
+
+		Expect(docs()).To(HaveOccurred())
+	})
+
+	It("should post the searching message", func() {
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, ":mag: Looking into it...").Return(errors.New("post failed"))
+
+		Expect(testAgent.AnswerQuestion(context.Background(), channel, threadTS, "metallb", "4.18", false, false)).To(HaveOccurred())
+	})
+
+	DescribeTable("should introduce the answer with the configured intro",
+		func(intro, expected string) {
+			answerBranding := agent.DefaultBranding()
+			answerBranding.AnswerIntro = intro
+			testAgent.SetBranding(answerBranding)
+			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+			mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "stored-slug", "How do I configure BGP?").Return("Create a BGPPeer", nil)
+			mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).DoAndReturn(func(_, _ string, blocks []slack.Block, _ ...slackbot.MessageOption) error {
+				Expect(slackbot.AnswerText(slack.Blocks{BlockSet: blocks})).To(Equal(expected))
+				return nil
+			})
+
+			Expect(testAgent.FollowUp(context.Background(), channel, threadTS, &database.SlackThreadToSlug{
+				ThreadSlug: "stored-slug",
+				Project:    "metallb",
+				Version:    "4.18",
+			}, "How do I configure BGP?")).To(Succeed())
+		},
+		Entry("default intro", agent.DefaultBranding().AnswerIntro, "Here is the information I was able to find\nCreate a BGPPeer"),
+		Entry("no intro", "", "Create a BGPPeer"),
+	)
+
+	It("should post the plain text answer without intro when it is empty", func() {
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, ":mag: Looking into it...").Return(nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "stored-slug", gomock.Any()).Return("Create a BGPPeer", nil)
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(errors.New("invalid_blocks"))
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Create a BGPPeer").Return(nil)
+
+		Expect(testAgent.FollowUp(context.Background(), channel, threadTS, &database.SlackThreadToSlug{
+			ThreadSlug: "stored-slug",
+			Project:    "metallb",
+			Version:    "4.18",
+		}, "How do I configure BGP?")).To(Succeed())
+	})
+
+	It("should append the answer footer after the sources and before the buttons", func() {
+		footerBranding := agent.DefaultBranding()
+		footerBranding.AnswerFooter = "_AI-generated, verify before acting_"
+		testAgent.SetBranding(footerBranding)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "stored-slug", gomock.Any()).Return("Create a BGPPeer", nil)
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).DoAndReturn(func(_, _ string, blocks []slack.Block, _ ...slackbot.MessageOption) error {
+			Expect(len(blocks)).To(BeNumerically(">=", 2))
+			footer, ok := blocks[len(blocks)-2].(*slack.ContextBlock)
+			Expect(ok).To(BeTrue())
+			text, ok := footer.ContextElements.Elements[0].(*slack.TextBlockObject)
+			Expect(ok).To(BeTrue())
+			Expect(text.Text).To(Equal("_AI-generated, verify before acting_"))
+			Expect(blocks[len(blocks)-1].BlockType()).To(Equal(slack.MBTAction))
+			Expect(slackbot.AnswerText(slack.Blocks{BlockSet: blocks})).NotTo(ContainSubstring("AI-generated"))
+			return nil
+		})
+
+		Expect(testAgent.FollowUp(context.Background(), channel, threadTS, &database.SlackThreadToSlug{
+			ThreadSlug: "stored-slug",
+			Project:    "metallb",
+			Version:    "4.18",
+		}, "How do I configure BGP?")).To(Succeed())
+	})
+
+	It("should append the answer footer to the plain text answer but not to the status messages", func() {
+		footerBranding := branding
+		footerBranding.AnswerFooter = "_AI-generated, verify before acting_"
+		testAgent.SetBranding(footerBranding)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, ":mag: Looking into it...").Return(nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "stored-slug", gomock.Any()).Return("Create a BGPPeer", nil)
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(errors.New("invalid_blocks"))
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Create a BGPPeer\n\n_AI-generated, verify before acting_").Return(nil)
+
+		Expect(testAgent.FollowUp(context.Background(), channel, threadTS, &database.SlackThreadToSlug{
+			ThreadSlug: "stored-slug",
+			Project:    "metallb",
+			Version:    "4.18",
+		}, "How do I configure BGP?")).To(Succeed())
+	})
+
+	It("should mark the App Home activity with the status emoji", func() {
+		view := agent.BuildHomeView([]database.CommandAudit{
+			{Command: "answer", Args: "sriov 4.16", Channel: "C123", Success: true},
+			{Command: "inject", Args: "metallb 4.18", Channel: "C456", Success: false},
+		}, nil, branding)
+
+		for index, emoji := range map[int]string{6: ":white_check_mark:", 7: ":rotating_light:"} {
+			block, ok := view.Blocks.BlockSet[index].(*slack.ContextBlock)
+			Expect(ok).To(BeTrue())
+			text, ok := block.ContextElements.Elements[0].(*slack.TextBlockObject)
+			Expect(ok).To(BeTrue())
+			Expect(text.Text).To(HavePrefix(emoji + " `"))
+		}
+	})
+})
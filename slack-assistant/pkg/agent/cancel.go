@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+)
+
+// inFlightWork holds the cancel functions of the commands running in each thread, so cancel can stop them
+type inFlightWork struct {
+	mu      sync.Mutex
+	nextID  uint64
+	cancels map[string]map[uint64]context.CancelFunc
+}
+
+// track returns a context cancelled by cancel for the thread, and the function to call once the command finished
+func (w *inFlightWork) track(ctx context.Context, threadTS string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cancels == nil {
+		w.cancels = map[string]map[uint64]context.CancelFunc{}
+	}
+	if w.cancels[threadTS] == nil {
+		w.cancels[threadTS] = map[uint64]context.CancelFunc{}
+	}
+	w.nextID++
+	id := w.nextID
+	w.cancels[threadTS][id] = cancel
+
+	return ctx, func() {
+		cancel()
+
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.cancels[threadTS], id)
+		if len(w.cancels[threadTS]) == 0 {
+			delete(w.cancels, threadTS)
+		}
+	}
+}
+
+// cancel cancels the commands running in the thread and returns how many there were
+func (w *inFlightWork) cancel(threadTS string) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	cancels := w.cancels[threadTS]
+	for _, cancel := range cancels {
+		cancel()
+	}
+	return len(cancels)
+}
+
+// Cancel stops the commands still running in the thread, e.g. a question asked by mistake,
+// the cancelled commands stop without posting their failure
+func (a *Agent) Cancel(channel, threadTS, user string) error {
+	cancelled := a.inFlight.cancel(threadTS)
+	if cancelled == 0 {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("cancel.nothing"))
+	}
+
+	fmt.Printf("🚫 Cancelled %d command(s) in thread %s\n", cancelled, threadTS)
+	if err := a.slackBot.PostMessage(channel, threadTS, i18n.T("cancel.done")); err != nil {
+		return fmt.Errorf("failed to send response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package agent_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Cancel", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.100000"
+		user     = "U123456"
+	)
+
+	var (
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	mention := func(text string) error {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            text,
+			Channel:         channel,
+			TimeStamp:       "1234567890.200000",
+			ThreadTimeStamp: threadTS,
+		}}.Process(context.Background(), testAgent)
+	}
+
+	BeforeEach(func() {
+		testAgent, mockDB, mockSlackBot, mockLLM = newTestAgent()
+	})
+
+	It("should abort the answer running in the thread", func() {
+		started := make(chan struct{})
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+			{Msg: slack.Msg{Text: "How do I create a VF?", User: "U123"}},
+			{Msg: slack.Msg{Text: "<@BOT123> answer sriov 4.16", User: "U123"}},
+			{Msg: slack.Msg{Text: "Searching for answer...", User: "BOT123"}},
+		}, nil)
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil).AnyTimes()
+		mockDB.EXPECT().GetSlugForThread(threadTS).Return("existing-slug", true, nil)
+		mockDB.EXPECT().SetLastQuestion(threadTS, gomock.Any()).Return(nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "existing-slug", gomock.Any()).DoAndReturn(
+			func(ctx context.Context, _, _, _, _ string) (string, error) {
+				// The LLM only returns once the question is cancelled
+				close(started)
+				<-ctx.Done()
+				return "", ctx.Err()
+			})
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "🚫 Cancelled").Return(nil)
+
+		answered := make(chan error, 1)
+		go func() {
+			defer GinkgoRecover()
+			answered <- mention("<@BOT123> answer sriov 4.16")
+		}()
+		Eventually(started).Should(BeClosed())
+
+		Expect(mention("<@BOT123> cancel")).To(Succeed())
+		var err error
+		Eventually(answered, time.Second).Should(Receive(&err))
+		Expect(err).To(MatchError(context.Canceled))
+	})
+
+	It("should tell the user when nothing runs in the thread", func() {
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "Nothing is running in this thread").Return(nil)
+
+		Expect(mention("<@BOT123> cancel")).To(Succeed())
+	})
+})
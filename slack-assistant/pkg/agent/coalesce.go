@@ -0,0 +1,55 @@
+package agent
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// commandCoalescer drops the commands repeated in the same thread within a window, e.g. a user mentioning the bot
+// with `answer` several times in a row, so the thread gets a single answer
+type commandCoalescer struct {
+	mu     sync.Mutex
+	window time.Duration
+	now    func() time.Time
+	// seen is when each command of each thread was last accepted
+	seen map[string]time.Time
+}
+
+// SetCoalesceWindow makes the identical commands repeated in a thread within the window run only once,
+// 0 runs every command
+func (a *Agent) SetCoalesceWindow(window time.Duration) {
+	a.coalescer.mu.Lock()
+	defer a.coalescer.mu.Unlock()
+	a.coalescer.window = window
+}
+
+// duplicate reports whether the same command was accepted in the thread within the window, the first one is accepted
+// and the later ones are dropped. Only the same command with the same arguments is coalesced.
+func (c *commandCoalescer) duplicate(threadTS string, parameters []string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.window <= 0 || len(parameters) < 2 {
+		return false
+	}
+	if c.now == nil {
+		c.now = time.Now
+	}
+	if c.seen == nil {
+		c.seen = map[string]time.Time{}
+	}
+
+	now := c.now()
+	for key, accepted := range c.seen {
+		if now.Sub(accepted) >= c.window {
+			delete(c.seen, key)
+		}
+	}
+
+	key := threadTS + " " + strings.Join(parameters[1:], " ")
+	if _, ok := c.seen[key]; ok {
+		return true
+	}
+	c.seen[key] = now
+	return false
+}
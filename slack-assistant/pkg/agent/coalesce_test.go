@@ -0,0 +1,94 @@
+package agent_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Command coalescing", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.100000"
+		user     = "U123456"
+	)
+
+	var (
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	mention := func(text string) error {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            text,
+			Channel:         channel,
+			TimeStamp:       "1234567890.200000",
+			ThreadTimeStamp: threadTS,
+		}}.Process(context.Background(), testAgent)
+	}
+
+	// expectAnswers expects the answer of the sriov question the given number of times
+	expectAnswers := func(times int) {
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil).Times(times)
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+			{Msg: slack.Msg{Text: "How do I create a VF?", User: "U123"}},
+			{Msg: slack.Msg{Text: "<@BOT123> answer sriov 4.16", User: "U123"}},
+			{Msg: slack.Msg{Text: "Searching for answer...", User: "BOT123"}},
+		}, nil).Times(times)
+		mockDB.EXPECT().GetSlugForThread(threadTS).Return("existing-slug", true, nil).Times(times)
+		mockDB.EXPECT().SetLastQuestion(threadTS, gomock.Any()).Return(nil).Times(times)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "existing-slug", gomock.Any()).Return("Create a SriovNetworkNodePolicy", nil).Times(times)
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil).Times(times)
+	}
+
+	BeforeEach(func() {
+		testAgent, mockDB, mockSlackBot, mockLLM = newTestAgent()
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil).AnyTimes()
+	})
+
+	It("should answer once when the same answer is asked twice within the window", func() {
+		testAgent.SetCoalesceWindow(time.Minute)
+		expectAnswers(1)
+
+		Expect(mention("<@BOT123> answer sriov 4.16")).To(Succeed())
+		Expect(mention("<@BOT123> answer sriov 4.16")).To(Succeed())
+	})
+
+	It("should run the distinct commands of the thread", func() {
+		testAgent.SetCoalesceWindow(time.Minute)
+		expectAnswers(1)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "There is no question to retry in this thread yet, please use answer first").Return(nil)
+
+		Expect(mention("<@BOT123> answer sriov 4.16")).To(Succeed())
+		Expect(mention("<@BOT123> retry")).To(Succeed())
+	})
+
+	It("should answer again once the window is over", func() {
+		testAgent.SetCoalesceWindow(20 * time.Millisecond)
+		expectAnswers(2)
+
+		Expect(mention("<@BOT123> answer sriov 4.16")).To(Succeed())
+		time.Sleep(30 * time.Millisecond)
+		Expect(mention("<@BOT123> answer sriov 4.16")).To(Succeed())
+	})
+
+	It("should answer every mention by default", func() {
+		expectAnswers(2)
+
+		Expect(mention("<@BOT123> answer sriov 4.16")).To(Succeed())
+		Expect(mention("<@BOT123> answer sriov 4.16")).To(Succeed())
+	})
+})
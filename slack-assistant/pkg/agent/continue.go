@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+)
+
+// continuePrompt asks the LLM thread for the rest of its last answer
+const continuePrompt = "Your previous answer was cut off. Continue it exactly from where it stopped, without repeating what you already wrote."
+
+// looksTruncated reports whether the LLM cut the answer off by its max tokens, it stops in the middle of a code block
+// or a sentence
+func looksTruncated(answer string) bool {
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return false
+	}
+	if strings.Count(answer, "```")%2 == 1 {
+		return true
+	}
+	return strings.ContainsAny(answer[len(answer)-1:], ",:;(")
+}
+
+// rememberTruncation stores whether the posted answer of the thread was cut off, so a complete answer clears the
+// state of an earlier truncated one, and tells the user how to get the rest of a truncated answer
+func (a *Agent) rememberTruncation(channel, threadTS, answer string) {
+	truncated := looksTruncated(answer)
+	if err := a.db.SetThreadTruncated(threadTS, truncated); err != nil {
+		fmt.Printf("⚠️ Failed to store whether the answer of thread %s is truncated: %v\n", threadTS, err)
+		return
+	}
+	if !truncated {
+		return
+	}
+	fmt.Printf("✂️ The answer of thread %s looks truncated\n", threadTS)
+	if err := a.slackBot.PostMessage(channel, threadTS, i18n.T("continue.hint")); err != nil {
+		fmt.Printf("⚠️ Failed to post the continue hint: %v\n", err)
+	}
+}
+
+// Continue asks the LLM thread to go on with its last answer when it was cut off, the continuation is posted
+// after the answer without an intro
+func (a *Agent) Continue(ctx context.Context, channel, threadTS, user string) error {
+	threadContext := a.getThreadContext(threadTS)
+	if threadContext == nil || !threadContext.Truncated {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("continue.nothing"))
+	}
+
+	fmt.Printf("⏩ Continuing the last answer of thread %s for project %s on version %s\n", threadTS, threadContext.Project, threadContext.Version)
+	placeholder, err := a.postPlaceholder(channel, threadTS)
+	if err != nil {
+		return err
+	}
+
+	return a.deletePlaceholder(channel, placeholder, a.generateContinuation(withThreadSettings(ctx, threadContext), channel, threadTS, threadContext.Project, threadContext.Version, threadContext.ThreadSlug))
+}
+
+// generateContinuation sends the continue prompt to the LLM thread and posts the rest of the answer,
+// the thread stays truncated while the continuation is cut off too
+func (a *Agent) generateContinuation(ctx context.Context, channel, threadTS, project, version, slug string) error {
+	continuation, answeredSlug, err := a.askInThread(ctx, threadTS, project, version, slug, continuePrompt)
+	if err != nil {
+		a.postFailure(channel, threadTS, "generate response", err)
+		return fmt.Errorf("failed to generate response: %w", err)
+	}
+
+	if err := a.postAnswerWithRetry(ctx, channel, threadTS, "", continuation, a.answerSources(answeredSlug), false); err != nil {
+		return err
+	}
+	a.rememberTruncation(channel, threadTS, continuation)
+	return nil
+}
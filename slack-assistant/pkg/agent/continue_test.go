@@ -0,0 +1,144 @@
+package agent_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+	slackbot "github.com/SchSeba/slack-ai-assistant/pkg/slack-bot"
+)
+
+var _ = Describe("Continue", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.100000"
+		user     = "U123456"
+		hint     = "✂️ The answer was cut off, mention me with `continue` to get the rest"
+	)
+
+	var (
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	mention := func(text string) error {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            text,
+			Channel:         channel,
+			TimeStamp:       "1234567890.200000",
+			ThreadTimeStamp: threadTS,
+		}}.Process(context.Background(), testAgent)
+	}
+
+	truncatedThread := &database.SlackThreadToSlug{
+		SlackThread:  threadTS,
+		ThreadSlug:   "stored-slug",
+		Project:      "metallb",
+		Version:      "4.18",
+		LastQuestion: "How do I configure BGP peers?",
+		Truncated:    true,
+	}
+
+	// The stored truncation is checked by every spec so the mocks aren't the stubbed ones of newTestAgent
+	BeforeEach(func() {
+		ctrl := gomock.NewController(GinkgoT())
+		mockDB = databaseMock.NewMockInterface(ctrl)
+		mockSlackBot = slackbotMock.NewMockInterface(ctrl)
+		mockLLM = llmMock.NewMockInterface(ctrl)
+		testAgent = agent.NewAgent(mockDB, mockSlackBot, mockLLM, nil, nil, nil, nil, 1, 10)
+
+		mockSlackBot.EXPECT().GetBotUser().Return(nil).AnyTimes()
+		mockSlackBot.EXPECT().GetUserName(gomock.Any()).Return("alice", nil).AnyTimes()
+		mockDB.EXPECT().RecordCommand(gomock.Any()).Return(nil).AnyTimes()
+		mockLLM.EXPECT().LastContext(gomock.Any()).Return(nil).AnyTimes()
+	})
+
+	It("should remember a truncated answer and tell the user how to continue it", func() {
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+			{Msg: slack.Msg{Text: "How do I configure BGP peers?", User: "U123"}},
+			{Msg: slack.Msg{Text: "<@BOT123> answer metallb 4.18", User: "U123"}},
+			{Msg: slack.Msg{Text: "Searching for answer...", User: "BOT123"}},
+		}, nil)
+		mockDB.EXPECT().GetSlugForThread(threadTS).Return("stored-slug", true, nil)
+		mockDB.EXPECT().SetLastQuestion(threadTS, gomock.Any()).Return(nil)
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil).AnyTimes()
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "stored-slug", gomock.Any()).
+			Return("Create the BGPPeer:\n```yaml\napiVersion: metallb.io/v1beta2", nil)
+		gomock.InOrder(
+			mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil),
+			mockDB.EXPECT().SetThreadTruncated(threadTS, true).Return(nil),
+			mockSlackBot.EXPECT().PostMessage(channel, threadTS, hint).Return(nil),
+		)
+
+		Expect(mention("<@BOT123> answer metallb 4.18")).To(Succeed())
+	})
+
+	It("should ask the stored LLM thread for the rest and append it", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(truncatedThread, true, nil)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "stored-slug", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _, _, message string) (string, error) {
+				Expect(message).To(ContainSubstring("Continue it exactly from where it stopped"))
+				return "peerASN: 64512 in the BGPPeer spec, then apply it.", nil
+			})
+		mockDB.EXPECT().SetThreadTruncated(threadTS, false).Return(nil)
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).DoAndReturn(func(_, _ string, blocks []slack.Block, _ ...slackbot.MessageOption) error {
+			// The continuation follows the answer without the answer intro
+			Expect(slackbot.AnswerText(slack.Blocks{BlockSet: blocks})).To(Equal("peerASN: 64512 in the BGPPeer spec, then apply it."))
+			return nil
+		})
+
+		Expect(mention("<@BOT123> continue")).To(Succeed())
+	})
+
+	It("should keep the thread truncated while the continuation is cut off too", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(truncatedThread, true, nil)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "stored-slug", gomock.Any()).
+			Return("kind: BGPPeer\nspec:", nil)
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+		mockDB.EXPECT().SetThreadTruncated(threadTS, true).Return(nil)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, hint).Return(nil)
+
+		Expect(mention("<@BOT123> continue")).To(Succeed())
+	})
+
+	It("should clear the truncated answer once the retried answer is complete", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(truncatedThread, true, nil)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "stored-slug", "How do I configure BGP peers?").
+			Return("Create a BGPPeer resource with the peer address and ASN.", nil)
+		gomock.InOrder(
+			mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil),
+			mockDB.EXPECT().SetThreadTruncated(threadTS, false).Return(nil),
+		)
+
+		Expect(mention("<@BOT123> retry")).To(Succeed())
+	})
+
+	It("should tell the user when the last answer wasn't cut off", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(&database.SlackThreadToSlug{
+			SlackThread: threadTS,
+			ThreadSlug:  "stored-slug",
+			Project:     "metallb",
+			Version:     "4.18",
+		}, true, nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "There is no cut off answer to continue in this thread").Return(nil)
+
+		Expect(mention("<@BOT123> continue")).To(Succeed())
+	})
+})
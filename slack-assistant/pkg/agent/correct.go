@@ -0,0 +1,73 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+	slackbot "github.com/SchSeba/slack-ai-assistant/pkg/slack-bot"
+)
+
+// Correct records the correction of the last answer of the thread as feedback and injects it into the knowledge base
+// of the thread project, marked as a human correction, so the next answers can use it
+func (a *Agent) Correct(ctx context.Context, channel, threadTS, user string, threadContext *database.SlackThreadToSlug, correction string) error {
+	answer, err := a.lastAnswer(channel, threadTS)
+	if err != nil {
+		return err
+	}
+	feedback := &database.Feedback{
+		SlackThread: threadTS,
+		User:        user,
+		Project:     threadContext.Project,
+		Version:     threadContext.Version,
+		Question:    threadContext.LastQuestion,
+		Correction:  correction,
+	}
+	if answer != nil {
+		feedback.Answer = slackbot.AnswerText(answer.Blocks)
+	}
+
+	content := correction
+	metadata := llm.DocumentMetadata{Source: a.threadPermalink(channel, threadTS), Correction: true}
+	if feedback.Question != "" {
+		content = fmt.Sprintf("Question: %s\nCorrect answer: %s", feedback.Question, correction)
+		metadata.Title = "Correction: " + historyQuestion(feedback.Question)
+	}
+
+	// Like inject, the LLM injects first and the records are only written once it accepted the correction
+	if err := a.llmClient.Inject(ctx, feedback.Project, feedback.Version, content, metadata); err != nil {
+		a.postFailure(channel, threadTS, "inject the correction", err)
+		return fmt.Errorf("failed to inject correction: %w", err)
+	}
+	err = a.db.WithTransaction(func(tx database.Interface) error {
+		if err := tx.RecordFeedback(feedback); err != nil {
+			return fmt.Errorf("failed to record feedback: %w", err)
+		}
+		record := &database.InjectedDocument{
+			SlackThread: threadTS,
+			Project:     feedback.Project,
+			Version:     feedback.Version,
+			Content:     content,
+			Source:      metadata.Source,
+		}
+		if err := tx.RecordInjection(record); err != nil {
+			return fmt.Errorf("failed to record injected document: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("❌ Correction injected for project %s on version %s but not recorded: %v\n", feedback.Project, feedback.Version, err)
+		if postErr := a.slackBot.PostMessage(channel, threadTS, withEmoji(a.branding.ErrorEmoji, i18n.T("inject.notRecorded"))); postErr != nil {
+			fmt.Printf("❌ Failed to post error message: %v\n", postErr)
+		}
+		return fmt.Errorf("failed to record correction: %w", err)
+	}
+
+	fmt.Printf("✍️ Correction of thread %s by %s injected into %s %s\n", threadTS, a.userLabel(user), feedback.Project, feedback.Version)
+	if err := a.slackBot.PostMessage(channel, threadTS, i18n.T("correct.done", feedback.Project, feedback.Version)); err != nil {
+		return fmt.Errorf("failed to send correction confirmation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package agent_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+	slackbot "github.com/SchSeba/slack-ai-assistant/pkg/slack-bot"
+)
+
+var _ = Describe("Correct", func() {
+	const (
+		channel   = "C1234567890"
+		user      = "U123456"
+		threadTS  = "1234567890.100000"
+		permalink = "https://example.slack.com/archives/C1234567890/p1234567890100000"
+	)
+
+	var (
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	mention := func(command string) error {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            "<@BOT123> " + command,
+			Channel:         channel,
+			TimeStamp:       "1234567890.900000",
+			ThreadTimeStamp: threadTS,
+		}}.Process(context.Background(), testAgent)
+	}
+
+	threadContext := &database.SlackThreadToSlug{
+		SlackThread:  threadTS,
+		ThreadSlug:   "slug-123",
+		Project:      "sriov",
+		Version:      "4.16",
+		LastQuestion: "How do I create a VF?",
+	}
+
+	BeforeEach(func() {
+		testAgent, mockDB, mockSlackBot, mockLLM = newTestAgent()
+		mockSlackBot.EXPECT().GetPermalink(channel, threadTS).Return(permalink, nil).AnyTimes()
+	})
+
+	It("should record the feedback and inject the correction as a human correction", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(threadContext, true, nil).AnyTimes()
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+			{Msg: slack.Msg{Timestamp: threadTS, Text: "How do I create a VF?", User: "U123"}},
+			{Msg: slack.Msg{
+				Timestamp: "1234567890.200000",
+				BotID:     "B123",
+				Blocks:    slack.Blocks{BlockSet: slackbot.BuildAnswerBlocks("Here is the information I was able to find", "Edit the node config", nil, "")},
+			}},
+		}, nil)
+		content := "Question: How do I create a VF?\nCorrect answer: Use a SriovNetworkNodePolicy"
+		mockDB.EXPECT().WithTransaction(gomock.Any()).DoAndReturn(func(fn func(database.Interface) error) error {
+			return fn(mockDB)
+		})
+		mockDB.EXPECT().RecordFeedback(gomock.Any()).DoAndReturn(func(feedback *database.Feedback) error {
+			Expect(feedback.SlackThread).To(Equal(threadTS))
+			Expect(feedback.User).To(Equal(user))
+			Expect(feedback.Project).To(Equal("sriov"))
+			Expect(feedback.Version).To(Equal("4.16"))
+			Expect(feedback.Question).To(Equal("How do I create a VF?"))
+			Expect(feedback.Answer).To(ContainSubstring("Edit the node config"))
+			Expect(feedback.Correction).To(Equal("Use a SriovNetworkNodePolicy"))
+			return nil
+		})
+		mockDB.EXPECT().RecordInjection(&database.InjectedDocument{
+			SlackThread: threadTS,
+			Project:     "sriov",
+			Version:     "4.16",
+			Content:     content,
+			Source:      permalink,
+		}).Return(nil)
+		mockLLM.EXPECT().Inject(gomock.Any(), "sriov", "4.16", content, llm.DocumentMetadata{
+			Title:      "Correction: How do I create a VF?",
+			Source:     permalink,
+			Correction: true,
+		}).Return(nil)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "✍️ Thanks, your correction was added to the sriov 4.16 knowledge base and will be used by the next answers").Return(nil)
+
+		Expect(mention("correct Use a SriovNetworkNodePolicy")).To(Succeed())
+	})
+
+	It("should tell the user when the correction can't be injected", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(threadContext, true, nil).AnyTimes()
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+			{Msg: slack.Msg{Timestamp: threadTS, Text: "How do I create a VF?", User: "U123"}},
+		}, nil)
+		mockLLM.EXPECT().Inject(gomock.Any(), "sriov", "4.16", gomock.Any(), gomock.Any()).Return(errors.New("workspace not found"))
+		// A correction that wasn't injected leaves no record
+		mockDB.EXPECT().WithTransaction(gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, gomock.Any()).Return(nil)
+
+		Expect(mention("correct Use a SriovNetworkNodePolicy")).To(HaveOccurred())
+	})
+
+	It("should tell the user when the injected correction can't be recorded", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(threadContext, true, nil).AnyTimes()
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+			{Msg: slack.Msg{Timestamp: threadTS, Text: "How do I create a VF?", User: "U123"}},
+		}, nil)
+		gomock.InOrder(
+			mockLLM.EXPECT().Inject(gomock.Any(), "sriov", "4.16", gomock.Any(), gomock.Any()).Return(nil),
+			mockDB.EXPECT().WithTransaction(gomock.Any()).DoAndReturn(func(fn func(database.Interface) error) error {
+				return fn(mockDB)
+			}),
+		)
+		mockDB.EXPECT().RecordFeedback(gomock.Any()).Return(errors.New("database is locked"))
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "❌ The document was injected but it could not be recorded, please don't inject it again").Return(nil)
+
+		Expect(mention("correct Use a SriovNetworkNodePolicy")).To(MatchError(ContainSubstring("failed to record correction")))
+	})
+
+	It("should ask for an answer first when the thread has no project", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil).AnyTimes()
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "There is no answer to correct in this thread yet, please use answer first").Return(nil)
+		mockLLM.EXPECT().Inject(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		Expect(mention("correct Use a SriovNetworkNodePolicy")).To(Succeed())
+	})
+
+	It("should show the usage when the correction is missing", func() {
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, gomock.Any()).Return(nil)
+		mockLLM.EXPECT().Inject(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		Expect(mention("correct")).To(Succeed())
+	})
+
+	It("should reject the correction of a read-only project", func() {
+		testAgent.SetReadOnlyProjects(agent.ProjectVersion{Project: "sriov", Version: "4.16"})
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(threadContext, true, nil).AnyTimes()
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "🔒 The sriov 4.16 knowledge base is curated and read-only, it can't be changed with inject, export or correct").Return(nil)
+		mockLLM.EXPECT().Inject(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		Expect(mention("correct Use a SriovNetworkNodePolicy")).To(Succeed())
+	})
+})
@@ -0,0 +1,62 @@
+package agent
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+)
+
+// AddAdmins allows the users to run the admin commands, e.g. debug
+func (a *Agent) AddAdmins(userIDs ...string) {
+	a.admins = append(a.admins, userIDs...)
+}
+
+// isAdmin reports whether the user may run the admin commands, nobody may when no admin is configured
+func (a *Agent) isAdmin(user string) bool {
+	return slices.Contains(a.admins, user)
+}
+
+// Debug shows an admin the LLM thread, project and version stored for the Slack thread and the LLM provider,
+// so a wrong answer can be traced back to the workspace that gave it
+func (a *Agent) Debug(channel, threadTS, user string) error {
+	if !a.isAdmin(user) {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("debug.notAdmin"))
+	}
+
+	threadContext, exist, err := a.db.GetThreadContext(threadTS)
+	if err != nil {
+		fmt.Printf("❌ Failed to get thread context from database: %v\n", err)
+		return fmt.Errorf("failed to get thread context: %w", err)
+	}
+
+	lines := []string{i18n.T("debug.header", threadTS)}
+	if exist {
+		lines = append(lines, i18n.T("debug.found", threadContext.Project, threadContext.Version, threadContext.ThreadSlug))
+		if threadContext.ElaborateSlug != "" {
+			lines = append(lines, i18n.T("debug.elaborate", threadContext.ElaborateSlug))
+		}
+		if threadContext.Mode != "" {
+			lines = append(lines, i18n.T("debug.mode", threadContext.Mode))
+		}
+		if threadContext.TopK != 0 {
+			lines = append(lines, i18n.T("debug.topK", threadContext.TopK))
+		}
+		if threadContext.Temperature != nil {
+			lines = append(lines, i18n.T("debug.temperature", *threadContext.Temperature))
+		}
+		if threadContext.Strict {
+			lines = append(lines, i18n.T("debug.strict"))
+		}
+		if threadContext.MemoryOff {
+			lines = append(lines, i18n.T("debug.memoryOff"))
+		}
+	} else {
+		lines = append(lines, i18n.T("debug.notFound"))
+	}
+	provider, host := a.llmBackend()
+	lines = append(lines, i18n.T("debug.provider", provider, host))
+
+	return a.slackBot.PostEphemeral(channel, user, strings.Join(lines, "\n"))
+}
@@ -0,0 +1,82 @@
+package agent_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Debug", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.100000"
+		admin    = "UADMIN"
+	)
+
+	var (
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	mention := func(user, command string) error {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            "<@BOT123> " + command,
+			Channel:         channel,
+			TimeStamp:       "1234567890.900000",
+			ThreadTimeStamp: threadTS,
+		}}.Process(context.Background(), testAgent)
+	}
+
+	BeforeEach(func() {
+		testAgent, mockDB, mockSlackBot, _ = newTestAgent()
+		testAgent.SetStatusInfo(agent.StatusInfo{LLMProvider: "anythingllm", LLMHost: "http://anythingllm:3001"})
+		testAgent.AddAdmins(admin)
+	})
+
+	It("should show the LLM thread stored for the Slack thread", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(&database.SlackThreadToSlug{
+			SlackThread:   threadTS,
+			ThreadSlug:    "stored-slug",
+			Project:       "metallb",
+			Version:       "4.18",
+			ElaborateSlug: "elaborate-slug",
+			Mode:          "chat",
+		}, true, nil)
+		mockSlackBot.EXPECT().PostEphemeral(channel, admin, "🔎 *Slack thread 1234567890.100000*\n"+
+			"• Mapping found in the database\n"+
+			"• Project: metallb\n"+
+			"• Version: 4.18\n"+
+			"• LLM thread: stored-slug\n"+
+			"• Elaborate thread: elaborate-slug\n"+
+			"• Answer mode: chat\n"+
+			"• LLM provider: anythingllm (http://anythingllm:3001)").Return(nil)
+
+		Expect(mention(admin, "debug")).To(Succeed())
+	})
+
+	It("should tell when the Slack thread isn't stored in the database", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil)
+		mockSlackBot.EXPECT().PostEphemeral(channel, admin, "🔎 *Slack thread 1234567890.100000*\n"+
+			"• No mapping found in the database, the thread wasn't answered yet\n"+
+			"• LLM provider: anythingllm (http://anythingllm:3001)").Return(nil)
+
+		Expect(mention(admin, "whoami")).To(Succeed())
+	})
+
+	It("should only be available to the admins", func() {
+		mockDB.EXPECT().GetThreadContext(gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostEphemeral(channel, "U123456", "Only the bot admins can use debug").Return(nil)
+
+		Expect(mention("U123456", "debug")).To(Succeed())
+	})
+})
@@ -0,0 +1,46 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+)
+
+// Docs posts the list of documents stored in the project and version workspace
+func (a *Agent) Docs(ctx context.Context, channel, threadTS, project, version string) error {
+	message, err := a.docsMessage(ctx, project, version)
+	if err != nil {
+		a.postFailure(channel, threadTS, "list documents", err)
+		return fmt.Errorf("failed to list documents: %w", err)
+	}
+
+	if err := a.slackBot.PostMessage(channel, threadTS, message); err != nil {
+		return fmt.Errorf("failed to send documents: %w", err)
+	}
+	return nil
+}
+
+// docsMessage formats the list of documents stored in the project and version workspace
+func (a *Agent) docsMessage(ctx context.Context, project, version string) (string, error) {
+	documents, err := a.llmClient.ListDocuments(ctx, project, version)
+	if err != nil {
+		return "", err
+	}
+
+	if len(documents) == 0 {
+		return i18n.T("docs.none", project, version), nil
+	}
+
+	lines := make([]string, 0, len(documents)+1)
+	lines = append(lines, i18n.T("docs.list", len(documents), project, version))
+	for _, document := range documents {
+		title := document.Title
+		if title == "" {
+			title = "Untitled"
+		}
+		lines = append(lines, fmt.Sprintf("• %s (%s)", title, document.ID))
+	}
+	return strings.Join(lines, "\n"), nil
+}
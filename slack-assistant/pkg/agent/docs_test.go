@@ -0,0 +1,74 @@
+package agent_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Docs", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.100000"
+		user     = "U123456"
+	)
+
+	var (
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	newMention := func(text string) agent.AppMentionWorkItem {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            text,
+			Channel:         channel,
+			TimeStamp:       "1234567890.200000",
+			ThreadTimeStamp: threadTS,
+		}}
+	}
+
+	BeforeEach(func() {
+		testAgent, _, mockSlackBot, mockLLM = newTestAgent()
+	})
+
+	It("should post the documents of the workspace", func() {
+		mockLLM.EXPECT().ListDocuments(gomock.Any(), "metallb", "4.18").Return([]llm.DocumentInfo{
+			{ID: "doc-1", Title: "MetalLB BGP peers"},
+			{ID: "doc-2"},
+		}, nil)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS,
+			"📚 2 document(s) for project metallb on version 4.18:\n• MetalLB BGP peers (doc-1)\n• Untitled (doc-2)").Return(nil)
+
+		Expect(newMention("<@BOT123> docs metallb 4.18").Process(context.Background(), testAgent)).To(Succeed())
+	})
+
+	It("should tell the user when the workspace has no documents", func() {
+		mockLLM.EXPECT().ListDocuments(gomock.Any(), "metallb", "4.18").Return([]llm.DocumentInfo{}, nil)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "There are no documents for project metallb on version 4.18").Return(nil)
+
+		Expect(newMention("<@BOT123> docs metallb 4.18").Process(context.Background(), testAgent)).To(Succeed())
+	})
+
+	It("should post the error when the documents can't be listed", func() {
+		mockLLM.EXPECT().ListDocuments(gomock.Any(), "metallb", "4.18").Return(nil, &llm.StatusError{StatusCode: 404, Body: "workspace not found"})
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "❌ Error: server returned status 404: workspace not found").Return(nil)
+
+		Expect(newMention("<@BOT123> docs metallb 4.18").Process(context.Background(), testAgent)).To(MatchError(ContainSubstring("failed to list documents")))
+	})
+
+	It("should ask for the project and version", func() {
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "To list the documents please provide the project name (example: sriov,metallb) and the openshift version (4.16,4.18, etc..)").Return(nil)
+
+		Expect(newMention("<@BOT123> docs metallb").Process(context.Background(), testAgent)).To(Succeed())
+	})
+})
@@ -0,0 +1,158 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+const (
+	// workKindAppMention is the durable queue kind of AppMentionWorkItem
+	workKindAppMention = "app_mention"
+	// workKindInteraction is the durable queue kind of InteractionWorkItem
+	workKindInteraction = "interaction"
+)
+
+// DurableWorkItem wraps a work item persisted in the database so it survives restarts
+type DurableWorkItem struct {
+	ID   uint
+	Item WorkItem
+}
+
+func (w DurableWorkItem) Process(ctx context.Context, agent *Agent) error {
+	_, err := w.Run(ctx, agent)
+	return err
+}
+
+// Run processes the wrapped work item, returning its command result when it runs a command
+func (w DurableWorkItem) Run(ctx context.Context, agent *Agent) (*CommandResult, error) {
+	if err := agent.db.MarkWorkInProgress(w.ID); err != nil {
+		fmt.Printf("❌ Failed to mark queued work %d in progress: %v\n", w.ID, err)
+	}
+
+	var result *CommandResult
+	var err error
+	if item, ok := w.Item.(CommandWorkItem); ok {
+		result, err = item.Run(ctx, agent)
+	} else {
+		err = w.Item.Process(ctx, agent)
+	}
+	if ctx.Err() != nil {
+		// Interrupted by the shutdown, keep the work so it's recovered after the restart
+		fmt.Printf("🛑 Queued work %d interrupted by shutdown, it will be recovered on restart\n", w.ID)
+		return result, err
+	}
+
+	if completeErr := agent.db.CompleteWork(w.ID); completeErr != nil {
+		fmt.Printf("❌ Failed to complete queued work %d: %v\n", w.ID, completeErr)
+	}
+	return result, err
+}
+
+func (w DurableWorkItem) String() string {
+	return fmt.Sprintf("Durable{ID: %d, %s}", w.ID, w.Item.String())
+}
+
+// EnableDurableQueue persists every queued app mention and interaction so they are re-enqueued after a restart
+func (a *Agent) EnableDurableQueue() {
+	a.durableQueue = true
+}
+
+// submit queues the work item, persisting it first when the durable queue is enabled
+func (a *Agent) submit(workItem WorkItem) error {
+	if !a.durableQueue {
+		return a.workerPool.Submit(workItem)
+	}
+
+	kind, payload, err := encodeWorkItem(workItem)
+	if err != nil {
+		fmt.Printf("⚠️ Work item %s can't be persisted, queuing it in memory: %v\n", workItem.String(), err)
+		return a.workerPool.Submit(workItem)
+	}
+
+	id, err := a.db.EnqueueWork(kind, payload)
+	if err != nil {
+		fmt.Printf("❌ Failed to persist work item %s, queuing it in memory: %v\n", workItem.String(), err)
+		return a.workerPool.Submit(workItem)
+	}
+
+	err = a.workerPool.Submit(DurableWorkItem{ID: id, Item: workItem})
+	if err != nil {
+		// The work item was dropped, there is nothing to recover after a restart
+		if completeErr := a.db.CompleteWork(id); completeErr != nil {
+			fmt.Printf("❌ Failed to remove dropped work %d: %v\n", id, completeErr)
+		}
+	}
+	return err
+}
+
+// recoverQueuedWork re-enqueues the work items persisted before the last shutdown
+func (a *Agent) recoverQueuedWork() {
+	queuedWork, err := a.db.GetQueuedWork()
+	if err != nil {
+		fmt.Printf("❌ Failed to load queued work: %v\n", err)
+		return
+	}
+
+	for _, work := range queuedWork {
+		workItem, err := decodeWorkItem(work.Kind, work.Payload)
+		if err != nil {
+			fmt.Printf("❌ Failed to decode queued work %d, discarding it: %v\n", work.ID, err)
+			if completeErr := a.db.CompleteWork(work.ID); completeErr != nil {
+				fmt.Printf("❌ Failed to remove queued work %d: %v\n", work.ID, completeErr)
+			}
+			continue
+		}
+		// The mention waited in the queue since it was persisted, across the restart
+		if mention, ok := workItem.(AppMentionWorkItem); ok {
+			mention.EnqueuedAt = work.CreatedAt
+			workItem = mention
+		}
+
+		fmt.Printf("♻️ Recovering queued work %d: %s\n", work.ID, workItem.String())
+		if err := a.workerPool.Submit(DurableWorkItem{ID: work.ID, Item: workItem}); err != nil {
+			fmt.Printf("❌ Failed to recover queued work %d: %v\n", work.ID, err)
+		}
+	}
+}
+
+// encodeWorkItem serializes the work items that can be persisted in the durable queue
+func encodeWorkItem(workItem WorkItem) (kind, payload string, err error) {
+	var data []byte
+	switch item := workItem.(type) {
+	case AppMentionWorkItem:
+		kind = workKindAppMention
+		data, err = json.Marshal(item.Event)
+	case InteractionWorkItem:
+		kind = workKindInteraction
+		data, err = json.Marshal(item.Callback)
+	default:
+		return "", "", fmt.Errorf("unsupported work item %s", workItem.String())
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal work item: %w", err)
+	}
+	return kind, string(data), nil
+}
+
+// decodeWorkItem rebuilds a work item persisted in the durable queue
+func decodeWorkItem(kind, payload string) (WorkItem, error) {
+	switch kind {
+	case workKindAppMention:
+		event := &slackevents.AppMentionEvent{}
+		if err := json.Unmarshal([]byte(payload), event); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal app mention: %w", err)
+		}
+		return AppMentionWorkItem{Event: event}, nil
+	case workKindInteraction:
+		callback := &slack.InteractionCallback{}
+		if err := json.Unmarshal([]byte(payload), callback); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal interaction: %w", err)
+		}
+		return InteractionWorkItem{Callback: callback}, nil
+	}
+	return nil, fmt.Errorf("unknown work kind %q", kind)
+}
@@ -0,0 +1,184 @@
+package agent_test
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Durable queue", func() {
+	var (
+		ctrl              *gomock.Controller
+		mockDB            *databaseMock.MockInterface
+		mockSlackBot      *slackbotMock.MockInterface
+		appMentionChannel chan *slackevents.AppMentionEvent
+		testAgent         *agent.Agent
+		ctx               context.Context
+		cancel            context.CancelFunc
+		stopped           <-chan struct{}
+		testEvent         *slackevents.AppMentionEvent
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockDB = databaseMock.NewMockInterface(ctrl)
+		mockSlackBot = slackbotMock.NewMockInterface(ctrl)
+		appMentionChannel = make(chan *slackevents.AppMentionEvent, 10)
+
+		testAgent = agent.NewAgent(mockDB, mockSlackBot, llmMock.NewMockInterface(ctrl), appMentionChannel, nil, nil, nil, 1, 10)
+		testAgent.EnableDurableQueue()
+
+		ctx, cancel = context.WithCancel(context.Background())
+		testEvent = &slackevents.AppMentionEvent{
+			User:      "U123456",
+			Text:      "<@BOT123> invalid",
+			Channel:   "C1234567890",
+			TimeStamp: "1234567890.123456",
+		}
+
+		mockSlackBot.EXPECT().GetBotUser().Return(nil).AnyTimes()
+		mockSlackBot.EXPECT().GetUserName(gomock.Any()).Return("alice", nil).AnyTimes()
+		mockDB.EXPECT().GetThreadContext(gomock.Any()).Return(nil, false, nil).AnyTimes()
+		mockDB.EXPECT().RecordCommand(gomock.Any()).Return(nil).AnyTimes()
+		mockSlackBot.EXPECT().Start(gomock.Any()).Do(func(ctx context.Context) {
+			<-ctx.Done()
+		}).AnyTimes()
+	})
+
+	AfterEach(func() {
+		cancel()
+		Eventually(stopped).Should(BeClosed())
+		ctrl.Finish()
+	})
+
+	It("should persist queued app mentions and complete them once processed", func() {
+		completed := make(chan struct{})
+		mockDB.EXPECT().GetQueuedWork().Return(nil, nil)
+		mockDB.EXPECT().EnqueueWork("app_mention", gomock.Any()).DoAndReturn(func(_, payload string) (uint, error) {
+			event := &slackevents.AppMentionEvent{}
+			Expect(json.Unmarshal([]byte(payload), event)).To(Succeed())
+			Expect(event.Text).To(Equal(testEvent.Text))
+			return 7, nil
+		})
+		gomock.InOrder(
+			mockDB.EXPECT().MarkWorkInProgress(uint(7)).Return(nil),
+			mockSlackBot.EXPECT().PostEphemeral("C1234567890", "U123456", gomock.Any()).Return(nil),
+			mockDB.EXPECT().CompleteWork(uint(7)).DoAndReturn(func(uint) error {
+				close(completed)
+				return nil
+			}),
+		)
+
+		stopped = startAgent(ctx, testAgent)
+		appMentionChannel <- testEvent
+
+		Eventually(completed).Should(BeClosed())
+	})
+
+	It("should keep the work interrupted by the shutdown for the next start", func() {
+		interrupted, interrupt := context.WithCancel(context.Background())
+		interrupt()
+
+		mockDB.EXPECT().MarkWorkInProgress(uint(8)).Return(nil)
+		mockSlackBot.EXPECT().PostEphemeral("C1234567890", "U123456", gomock.Any()).Return(nil)
+		mockDB.EXPECT().CompleteWork(gomock.Any()).Times(0)
+
+		workItem := agent.DurableWorkItem{ID: 8, Item: agent.AppMentionWorkItem{Event: testEvent}}
+		Expect(workItem.Process(interrupted, testAgent)).To(Succeed())
+	})
+
+	It("should re-enqueue the work persisted before a restart", func() {
+		completed := make(chan struct{})
+		payload, err := json.Marshal(testEvent)
+		Expect(err).NotTo(HaveOccurred())
+
+		mockDB.EXPECT().GetQueuedWork().Return([]database.QueuedWork{
+			{ID: 3, Kind: "app_mention", Payload: string(payload), InProgress: true},
+		}, nil)
+		mockDB.EXPECT().MarkWorkInProgress(uint(3)).Return(nil)
+		mockSlackBot.EXPECT().PostEphemeral("C1234567890", "U123456", gomock.Any()).Return(nil)
+		mockDB.EXPECT().CompleteWork(uint(3)).DoAndReturn(func(uint) error {
+			close(completed)
+			return nil
+		})
+
+		stopped = startAgent(ctx, testAgent)
+
+		Eventually(completed).Should(BeClosed())
+	})
+
+	It("should skip the recovered mentions that waited longer than the max queue wait", func() {
+		completed := make(chan struct{})
+		payload, err := json.Marshal(testEvent)
+		Expect(err).NotTo(HaveOccurred())
+		testAgent.SetMaxQueueWait(time.Minute, true)
+
+		mockDB.EXPECT().GetQueuedWork().Return([]database.QueuedWork{
+			{ID: 5, Kind: "app_mention", Payload: string(payload), CreatedAt: time.Now().Add(-time.Hour)},
+		}, nil)
+		mockDB.EXPECT().MarkWorkInProgress(uint(5)).Return(nil)
+		mockSlackBot.EXPECT().PostMessage("C1234567890", "1234567890.123456", gomock.Any()).Return(nil)
+		mockSlackBot.EXPECT().PostEphemeral(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		mockDB.EXPECT().CompleteWork(uint(5)).DoAndReturn(func(uint) error {
+			close(completed)
+			return nil
+		})
+
+		stopped = startAgent(ctx, testAgent)
+
+		Eventually(completed).Should(BeClosed())
+	})
+
+	It("should discard persisted work that can't be decoded", func() {
+		discarded := make(chan struct{})
+		mockDB.EXPECT().GetQueuedWork().Return([]database.QueuedWork{
+			{ID: 4, Kind: "unknown", Payload: "{}"},
+		}, nil)
+		mockDB.EXPECT().CompleteWork(uint(4)).DoAndReturn(func(uint) error {
+			close(discarded)
+			return nil
+		})
+
+		stopped = startAgent(ctx, testAgent)
+
+		Eventually(discarded).Should(BeClosed())
+	})
+
+	It("should serialize interactions", func() {
+		completed := make(chan struct{})
+		callback := &slack.InteractionCallback{
+			Type:    slack.InteractionTypeBlockActions,
+			User:    slack.User{ID: "U123456"},
+			Channel: slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C1234567890"}}},
+		}
+		callback.ActionCallback.BlockActions = []*slack.BlockAction{{ActionID: "sources"}}
+		payload, err := json.Marshal(callback)
+		Expect(err).NotTo(HaveOccurred())
+
+		mockDB.EXPECT().GetQueuedWork().Return([]database.QueuedWork{
+			{ID: 5, Kind: "interaction", Payload: string(payload)},
+		}, nil)
+		mockDB.EXPECT().MarkWorkInProgress(uint(5)).Return(nil)
+		mockSlackBot.EXPECT().PostEphemeral("C1234567890", "U123456", "No sources were returned for this answer").Return(nil)
+		mockDB.EXPECT().CompleteWork(uint(5)).DoAndReturn(func(uint) error {
+			close(completed)
+			return nil
+		})
+
+		stopped = startAgent(ctx, testAgent)
+
+		Eventually(completed).Should(BeClosed())
+	})
+})
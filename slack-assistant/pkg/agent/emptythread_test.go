@@ -0,0 +1,66 @@
+package agent_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Empty thread replies", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.100000"
+		message  = "❌ I couldn't read the messages of this thread, please try again in a moment"
+	)
+
+	var (
+		ctrl         *gomock.Controller
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockSlackBot = slackbotMock.NewMockInterface(ctrl)
+		mockLLM = llmMock.NewMockInterface(ctrl)
+		testAgent = agent.NewAgent(databaseMock.NewMockInterface(ctrl), mockSlackBot, mockLLM, nil, nil, nil, nil, 1, 10)
+
+		// Slack may answer without error and without messages
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return(nil, nil)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, message).Return(nil)
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	It("should not answer the whole thread", func() {
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+
+		err := testAgent.AnswerQuestion(context.Background(), channel, threadTS, "sriov", "4.16", true, false)
+		Expect(err).To(MatchError(agent.ErrEmptyThread))
+	})
+
+	It("should not answer the last message", func() {
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+
+		err := testAgent.AnswerQuestion(context.Background(), channel, threadTS, "sriov", "4.16", false, false)
+		Expect(err).To(MatchError(agent.ErrEmptyThread))
+	})
+
+	It("should not inject the messages of the user", func() {
+		mockSlackBot.EXPECT().GetPermalink(channel, threadTS).Return("", nil)
+		mockLLM.EXPECT().Inject(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		err := testAgent.Inject(context.Background(), channel, threadTS, "sriov", "4.16", false)
+		Expect(err).To(MatchError(agent.ErrEmptyThread))
+	})
+})
@@ -0,0 +1,88 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+)
+
+// ErrEmptyThread is returned when Slack returns no messages for a thread, e.g. while the API is degraded
+var ErrEmptyThread = errors.New("no messages found in the thread")
+
+// ErrorCategory tells who can fix a failed command, it picks the wording of the message posted to the user
+type ErrorCategory int
+
+const (
+	// SystemError is an unexpected failure of the bot or its backends, only the admins can fix it
+	SystemError ErrorCategory = iota
+	// UserError is a request the LLM backend rejected, e.g. an unknown workspace, the user can fix it
+	UserError
+	// UnavailableError is a temporary outage, e.g. the open circuit breaker or a timeout, retrying later helps
+	UnavailableError
+)
+
+func (c ErrorCategory) String() string {
+	switch c {
+	case UserError:
+		return "user error"
+	case UnavailableError:
+		return "temporarily unavailable"
+	default:
+		return "system error"
+	}
+}
+
+// ClassifyError returns the category of a command failure
+func ClassifyError(err error) ErrorCategory {
+	if errors.Is(err, llm.ErrCircuitOpen) || errors.Is(err, ErrQueueFull) || errors.Is(err, ErrPoolShuttingDown) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return UnavailableError
+	}
+
+	var statusErr *llm.StatusError
+	if errors.As(err, &statusErr) {
+		switch {
+		case statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode == http.StatusServiceUnavailable:
+			return UnavailableError
+		case statusErr.StatusCode >= 400 && statusErr.StatusCode < 500:
+			return UserError
+		}
+	}
+	return SystemError
+}
+
+// postFailure logs a failed LLM call and tells the thread about it, the error detail is only logged for system errors
+func (a *Agent) postFailure(channel, threadTS, action string, err error) {
+	// The cancel command already told the thread
+	if errors.Is(err, context.Canceled) {
+		fmt.Printf("🚫 Cancelled %s in thread %s\n", action, threadTS)
+		return
+	}
+	logFailure(action, err)
+	if postErr := a.slackBot.PostMessage(channel, threadTS, a.branding.errorMessage(err)); postErr != nil {
+		fmt.Printf("❌ Failed to post error message: %v\n", postErr)
+	}
+}
+
+// logFailure logs the full error of system failures, the others are expected and only logged with their category
+func logFailure(action string, err error) {
+	category := ClassifyError(err)
+	if category == SystemError {
+		fmt.Printf("❌ Failed to %s: %v\n", action, err)
+		return
+	}
+	fmt.Printf("⚠️ Failed to %s: %s\n", action, category)
+}
+
+// emptyThread tells the thread that its messages couldn't be read and returns ErrEmptyThread
+func (a *Agent) emptyThread(channel, threadTS string) error {
+	fmt.Printf("⚠️ No messages returned for thread %s\n", threadTS)
+	if err := a.slackBot.PostMessage(channel, threadTS, withEmoji(a.branding.ErrorEmoji, i18n.T("error.emptyThread"))); err != nil {
+		fmt.Printf("❌ Failed to post error message: %v\n", err)
+	}
+	return ErrEmptyThread
+}
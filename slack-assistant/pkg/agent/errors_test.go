@@ -0,0 +1,32 @@
+package agent_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+)
+
+var _ = Describe("ClassifyError", func() {
+	DescribeTable("should map the error to the category the user can act on",
+		func(err error, expected agent.ErrorCategory) {
+			Expect(agent.ClassifyError(err)).To(Equal(expected))
+		},
+		Entry("unknown workspace", &llm.StatusError{StatusCode: 404, Body: "workspace not found"}, agent.UserError),
+		Entry("rejected request", &llm.StatusError{StatusCode: 400, Body: "message is too long"}, agent.UserError),
+		Entry("wrapped rejected request", fmt.Errorf("failed to send message: %w", &llm.StatusError{StatusCode: 404}), agent.UserError),
+		Entry("open circuit breaker", llm.ErrCircuitOpen, agent.UnavailableError),
+		Entry("full work queue", agent.ErrQueueFull, agent.UnavailableError),
+		Entry("timeout", fmt.Errorf("failed to send request: %w", context.DeadlineExceeded), agent.UnavailableError),
+		Entry("rate limited backend", &llm.StatusError{StatusCode: 429}, agent.UnavailableError),
+		Entry("overloaded backend", &llm.StatusError{StatusCode: 503}, agent.UnavailableError),
+		Entry("backend failure", &llm.StatusError{StatusCode: 500, Body: "internal error"}, agent.SystemError),
+		Entry("malformed response", fmt.Errorf("failed to parse response: %w", llm.ErrMalformedResponse), agent.SystemError),
+		Entry("unexpected error", errors.New("connection refused"), agent.SystemError),
+	)
+})
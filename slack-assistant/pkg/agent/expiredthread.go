@@ -0,0 +1,83 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+)
+
+// chatRecovering sends the message to the LLM thread of the slug with chat, when the backend expired that thread
+// newThread replaces it and the message is sent again in the new thread. Every chat with a stored or just created LLM
+// thread goes through it, so an expired thread never fails an answer
+func chatRecovering(slug string, chat func(slug string) (string, error), newThread func(expiredSlug string) (string, error)) (string, error) {
+	response, err := chat(slug)
+	if !errors.Is(err, llm.ErrThreadNotFound) {
+		return response, err
+	}
+
+	fmt.Printf("♻️ The LLM thread %s no longer exists, asking again in a new one\n", slug)
+	slug, err = newThread(slug)
+	if err != nil {
+		return "", err
+	}
+	return chat(slug)
+}
+
+// askInThread asks the message in the LLM thread of the Slack thread and returns the answer with the slug of the
+// LLM thread that answered it, the Slack thread is mapped to a new LLM thread when the backend expired its thread so
+// the next answers use it
+func (a *Agent) askInThread(ctx context.Context, threadTS, project, version, slug, message string) (answer, answeredSlug string, err error) {
+	answer, err = chatRecovering(slug, func(slug string) (string, error) {
+		answeredSlug = slug
+		return a.llmClient.SendMessageToChat(ctx, project, version, slug, message)
+	}, func(expiredSlug string) (string, error) {
+		return a.replaceSlug(ctx, threadTS, project, version, expiredSlug)
+	})
+	return answer, answeredSlug, err
+}
+
+// replaceSlug creates a new LLM thread for the Slack thread in place of the expired one, unless a concurrent
+// mention of the thread already replaced it
+func (a *Agent) replaceSlug(ctx context.Context, threadTS, project, version, expiredSlug string) (string, error) {
+	unlock := a.slugLocks.lock(threadTS)
+	defer unlock()
+
+	if slug, exist, err := a.db.GetSlugForThread(threadTS); err == nil && exist && slug != expiredSlug {
+		return slug, nil
+	}
+
+	slug, err := a.llmClient.CreateThread(ctx, project, version)
+	if err != nil {
+		fmt.Printf("❌ Failed to create thread: %v\n", err)
+		return "", fmt.Errorf("failed to create thread: %w", err)
+	}
+	if err := a.db.UpdateThreadContext(threadTS, slug, project, version); err != nil {
+		fmt.Printf("❌ Failed to update thread context in database: %v\n", err)
+		return "", fmt.Errorf("failed to update thread context in database: %w", err)
+	}
+	return slug, nil
+}
+
+// replaceElaborateSlug creates a new elaborate thread for the Slack thread in place of the expired one, unless a
+// concurrent elaboration of the thread already replaced it
+func (a *Agent) replaceElaborateSlug(ctx context.Context, threadTS, expiredSlug string) (string, error) {
+	unlock := a.slugLocks.lock(threadTS)
+	defer unlock()
+
+	if slug, exist, err := a.db.GetElaborateSlugForThread(threadTS); err == nil && exist && slug != expiredSlug {
+		return slug, nil
+	}
+
+	slug, err := a.llmClient.CreateThread(ctx, a.elaborateWorkspace, "")
+	if err != nil {
+		fmt.Printf("❌ Failed to create thread: %v\n", err)
+		return "", fmt.Errorf("failed to create thread: %w", err)
+	}
+	// The thread still works without being stored, it just won't be reused
+	if err := a.db.SetElaborateSlugForThread(threadTS, slug); err != nil {
+		fmt.Printf("❌ Failed to store elaborate slug in database: %v\n", err)
+	}
+	return slug, nil
+}
@@ -0,0 +1,164 @@
+package agent_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Expired LLM threads", func() {
+	const (
+		channel  = "C1234567890"
+		user     = "U123456"
+		threadTS = "1234567890.100000"
+	)
+
+	var (
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+		stored       *database.SlackThreadToSlug
+		threadGone   = fmt.Errorf("failed to send message to thread old-slug of workspace sriov-4-dot-16: %w", llm.ErrThreadNotFound)
+	)
+
+	mention := func(text string) error {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            text,
+			Channel:         channel,
+			TimeStamp:       "1234567890.300000",
+			ThreadTimeStamp: threadTS,
+		}}.Process(context.Background(), testAgent)
+	}
+
+	followUp := func() error {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            "<@BOT123> and on 4.18?",
+			Channel:         channel,
+			TimeStamp:       "1234567890.300000",
+			ThreadTimeStamp: threadTS,
+		}}.Process(context.Background(), testAgent)
+	}
+
+	BeforeEach(func() {
+		testAgent, mockDB, mockSlackBot, mockLLM = newTestAgent()
+		stored = &database.SlackThreadToSlug{SlackThread: threadTS, ThreadSlug: "old-slug", Project: "sriov", Version: "4.16"}
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(stored, true, nil).AnyTimes()
+	})
+
+	It("should recreate the expired thread, store it and answer", func() {
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		gomock.InOrder(
+			mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "old-slug", "and on 4.18?").Return("", threadGone),
+			mockDB.EXPECT().GetSlugForThread(threadTS).Return("old-slug", true, nil),
+			mockLLM.EXPECT().CreateThread(gomock.Any(), "sriov", "4.16").Return("new-slug", nil),
+			mockDB.EXPECT().UpdateThreadContext(threadTS, "new-slug", "sriov", "4.16").Return(nil),
+			mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "new-slug", "and on 4.18?").Return("The same way", nil),
+		)
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+
+		Expect(followUp()).To(Succeed())
+	})
+
+	It("should use the thread already recreated by another mention", func() {
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		gomock.InOrder(
+			mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "old-slug", "and on 4.18?").Return("", threadGone),
+			mockDB.EXPECT().GetSlugForThread(threadTS).Return("new-slug", true, nil),
+			mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "new-slug", "and on 4.18?").Return("The same way", nil),
+		)
+		mockLLM.EXPECT().CreateThread(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+
+		Expect(followUp()).To(Succeed())
+	})
+
+	It("should tell the thread when the thread can't be recreated", func() {
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "old-slug", "and on 4.18?").Return("", threadGone)
+		mockDB.EXPECT().GetSlugForThread(threadTS).Return("old-slug", true, nil)
+		mockLLM.EXPECT().CreateThread(gomock.Any(), "sriov", "4.16").Return("", errors.New("connection refused"))
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, gomock.Any()).Return(nil)
+
+		Expect(followUp()).To(MatchError(ContainSubstring("failed to create thread")))
+	})
+
+	It("should not recreate the thread for other errors", func() {
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "old-slug", "and on 4.18?").Return("", errors.New("connection refused"))
+		mockLLM.EXPECT().CreateThread(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, gomock.Any()).Return(nil)
+
+		Expect(followUp()).To(MatchError(ContainSubstring("connection refused")))
+	})
+
+	It("should continue a truncated answer in the recreated thread", func() {
+		stored.Truncated = true
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		gomock.InOrder(
+			mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "old-slug", gomock.Any()).Return("", threadGone),
+			mockDB.EXPECT().GetSlugForThread(threadTS).Return("old-slug", true, nil),
+			mockLLM.EXPECT().CreateThread(gomock.Any(), "sriov", "4.16").Return("new-slug", nil),
+			mockDB.EXPECT().UpdateThreadContext(threadTS, "new-slug", "sriov", "4.16").Return(nil),
+			mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "new-slug", gomock.Any()).Return("then apply it.", nil),
+		)
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+
+		Expect(mention("<@BOT123> continue")).To(Succeed())
+	})
+
+	It("should elaborate in a recreated elaborate thread", func() {
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Elaborating...").Return(nil)
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+			{Msg: slack.Msg{Timestamp: threadTS, Text: "Create a BGPPeer", User: "U999"}},
+			{Msg: slack.Msg{Timestamp: "1234567890.300000", Text: "<@BOT123> elaborate", User: user}},
+			{Msg: slack.Msg{Timestamp: "1234567890.400000", Text: "Elaborating...", User: "BOT123"}},
+		}, nil)
+		gomock.InOrder(
+			mockDB.EXPECT().GetElaborateSlugForThread(threadTS).Return("old-elaborate", true, nil),
+			mockLLM.EXPECT().Elaborate(gomock.Any(), agent.DefaultElaborateWorkspace, "old-elaborate", "Create a BGPPeer").Return("", threadGone),
+			mockDB.EXPECT().GetElaborateSlugForThread(threadTS).Return("old-elaborate", true, nil),
+			mockLLM.EXPECT().CreateThread(gomock.Any(), agent.DefaultElaborateWorkspace, "").Return("new-elaborate", nil),
+			mockDB.EXPECT().SetElaborateSlugForThread(threadTS, "new-elaborate").Return(nil),
+			mockLLM.EXPECT().Elaborate(gomock.Any(), agent.DefaultElaborateWorkspace, "new-elaborate", "Create a BGPPeer").Return("A BGPPeer connects MetalLB to a router", nil),
+		)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "A BGPPeer connects MetalLB to a router").Return(nil)
+
+		Expect(mention("<@BOT123> elaborate")).To(Succeed())
+	})
+
+	It("should answer a project of answer-multi in a new thread when its thread expired", func() {
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+			{Msg: slack.Msg{Text: "How do I configure BGP?", User: "U999"}},
+			{Msg: slack.Msg{Text: "<@BOT123> answer-multi sriov/4.16 metallb/4.18", User: user}},
+			{Msg: slack.Msg{Text: "Searching for answer...", User: "BOT123"}},
+		}, nil)
+		gomock.InOrder(
+			mockLLM.EXPECT().CreateThread(gomock.Any(), "sriov", "4.16").Return("multi-slug", nil),
+			mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "multi-slug", gomock.Any()).Return("", threadGone),
+			mockLLM.EXPECT().CreateThread(gomock.Any(), "sriov", "4.16").Return("multi-slug-2", nil),
+			mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "multi-slug-2", gomock.Any()).Return("Not with SR-IOV", nil),
+		)
+		mockLLM.EXPECT().CreateThread(gomock.Any(), "metallb", "4.18").Return("metallb-slug", nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "metallb-slug", gomock.Any()).Return("Create a BGPPeer", nil)
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+
+		Expect(mention("<@BOT123> answer-multi sriov/4.16 metallb/4.18")).To(Succeed())
+	})
+})
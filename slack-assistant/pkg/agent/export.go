@@ -0,0 +1,90 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+	slackbot "github.com/SchSeba/slack-ai-assistant/pkg/slack-bot"
+)
+
+// exportTitleMaxLength caps the length of the document title taken from the first question
+const exportTitleMaxLength = 80
+
+// Export formats the whole thread as a Q&A document and injects it into the project and version workspace
+func (a *Agent) Export(ctx context.Context, channel, threadTS, project, version string) error {
+	replies, permalink, err := a.getRepliesAndPermalink(ctx, channel, threadTS)
+	if err != nil {
+		return fmt.Errorf("failed to get thread messages: %w", err)
+	}
+
+	title, document := FormatThreadExport(replies, a.botIdentity(), a.branding)
+	if document == "" {
+		return a.slackBot.PostMessage(channel, threadTS, i18n.T("export.nothing"))
+	}
+
+	metadata := llm.DocumentMetadata{Title: title, Source: permalink}
+	if err := a.llmClient.Inject(ctx, project, version, document, metadata); err != nil {
+		a.postFailure(channel, threadTS, "export thread", err)
+		return fmt.Errorf("failed to export thread: %w", err)
+	}
+
+	err = a.slackBot.PostMessage(channel, threadTS, i18n.T("export.done", project, version, title))
+	if err != nil {
+		return fmt.Errorf("failed to send response: %w", err)
+	}
+	return nil
+}
+
+// FormatThreadExport formats the thread messages as a Q&A document titled after the first question,
+// bot commands and progress messages are left out
+func FormatThreadExport(replies []slack.Message, bot BotIdentity, branding Branding) (title, document string) {
+	var builder strings.Builder
+	for _, msg := range replies {
+		isBot := bot.IsOwnMessage(msg)
+		text := msg.Text
+		if isBot {
+			if answer := slackbot.AnswerText(msg.Blocks); answer != "" {
+				text = answer
+			}
+		}
+		text = strings.TrimSpace(text)
+
+		if text == "" || bot.IsMention(strings.Fields(text)[0]) {
+			continue
+		}
+		if isBot && branding.isProgressMessage(text) {
+			continue
+		}
+
+		if isBot {
+			builder.WriteString(fmt.Sprintf("A: %s\n\n", text))
+			continue
+		}
+		if title == "" {
+			title = exportTitle(text)
+		}
+		builder.WriteString(fmt.Sprintf("Q: %s\n\n", text))
+	}
+
+	if builder.Len() == 0 {
+		return "", ""
+	}
+	if title == "" {
+		title = "Slack thread"
+	}
+	return title, fmt.Sprintf("# %s\n\n%s", title, strings.TrimSpace(builder.String()))
+}
+
+// exportTitle returns the first line of the question, shortened to exportTitleMaxLength
+func exportTitle(question string) string {
+	title, _, _ := strings.Cut(question, "\n")
+	if runes := []rune(title); len(runes) > exportTitleMaxLength {
+		title = string(runes[:exportTitleMaxLength-3]) + "..."
+	}
+	return title
+}
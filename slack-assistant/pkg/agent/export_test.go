@@ -0,0 +1,130 @@
+package agent_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+	slackbot "github.com/SchSeba/slack-ai-assistant/pkg/slack-bot"
+)
+
+var _ = Describe("Export", func() {
+	const (
+		channel   = "C1234567890"
+		threadTS  = "1234567890.100000"
+		user      = "U123456"
+		botUserID = "BOT123"
+		permalink = "https://example.slack.com/archives/C1234567890/p1234567890100000"
+	)
+
+	bot := agent.BotIdentity{UserIDs: []string{botUserID}, Names: []string{"bot"}}
+
+	thread := []slack.Message{
+		{Msg: slack.Msg{Text: "How do I configure BGP peers?\nWe run MetalLB on bare metal", User: user}},
+		{Msg: slack.Msg{Text: "<@BOT123> answer metallb 4.18", User: user}},
+		{Msg: slack.Msg{Text: "Searching for answer...", User: botUserID}},
+		{Msg: slack.Msg{User: botUserID, Blocks: slack.Blocks{BlockSet: slackbot.BuildAnswerBlocks("", "Create a BGPPeer resource", nil, "")}}},
+		{Msg: slack.Msg{Text: "Thanks, that worked", User: user}},
+	}
+
+	Describe("FormatThreadExport", func() {
+		It("should format the thread as questions and answers", func() {
+			title, document := agent.FormatThreadExport(thread, bot, agent.DefaultBranding())
+			Expect(title).To(Equal("How do I configure BGP peers?"))
+			Expect(document).To(Equal("# How do I configure BGP peers?\n\n" +
+				"Q: How do I configure BGP peers?\nWe run MetalLB on bare metal\n\n" +
+				"A: Create a BGPPeer resource\n\n" +
+				"Q: Thanks, that worked"))
+		})
+
+		It("should shorten long titles", func() {
+			question := "Why does the SR-IOV operator keep draining every node of the cluster after each policy update?"
+			title, _ := agent.FormatThreadExport([]slack.Message{{Msg: slack.Msg{Text: question, User: user}}}, bot, agent.DefaultBranding())
+			Expect(title).To(HaveLen(80))
+			Expect(title).To(HaveSuffix("..."))
+		})
+
+		It("should return an empty document when there is nothing to export", func() {
+			title, document := agent.FormatThreadExport([]slack.Message{
+				{Msg: slack.Msg{Text: "<@BOT123> export metallb 4.18", User: user}},
+			}, bot, agent.DefaultBranding())
+			Expect(title).To(BeEmpty())
+			Expect(document).To(BeEmpty())
+		})
+	})
+
+	Describe("command", func() {
+		var (
+			ctrl         *gomock.Controller
+			mockSlackBot *slackbotMock.MockInterface
+			mockLLM      *llmMock.MockInterface
+			testAgent    *agent.Agent
+		)
+
+		export := func(text string) error {
+			return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+				User:            user,
+				Text:            text,
+				Channel:         channel,
+				TimeStamp:       "1234567890.200000",
+				ThreadTimeStamp: threadTS,
+			}}.Process(context.Background(), testAgent)
+		}
+
+		BeforeEach(func() {
+			ctrl = gomock.NewController(GinkgoT())
+			mockDB := databaseMock.NewMockInterface(ctrl)
+			mockSlackBot = slackbotMock.NewMockInterface(ctrl)
+			mockLLM = llmMock.NewMockInterface(ctrl)
+			testAgent = agent.NewAgent(mockDB, mockSlackBot, mockLLM, nil, nil, nil, nil, 1, 10)
+
+			mockSlackBot.EXPECT().GetBotUser().Return(&slack.AuthTestResponse{User: "bot", UserID: botUserID}).AnyTimes()
+			mockSlackBot.EXPECT().GetUserName(gomock.Any()).Return("alice", nil).AnyTimes()
+			mockDB.EXPECT().RecordCommand(gomock.Any()).Return(nil).AnyTimes()
+		})
+
+		AfterEach(func() {
+			ctrl.Finish()
+		})
+
+		It("should inject the formatted thread with its title and permalink", func() {
+			_, document := agent.FormatThreadExport(thread, bot, agent.DefaultBranding())
+			mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return(thread, nil)
+			mockSlackBot.EXPECT().GetPermalink(channel, threadTS).Return(permalink, nil)
+			mockLLM.EXPECT().Inject(gomock.Any(), "metallb", "4.18", document, llm.DocumentMetadata{
+				Title:  "How do I configure BGP peers?",
+				Source: permalink,
+			}).Return(nil)
+			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "📤 Thread exported for project metallb on version 4.18 as \"How do I configure BGP peers?\"").Return(nil)
+
+			Expect(export("<@BOT123> export metallb 4.18")).To(Succeed())
+		})
+
+		It("should report the inject failure in the thread", func() {
+			mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return(thread, nil)
+			mockSlackBot.EXPECT().GetPermalink(channel, threadTS).Return(permalink, nil)
+			mockLLM.EXPECT().Inject(gomock.Any(), "metallb", "4.18", gomock.Any(), gomock.Any()).Return(errors.New("workspace not found"))
+			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "❌ Error: something went wrong on our side, please try again later or contact the bot admins").Return(nil)
+
+			err := export("<@BOT123> export metallb 4.18")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("failed to export thread"))
+		})
+
+		It("should ask for the project and version", func() {
+			mockSlackBot.EXPECT().PostEphemeral(channel, user, gomock.Any()).Return(nil)
+
+			Expect(export("<@BOT123> export metallb")).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,79 @@
+package agent
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+)
+
+const (
+	// defaultHistoryCount is the number of questions listed by the history command without a count
+	defaultHistoryCount = 10
+	// maxHistoryCount bounds the count of the history command, every question needs a permalink lookup
+	maxHistoryCount = 50
+	// historyQuestionLength is the number of characters of a question kept in the history
+	historyQuestionLength = 80
+)
+
+// historyCommands are the audited commands that answer the last question of a thread
+var historyCommands = []string{"answer", "answer!", "answer-all", "answer-all!", "retry"}
+
+// History shows the user a page of the questions answered in the channel with links to their threads, newest first
+func (a *Agent) History(channel, user string, count, page int) error {
+	questions, err := a.db.ListAskedQuestions(channel, historyCommands, count, (page-1)*count)
+	if err != nil {
+		fmt.Printf("❌ Failed to list the questions of channel %s: %v\n", channel, err)
+		return fmt.Errorf("failed to list questions: %w", err)
+	}
+
+	if len(questions) == 0 {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("history.none", page))
+	}
+
+	lines := make([]string, 0, len(questions)+2)
+	lines = append(lines, i18n.T("history.page", page))
+	for _, question := range questions {
+		text := historyQuestion(question.Question)
+		if permalink := a.threadPermalink(channel, question.SlackThread); permalink != "" {
+			text = fmt.Sprintf("<%s|%s>", permalink, text)
+		}
+		lines = append(lines, fmt.Sprintf("• %s (%s %s, <@%s>, %s)",
+			text, question.Project, question.Version, question.User, question.AskedAt.Format("2006-01-02")))
+	}
+	if len(questions) == count {
+		lines = append(lines, i18n.T("history.next", count, page+1))
+	}
+	return a.slackBot.PostEphemeral(channel, user, strings.Join(lines, "\n"))
+}
+
+// historyQuestion returns the question on a single line, shortened and without the characters ending a Slack link
+func historyQuestion(question string) string {
+	question = strings.Join(strings.Fields(question), " ")
+	question = strings.NewReplacer("<", "‹", ">", "›", "|", "¦").Replace(question)
+	if runes := []rune(question); len(runes) > historyQuestionLength {
+		question = string(runes[:historyQuestionLength-1]) + "…"
+	}
+	return question
+}
+
+// historyArguments parses the optional count and page number of the history command, pages start at 1
+func historyArguments(parameters []string) (count, page int, ok bool) {
+	count, page = defaultHistoryCount, 1
+	if len(parameters) > 2 && parameters[2] != "" {
+		value, err := strconv.Atoi(parameters[2])
+		if err != nil || value < 1 || value > maxHistoryCount {
+			return 0, 0, false
+		}
+		count = value
+	}
+	if len(parameters) > 3 && parameters[3] != "" {
+		value, err := strconv.Atoi(parameters[3])
+		if err != nil || value < 1 {
+			return 0, 0, false
+		}
+		page = value
+	}
+	return count, page, true
+}
@@ -0,0 +1,135 @@
+package agent_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("History", func() {
+	const (
+		channel = "C1234567890"
+		user    = "U123456"
+	)
+
+	var (
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		testAgent    *agent.Agent
+		askedAt      = time.Date(2026, 3, 14, 10, 0, 0, 0, time.UTC)
+		answers      = []string{"answer", "answer!", "answer-all", "answer-all!", "retry"}
+	)
+
+	history := func(text string) error {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:      user,
+			Text:      text,
+			Channel:   channel,
+			TimeStamp: "1234567890.200000",
+		}}.Process(context.Background(), testAgent)
+	}
+
+	// questions returns count questions of successive threads, newest first
+	questions := func(count int) []database.AskedQuestion {
+		asked := make([]database.AskedQuestion, count)
+		for i := range asked {
+			asked[i] = database.AskedQuestion{
+				SlackThread: fmt.Sprintf("17000000%02d.000100", count-i),
+				User:        "U999",
+				Project:     "sriov",
+				Version:     "4.16",
+				Question:    fmt.Sprintf("Question %d?", count-i),
+				AskedAt:     askedAt,
+			}
+		}
+		return asked
+	}
+
+	BeforeEach(func() {
+		testAgent, mockDB, mockSlackBot, _ = newTestAgent()
+	})
+
+	It("should list the last 10 questions of the channel with links to their threads", func() {
+		asked := questions(2)
+		asked[0].Project, asked[0].Version = "metallb", "4.18"
+		mockDB.EXPECT().ListAskedQuestions(channel, answers, 10, 0).Return(asked, nil)
+		mockSlackBot.EXPECT().GetPermalink(channel, "1700000002.000100").Return("https://slack.example/p2", nil)
+		mockSlackBot.EXPECT().GetPermalink(channel, "1700000001.000100").Return("", errors.New("not found"))
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "📜 Questions answered in this channel, page 1:\n"+
+			"• <https://slack.example/p2|Question 2?> (metallb 4.18, <@U999>, 2026-03-14)\n"+
+			"• Question 1? (sriov 4.16, <@U999>, 2026-03-14)").Return(nil)
+
+		Expect(history("<@BOT123> history")).To(Succeed())
+	})
+
+	It("should list the requested number of questions and point to the next page when it is full", func() {
+		mockDB.EXPECT().ListAskedQuestions(channel, answers, 3, 3).Return(questions(3), nil)
+		mockSlackBot.EXPECT().GetPermalink(channel, gomock.Any()).Return("https://slack.example/p", nil).Times(3)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, gomock.Any()).DoAndReturn(func(_, _, message string) error {
+			Expect(message).To(HavePrefix("📜 Questions answered in this channel, page 2:"))
+			Expect(strings.Count(message, "• ")).To(Equal(3))
+			Expect(message).To(HaveSuffix("Use `history 3 3` for the next page"))
+			return nil
+		})
+
+		Expect(history("<@BOT123> history 3 2")).To(Succeed())
+	})
+
+	It("should not point to a next page when the page isn't full", func() {
+		mockDB.EXPECT().ListAskedQuestions(channel, answers, 5, 0).Return(questions(1), nil)
+		mockSlackBot.EXPECT().GetPermalink(channel, gomock.Any()).Return("https://slack.example/p", nil)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, gomock.Any()).DoAndReturn(func(_, _, message string) error {
+			Expect(message).NotTo(ContainSubstring("next page"))
+			return nil
+		})
+
+		Expect(history("<@BOT123> history 5")).To(Succeed())
+	})
+
+	It("should shorten the questions and keep them from breaking the links", func() {
+		asked := questions(1)
+		asked[0].Question = "Why does <the|VF>\nfail " + strings.Repeat("a", 100)
+		mockDB.EXPECT().ListAskedQuestions(channel, answers, 10, 0).Return(asked, nil)
+		mockSlackBot.EXPECT().GetPermalink(channel, gomock.Any()).Return("https://slack.example/p", nil)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, gomock.Any()).DoAndReturn(func(_, _, message string) error {
+			Expect(message).To(ContainSubstring("<https://slack.example/p|Why does ‹the¦VF› fail aaa"))
+			Expect(message).To(ContainSubstring("a…> (sriov 4.16"))
+			return nil
+		})
+
+		Expect(history("<@BOT123> history")).To(Succeed())
+	})
+
+	It("should tell the user when no question was answered", func() {
+		mockDB.EXPECT().ListAskedQuestions(channel, answers, 10, 0).Return(nil, nil)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "No questions were answered in this channel on page 1").Return(nil)
+
+		Expect(history("<@BOT123> history")).To(Succeed())
+	})
+
+	It("should show the usage for an invalid count or page", func() {
+		for _, text := range []string{"<@BOT123> history 0", "<@BOT123> history 51", "<@BOT123> history ten", "<@BOT123> history 5 0"} {
+			mockSlackBot.EXPECT().PostEphemeral(channel, user, "To list the questions answered in this channel please provide how many to show, from 1 to 50, and optionally a page number starting at 1").Return(nil)
+
+			Expect(history(text)).To(Succeed())
+		}
+	})
+
+	It("should return the database error", func() {
+		mockDB.EXPECT().ListAskedQuestions(channel, answers, 10, 0).Return(nil, errors.New("database is locked"))
+
+		Expect(history("<@BOT123> history")).To(MatchError(ContainSubstring("failed to list questions")))
+	})
+})
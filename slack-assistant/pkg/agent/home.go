@@ -0,0 +1,65 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+)
+
+// homeRecentActivityLimit is the number of recent commands shown in the App Home tab
+const homeRecentActivityLimit = 10
+
+// BuildHomeView builds the App Home tab with the usage instructions, the workspace slugs of the projects that can be
+// asked about and the user recent activity
+func BuildHomeView(audits []database.CommandAudit, projects []string, branding Branding) slack.HomeTabViewRequest {
+	projectsText := i18n.T("home.noProjects")
+	if len(projects) > 0 {
+		projectsText = i18n.T("home.projects", "`"+strings.Join(projects, "`, `")+"`")
+	}
+
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, i18n.T("home.title"), false, false)),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, i18n.T("home.usage"), false, false), nil, nil),
+		slack.NewDividerBlock(),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, projectsText, false, false), nil, nil),
+		slack.NewDividerBlock(),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, i18n.T("home.activity"), false, false), nil, nil),
+	}
+
+	if len(audits) == 0 {
+		blocks = append(blocks, slack.NewContextBlock("",
+			slack.NewTextBlockObject(slack.MarkdownType, i18n.T("home.noActivity"), false, false)))
+	}
+
+	for i := range audits {
+		audit := &audits[i]
+		text := i18n.T("home.audit", branding.statusEmoji(audit.Success), audit.Command, audit.Args, audit.Channel,
+			audit.CreatedAt.Format("2006-01-02 15:04"))
+		blocks = append(blocks, slack.NewContextBlock("",
+			slack.NewTextBlockObject(slack.MarkdownType, text, false, false)))
+	}
+
+	return slack.HomeTabViewRequest{
+		Type:   slack.VTHomeTab,
+		Blocks: slack.Blocks{BlockSet: blocks},
+	}
+}
+
+// handleAppHomeOpened is the internal implementation called by worker pool when a user opens the App Home tab
+func (a *Agent) handleAppHomeOpened(event *slackevents.AppHomeOpenedEvent) error {
+	if event.Tab != "home" {
+		return nil
+	}
+
+	audits, err := a.db.GetRecentCommandsForUser(event.User, homeRecentActivityLimit)
+	if err != nil {
+		fmt.Printf("❌ Failed to get recent activity for user %s: %v\n", event.User, err)
+	}
+
+	return a.slackBot.PublishHomeView(event.User, BuildHomeView(audits, a.knownProjectList(), a.branding))
+}
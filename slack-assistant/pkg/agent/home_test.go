@@ -0,0 +1,126 @@
+package agent_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Home", func() {
+	Describe("BuildHomeView", func() {
+		It("should build a home tab with usage instructions when there is no activity", func() {
+			view := agent.BuildHomeView(nil, nil, agent.DefaultBranding())
+			Expect(view.Type).To(Equal(slack.VTHomeTab))
+			Expect(view.Blocks.BlockSet).To(HaveLen(7))
+
+			usage, ok := view.Blocks.BlockSet[1].(*slack.SectionBlock)
+			Expect(ok).To(BeTrue())
+			Expect(usage.Text.Text).To(ContainSubstring("answer <project> <version>"))
+			Expect(usage.Text.Text).To(ContainSubstring("elaborate"))
+
+			projects, ok := view.Blocks.BlockSet[3].(*slack.SectionBlock)
+			Expect(ok).To(BeTrue())
+			Expect(projects.Text.Text).To(ContainSubstring("list of projects isn't loaded yet"))
+
+			empty, ok := view.Blocks.BlockSet[6].(*slack.ContextBlock)
+			Expect(ok).To(BeTrue())
+			text, ok := empty.ContextElements.Elements[0].(*slack.TextBlockObject)
+			Expect(ok).To(BeTrue())
+			Expect(text.Text).To(ContainSubstring("haven't asked me anything"))
+		})
+
+		It("should list the recent activity", func() {
+			createdAt := time.Date(2025, 7, 29, 10, 30, 0, 0, time.UTC)
+			view := agent.BuildHomeView([]database.CommandAudit{
+				{Command: "answer", Args: "sriov 4.16", Channel: "C123", Success: true, CreatedAt: createdAt},
+				{Command: "inject", Args: "metallb 4.18", Channel: "C456", Success: false, CreatedAt: createdAt},
+			}, nil, agent.DefaultBranding())
+			Expect(view.Blocks.BlockSet).To(HaveLen(8))
+
+			first, ok := view.Blocks.BlockSet[6].(*slack.ContextBlock)
+			Expect(ok).To(BeTrue())
+			text, ok := first.ContextElements.Elements[0].(*slack.TextBlockObject)
+			Expect(ok).To(BeTrue())
+			Expect(text.Text).To(Equal("✅ `answer sriov 4.16` in <#C123> on 2025-07-29 10:30"))
+
+			second, ok := view.Blocks.BlockSet[7].(*slack.ContextBlock)
+			Expect(ok).To(BeTrue())
+			text, ok = second.ContextElements.Elements[0].(*slack.TextBlockObject)
+			Expect(ok).To(BeTrue())
+			Expect(text.Text).To(HavePrefix("❌ `inject metallb 4.18`"))
+		})
+
+		It("should list the projects that can be asked about", func() {
+			view := agent.BuildHomeView(nil, []string{"metallb-4-dot-18", "sriov-4-dot-16"}, agent.DefaultBranding())
+
+			projects, ok := view.Blocks.BlockSet[3].(*slack.SectionBlock)
+			Expect(ok).To(BeTrue())
+			Expect(projects.Text.Text).To(Equal("*Projects you can ask about*\n`metallb-4-dot-18`, `sriov-4-dot-16`"))
+		})
+	})
+
+	Describe("AppHomeOpenedWorkItem", func() {
+		var (
+			ctrl         *gomock.Controller
+			mockDB       *databaseMock.MockInterface
+			mockSlackBot *slackbotMock.MockInterface
+			mockLLM      *llmMock.MockInterface
+			testAgent    *agent.Agent
+		)
+
+		BeforeEach(func() {
+			ctrl = gomock.NewController(GinkgoT())
+			mockDB = databaseMock.NewMockInterface(ctrl)
+			mockSlackBot = slackbotMock.NewMockInterface(ctrl)
+			mockLLM = llmMock.NewMockInterface(ctrl)
+			testAgent = agent.NewAgent(mockDB, mockSlackBot, mockLLM, nil, nil, nil, nil, 1, 10)
+		})
+
+		AfterEach(func() {
+			ctrl.Finish()
+		})
+
+		It("should publish the home view with the known projects and the user recent activity", func() {
+			mockLLM.EXPECT().ListProjects(gomock.Any()).Return([]string{"sriov-4-dot-16"}, nil)
+			_, err := testAgent.RefreshProjects(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+
+			mockDB.EXPECT().GetRecentCommandsForUser("U123", 10).Return([]database.CommandAudit{{Command: "answer", Success: true}}, nil)
+			mockSlackBot.EXPECT().PublishHomeView("U123", gomock.Any()).DoAndReturn(func(_ string, view slack.HomeTabViewRequest) error {
+				Expect(view.Blocks.BlockSet).To(HaveLen(7))
+				projects, ok := view.Blocks.BlockSet[3].(*slack.SectionBlock)
+				Expect(ok).To(BeTrue())
+				Expect(projects.Text.Text).To(ContainSubstring("`sriov-4-dot-16`"))
+				return nil
+			})
+
+			workItem := agent.AppHomeOpenedWorkItem{Event: &slackevents.AppHomeOpenedEvent{User: "U123", Tab: "home"}}
+			Expect(workItem.Process(context.Background(), testAgent)).To(Succeed())
+		})
+
+		It("should still publish the home view when the activity can't be loaded", func() {
+			mockDB.EXPECT().GetRecentCommandsForUser("U123", 10).Return(nil, errors.New("database error"))
+			mockSlackBot.EXPECT().PublishHomeView("U123", gomock.Any()).Return(nil)
+
+			workItem := agent.AppHomeOpenedWorkItem{Event: &slackevents.AppHomeOpenedEvent{User: "U123", Tab: "home"}}
+			Expect(workItem.Process(context.Background(), testAgent)).To(Succeed())
+		})
+
+		It("should ignore other tabs", func() {
+			workItem := agent.AppHomeOpenedWorkItem{Event: &slackevents.AppHomeOpenedEvent{User: "U123", Tab: "messages"}}
+			Expect(workItem.Process(context.Background(), testAgent)).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,49 @@
+package agent
+
+import (
+	"fmt"
+
+	"github.com/slack-go/slack"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+	slackbot "github.com/SchSeba/slack-ai-assistant/pkg/slack-bot"
+)
+
+// SetVision sends the images attached to the questions to the LLM, the model of the backend must support vision
+func (a *Agent) SetVision(enabled bool) {
+	a.vision = enabled
+}
+
+// questionImages returns the images attached to the question to send with it, without vision the thread is told
+// the images aren't read rather than answering as if there were none
+func (a *Agent) questionImages(channel, threadTS string, message slack.Message) []llm.Image {
+	if len(slackbot.ImageFiles(message)) == 0 {
+		return nil
+	}
+	if !a.vision {
+		fmt.Printf("🖼️ Ignoring the images of the question in thread %s, vision is disabled\n", threadTS)
+		a.postImagesNotice(channel, threadTS, "answer.imagesUnsupported")
+		return nil
+	}
+
+	downloaded, err := a.slackBot.GetImages(channel, message)
+	if err != nil {
+		fmt.Printf("⚠️ Failed to get the images of the question in thread %s: %v\n", threadTS, err)
+		a.postImagesNotice(channel, threadTS, "answer.imagesFailed")
+		return nil
+	}
+	images := make([]llm.Image, len(downloaded))
+	for i, image := range downloaded {
+		images[i] = llm.Image{Name: image.Name, MimeType: image.MimeType, Data: image.Data}
+	}
+	fmt.Printf("🖼️ Sending %d images with the question of thread %s\n", len(images), threadTS)
+	return images
+}
+
+// postImagesNotice tells the thread the answer doesn't look at the images of the question
+func (a *Agent) postImagesNotice(channel, threadTS, key string) {
+	if err := a.slackBot.PostMessage(channel, threadTS, i18n.T(key)); err != nil {
+		fmt.Printf("❌ Failed to post images notice: %v\n", err)
+	}
+}
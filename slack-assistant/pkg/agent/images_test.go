@@ -0,0 +1,108 @@
+package agent_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+	slackbot "github.com/SchSeba/slack-ai-assistant/pkg/slack-bot"
+)
+
+var _ = Describe("Image questions", func() {
+	const (
+		channel  = "C1234567890"
+		user     = "U123456"
+		threadTS = "1234567890.100000"
+		question = "Why does my node fail like this?"
+	)
+
+	var (
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+		screenshot   = slack.File{Name: "error.png", Mimetype: "image/png"}
+	)
+
+	answer := func() error {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            "<@BOT123> answer sriov 4.16",
+			Channel:         channel,
+			TimeStamp:       "1234567890.300000",
+			ThreadTimeStamp: threadTS,
+		}}.Process(context.Background(), testAgent)
+	}
+
+	// thread returns the replies of a thread whose question has the files attached
+	thread := func(files ...slack.File) []slack.Message {
+		return []slack.Message{
+			{Msg: slack.Msg{Text: question, User: "U999", Files: files}},
+			{Msg: slack.Msg{Text: "<@BOT123> answer sriov 4.16", User: user}},
+			{Msg: slack.Msg{Text: "Searching for answer...", User: "BOT123"}},
+		}
+	}
+
+	// expectAnswer expects the question to be answered with the images
+	expectAnswer := func(replies []slack.Message, images []llm.Image) {
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return(replies, nil)
+		mockDB.EXPECT().GetSlugForThread(threadTS).Return("slug", true, nil)
+		mockDB.EXPECT().SetLastQuestion(threadTS, question).Return(nil)
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil).AnyTimes()
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "slug", question).
+			DoAndReturn(func(ctx context.Context, _, _, _, _ string) (string, error) {
+				Expect(llm.ImagesFrom(ctx)).To(Equal(images))
+				return "The PF has no VFs configured", nil
+			})
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+	}
+
+	BeforeEach(func() {
+		testAgent, mockDB, mockSlackBot, mockLLM = newTestAgent()
+	})
+
+	It("should send the images of the question to a backend with vision", func() {
+		testAgent.SetVision(true)
+		replies := thread(screenshot)
+		mockSlackBot.EXPECT().GetImages(channel, replies[0]).Return([]slackbot.Image{{Name: "error.png", MimeType: "image/png", Data: []byte("png")}}, nil)
+		expectAnswer(replies, []llm.Image{{Name: "error.png", MimeType: "image/png", Data: []byte("png")}})
+
+		Expect(answer()).To(Succeed())
+	})
+
+	It("should tell the thread the images aren't supported without vision and answer the text", func() {
+		mockSlackBot.EXPECT().GetImages(gomock.Any(), gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "🖼️ Images aren't supported by the AI backend, I can only answer the text of your question. Paste the relevant part of the screenshot as text for a better answer").Return(nil)
+		expectAnswer(thread(screenshot), nil)
+
+		Expect(answer()).To(Succeed())
+	})
+
+	It("should tell the thread when the images can't be downloaded and answer the text", func() {
+		testAgent.SetVision(true)
+		mockSlackBot.EXPECT().GetImages(channel, gomock.Any()).Return(nil, errors.New("file_not_found"))
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "🖼️ I couldn't read the images of your question, I can only answer its text").Return(nil)
+		expectAnswer(thread(screenshot), nil)
+
+		Expect(answer()).To(Succeed())
+	})
+
+	It("should answer a question without images as before", func() {
+		testAgent.SetVision(true)
+		mockSlackBot.EXPECT().GetImages(gomock.Any(), gomock.Any()).Times(0)
+		expectAnswer(thread(slack.File{Name: "must-gather.tar.gz", Mimetype: "application/gzip"}), nil)
+
+		Expect(answer()).To(Succeed())
+	})
+})
@@ -0,0 +1,164 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+	slackbot "github.com/SchSeba/slack-ai-assistant/pkg/slack-bot"
+)
+
+// pendingInject is an injection waiting for the user who requested it to confirm it
+type pendingInject struct {
+	channel  string
+	threadTS string
+	user     string
+	project  string
+	version  string
+	messages []string
+	split    bool
+	// source is the source of the injected documents, the thread permalink when it is empty
+	source string
+	timer  *time.Timer
+}
+
+// EnableInjectConfirmation makes inject post a preview with confirm and cancel buttons instead of injecting right away,
+// the injection is cancelled when it isn't confirmed within the timeout
+func (a *Agent) EnableInjectConfirmation(timeout time.Duration) {
+	a.injectConfirmTimeout = timeout
+}
+
+// RequestInjectConfirmation posts a preview of the messages inject would add and waits for the user to confirm it
+func (a *Agent) RequestInjectConfirmation(channel, threadTS, user, project, version string, split bool) error {
+	messages, err := a.getLastMessagesFromTheSameUser(channel, threadTS)
+	if errors.Is(err, ErrNoQuestion) {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("inject.nothing"))
+	}
+	if err != nil {
+		fmt.Printf("❌ Failed to get thread messages: %v\n", err)
+		return fmt.Errorf("failed to get thread messages: %w", err)
+	}
+	if strings.TrimSpace(strings.Join(messages, "")) == "" {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("inject.nothing"))
+	}
+
+	return a.requestConfirmation(&pendingInject{
+		channel:  channel,
+		threadTS: threadTS,
+		user:     user,
+		project:  project,
+		version:  version,
+		messages: messages,
+		split:    split,
+	})
+}
+
+// requestConfirmation stores the pending injection and posts its preview with the confirm and cancel buttons
+func (a *Agent) requestConfirmation(pending *pendingInject) error {
+	a.pendingMu.Lock()
+	a.nextPendingID++
+	pendingID := strconv.FormatUint(a.nextPendingID, 10)
+	if a.pendingInjects == nil {
+		a.pendingInjects = map[string]*pendingInject{}
+	}
+	a.pendingInjects[pendingID] = pending
+	pending.timer = time.AfterFunc(a.injectConfirmTimeout, func() { a.expireInject(pendingID) })
+	a.pendingMu.Unlock()
+
+	documents := i18n.T("inject.singleDocument")
+	if pending.split {
+		documents = i18n.T("inject.documents", len(pending.messages))
+	}
+	intro := i18n.T("inject.confirm", pending.project, pending.version, documents, a.injectConfirmTimeout)
+	preview := strings.Join(pending.messages, "\n\n")
+	if err := a.slackBot.PostBlocks(pending.channel, pending.threadTS, slackbot.BuildInjectConfirmationBlocks(intro, preview, pendingID)); err != nil {
+		a.takePendingInject(pendingID)
+		return fmt.Errorf("failed to post inject confirmation: %w", err)
+	}
+	return nil
+}
+
+// ConfirmInject injects the messages of a pending injection, only the user who requested it can confirm it
+func (a *Agent) ConfirmInject(ctx context.Context, channel, user, pendingID string) error {
+	pending, err := a.takeOwnPendingInject(channel, user, pendingID)
+	if pending == nil {
+		return err
+	}
+	source := pending.source
+	if source == "" {
+		source = a.threadPermalink(pending.channel, pending.threadTS)
+	}
+	return a.injectMessages(ctx, pending.channel, pending.threadTS, pending.project, pending.version, source, pending.messages, pending.split)
+}
+
+// CancelInject drops a pending injection, only the user who requested it can cancel it
+func (a *Agent) CancelInject(channel, user, pendingID string) error {
+	pending, err := a.takeOwnPendingInject(channel, user, pendingID)
+	if pending == nil {
+		return err
+	}
+	return a.slackBot.PostMessage(pending.channel, pending.threadTS, i18n.T("inject.cancelled"))
+}
+
+// expireInject cancels a pending injection that wasn't confirmed in time
+func (a *Agent) expireInject(pendingID string) {
+	pending := a.takePendingInject(pendingID)
+	if pending == nil {
+		return
+	}
+
+	fmt.Printf("⌛ Inject %s for project %s on version %s was not confirmed in time\n", pendingID, pending.project, pending.version)
+	message := i18n.T("inject.expired", a.injectConfirmTimeout)
+	if err := a.slackBot.PostMessage(pending.channel, pending.threadTS, message); err != nil {
+		fmt.Printf("❌ Failed to post inject timeout: %v\n", err)
+	}
+}
+
+// dropPendingInjects stops the timers of the pending injections on shutdown, they are lost with the process
+func (a *Agent) dropPendingInjects() {
+	a.pendingMu.Lock()
+	defer a.pendingMu.Unlock()
+
+	for pendingID, pending := range a.pendingInjects {
+		pending.timer.Stop()
+		delete(a.pendingInjects, pendingID)
+	}
+}
+
+// takeOwnPendingInject removes and returns the pending injection when it belongs to the user,
+// otherwise it tells the user why nothing happened and returns nil
+func (a *Agent) takeOwnPendingInject(channel, user, pendingID string) (*pendingInject, error) {
+	a.pendingMu.Lock()
+	pending, ok := a.pendingInjects[pendingID]
+	if ok && pending.user == user {
+		delete(a.pendingInjects, pendingID)
+		pending.timer.Stop()
+	}
+	a.pendingMu.Unlock()
+
+	if !ok {
+		return nil, a.slackBot.PostEphemeral(channel, user, i18n.T("inject.handled"))
+	}
+	if pending.user != user {
+		return nil, a.slackBot.PostEphemeral(channel, user, i18n.T("inject.notRequester"))
+	}
+	return pending, nil
+}
+
+// takePendingInject removes and returns the pending injection, or nil when it was already handled
+func (a *Agent) takePendingInject(pendingID string) *pendingInject {
+	a.pendingMu.Lock()
+	defer a.pendingMu.Unlock()
+
+	pending, ok := a.pendingInjects[pendingID]
+	if !ok {
+		return nil
+	}
+	delete(a.pendingInjects, pendingID)
+	pending.timer.Stop()
+	return pending
+}
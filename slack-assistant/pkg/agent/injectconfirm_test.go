@@ -0,0 +1,145 @@
+package agent_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+	slackbot "github.com/SchSeba/slack-ai-assistant/pkg/slack-bot"
+)
+
+var _ = Describe("Inject confirmation", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.100000"
+		user     = "U123456"
+		preview  = "MetalLB needs a BGPPeer per router"
+	)
+
+	var (
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	thread := []slack.Message{
+		{Msg: slack.Msg{Text: "How do I configure BGP?", User: user}},
+		{Msg: slack.Msg{Text: preview, User: user}},
+		{Msg: slack.Msg{Text: "<@BOT123> inject metallb 4.18", User: user}},
+	}
+
+	// requestInject runs the inject command and returns the ID of the pending injection from the posted buttons
+	requestInject := func() string {
+		var pendingID string
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return(thread, nil)
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).DoAndReturn(func(_, _ string, blocks []slack.Block, _ ...slackbot.MessageOption) error {
+			Expect(slackbot.AnswerText(slack.Blocks{BlockSet: blocks})).To(ContainSubstring(preview))
+			actions, ok := blocks[len(blocks)-1].(*slack.ActionBlock)
+			Expect(ok).To(BeTrue())
+			button, ok := actions.Elements.ElementSet[0].(*slack.ButtonBlockElement)
+			Expect(ok).To(BeTrue())
+			Expect(button.ActionID).To(Equal(slackbot.ActionInjectConfirm))
+			pendingID = button.Value
+			return nil
+		})
+
+		Expect(agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            "<@BOT123> inject metallb 4.18",
+			Channel:         channel,
+			TimeStamp:       "1234567890.200000",
+			ThreadTimeStamp: threadTS,
+		}}.Process(context.Background(), testAgent)).To(Succeed())
+		Expect(pendingID).NotTo(BeEmpty())
+		return pendingID
+	}
+
+	// click presses an inject preview button as the given user
+	click := func(actionID, pendingID, clickedBy string) error {
+		callback := &slack.InteractionCallback{
+			Type:    slack.InteractionTypeBlockActions,
+			User:    slack.User{ID: clickedBy},
+			Channel: slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: channel}}},
+			Message: slack.Message{Msg: slack.Msg{Timestamp: "1234567890.300000", ThreadTimestamp: threadTS}},
+		}
+		callback.ActionCallback.BlockActions = []*slack.BlockAction{{ActionID: actionID, Value: pendingID}}
+		return agent.InteractionWorkItem{Callback: callback}.Process(context.Background(), testAgent)
+	}
+
+	BeforeEach(func() {
+		testAgent, mockDB, mockSlackBot, mockLLM = newTestAgent()
+		testAgent.EnableInjectConfirmation(time.Minute)
+
+		mockDB.EXPECT().WithTransaction(gomock.Any()).DoAndReturn(func(fn func(database.Interface) error) error {
+			return fn(mockDB)
+		}).AnyTimes()
+		mockDB.EXPECT().RecordInjection(gomock.Any()).Return(nil).AnyTimes()
+	})
+
+	It("should inject the previewed messages once confirmed", func() {
+		pendingID := requestInject()
+
+		mockSlackBot.EXPECT().GetPermalink(channel, threadTS).Return("", nil)
+		mockLLM.EXPECT().Inject(gomock.Any(), "metallb", "4.18", preview, gomock.Any()).Return(nil)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Document injected for project metallb on version 4.18").Return(nil)
+
+		Expect(click(slackbot.ActionInjectConfirm, pendingID, user)).To(Succeed())
+	})
+
+	It("should not preview anything when the command starts the thread", func() {
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+			{Msg: slack.Msg{Text: "<@BOT123> inject metallb 4.18", User: user}},
+		}, nil)
+		mockSlackBot.EXPECT().PostBlocks(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "There is nothing to inject in this thread").Return(nil)
+
+		Expect(testAgent.RequestInjectConfirmation(channel, threadTS, user, "metallb", "4.18", false)).To(Succeed())
+	})
+
+	It("should not inject when cancelled", func() {
+		pendingID := requestInject()
+
+		mockLLM.EXPECT().Inject(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "🚫 Injection cancelled").Return(nil)
+		Expect(click(slackbot.ActionInjectCancel, pendingID, user)).To(Succeed())
+
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "This injection request expired or was already handled").Return(nil)
+		Expect(click(slackbot.ActionInjectConfirm, pendingID, user)).To(Succeed())
+	})
+
+	It("should cancel the injection when it isn't confirmed in time", func() {
+		testAgent.EnableInjectConfirmation(10 * time.Millisecond)
+		expired := make(chan struct{})
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "⌛ Injection cancelled, it was not confirmed within 10ms").DoAndReturn(func(_, _, _ string, _ ...slackbot.MessageOption) error {
+			close(expired)
+			return nil
+		})
+		pendingID := requestInject()
+		Eventually(expired).Should(BeClosed())
+
+		mockLLM.EXPECT().Inject(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "This injection request expired or was already handled").Return(nil)
+		Expect(click(slackbot.ActionInjectConfirm, pendingID, user)).To(Succeed())
+	})
+
+	It("should only let the requester confirm the injection", func() {
+		pendingID := requestInject()
+
+		mockSlackBot.EXPECT().PostEphemeral(channel, "U999999", "Only the user who requested the injection can confirm or cancel it").Return(nil)
+		Expect(click(slackbot.ActionInjectConfirm, pendingID, "U999999")).To(Succeed())
+
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "🚫 Injection cancelled").Return(nil)
+		Expect(click(slackbot.ActionInjectCancel, pendingID, user)).To(Succeed())
+	})
+})
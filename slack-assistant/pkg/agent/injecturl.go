@@ -0,0 +1,94 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+	"github.com/SchSeba/slack-ai-assistant/pkg/webpage"
+)
+
+// pageFetchTimeout bounds the download of a page injected from a URL
+const pageFetchTimeout = 30 * time.Second
+
+// newPageFetcher returns the fetcher of the pages injected from a URL, it refuses the internal addresses so a user
+// can't make the bot read the services of its own network
+func newPageFetcher() *webpage.Fetcher {
+	return webpage.NewFetcher(webpage.NewClient(pageFetchTimeout), webpage.DefaultMaxSize)
+}
+
+// SetPageClient replaces the client fetching the pages injected from a URL, e.g. with one reaching a test server
+func (a *Agent) SetPageClient(client *http.Client) {
+	a.pages = webpage.NewFetcher(client, webpage.DefaultMaxSize)
+}
+
+// injectURL returns the http(s) URL given to inject, or an empty string when the thread messages are injected,
+// Slack wraps the links as <url> or <url|label>
+func injectURL(parameters []string) string {
+	for _, parameter := range parameters {
+		link := strings.TrimSuffix(strings.TrimPrefix(parameter, "<"), ">")
+		link, _, _ = strings.Cut(link, "|")
+		if strings.HasPrefix(link, "http://") || strings.HasPrefix(link, "https://") {
+			return link
+		}
+	}
+	return ""
+}
+
+// InjectURL fetches the page and adds its readable text to the project and version knowledge base as a single document,
+// the page URL is the source of the document
+func (a *Agent) InjectURL(ctx context.Context, channel, threadTS, user, project, version, pageURL string) error {
+	fmt.Printf("🌐 Fetching %s to inject it into project %s on version %s\n", pageURL, project, version)
+	page, err := a.pages.Fetch(ctx, pageURL)
+	if err != nil {
+		fmt.Printf("❌ Failed to fetch %s: %v\n", pageURL, err)
+		if postErr := a.slackBot.PostEphemeral(channel, user, pageFailureMessage(pageURL, err)); postErr != nil {
+			fmt.Printf("❌ Failed to post error message: %v\n", postErr)
+		}
+		return fmt.Errorf("failed to fetch %s: %w", pageURL, err)
+	}
+
+	content := page.Text
+	if page.Title != "" {
+		content = fmt.Sprintf("# %s\n\n%s", page.Title, page.Text)
+	}
+
+	if a.injectConfirmTimeout > 0 {
+		return a.requestConfirmation(&pendingInject{
+			channel:  channel,
+			threadTS: threadTS,
+			user:     user,
+			project:  project,
+			version:  version,
+			messages: []string{content},
+			source:   page.URL,
+		})
+	}
+	return a.injectMessages(ctx, channel, threadTS, project, version, page.URL, []string{content}, false)
+}
+
+// pageFailureMessage tells the user why the page couldn't be injected
+func pageFailureMessage(pageURL string, err error) string {
+	var (
+		statusErr   *webpage.StatusError
+		unsupported *webpage.UnsupportedTypeError
+	)
+	switch {
+	case errors.As(err, &statusErr):
+		return i18n.T("inject.urlStatus", pageURL, statusErr.StatusCode)
+	case errors.As(err, &unsupported):
+		return i18n.T("inject.urlUnsupported", pageURL, unsupported.ContentType)
+	case errors.Is(err, webpage.ErrTooLarge):
+		return i18n.T("inject.urlTooLarge", pageURL, webpage.DefaultMaxSize>>20)
+	case errors.Is(err, webpage.ErrNoText):
+		return i18n.T("inject.urlNoText", pageURL)
+	case errors.Is(err, webpage.ErrForbiddenAddress):
+		return i18n.T("inject.urlForbidden", pageURL)
+	default:
+		return i18n.T("inject.urlUnreachable", pageURL)
+	}
+}
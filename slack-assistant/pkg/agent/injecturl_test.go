@@ -0,0 +1,108 @@
+package agent_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Inject from a URL", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.100000"
+		user     = "U123456"
+	)
+
+	var (
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+		server       *httptest.Server
+	)
+
+	mention := func(text string) error {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            text,
+			Channel:         channel,
+			TimeStamp:       "1234567890.200000",
+			ThreadTimeStamp: threadTS,
+		}}.Process(context.Background(), testAgent)
+	}
+
+	BeforeEach(func() {
+		testAgent, mockDB, mockSlackBot, mockLLM = newTestAgent()
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/sriov", func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			//nolint:errcheck // test server
+			_, _ = w.Write([]byte(`<html><head><title>SR-IOV</title></head><body><nav>Home</nav>` +
+				`<h1>Creating a VF</h1><p>Create a SriovNetworkNodePolicy.</p><script>track()</script></body></html>`))
+		})
+		mux.HandleFunc("/manual.pdf", func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/pdf")
+			//nolint:errcheck // test server
+			_, _ = w.Write([]byte("%PDF-1.7"))
+		})
+		server = httptest.NewServer(mux)
+		testAgent.SetPageClient(server.Client())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should inject the readable text of the page with the page as source", func() {
+		pageURL := server.URL + "/sriov"
+		mockDB.EXPECT().WithTransaction(gomock.Any()).DoAndReturn(func(fn func(database.Interface) error) error {
+			return fn(mockDB)
+		})
+		mockDB.EXPECT().RecordInjection(gomock.Any()).DoAndReturn(func(document *database.InjectedDocument) error {
+			Expect(document.Source).To(Equal(pageURL))
+			return nil
+		})
+		mockLLM.EXPECT().Inject(gomock.Any(), "sriov", "4.16", "# SR-IOV\n\nCreating a VF\nCreate a SriovNetworkNodePolicy.", llm.DocumentMetadata{Source: pageURL}).Return(nil)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Document injected for project sriov on version 4.16").Return(nil)
+
+		// Slack wraps the links of the message in angle brackets
+		Expect(mention("<@BOT123> inject sriov 4.16 <" + pageURL + ">")).To(Succeed())
+	})
+
+	It("should tell the user when the page isn't HTML or text", func() {
+		pageURL := server.URL + "/manual.pdf"
+		mockLLM.EXPECT().Inject(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, `I can't inject `+pageURL+`, only HTML and text pages are supported and it is "application/pdf"`).Return(nil)
+
+		Expect(mention("<@BOT123> inject sriov 4.16 <" + pageURL + "|the manual>")).To(HaveOccurred())
+	})
+
+	It("should tell the user when the page can't be fetched", func() {
+		pageURL := server.URL + "/missing"
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "I couldn't fetch "+pageURL+", the site answered with status 404").Return(nil)
+
+		Expect(mention("<@BOT123> inject sriov 4.16 " + pageURL)).To(HaveOccurred())
+	})
+
+	It("should refuse the pages of an internal address", func() {
+		testAgent = agent.NewAgent(mockDB, mockSlackBot, mockLLM, nil, nil, nil, nil, 1, 10)
+		pageURL := server.URL + "/sriov"
+		mockLLM.EXPECT().Inject(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "I can't inject "+pageURL+", the pages of internal addresses can't be fetched").Return(nil)
+
+		Expect(mention("<@BOT123> inject sriov 4.16 " + pageURL)).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+	"github.com/SchSeba/slack-ai-assistant/pkg/metrics"
+)
+
+// timedCommands are the commands whose latency is recorded, true when their first argument is the project
+var timedCommands = map[string]bool{
+	"answer":       true,
+	"answer-all":   true,
+	"answer-multi": false,
+	"continue":     false,
+	"inject":       true,
+	"export":       true,
+	"docs":         true,
+	"elaborate":    false,
+	"retry":        false,
+	"switch":       true,
+}
+
+// otherProject is the latency label of the projects outside the allowlist
+const otherProject = "other"
+
+// Latencies returns the latency histograms of the commands by command and project
+func (a *Agent) Latencies() *metrics.LatencyRecorder {
+	return a.latencies
+}
+
+// observeLatency records and logs how long a timed command took, the other commands are ignored
+// so free-form follow-up questions don't create a histogram each
+func (a *Agent) observeLatency(command string, parameters []string, duration time.Duration) {
+	command = strings.TrimSuffix(command, "!")
+	hasProject, ok := timedCommands[command]
+	if !ok {
+		return
+	}
+	project := ""
+	if hasProject && len(parameters) > 3 {
+		project = a.latencyProject(parameters[2], parameters[3])
+	}
+
+	fmt.Printf("⏱️ Command %s for project %q took %s\n", command, project, duration)
+	a.latencies.Observe(command, project, duration)
+}
+
+// latencyProject returns the project as latency label once its workspace is in the allowlist, the unknown projects
+// and all of them before the allowlist is loaded share the other label so the user input can't add histograms
+func (a *Agent) latencyProject(project, version string) string {
+	a.projectsMu.RLock()
+	defer a.projectsMu.RUnlock()
+	if _, ok := a.knownProjects[llm.WorkspaceSlug(project, version)]; !ok {
+		return otherProject
+	}
+	return project
+}
@@ -0,0 +1,102 @@
+package agent_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+	"github.com/SchSeba/slack-ai-assistant/pkg/metrics"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Command latency", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.100000"
+		user     = "U123456"
+		// llmLatency is the artificial latency of every LLM call
+		llmLatency = 20 * time.Millisecond
+	)
+
+	var (
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	mention := func(text string) error {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            text,
+			Channel:         channel,
+			TimeStamp:       "1234567890.200000",
+			ThreadTimeStamp: threadTS,
+		}}.Process(context.Background(), testAgent)
+	}
+
+	BeforeEach(func() {
+		testAgent, _, mockSlackBot, mockLLM = newTestAgent()
+	})
+
+	It("should record a timing per command execution by project", func() {
+		mockLLM.EXPECT().ListProjects(gomock.Any()).Return([]string{"metallb-4-dot-18", "sriov-4-dot-16"}, nil)
+		_, err := testAgent.RefreshProjects(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+
+		mockLLM.EXPECT().ListDocuments(gomock.Any(), "metallb", "4.18").DoAndReturn(func(context.Context, string, string) ([]llm.DocumentInfo, error) {
+			time.Sleep(llmLatency)
+			return []llm.DocumentInfo{}, nil
+		}).Times(2)
+		mockLLM.EXPECT().ListDocuments(gomock.Any(), "sriov", "4.16").Return(nil, &llm.StatusError{StatusCode: 404, Body: "workspace not found"})
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, gomock.Any()).Return(nil).Times(3)
+
+		Expect(mention("<@BOT123> docs metallb 4.18")).To(Succeed())
+		Expect(mention("<@BOT123> docs metallb 4.18")).To(Succeed())
+		// A failed command is timed as well
+		Expect(mention("<@BOT123> docs sriov 4.16")).NotTo(Succeed())
+
+		snapshot := testAgent.Latencies().Snapshot()
+		Expect(snapshot).To(HaveLen(2))
+		metallb := snapshot[metrics.LatencyKey{Command: "docs", Project: "metallb"}]
+		Expect(metallb.Count).To(BeEquivalentTo(2))
+		Expect(metallb.Sum).To(BeNumerically(">=", 2*llmLatency))
+		Expect(snapshot[metrics.LatencyKey{Command: "docs", Project: "sriov"}].Count).To(BeEquivalentTo(1))
+	})
+
+	It("should record the projects outside the allowlist under a single label", func() {
+		mockLLM.EXPECT().ListProjects(gomock.Any()).Return([]string{"metallb-4-dot-18"}, nil)
+		_, err := testAgent.RefreshProjects(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, gomock.Any()).Return(nil).Times(2)
+
+		Expect(mention("<@BOT123> docs sriov 4.16")).To(Succeed())
+		Expect(mention("<@BOT123> docs made-up-project 1.0")).To(Succeed())
+
+		snapshot := testAgent.Latencies().Snapshot()
+		Expect(snapshot).To(HaveLen(1))
+		Expect(snapshot[metrics.LatencyKey{Command: "docs", Project: "other"}].Count).To(BeEquivalentTo(2))
+	})
+
+	It("should record every project under a single label until the allowlist is loaded", func() {
+		mockLLM.EXPECT().ListDocuments(gomock.Any(), "metallb", "4.18").Return([]llm.DocumentInfo{}, nil)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, gomock.Any()).Return(nil)
+
+		Expect(mention("<@BOT123> docs metallb 4.18")).To(Succeed())
+		Expect(testAgent.Latencies().Snapshot()).To(HaveKey(metrics.LatencyKey{Command: "docs", Project: "other"}))
+	})
+
+	It("should not time the commands that don't wait for the LLM", func() {
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, gomock.Any()).Return(nil)
+
+		Expect(mention("<@BOT123> status")).To(Succeed())
+		Expect(testAgent.Latencies().Snapshot()).To(BeEmpty())
+	})
+})
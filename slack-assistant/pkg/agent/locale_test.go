@@ -0,0 +1,65 @@
+package agent_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Locale", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.100000"
+		user     = "U123456"
+	)
+
+	BeforeEach(func() {
+		Expect(i18n.SetLocale("es")).To(Succeed())
+		DeferCleanup(i18n.SetLocale, i18n.DefaultLocale)
+	})
+
+	It("should post the command help in the selected locale", func() {
+		ctrl := gomock.NewController(GinkgoT())
+		defer ctrl.Finish()
+		mockDB := databaseMock.NewMockInterface(ctrl)
+		mockSlackBot := slackbotMock.NewMockInterface(ctrl)
+		testAgent := agent.NewAgent(mockDB, mockSlackBot, llmMock.NewMockInterface(ctrl), nil, nil, nil, nil, 1, 10)
+
+		mockSlackBot.EXPECT().GetBotUser().Return(nil).AnyTimes()
+		mockSlackBot.EXPECT().GetUserName(gomock.Any()).Return("alice", nil).AnyTimes()
+		mockDB.EXPECT().RecordCommand(gomock.Any()).Return(nil).AnyTimes()
+		mockDB.EXPECT().GetThreadContext(gomock.Any()).Return(nil, false, nil).AnyTimes()
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "Usa uno de los siguientes comandos (answer,answer-multi,cancel,continue,correct,docs,elaborate,export,history,inject,memory,mode,pin,retry,status,strict,switch,temperature,topk,unpin)").Return(nil)
+
+		workItem := agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:      user,
+			Text:      "<@BOT123> unknown",
+			Channel:   channel,
+			TimeStamp: threadTS,
+		}}
+		Expect(workItem.Process(context.Background(), testAgent)).To(Succeed())
+	})
+
+	It("should show the App Home usage in the selected locale", func() {
+		view := agent.BuildHomeView(nil, nil, agent.DefaultBranding())
+
+		usage, ok := view.Blocks.BlockSet[1].(*slack.SectionBlock)
+		Expect(ok).To(BeTrue())
+		Expect(usage.Text.Text).To(HavePrefix("*Cómo usarme*"))
+		Expect(usage.Text.Text).To(ContainSubstring("`answer <project> <version>` - responde al último mensaje del hilo"))
+	})
+
+	It("should use the selected locale for the default progress messages", func() {
+		Expect(agent.DefaultBranding().SearchingMessage).To(Equal("Buscando una respuesta..."))
+	})
+})
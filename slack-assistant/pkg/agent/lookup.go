@@ -0,0 +1,84 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/slack-go/slack"
+	"golang.org/x/sync/errgroup"
+)
+
+// slackLookupConcurrency bounds the Slack API calls made at the same time to assemble the context of a thread
+const slackLookupConcurrency = 4
+
+// lookupConcurrently runs independent Slack lookups, at most slackLookupConcurrency at a time.
+// Every failure is returned joined instead of only the first one, and the lookups not started yet
+// are skipped once the context is cancelled.
+func lookupConcurrently(ctx context.Context, lookups ...func() error) error {
+	errs := make([]error, len(lookups))
+	var group errgroup.Group
+	group.SetLimit(slackLookupConcurrency)
+	for i, lookup := range lookups {
+		group.Go(func() error {
+			if ctx.Err() != nil {
+				return nil
+			}
+			errs[i] = lookup()
+			return nil
+		})
+	}
+	// The failures are kept per lookup so they can all be reported
+	_ = group.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// sanitizeMessages sanitizes the messages concurrently, so the user names they mention are resolved in parallel
+func (a *Agent) sanitizeMessages(ctx context.Context, texts []string) ([]string, error) {
+	sanitized := make([]string, len(texts))
+	lookups := make([]func() error, len(texts))
+	for i, text := range texts {
+		lookups[i] = func() error {
+			sanitized[i] = a.sanitize(text)
+			return nil
+		}
+	}
+
+	if err := lookupConcurrently(ctx, lookups...); err != nil {
+		return nil, err
+	}
+	return sanitized, nil
+}
+
+// getRepliesAndPermalink fetches the thread replies and the thread permalink at the same time,
+// the permalink is empty when Slack doesn't return one
+func (a *Agent) getRepliesAndPermalink(ctx context.Context, channel, threadTS string) ([]slack.Message, string, error) {
+	var (
+		replies   []slack.Message
+		permalink string
+	)
+	err := lookupConcurrently(ctx,
+		func() error {
+			var err error
+			replies, err = a.slackBot.GetConversationReplies(&slack.GetConversationRepliesParameters{
+				ChannelID: channel,
+				Timestamp: threadTS,
+				Inclusive: true, // Include the parent message
+			})
+			return err
+		},
+		func() error {
+			permalink = a.threadPermalink(channel, threadTS)
+			return nil
+		},
+	)
+	if err != nil {
+		fmt.Printf("❌ Failed to retrieve thread messages: %v\n", err)
+		return nil, "", err
+	}
+	return replies, permalink, nil
+}
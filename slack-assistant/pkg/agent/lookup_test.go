@@ -0,0 +1,122 @@
+package agent_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+	slackbot "github.com/SchSeba/slack-ai-assistant/pkg/slack-bot"
+)
+
+var _ = Describe("Slack lookups", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.100000"
+		// slackLatency is the artificial latency of every user name lookup
+		slackLatency = 50 * time.Millisecond
+		mentions     = 8
+	)
+
+	var (
+		ctrl         *gomock.Controller
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	// threadMentioningUsers returns a thread where every message mentions another user
+	threadMentioningUsers := func() []slack.Message {
+		replies := make([]slack.Message, 0, mentions)
+		for i := range mentions {
+			replies = append(replies, slack.Message{Msg: slack.Msg{Text: fmt.Sprintf("ping <@U%03d>", i), User: "U123"}})
+		}
+		return replies
+	}
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockDB = databaseMock.NewMockInterface(ctrl)
+		mockSlackBot = slackbotMock.NewMockInterface(ctrl)
+		mockLLM = llmMock.NewMockInterface(ctrl)
+		testAgent = agent.NewAgent(mockDB, mockSlackBot, mockLLM, nil, nil, nil, nil, 1, 10)
+
+		mockDB.EXPECT().SetThreadTruncated(gomock.Any(), false).Return(nil).AnyTimes()
+		mockLLM.EXPECT().LastContext(gomock.Any()).Return(nil).AnyTimes()
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	It("should resolve the user names of the thread in parallel", func() {
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return(threadMentioningUsers(), nil)
+		mockSlackBot.EXPECT().GetUserName(gomock.Any()).DoAndReturn(func(userID string) (string, error) {
+			time.Sleep(slackLatency)
+			return "name-" + userID, nil
+		}).Times(mentions)
+		mockDB.EXPECT().GetSlugForThread(threadTS).Return("existing-slug", true, nil)
+		mockDB.EXPECT().SetLastQuestion(threadTS, gomock.Any()).Return(nil)
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil).AnyTimes()
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "existing-slug", gomock.Any()).DoAndReturn(
+			func(_ context.Context, _, _, _, message string) (string, error) {
+				// The messages keep their order whatever lookup finished first
+				for i := range mentions {
+					Expect(message).To(ContainSubstring(fmt.Sprintf("ping @name-U%03d\n", i)))
+				}
+				return "answer", nil
+			})
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+
+		start := time.Now()
+		Expect(testAgent.AnswerQuestion(context.Background(), channel, threadTS, "sriov", "4.16", true, false)).To(Succeed())
+		// Sequential lookups take mentions*slackLatency, the bounded parallel ones a few rounds of slackLatency
+		Expect(time.Since(start)).To(BeNumerically("<", mentions*slackLatency/2))
+	})
+
+	It("should stop the lookups when the context is cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).DoAndReturn(func(_ *slack.GetConversationRepliesParameters) ([]slack.Message, error) {
+			cancel()
+			return threadMentioningUsers(), nil
+		})
+		mockSlackBot.EXPECT().GetUserName(gomock.Any()).Times(0)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		err := testAgent.AnswerQuestion(ctx, channel, threadTS, "sriov", "4.16", true, false)
+		Expect(err).To(MatchError(context.Canceled))
+	})
+
+	It("should fetch the replies and the permalink of an export at the same time", func() {
+		released := make(chan struct{})
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).DoAndReturn(func(_ *slack.GetConversationRepliesParameters) ([]slack.Message, error) {
+			// The replies are only returned once the permalink was requested
+			Eventually(released).Should(BeClosed())
+			return []slack.Message{{Msg: slack.Msg{Text: "How do I create a VF?", User: "U123"}}}, nil
+		})
+		mockSlackBot.EXPECT().GetPermalink(channel, threadTS).DoAndReturn(func(_, _ string) (string, error) {
+			close(released)
+			return "https://example.slack.com/archives/C1234567890/p1234567890100000", nil
+		})
+		mockSlackBot.EXPECT().GetBotUser().Return(nil)
+		mockSlackBot.EXPECT().GetUserName(gomock.Any()).Return("", errors.New("not found")).AnyTimes()
+		mockLLM.EXPECT().Inject(gomock.Any(), "sriov", "4.16", gomock.Any(), gomock.Any()).Return(nil)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, gomock.Any()).DoAndReturn(func(_, _, _ string, _ ...slackbot.MessageOption) error {
+			return nil
+		})
+
+		Expect(testAgent.Export(context.Background(), channel, threadTS, "sriov", "4.16")).To(Succeed())
+	})
+})
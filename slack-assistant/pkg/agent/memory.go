@@ -0,0 +1,61 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+)
+
+// memoryDescriptions are the message keys explaining the values the memory command accepts
+var memoryDescriptions = map[string]string{
+	"on":  "memory.on",
+	"off": "memory.off",
+}
+
+// Memory sets whether the next questions of the thread are answered in the context of the previous ones (on)
+// or each on its own (off), the setting is stored with the thread context
+func (a *Agent) Memory(channel, threadTS, user, value string) error {
+	description, ok := memoryDescriptions[value]
+	if !ok {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("usage.memory"))
+	}
+	if a.getThreadContext(threadTS) == nil {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("thread.noAnswer"))
+	}
+
+	if err := a.db.SetThreadMemoryOff(threadTS, value == "off"); err != nil {
+		fmt.Printf("❌ Failed to store the memory of thread %s: %v\n", threadTS, err)
+		return fmt.Errorf("failed to set thread memory: %w", err)
+	}
+
+	if err := a.slackBot.PostMessage(channel, threadTS, i18n.T("memory.done", i18n.T(description))); err != nil {
+		return fmt.Errorf("failed to send response: %w", err)
+	}
+	return nil
+}
+
+// questionSlug returns the LLM thread answering the next question of the Slack thread: the stored slug while
+// the thread keeps its memory, a new LLM thread when the memory is off. The new thread is stored with the
+// Slack thread so continue carries on the answer given in it
+func (a *Agent) questionSlug(ctx context.Context, threadTS string, threadContext *database.SlackThreadToSlug, slug string) (string, error) {
+	if threadContext == nil || !threadContext.MemoryOff {
+		return slug, nil
+	}
+
+	unlock := a.slugLocks.lock(threadTS)
+	defer unlock()
+
+	fmt.Printf("🧠 The memory of thread %s is off, asking in a new LLM thread\n", threadTS)
+	slug, err := a.llmClient.CreateThread(ctx, threadContext.Project, threadContext.Version)
+	if err != nil {
+		fmt.Printf("❌ Failed to create thread: %v\n", err)
+		return "", fmt.Errorf("failed to create thread: %w", err)
+	}
+	if err := a.db.UpdateThreadContext(threadTS, slug, threadContext.Project, threadContext.Version); err != nil {
+		fmt.Printf("❌ Failed to update thread context in database: %v\n", err)
+		return "", fmt.Errorf("failed to update thread context in database: %w", err)
+	}
+	return slug, nil
+}
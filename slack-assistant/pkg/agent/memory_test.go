@@ -0,0 +1,155 @@
+package agent_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Memory", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.100000"
+		user     = "U123456"
+		usage    = "To change the memory of the thread please use `memory on` to answer in the context of the previous questions or `memory off` to answer each question on its own"
+	)
+
+	var (
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	mention := func(text string) error {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            text,
+			Channel:         channel,
+			TimeStamp:       "1234567890.200000",
+			ThreadTimeStamp: threadTS,
+		}}.Process(context.Background(), testAgent)
+	}
+
+	// storedThread is the thread context of a thread answered for metallb
+	storedThread := func(memoryOff bool) *database.SlackThreadToSlug {
+		return &database.SlackThreadToSlug{
+			SlackThread:  threadTS,
+			ThreadSlug:   "stored-slug",
+			Project:      "metallb",
+			Version:      "4.18",
+			LastQuestion: "How do I configure BGP?",
+			MemoryOff:    memoryOff,
+		}
+	}
+
+	BeforeEach(func() {
+		testAgent, mockDB, mockSlackBot, mockLLM = newTestAgent()
+	})
+
+	It("should reuse the stored LLM thread for each question when the memory is on", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(storedThread(false), true, nil).Times(2)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil).Times(2)
+		mockLLM.EXPECT().CreateThread(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		mockDB.EXPECT().UpdateThreadContext(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "stored-slug", gomock.Any()).Return("Create a BGPPeer", nil).Times(2)
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil).Times(2)
+
+		Expect(mention("<@BOT123> how do I configure BGP?")).To(Succeed())
+		Expect(mention("<@BOT123> and with BFD?")).To(Succeed())
+	})
+
+	It("should ask each question in a new LLM thread when the memory is off", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(storedThread(true), true, nil).Times(2)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil).Times(2)
+		gomock.InOrder(
+			mockLLM.EXPECT().CreateThread(gomock.Any(), "metallb", "4.18").Return("fresh-slug-1", nil),
+			mockDB.EXPECT().UpdateThreadContext(threadTS, "fresh-slug-1", "metallb", "4.18").Return(nil),
+			mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "fresh-slug-1", "how do I configure BGP?").Return("Create a BGPPeer", nil),
+			mockLLM.EXPECT().CreateThread(gomock.Any(), "metallb", "4.18").Return("fresh-slug-2", nil),
+			mockDB.EXPECT().UpdateThreadContext(threadTS, "fresh-slug-2", "metallb", "4.18").Return(nil),
+			mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "fresh-slug-2", "and with BFD?").Return("Add a BFDProfile", nil),
+		)
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil).Times(2)
+
+		Expect(mention("<@BOT123> how do I configure BGP?")).To(Succeed())
+		Expect(mention("<@BOT123> and with BFD?")).To(Succeed())
+	})
+
+	It("should answer the answer command in a new LLM thread when the memory is off", func() {
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+			{Msg: slack.Msg{Timestamp: threadTS, Text: "how do I configure BGP?", User: "U999"}},
+			{Msg: slack.Msg{Timestamp: "1234567890.200000", Text: "<@BOT123> answer metallb 4.18", User: user}},
+			{Msg: slack.Msg{Timestamp: "1234567890.300000", Text: "Searching for answer...", User: "BOT123"}},
+		}, nil)
+		mockDB.EXPECT().GetSlugForThread(threadTS).Return("stored-slug", true, nil)
+		mockDB.EXPECT().SetLastQuestion(threadTS, "how do I configure BGP?").Return(nil)
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(storedThread(true), true, nil).AnyTimes()
+		mockLLM.EXPECT().CreateThread(gomock.Any(), "metallb", "4.18").Return("fresh-slug", nil)
+		mockDB.EXPECT().UpdateThreadContext(threadTS, "fresh-slug", "metallb", "4.18").Return(nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "fresh-slug", "how do I configure BGP?").Return("Create a BGPPeer", nil)
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+
+		Expect(mention("<@BOT123> answer metallb 4.18")).To(Succeed())
+	})
+
+	It("should retry the last question in a new LLM thread when the memory is off", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(storedThread(true), true, nil)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockLLM.EXPECT().CreateThread(gomock.Any(), "metallb", "4.18").Return("fresh-slug", nil)
+		mockDB.EXPECT().UpdateThreadContext(threadTS, "fresh-slug", "metallb", "4.18").Return(nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "fresh-slug", "How do I configure BGP?").Return("Create a BGPPeer", nil)
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+
+		Expect(mention("<@BOT123> retry")).To(Succeed())
+	})
+
+	It("should not answer when the new LLM thread can't be created", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(storedThread(true), true, nil)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockLLM.EXPECT().CreateThread(gomock.Any(), "metallb", "4.18").Return("", errors.New("workspace not found"))
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		Expect(mention("<@BOT123> how do I configure BGP?")).To(HaveOccurred())
+	})
+
+	DescribeTable("should store the memory of the thread",
+		func(value string, memoryOff bool, confirmation string) {
+			mockDB.EXPECT().GetThreadContext(threadTS).Return(storedThread(!memoryOff), true, nil)
+			mockDB.EXPECT().SetThreadMemoryOff(threadTS, memoryOff).Return(nil)
+			mockSlackBot.EXPECT().PostMessage(channel, threadTS, confirmation).Return(nil)
+
+			Expect(mention("<@BOT123> memory " + value)).To(Succeed())
+		},
+		Entry("off", "off", true, "🧠 The next questions in this thread are answered each on its own, without the previous ones"),
+		Entry("on", "on", false, "🧠 The next questions in this thread are answered in the context of the previous ones"),
+	)
+
+	It("should reject an unknown memory value", func() {
+		mockDB.EXPECT().SetThreadMemoryOff(gomock.Any(), gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, usage).Return(nil).Times(2)
+
+		Expect(mention("<@BOT123> memory maybe")).To(Succeed())
+		Expect(mention("<@BOT123> memory")).To(Succeed())
+	})
+
+	It("should ask to answer first in a thread without answer", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil)
+		mockDB.EXPECT().SetThreadMemoryOff(gomock.Any(), gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "There is no answer in this thread yet, please use answer first").Return(nil)
+
+		Expect(mention("<@BOT123> memory off")).To(Succeed())
+	})
+})
@@ -0,0 +1,93 @@
+package agent
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// BotIdentity holds every ID and name the bot can be mentioned with or post as
+type BotIdentity struct {
+	// UserIDs are the bot user ID and the enterprise grid user IDs mapped to it
+	UserIDs []string
+	BotID   string
+	// Names are the bot username and display name, matched case-insensitively
+	Names []string
+}
+
+// IsMention reports whether the token mentions the bot, e.g. <@U123>, <@W123|bot>, <@B123> or @bot
+func (b BotIdentity) IsMention(token string) bool {
+	if markup, ok := strings.CutPrefix(token, "<@"); ok {
+		target, label, hasLabel := strings.Cut(strings.TrimSuffix(markup, ">"), "|")
+		if b.isID(target) {
+			return true
+		}
+		return hasLabel && b.isName(label)
+	}
+	if name, ok := strings.CutPrefix(token, "@"); ok {
+		return b.isName(name)
+	}
+	return false
+}
+
+// IsOwnMessage reports whether the message was posted by a bot, or by a user mapped to this bot
+func (b BotIdentity) IsOwnMessage(msg slack.Message) bool {
+	return msg.BotID != "" || b.isID(msg.User)
+}
+
+// mentionIndex returns the index of the first parameter mentioning the bot, or -1 when none does
+func (b BotIdentity) mentionIndex(parameters []string) int {
+	return slices.IndexFunc(parameters, b.IsMention)
+}
+
+func (b BotIdentity) isID(id string) bool {
+	return id != "" && (id == b.BotID || slices.Contains(b.UserIDs, id))
+}
+
+func (b BotIdentity) isName(name string) bool {
+	name = strings.TrimPrefix(name, "@")
+	return name != "" && slices.ContainsFunc(b.Names, func(botName string) bool {
+		return strings.EqualFold(botName, name)
+	})
+}
+
+// specialMentions are the Slack broadcast mentions, they can be written anywhere in a mention of the bot
+var specialMentions = []string{"<!here", "<!channel", "<!everyone"}
+
+// stripSpecialMentions removes the @here, @channel and @everyone mentions, e.g. <!here> or <!channel|channel>,
+// so they aren't taken for the command or its arguments
+func stripSpecialMentions(parameters []string) []string {
+	return slices.DeleteFunc(parameters, func(parameter string) bool {
+		return slices.ContainsFunc(specialMentions, func(mention string) bool {
+			rest, ok := strings.CutPrefix(parameter, mention)
+			return ok && (strings.HasPrefix(rest, ">") || strings.HasPrefix(rest, "|"))
+		})
+	})
+}
+
+// AddBotUserIDs maps additional user IDs to the bot, e.g. the enterprise grid user IDs its mentions are delivered with
+func (a *Agent) AddBotUserIDs(userIDs ...string) {
+	a.botUserIDs = append(a.botUserIDs, userIDs...)
+}
+
+// botIdentity returns the IDs and names of the bot, only the configured user IDs are known when the bot user isn't
+func (a *Agent) botIdentity() BotIdentity {
+	identity := BotIdentity{UserIDs: slices.Clone(a.botUserIDs)}
+	botUser := a.slackBot.GetBotUser()
+	if botUser == nil {
+		return identity
+	}
+
+	identity.BotID = botUser.BotID
+	if botUser.User != "" {
+		identity.Names = append(identity.Names, botUser.User)
+	}
+	if botUser.UserID != "" {
+		identity.UserIDs = append(identity.UserIDs, botUser.UserID)
+		if displayName := a.resolveUserName(botUser.UserID); displayName != "" {
+			identity.Names = append(identity.Names, displayName)
+		}
+	}
+	return identity
+}
@@ -0,0 +1,124 @@
+package agent_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Bot mention", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.100000"
+		user     = "U123456"
+	)
+
+	bot := agent.BotIdentity{
+		UserIDs: []string{"UBOT123", "WBOT123"},
+		BotID:   "BBOT123",
+		Names:   []string{"slack-ai-assistant", "AI-Helper"},
+	}
+
+	DescribeTable("should recognize the bot mention",
+		func(token string, expected bool) {
+			Expect(bot.IsMention(token)).To(Equal(expected))
+		},
+		Entry("user ID", "<@UBOT123>", true),
+		Entry("user ID with a label", "<@UBOT123|slack-ai-assistant>", true),
+		Entry("enterprise grid user ID", "<@WBOT123>", true),
+		Entry("bot ID", "<@BBOT123>", true),
+		Entry("unknown ID labeled with the bot name", "<@UOTHER|slack-ai-assistant>", true),
+		Entry("username", "@slack-ai-assistant", true),
+		Entry("display name in another case", "@ai-helper", true),
+		Entry("another user", "<@U123456>", false),
+		Entry("another user with a label", "<@U123456|alice>", false),
+		Entry("another name", "@alice", false),
+		Entry("plain word", "slack-ai-assistant", false),
+	)
+
+	DescribeTable("should recognize the bot messages",
+		func(msg slack.Msg, expected bool) {
+			Expect(bot.IsOwnMessage(slack.Message{Msg: msg})).To(Equal(expected))
+		},
+		Entry("posted by the bot user", slack.Msg{User: "UBOT123"}, true),
+		Entry("posted by the enterprise grid user", slack.Msg{User: "WBOT123"}, true),
+		Entry("posted by a bot", slack.Msg{BotID: "BBOT123"}, true),
+		Entry("posted by a user", slack.Msg{User: user}, false),
+	)
+
+	Describe("command", func() {
+		var (
+			ctrl         *gomock.Controller
+			mockSlackBot *slackbotMock.MockInterface
+			mockLLM      *llmMock.MockInterface
+			testAgent    *agent.Agent
+		)
+
+		BeforeEach(func() {
+			ctrl = gomock.NewController(GinkgoT())
+			mockDB := databaseMock.NewMockInterface(ctrl)
+			mockSlackBot = slackbotMock.NewMockInterface(ctrl)
+			mockLLM = llmMock.NewMockInterface(ctrl)
+			testAgent = agent.NewAgent(mockDB, mockSlackBot, mockLLM, nil, nil, nil, nil, 1, 10)
+			testAgent.AddBotUserIDs("WBOT123")
+
+			mockSlackBot.EXPECT().GetBotUser().Return(&slack.AuthTestResponse{User: "slack-ai-assistant", UserID: "UBOT123", BotID: "BBOT123"}).AnyTimes()
+			mockSlackBot.EXPECT().GetUserName(gomock.Any()).DoAndReturn(func(userID string) (string, error) {
+				if userID == "UBOT123" {
+					return "AI-Helper", nil
+				}
+				return "alice", nil
+			}).AnyTimes()
+			mockDB.EXPECT().RecordCommand(gomock.Any()).Return(nil).AnyTimes()
+		})
+
+		AfterEach(func() {
+			ctrl.Finish()
+		})
+
+		DescribeTable("should find the command after the mention",
+			func(text string) {
+				mockSlackBot.EXPECT().PostEphemeral(channel, user, "To list the documents please provide the project name (example: sriov,metallb) and the openshift version (4.16,4.18, etc..)").Return(nil)
+
+				Expect(agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+					User:            user,
+					Text:            text,
+					Channel:         channel,
+					TimeStamp:       "1234567890.200000",
+					ThreadTimeStamp: threadTS,
+				}}.Process(context.Background(), testAgent)).To(Succeed())
+			},
+			Entry("user ID mention", "<@UBOT123> docs"),
+			Entry("labeled mention", "<@UBOT123|slack-ai-assistant> docs"),
+			Entry("enterprise grid mention", "<@WBOT123> docs"),
+			Entry("display name mention", "@AI-Helper docs"),
+			Entry("mention after other words", "hey <@WBOT123> docs"),
+			Entry("@here before the mention", "<!here> <@UBOT123> docs"),
+			Entry("@channel between the mention and the command", "<@UBOT123> <!channel> docs"),
+			Entry("labeled @everyone between the mention and the command", "<@UBOT123> <!everyone|everyone> docs"),
+			Entry("several special mentions", "<!here> <@UBOT123> <!channel> <!here> docs"),
+		)
+
+		It("should keep the special mentions out of the command arguments", func() {
+			mockLLM.EXPECT().ListDocuments(gomock.Any(), "metallb", "4.18").Return(nil, nil)
+			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "There are no documents for project metallb on version 4.18").Return(nil)
+
+			Expect(agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+				User:            user,
+				Text:            "<@UBOT123> docs <!here> metallb <!channel> 4.18",
+				Channel:         channel,
+				TimeStamp:       "1234567890.200000",
+				ThreadTimeStamp: threadTS,
+			}}.Process(context.Background(), testAgent)).To(Succeed())
+		})
+	})
+})
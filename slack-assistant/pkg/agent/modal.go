@@ -0,0 +1,93 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/slack-go/slack"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+	slackbot "github.com/SchSeba/slack-ai-assistant/pkg/slack-bot"
+)
+
+// workspaceVersion matches the version WorkspaceSlug appends to the project, e.g. -4-dot-16
+var workspaceVersion = regexp.MustCompile(`-\d+(-dot-\d+)*$`)
+
+// openCommandModal opens the modal of the answer or inject slash command, a form is easier to fill
+// than the positional arguments
+func (a *Agent) openCommandModal(command *slack.SlashCommand, name string) error {
+	modal, err := slackbot.BuildCommandModal(name, command.ChannelID, a.knownProjectNames())
+	if err != nil {
+		return err
+	}
+	if err := a.slackBot.OpenView(command.UserID, command.TriggerID, modal); err != nil {
+		return fmt.Errorf("failed to open the %s modal: %w", name, err)
+	}
+	return nil
+}
+
+// knownProjectNames returns the sorted projects of the allowlist without their versions
+func (a *Agent) knownProjectNames() []string {
+	var projects []string
+	for _, workspace := range a.knownProjectList() {
+		project := workspaceVersion.ReplaceAllString(workspace, "")
+		if !slices.Contains(projects, project) {
+			projects = append(projects, project)
+		}
+	}
+	return projects
+}
+
+// handleModalSubmission runs the answer or inject command submitted with its modal
+func (a *Agent) handleModalSubmission(ctx context.Context, callback *slack.InteractionCallback) error {
+	submission, err := slackbot.ParseCommandModal(callback.View)
+	if err != nil {
+		return fmt.Errorf("failed to parse modal submission: %w", err)
+	}
+	user := callback.User.ID
+	fmt.Printf("🪟 Modal %s %s %s submitted by user %s in channel %s\n", submission.Command, submission.Project, submission.Version, a.userLabel(user), submission.Channel)
+
+	if !a.isKnownProject(submission.Project, submission.Version) {
+		return a.unknownProject(submission.Channel, user, submission.Project, submission.Version)
+	}
+
+	if submission.Command == "inject" {
+		if a.isReadOnlyProject(submission.Project, submission.Version) {
+			return a.readOnlyProject(submission.Channel, user, submission.Project, submission.Version)
+		}
+		// The content comes from the modal, there is no thread to record or link as its source
+		return a.injectMessages(ctx, submission.Channel, "", submission.Project, submission.Version, "", []string{submission.Content}, false)
+	}
+	return a.answerSubmission(ctx, user, submission)
+}
+
+// answerSubmission posts the question of the answer modal to the channel and answers it in the thread of the question,
+// follow-ups and retry then work like in a thread answered with a mention
+func (a *Agent) answerSubmission(ctx context.Context, user string, submission slackbot.ModalSubmission) error {
+	channel := submission.Channel
+	question := i18n.T("modal.asked", user, submission.Project, submission.Version, submission.Content)
+	threadTS, err := a.slackBot.PostMessageTS(channel, "", question)
+	if err != nil {
+		return fmt.Errorf("failed to post the question: %w", err)
+	}
+
+	placeholder, err := a.postPlaceholder(channel, threadTS)
+	if err != nil {
+		return err
+	}
+
+	slug, err := a.getOrCreateSlug(ctx, threadTS, submission.Project, submission.Version)
+	if err != nil {
+		a.postUnavailable(channel, threadTS, err)
+		return err
+	}
+	// Remember the question so retry can ask it again
+	if err := a.db.SetLastQuestion(threadTS, submission.Content); err != nil {
+		fmt.Printf("⚠️ Failed to store the last question of thread %s: %v\n", threadTS, err)
+	}
+
+	ctx = a.withCommandTemperature(ctx, "answer")
+	return a.deletePlaceholder(channel, placeholder, a.generateAndPostResponse(ctx, channel, threadTS, submission.Project, submission.Version, slug, submission.Content, false))
+}
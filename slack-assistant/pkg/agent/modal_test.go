@@ -0,0 +1,151 @@
+package agent_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+	slackbot "github.com/SchSeba/slack-ai-assistant/pkg/slack-bot"
+)
+
+var _ = Describe("Command modals", func() {
+	const (
+		channel   = "C1234567890"
+		user      = "U123456"
+		triggerID = "trigger-123"
+		threadTS  = "1234567890.500000"
+	)
+
+	var (
+		ctrl         *gomock.Controller
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	slashCommand := func(text string) error {
+		return agent.SlashCommandWorkItem{Command: &slack.SlashCommand{
+			Command:   "/assistant",
+			Text:      text,
+			UserID:    user,
+			ChannelID: channel,
+			TriggerID: triggerID,
+		}}.Process(context.Background(), testAgent)
+	}
+
+	// submit submits the modal with the values typed by the user
+	submit := func(callbackID, project, version, content string) error {
+		return agent.InteractionWorkItem{Callback: &slack.InteractionCallback{
+			Type: slack.InteractionTypeViewSubmission,
+			User: slack.User{ID: user},
+			View: slack.View{
+				CallbackID:      callbackID,
+				PrivateMetadata: channel,
+				State: &slack.ViewState{Values: map[string]map[string]slack.BlockAction{
+					"project": {"input": {SelectedOption: slack.OptionBlockObject{Value: project}}},
+					"version": {"input": {Value: version}},
+					"content": {"input": {Value: content}},
+				}},
+			},
+		}}.Process(context.Background(), testAgent)
+	}
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockDB = databaseMock.NewMockInterface(ctrl)
+		mockSlackBot = slackbotMock.NewMockInterface(ctrl)
+		mockLLM = llmMock.NewMockInterface(ctrl)
+		testAgent = agent.NewAgent(mockDB, mockSlackBot, mockLLM, nil, nil, nil, nil, 1, 10)
+
+		mockDB.EXPECT().SetThreadTruncated(gomock.Any(), false).Return(nil).AnyTimes()
+		mockLLM.EXPECT().LastContext(gomock.Any()).Return(nil).AnyTimes()
+
+		mockSlackBot.EXPECT().GetUserName(gomock.Any()).Return("alice", nil).AnyTimes()
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	It("should open the answer modal with the known projects", func() {
+		mockLLM.EXPECT().ListProjects(gomock.Any()).Return([]string{"sriov-4-dot-16", "sriov-4-dot-18", "metallb-4-dot-18"}, nil)
+		_, err := testAgent.RefreshProjects(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+
+		mockSlackBot.EXPECT().OpenView(user, triggerID, gomock.Any()).DoAndReturn(func(_, _ string, modal slack.ModalViewRequest) error {
+			Expect(modal.CallbackID).To(Equal(slackbot.ModalAnswer))
+			Expect(modal.PrivateMetadata).To(Equal(channel))
+			dropdown := modal.Blocks.BlockSet[0].(*slack.InputBlock).Element.(*slack.SelectBlockElement)
+			Expect(dropdown.Options).To(HaveLen(2))
+			Expect(dropdown.Options[0].Value).To(Equal("metallb"))
+			Expect(dropdown.Options[1].Value).To(Equal("sriov"))
+			return nil
+		})
+
+		Expect(slashCommand("answer")).To(Succeed())
+	})
+
+	It("should open the inject modal", func() {
+		mockSlackBot.EXPECT().OpenView(user, triggerID, gomock.Any()).DoAndReturn(func(_, _ string, modal slack.ModalViewRequest) error {
+			Expect(modal.CallbackID).To(Equal(slackbot.ModalInject))
+			return nil
+		})
+
+		Expect(slashCommand("inject")).To(Succeed())
+	})
+
+	It("should answer the submitted question in a new thread of the channel", func() {
+		mockSlackBot.EXPECT().PostMessageTS(channel, "", "❓ <@U123456> asked about sriov 4.16:\nHow do I create a VF?").Return(threadTS, nil)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockDB.EXPECT().GetSlugForThread(threadTS).Return("", false, nil)
+		mockLLM.EXPECT().CreateThread(gomock.Any(), "sriov", "4.16").Return("new-slug", nil)
+		mockDB.EXPECT().CreateSlackThreadWithSlug(threadTS, "new-slug", "sriov", "4.16").Return(nil)
+		mockDB.EXPECT().SetLastQuestion(threadTS, "How do I create a VF?").Return(nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "new-slug", "How do I create a VF?").Return("Use the SriovNetworkNodePolicy", nil)
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+
+		Expect(submit(slackbot.ModalAnswer, "sriov", "4.16", "How do I create a VF?")).To(Succeed())
+	})
+
+	It("should inject the submitted content", func() {
+		mockDB.EXPECT().WithTransaction(gomock.Any()).DoAndReturn(func(fn func(database.Interface) error) error {
+			return fn(mockDB)
+		})
+		mockDB.EXPECT().RecordInjection(&database.InjectedDocument{Project: "metallb", Version: "4.18", Content: "BGP peers need a password"}).Return(nil)
+		mockLLM.EXPECT().Inject(gomock.Any(), "metallb", "4.18", "BGP peers need a password", gomock.Any()).Return(nil)
+		mockSlackBot.EXPECT().PostMessage(channel, "", gomock.Any()).Return(nil)
+
+		Expect(submit(slackbot.ModalInject, "metallb", "4.18", "BGP peers need a password")).To(Succeed())
+	})
+
+	It("should reject the content submitted to a read-only project", func() {
+		testAgent.SetReadOnlyProjects(agent.ProjectVersion{Project: "metallb", Version: "4.18"})
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "🔒 The metallb 4.18 knowledge base is curated and read-only, it can't be changed with inject, export or correct").Return(nil)
+		mockLLM.EXPECT().Inject(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		Expect(submit(slackbot.ModalInject, "metallb", "4.18", "BGP peers need a password")).To(Succeed())
+	})
+
+	It("should tell the user when the submitted project is unknown", func() {
+		mockLLM.EXPECT().ListProjects(gomock.Any()).Return([]string{"sriov-4-dot-16"}, nil)
+		_, err := testAgent.RefreshProjects(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, gomock.Any()).Return(nil)
+		mockLLM.EXPECT().Inject(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		Expect(submit(slackbot.ModalInject, "sriov", "4.18", "VFs need SR-IOV enabled in the BIOS")).To(Succeed())
+	})
+
+	It("should fail an incomplete submission", func() {
+		Expect(submit(slackbot.ModalAnswer, "sriov", "", "How do I create a VF?")).To(MatchError(ContainSubstring("the version of the answer modal is missing")))
+	})
+})
@@ -0,0 +1,48 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+)
+
+// modeDescriptions are the message keys explaining the answer modes the mode command accepts
+var modeDescriptions = map[llm.ChatMode]string{
+	llm.ModeQuery: "mode.query",
+	llm.ModeChat:  "mode.chat",
+}
+
+// Mode sets whether the next answers of the thread only use the project documents (query)
+// or are more free-form (chat), the mode is stored with the thread context
+func (a *Agent) Mode(channel, threadTS, user, mode string) error {
+	chatMode := llm.ChatMode(mode)
+	description, ok := modeDescriptions[chatMode]
+	if !ok {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("usage.mode"))
+	}
+	if a.getThreadContext(threadTS) == nil {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("thread.noAnswer"))
+	}
+
+	if err := a.db.SetThreadMode(threadTS, mode); err != nil {
+		fmt.Printf("❌ Failed to store the mode of thread %s: %v\n", threadTS, err)
+		return fmt.Errorf("failed to set thread mode: %w", err)
+	}
+
+	if err := a.slackBot.PostMessage(channel, threadTS, i18n.T("mode.done", mode, i18n.T(description))); err != nil {
+		return fmt.Errorf("failed to send response: %w", err)
+	}
+	return nil
+}
+
+// withThreadMode returns the context answering in the mode chosen for the thread,
+// the context is returned as is when the thread has no mode
+func withThreadMode(ctx context.Context, threadContext *database.SlackThreadToSlug) context.Context {
+	if threadContext == nil || threadContext.Mode == "" {
+		return ctx
+	}
+	return llm.WithChatMode(ctx, llm.ChatMode(threadContext.Mode))
+}
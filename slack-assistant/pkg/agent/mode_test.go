@@ -0,0 +1,115 @@
+package agent_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Mode", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.100000"
+		user     = "U123456"
+	)
+
+	var (
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	mention := func(text string) error {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            text,
+			Channel:         channel,
+			TimeStamp:       "1234567890.200000",
+			ThreadTimeStamp: threadTS,
+		}}.Process(context.Background(), testAgent)
+	}
+
+	// storedThread is the thread context of a thread answered for metallb
+	storedThread := func(mode string) *database.SlackThreadToSlug {
+		return &database.SlackThreadToSlug{
+			SlackThread: threadTS,
+			ThreadSlug:  "stored-slug",
+			Project:     "metallb",
+			Version:     "4.18",
+			Mode:        mode,
+		}
+	}
+
+	BeforeEach(func() {
+		testAgent, mockDB, mockSlackBot, mockLLM = newTestAgent()
+	})
+
+	It("should store the mode of the thread", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(storedThread(""), true, nil)
+		mockDB.EXPECT().SetThreadMode(threadTS, "chat").Return(nil)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "🎛️ The next answers in this thread use the chat mode, the model may also answer from its own knowledge").Return(nil)
+
+		Expect(mention("<@BOT123> mode chat")).To(Succeed())
+	})
+
+	It("should answer the next question in the stored mode", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(storedThread("chat"), true, nil)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "stored-slug", "how do I configure BGP?").DoAndReturn(
+			func(ctx context.Context, _, _, _, _ string) (string, error) {
+				Expect(llm.ChatModeFrom(ctx)).To(Equal(llm.ModeChat))
+				return "Create a BGPPeer", nil
+			})
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+
+		Expect(mention("<@BOT123> how do I configure BGP?")).To(Succeed())
+	})
+
+	It("should answer with the stored mode after answer", func() {
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+			{Msg: slack.Msg{Text: "How do I configure BGP?", User: user}},
+			{Msg: slack.Msg{Text: "<@BOT123> answer metallb 4.18", User: user}},
+			{Msg: slack.Msg{Text: "Searching for answer...", User: "BOT123"}},
+		}, nil)
+		mockDB.EXPECT().GetSlugForThread(threadTS).Return("stored-slug", true, nil)
+		mockDB.EXPECT().SetLastQuestion(threadTS, "How do I configure BGP?").Return(nil)
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(storedThread("query"), true, nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "stored-slug", "How do I configure BGP?").DoAndReturn(
+			func(ctx context.Context, _, _, _, _ string) (string, error) {
+				Expect(llm.ChatModeFrom(ctx)).To(Equal(llm.ModeQuery))
+				return "Create a BGPPeer", nil
+			})
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+
+		Expect(mention("<@BOT123> answer metallb 4.18")).To(Succeed())
+	})
+
+	It("should reject an unknown mode", func() {
+		mockDB.EXPECT().SetThreadMode(gomock.Any(), gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "To change the answer mode please use `mode query` for answers grounded in the documents or `mode chat` for more free-form answers").Return(nil).Times(2)
+
+		Expect(mention("<@BOT123> mode creative")).To(Succeed())
+		Expect(mention("<@BOT123> mode")).To(Succeed())
+	})
+
+	It("should ask for an answer first in a thread without context", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil)
+		mockDB.EXPECT().SetThreadMode(gomock.Any(), gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "There is no answer in this thread yet, please use answer first").Return(nil)
+
+		Expect(mention("<@BOT123> mode query")).To(Succeed())
+	})
+})
@@ -0,0 +1,118 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+)
+
+// multiAnswerConcurrency bounds how many workspaces answer-multi queries at the same time
+const multiAnswerConcurrency = 4
+
+// ProjectVersion is the project and version of a workspace
+type ProjectVersion struct {
+	Project string
+	Version string
+}
+
+// parseProjectVersions parses the project/version pairs of answer-multi, ok is false when a pair is malformed
+// or fewer than two pairs are given
+func parseProjectVersions(parameters []string) ([]ProjectVersion, bool) {
+	var projects []ProjectVersion
+	for _, parameter := range parameters {
+		if parameter == "" {
+			continue
+		}
+		project, version, found := strings.Cut(parameter, "/")
+		if !found || project == "" || version == "" {
+			return nil, false
+		}
+		projects = append(projects, ProjectVersion{Project: project, Version: version})
+	}
+	return projects, len(projects) > 1
+}
+
+// AnswerMulti answers the last message from several project and version workspaces at once and merges the answers
+// in one reply with a section per project, a failing workspace only fails its own section
+func (a *Agent) AnswerMulti(ctx context.Context, channel, threadTS string, projects []ProjectVersion) error {
+	placeholder, err := a.postPlaceholder(channel, threadTS)
+	if err != nil {
+		return err
+	}
+
+	message, images, err := a.getMessages(ctx, channel, threadTS, false)
+	if err != nil {
+		return err
+	}
+	if len(images) > 0 {
+		ctx = llm.WithImages(ctx, images)
+	}
+
+	// Every workspace gets its own LLM thread, the Slack thread only maps to the thread of answer
+	answers := make([]string, len(projects))
+	sources := make([][]string, len(projects))
+	errs := make([]error, len(projects))
+	var group errgroup.Group
+	group.SetLimit(multiAnswerConcurrency)
+	for i, project := range projects {
+		group.Go(func() error {
+			answers[i], sources[i], errs[i] = a.answerProject(ctx, project, message)
+			return nil
+		})
+	}
+	// The failures are kept per project so the other answers are still posted
+	_ = group.Wait()
+
+	sections := make([]string, len(projects))
+	var answerSources []string
+	var failures []error
+	for i, project := range projects {
+		answer := answers[i]
+		if errs[i] != nil {
+			logFailure(fmt.Sprintf("answer from %s %s", project.Project, project.Version), errs[i])
+			answer = a.branding.errorMessage(errs[i])
+			failures = append(failures, fmt.Errorf("%s %s: %w", project.Project, project.Version, errs[i]))
+		}
+		sections[i] = fmt.Sprintf("*%s %s*\n%s", project.Project, project.Version, answer)
+		for _, source := range sources[i] {
+			if !slices.Contains(answerSources, source) {
+				answerSources = append(answerSources, source)
+			}
+		}
+	}
+
+	if err := a.deletePlaceholder(channel, placeholder, a.postAnswerWithRetry(ctx, channel, threadTS, a.branding.AnswerIntro, strings.Join(sections, "\n\n"), answerSources, false)); err != nil {
+		return err
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to answer from %d of %d projects: %w", len(failures), len(projects), errors.Join(failures...))
+	}
+	return nil
+}
+
+// answerProject asks the message in a new thread of the project and version workspace and returns the answer with
+// its sources
+func (a *Agent) answerProject(ctx context.Context, project ProjectVersion, message string) (string, []string, error) {
+	slug, err := a.llmClient.CreateThread(ctx, project.Project, project.Version)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create thread: %w", err)
+	}
+
+	// The thread isn't stored, an expired one is only replaced for this answer
+	response, err := chatRecovering(slug, func(answeredSlug string) (string, error) {
+		slug = answeredSlug
+		return a.llmClient.SendMessageToChat(ctx, project.Project, project.Version, slug, a.withProjectPrompt(project.Project, message))
+	}, func(string) (string, error) {
+		return a.llmClient.CreateThread(ctx, project.Project, project.Version)
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate response: %w", err)
+	}
+	return response, a.answerSources(slug), nil
+}
@@ -0,0 +1,97 @@
+package agent_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+	slackbot "github.com/SchSeba/slack-ai-assistant/pkg/slack-bot"
+)
+
+var _ = Describe("Answer multi", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.100000"
+		user     = "U123456"
+	)
+
+	var (
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	answerMulti := func(text string) error {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            text,
+			Channel:         channel,
+			TimeStamp:       "1234567890.200000",
+			ThreadTimeStamp: threadTS,
+		}}.Process(context.Background(), testAgent)
+	}
+
+	BeforeEach(func() {
+		testAgent, _, mockSlackBot, mockLLM = newTestAgent()
+	})
+
+	It("should merge the answers of every project in one reply with a section per project", func() {
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+			{Msg: slack.Msg{Text: "How do I expose a VF to a pod?", User: user}},
+			{Msg: slack.Msg{Text: "<@BOT123> answer-multi sriov/4.16 metallb/4.18", User: user}},
+			{Msg: slack.Msg{Text: "Searching for answer...", User: "BOT123"}},
+		}, nil)
+		mockLLM.EXPECT().CreateThread(gomock.Any(), "sriov", "4.16").Return("sriov-thread", nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "sriov-thread", "How do I expose a VF to a pod?").Return("Use a SriovNetwork", nil)
+		mockLLM.EXPECT().CreateThread(gomock.Any(), "metallb", "4.18").Return("metallb-thread", nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "metallb-thread", "How do I expose a VF to a pod?").Return("MetalLB doesn't manage VFs", nil)
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).DoAndReturn(func(_, _ string, blocks []slack.Block, _ ...slackbot.MessageOption) error {
+			Expect(slackbot.AnswerText(slack.Blocks{BlockSet: blocks})).To(Equal("Here is the information I was able to find\n" +
+				"*sriov 4.16*\nUse a SriovNetwork\n\n" +
+				"*metallb 4.18*\nMetalLB doesn't manage VFs"))
+			return nil
+		})
+
+		Expect(answerMulti("<@BOT123> answer-multi sriov/4.16 metallb/4.18")).To(Succeed())
+	})
+
+	It("should still post the other answers when a project fails", func() {
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+			{Msg: slack.Msg{Text: "How do I configure BGP?", User: user}},
+			{Msg: slack.Msg{Text: "<@BOT123> answer-multi sriov/4.16 metallb/4.18", User: user}},
+			{Msg: slack.Msg{Text: "Searching for answer...", User: "BOT123"}},
+		}, nil)
+		mockLLM.EXPECT().CreateThread(gomock.Any(), "sriov", "4.16").Return("", llm.ErrCircuitOpen)
+		mockLLM.EXPECT().CreateThread(gomock.Any(), "metallb", "4.18").Return("metallb-thread", nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "metallb-thread", "How do I configure BGP?").Return("Create a BGPPeer", nil)
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).DoAndReturn(func(_, _ string, blocks []slack.Block, _ ...slackbot.MessageOption) error {
+			Expect(slackbot.AnswerText(slack.Blocks{BlockSet: blocks})).To(Equal("Here is the information I was able to find\n" +
+				"*sriov 4.16*\n⏳ The assistant is temporarily unavailable, please try again in a few minutes\n\n" +
+				"*metallb 4.18*\nCreate a BGPPeer"))
+			return nil
+		})
+
+		err := answerMulti("<@BOT123> answer-multi sriov/4.16 metallb/4.18")
+		Expect(err).To(MatchError(ContainSubstring("failed to answer from 1 of 2 projects")))
+		Expect(err).To(MatchError(llm.ErrCircuitOpen))
+	})
+
+	It("should reject a single or malformed project", func() {
+		message := "To answer from several projects please provide at least two project/version pairs (example: sriov/4.16 metallb/4.18)"
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, message).Return(nil).Times(2)
+		mockLLM.EXPECT().CreateThread(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		Expect(answerMulti("<@BOT123> answer-multi sriov/4.16")).To(Succeed())
+		Expect(answerMulti("<@BOT123> answer-multi sriov/4.16 metallb")).To(Succeed())
+	})
+})
@@ -0,0 +1,87 @@
+package agent
+
+import (
+	"fmt"
+
+	"github.com/slack-go/slack"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+	slackbot "github.com/SchSeba/slack-ai-assistant/pkg/slack-bot"
+)
+
+// Pin pins the most recent answer of the bot in the thread, e.g. once it resolved the issue, so it can be found from the channel details
+func (a *Agent) Pin(channel, threadTS, user string) error {
+	answerTS, err := a.lastAnswerTimestamp(channel, threadTS)
+	if err != nil || answerTS == "" {
+		return a.noAnswerToPin(channel, user, err)
+	}
+
+	if err := a.slackBot.PinMessage(channel, answerTS); err != nil {
+		a.postFailure(channel, threadTS, "pin the answer", err)
+		return err
+	}
+	if err := a.slackBot.PostMessage(channel, threadTS, i18n.T("pin.done")); err != nil {
+		return fmt.Errorf("failed to send pin confirmation: %w", err)
+	}
+	return nil
+}
+
+// Unpin unpins the most recent answer of the bot in the thread
+func (a *Agent) Unpin(channel, threadTS, user string) error {
+	answerTS, err := a.lastAnswerTimestamp(channel, threadTS)
+	if err != nil || answerTS == "" {
+		return a.noAnswerToPin(channel, user, err)
+	}
+
+	if err := a.slackBot.UnpinMessage(channel, answerTS); err != nil {
+		a.postFailure(channel, threadTS, "unpin the answer", err)
+		return err
+	}
+	if err := a.slackBot.PostMessage(channel, threadTS, i18n.T("unpin.done")); err != nil {
+		return fmt.Errorf("failed to send unpin confirmation: %w", err)
+	}
+	return nil
+}
+
+// noAnswerToPin tells the user the thread has no answer to pin, the error of the lookup is returned as is
+func (a *Agent) noAnswerToPin(channel, user string, err error) error {
+	if err != nil {
+		return err
+	}
+	return a.slackBot.PostEphemeral(channel, user, i18n.T("thread.noAnswer"))
+}
+
+// lastAnswerTimestamp returns the timestamp of the most recent answer of the bot in the thread,
+// or an empty timestamp when the bot didn't answer in the thread
+func (a *Agent) lastAnswerTimestamp(channel, threadTS string) (string, error) {
+	answer, err := a.lastAnswer(channel, threadTS)
+	if err != nil || answer == nil {
+		return "", err
+	}
+	return answer.Timestamp, nil
+}
+
+// lastAnswer returns the most recent answer of the bot in the thread, found by its answer buttons,
+// or nil when the bot didn't answer in the thread
+func (a *Agent) lastAnswer(channel, threadTS string) (*slack.Message, error) {
+	replies, err := a.slackBot.GetConversationReplies(&slack.GetConversationRepliesParameters{
+		ChannelID: channel,
+		Timestamp: threadTS,
+		Inclusive: true, // Include the parent message
+	})
+	if err != nil {
+		fmt.Printf("❌ Failed to retrieve thread messages: %v\n", err)
+		return nil, fmt.Errorf("failed to get thread messages: %w", err)
+	}
+	if len(replies) == 0 {
+		return nil, a.emptyThread(channel, threadTS)
+	}
+
+	bot := a.botIdentity()
+	for i := len(replies) - 1; i >= 0; i-- {
+		if bot.IsOwnMessage(replies[i]) && slackbot.IsAnswer(replies[i].Blocks) {
+			return &replies[i], nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,93 @@
+package agent_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+	slackbot "github.com/SchSeba/slack-ai-assistant/pkg/slack-bot"
+)
+
+var _ = Describe("Pin", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.100000"
+		user     = "U123456"
+	)
+
+	var (
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	mention := func(command string) error {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            "<@BOT123> " + command,
+			Channel:         channel,
+			TimeStamp:       "1234567890.900000",
+			ThreadTimeStamp: threadTS,
+		}}.Process(context.Background(), testAgent)
+	}
+
+	// answer returns an answer of the bot posted at the timestamp
+	answer := func(timestamp, text string) slack.Message {
+		return slack.Message{Msg: slack.Msg{
+			Timestamp: timestamp,
+			BotID:     "B123",
+			Text:      text,
+			Blocks:    slack.Blocks{BlockSet: slackbot.BuildAnswerBlocks("Here is the information I was able to find", text, nil, "")},
+		}}
+	}
+
+	BeforeEach(func() {
+		testAgent, mockDB, mockSlackBot, _ = newTestAgent()
+
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil).AnyTimes()
+	})
+
+	It("should pin the most recent answer of the bot in the thread", func() {
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+			{Msg: slack.Msg{Timestamp: threadTS, Text: "How do I create a VF?", User: "U123"}},
+			answer("1234567890.200000", "Use a SriovNetworkNodePolicy"),
+			{Msg: slack.Msg{Timestamp: "1234567890.300000", Text: "<@BOT123> answer-all sriov 4.16", User: "U123"}},
+			answer("1234567890.400000", "Set numVfs in the SriovNetworkNodePolicy"),
+			{Msg: slack.Msg{Timestamp: "1234567890.500000", Text: "🔀 Switched this thread to project sriov on version 4.18", BotID: "B123"}},
+			{Msg: slack.Msg{Timestamp: "1234567890.900000", Text: "<@BOT123> pin", User: user}},
+		}, nil)
+		mockSlackBot.EXPECT().PinMessage(channel, "1234567890.400000").Return(nil)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "📌 Pinned the last answer of this thread to the channel").Return(nil)
+
+		Expect(mention("pin")).To(Succeed())
+	})
+
+	It("should unpin the most recent answer of the bot in the thread", func() {
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+			{Msg: slack.Msg{Timestamp: threadTS, Text: "How do I create a VF?", User: "U123"}},
+			answer("1234567890.200000", "Use a SriovNetworkNodePolicy"),
+		}, nil)
+		mockSlackBot.EXPECT().UnpinMessage(channel, "1234567890.200000").Return(nil)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "📌 Unpinned the last answer of this thread from the channel").Return(nil)
+
+		Expect(mention("unpin")).To(Succeed())
+	})
+
+	It("should tell the user when the bot didn't answer in the thread yet", func() {
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+			{Msg: slack.Msg{Timestamp: threadTS, Text: "How do I create a VF?", User: "U123"}},
+			{Msg: slack.Msg{Timestamp: "1234567890.200000", Text: "Searching for answer...", BotID: "B123"}},
+		}, nil)
+		mockSlackBot.EXPECT().PinMessage(gomock.Any(), gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "There is no answer in this thread yet, please use answer first").Return(nil)
+
+		Expect(mention("pin")).To(Succeed())
+	})
+})
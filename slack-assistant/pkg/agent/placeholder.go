@@ -0,0 +1,36 @@
+package agent
+
+import "fmt"
+
+// EnablePlaceholderCleanup deletes the searching message once the answer is posted, so the thread only keeps the answer
+func (a *Agent) EnablePlaceholderCleanup() {
+	a.deletePlaceholders = true
+}
+
+// postPlaceholder posts the searching message, its timestamp is only returned when it is deleted once answered
+func (a *Agent) postPlaceholder(channel, threadTS string) (string, error) {
+	if !a.deletePlaceholders {
+		if err := a.slackBot.PostMessage(channel, threadTS, a.branding.SearchingMessage); err != nil {
+			return "", fmt.Errorf("failed to post initial message: %w", err)
+		}
+		return "", nil
+	}
+
+	timestamp, err := a.slackBot.PostMessageTS(channel, threadTS, a.branding.SearchingMessage)
+	if err != nil {
+		return "", fmt.Errorf("failed to post initial message: %w", err)
+	}
+	return timestamp, nil
+}
+
+// deletePlaceholder deletes the searching message once the answer was posted and returns the error of the answer,
+// the message is kept when the answer failed and failing to delete it doesn't fail the answer
+func (a *Agent) deletePlaceholder(channel, timestamp string, answerErr error) error {
+	if timestamp == "" || answerErr != nil {
+		return answerErr
+	}
+	if err := a.slackBot.DeleteMessage(channel, timestamp); err != nil {
+		fmt.Printf("⚠️ Failed to delete the searching message %s: %v\n", timestamp, err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package agent_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Placeholder cleanup", func() {
+	const (
+		channel       = "C1234567890"
+		threadTS      = "1234567890.100000"
+		placeholderTS = "1234567890.300000"
+	)
+
+	var (
+		ctrl          *gomock.Controller
+		mockSlackBot  *slackbotMock.MockInterface
+		mockLLM       *llmMock.MockInterface
+		testAgent     *agent.Agent
+		threadContext *database.SlackThreadToSlug
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockDB := databaseMock.NewMockInterface(ctrl)
+		mockSlackBot = slackbotMock.NewMockInterface(ctrl)
+		mockLLM = llmMock.NewMockInterface(ctrl)
+		testAgent = agent.NewAgent(mockDB, mockSlackBot, mockLLM, nil, nil, nil, nil, 1, 10)
+		testAgent.EnablePlaceholderCleanup()
+		threadContext = &database.SlackThreadToSlug{SlackThread: threadTS, ThreadSlug: "stored-slug", Project: "sriov", Version: "4.16"}
+
+		mockDB.EXPECT().SetThreadTruncated(gomock.Any(), false).Return(nil).AnyTimes()
+		mockLLM.EXPECT().LastContext(gomock.Any()).Return(nil).AnyTimes()
+
+		mockSlackBot.EXPECT().PostMessageTS(channel, threadTS, "Searching for answer...").Return(placeholderTS, nil)
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	It("should delete the searching message once the answer is posted", func() {
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "stored-slug", "How do I create a VF?").Return("Use the SriovNetworkNodePolicy", nil)
+		gomock.InOrder(
+			mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil),
+			mockSlackBot.EXPECT().DeleteMessage(channel, placeholderTS).Return(nil),
+		)
+
+		Expect(testAgent.FollowUp(context.Background(), channel, threadTS, threadContext, "How do I create a VF?")).To(Succeed())
+	})
+
+	It("should keep the searching message when the answer failed", func() {
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "stored-slug", gomock.Any()).Return("", errors.New("LLM down"))
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, gomock.Any()).Return(nil)
+		mockSlackBot.EXPECT().DeleteMessage(gomock.Any(), gomock.Any()).Times(0)
+
+		Expect(testAgent.FollowUp(context.Background(), channel, threadTS, threadContext, "How do I create a VF?")).NotTo(Succeed())
+	})
+
+	It("should not fail the answer when the searching message can't be deleted", func() {
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "stored-slug", gomock.Any()).Return("Use the SriovNetworkNodePolicy", nil)
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+		mockSlackBot.EXPECT().DeleteMessage(channel, placeholderTS).Return(errors.New("cant_delete_message"))
+
+		Expect(testAgent.FollowUp(context.Background(), channel, threadTS, threadContext, "How do I create a VF?")).To(Succeed())
+	})
+})
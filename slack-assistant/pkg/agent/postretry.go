@@ -0,0 +1,58 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	slackbot "github.com/SchSeba/slack-ai-assistant/pkg/slack-bot"
+)
+
+const (
+	// DefaultPostAttempts is how many times an answer is posted before giving up
+	DefaultPostAttempts = 3
+	// DefaultPostRetryBackoff is the wait before posting an answer again, doubled on every attempt
+	DefaultPostRetryBackoff = time.Second
+)
+
+// SetPostRetry sets how many times an answer is posted and the backoff between the attempts, so a rate limit or a
+// transient Slack failure doesn't lose an answer the LLM already generated
+func (a *Agent) SetPostRetry(attempts int, backoff time.Duration) {
+	a.postAttempts = max(attempts, 1)
+	a.postRetryBackoff = backoff
+}
+
+// postAnswerWithRetry posts the answer again with an exponential backoff while it fails, a deleted thread isn't
+// retried. The answer is logged in full when it can't be posted so it can still be recovered.
+func (a *Agent) postAnswerWithRetry(ctx context.Context, channel, threadTS, intro, answer string, sources []string, broadcast bool) error {
+	attempts := max(a.postAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		err := a.postAnswer(channel, threadTS, intro, answer, sources, broadcast)
+		if err == nil {
+			return nil
+		}
+		if attempt == attempts || errors.Is(err, slackbot.ErrThreadGone) {
+			logLostAnswer(channel, threadTS, answer)
+			return err
+		}
+
+		wait := a.postRetryBackoff << (attempt - 1)
+		fmt.Printf("⏳ Posting the answer of thread %s failed (attempt %d/%d): %v, retrying in %s\n",
+			threadTS, attempt, attempts, err, wait)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			logLostAnswer(channel, threadTS, answer)
+			return fmt.Errorf("%w: %w", err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// logLostAnswer logs the full answer that couldn't be posted so it can be recovered from the logs
+func logLostAnswer(channel, threadTS, answer string) {
+	fmt.Printf("📝 Answer of thread %s in channel %s that couldn't be posted:\n%s\n", threadTS, channel, answer)
+}
@@ -0,0 +1,95 @@
+package agent_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+	slackbot "github.com/SchSeba/slack-ai-assistant/pkg/slack-bot"
+)
+
+var _ = Describe("Answer post retries", func() {
+	const (
+		channel  = "C1234567890"
+		user     = "U123456"
+		threadTS = "1234567890.100000"
+		answer   = "Use the SriovNetworkNodePolicy"
+	)
+
+	var (
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+		rateLimited  = errors.New("slack rate limit exceeded, retry after 1s")
+	)
+
+	followUp := func(ctx context.Context) error {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            "<@BOT123> how do I create a VF?",
+			Channel:         channel,
+			TimeStamp:       "1234567890.300000",
+			ThreadTimeStamp: threadTS,
+		}}.Process(ctx, testAgent)
+	}
+
+	// failPost makes the next answer post fail, both as blocks and as the plain text fallback
+	failPost := func() *gomock.Call {
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(rateLimited)
+		return mockSlackBot.EXPECT().PostMessage(channel, threadTS, gomock.Any(), gomock.Any()).Return(rateLimited)
+	}
+
+	BeforeEach(func() {
+		testAgent, mockDB, mockSlackBot, mockLLM = newTestAgent()
+		testAgent.SetPostRetry(3, time.Millisecond)
+
+		threadContext := &database.SlackThreadToSlug{SlackThread: threadTS, ThreadSlug: "slug", Project: "sriov", Version: "4.16"}
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(threadContext, true, nil).AnyTimes()
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "slug", "how do I create a VF?").Return(answer, nil)
+	})
+
+	It("should post the answer again when the first post fails", func() {
+		gomock.InOrder(
+			failPost(),
+			mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil),
+		)
+
+		Expect(followUp(context.Background())).To(Succeed())
+	})
+
+	It("should return the error once every attempt failed", func() {
+		gomock.InOrder(failPost(), failPost(), failPost())
+
+		Expect(followUp(context.Background())).To(MatchError(ContainSubstring("rate limit")))
+	})
+
+	It("should stop waiting for the next attempt when the context is canceled", func() {
+		testAgent.SetPostRetry(3, time.Minute)
+		ctx, cancel := context.WithCancel(context.Background())
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(rateLimited)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_, _, _ string, _ ...slackbot.MessageOption) error {
+				cancel()
+				return rateLimited
+			})
+
+		done := make(chan error)
+		go func() {
+			defer GinkgoRecover()
+			done <- followUp(ctx)
+		}()
+		Eventually(done).Should(Receive(MatchError(context.Canceled)))
+	})
+})
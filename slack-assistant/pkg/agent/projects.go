@@ -0,0 +1,85 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+)
+
+// RefreshProjects reloads the allowlist of project/version workspaces from the LLM backend
+func (a *Agent) RefreshProjects(ctx context.Context) (int, error) {
+	projects, err := a.llmClient.ListProjects(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list projects: %w", err)
+	}
+
+	knownProjects := make(map[string]struct{}, len(projects))
+	for _, project := range projects {
+		knownProjects[project] = struct{}{}
+	}
+
+	a.projectsMu.Lock()
+	defer a.projectsMu.Unlock()
+	a.knownProjects = knownProjects
+	return len(knownProjects), nil
+}
+
+// isKnownProject reports whether the project and version have a workspace,
+// every project is accepted until the allowlist was loaded at least once
+func (a *Agent) isKnownProject(project, version string) bool {
+	a.projectsMu.RLock()
+	defer a.projectsMu.RUnlock()
+	if a.knownProjects == nil {
+		return true
+	}
+
+	_, ok := a.knownProjects[llm.WorkspaceSlug(project, version)]
+	return ok
+}
+
+// knownProjectList returns the sorted workspace slugs of the allowlist
+func (a *Agent) knownProjectList() []string {
+	a.projectsMu.RLock()
+	defer a.projectsMu.RUnlock()
+
+	projects := make([]string, 0, len(a.knownProjects))
+	for project := range a.knownProjects {
+		projects = append(projects, project)
+	}
+	sort.Strings(projects)
+	return projects
+}
+
+// unknownProject tells the user that the project and version are not in the allowlist
+func (a *Agent) unknownProject(channel, user, project, version string) error {
+	return a.slackBot.PostEphemeral(channel, user, a.unknownProjectMessage(project, version))
+}
+
+// unknownProjectMessage lists the available projects to the user of an unknown project and version
+func (a *Agent) unknownProjectMessage(project, version string) string {
+	return i18n.T("projects.unknown", project, version, strings.Join(a.knownProjectList(), ", "))
+}
+
+// Refresh reloads the project allowlist and reports the result to the admin
+func (a *Agent) Refresh(ctx context.Context, channel, user string) error {
+	if !a.isAdmin(user) {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("projects.refreshNotAdmin"))
+	}
+
+	count, err := a.RefreshProjects(ctx)
+	if err != nil {
+		if postErr := a.slackBot.PostEphemeral(channel, user, withEmoji(a.branding.ErrorEmoji, i18n.T("projects.refreshFailed"))); postErr != nil {
+			fmt.Printf("❌ Failed to post refresh failure: %v\n", postErr)
+		}
+		return err
+	}
+
+	if err := a.slackBot.PostEphemeral(channel, user, i18n.T("projects.refreshed", count)); err != nil {
+		return fmt.Errorf("failed to send refresh result: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package agent_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Projects", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.100000"
+		user     = "U123456"
+	)
+
+	var (
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	newMention := func(text string) agent.AppMentionWorkItem {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            text,
+			Channel:         channel,
+			TimeStamp:       "1234567890.200000",
+			ThreadTimeStamp: threadTS,
+		}}
+	}
+
+	BeforeEach(func() {
+		testAgent, mockDB, mockSlackBot, mockLLM = newTestAgent()
+		testAgent.AddAdmins(user)
+	})
+
+	It("should accept every project until the allowlist is loaded", func() {
+		// Failing the initial message is enough to prove the answer was attempted
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(errors.New("slack error"))
+
+		Expect(newMention("<@BOT123> answer sriov 4.16").Process(context.Background(), testAgent)).To(MatchError(ContainSubstring("failed to post initial message")))
+	})
+
+	It("should reject projects that are not in the allowlist", func() {
+		mockLLM.EXPECT().ListProjects(gomock.Any()).Return([]string{"sriov-4-dot-16", "metallb-4-dot-18"}, nil)
+		count, err := testAgent.RefreshProjects(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(2))
+
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "Unknown project sriov on version 4.20, available projects: metallb-4-dot-18, sriov-4-dot-16").Return(nil)
+
+		Expect(newMention("<@BOT123> inject sriov 4.20").Process(context.Background(), testAgent)).To(Succeed())
+	})
+
+	It("should recognize a newly added workspace after a refresh without restart", func() {
+		gomock.InOrder(
+			mockLLM.EXPECT().ListProjects(gomock.Any()).Return([]string{"sriov-4-dot-16"}, nil),
+			mockLLM.EXPECT().ListProjects(gomock.Any()).Return([]string{"sriov-4-dot-16", "sriov-4-dot-20"}, nil),
+		)
+		_, err := testAgent.RefreshProjects(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "Unknown project sriov on version 4.20, available projects: sriov-4-dot-16").Return(nil)
+		Expect(newMention("<@BOT123> answer sriov 4.20").Process(context.Background(), testAgent)).To(Succeed())
+
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "🔄 Refreshed the project list, 2 projects available").Return(nil)
+		Expect(newMention("<@BOT123> refresh").Process(context.Background(), testAgent)).To(Succeed())
+
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(errors.New("slack error"))
+		Expect(newMention("<@BOT123> answer sriov 4.20").Process(context.Background(), testAgent)).To(MatchError(ContainSubstring("failed to post initial message")))
+	})
+
+	It("should keep the previous allowlist when the refresh fails", func() {
+		gomock.InOrder(
+			mockLLM.EXPECT().ListProjects(gomock.Any()).Return([]string{"sriov-4-dot-16"}, nil),
+			mockLLM.EXPECT().ListProjects(gomock.Any()).Return(nil, errors.New("connection refused")),
+		)
+		_, err := testAgent.RefreshProjects(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "❌ Failed to refresh the project list").Return(nil)
+		Expect(newMention("<@BOT123> refresh").Process(context.Background(), testAgent)).To(MatchError(ContainSubstring("connection refused")))
+
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "Unknown project metallb on version 4.18, available projects: sriov-4-dot-16").Return(nil)
+		Expect(newMention("<@BOT123> answer metallb 4.18").Process(context.Background(), testAgent)).To(Succeed())
+	})
+
+	It("should only let the admins refresh the allowlist", func() {
+		testAgent = agent.NewAgent(mockDB, mockSlackBot, mockLLM, nil, nil, nil, nil, 1, 10)
+		mockLLM.EXPECT().ListProjects(gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "Only the bot admins can use refresh").Return(nil)
+
+		Expect(newMention("<@BOT123> refresh").Process(context.Background(), testAgent)).To(Succeed())
+	})
+})
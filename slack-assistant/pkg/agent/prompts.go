@@ -0,0 +1,19 @@
+package agent
+
+import "fmt"
+
+// SetProjectPrompts sets the system prompt of each project, e.g. the tone, audience and formatting rules of its answers,
+// the prompt is prepended to every question sent to the workspaces of the project
+func (a *Agent) SetProjectPrompts(prompts map[string]string) {
+	a.projectPrompts = prompts
+}
+
+// withProjectPrompt returns the question prefixed with the system prompt of the project,
+// the question is returned as is when the project has no prompt
+func (a *Agent) withProjectPrompt(project, question string) string {
+	prompt, ok := a.projectPrompts[project]
+	if !ok || prompt == "" {
+		return question
+	}
+	return fmt.Sprintf("%s\n\n%s", prompt, question)
+}
@@ -0,0 +1,71 @@
+package agent_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Project prompts", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.100000"
+	)
+
+	var (
+		ctrl         *gomock.Controller
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	// followUp asks the question in a thread answered for the project
+	followUp := func(project, question string) error {
+		return testAgent.FollowUp(context.Background(), channel, threadTS, &database.SlackThreadToSlug{
+			SlackThread: threadTS,
+			ThreadSlug:  "stored-slug",
+			Project:     project,
+			Version:     "4.16",
+		}, question)
+	}
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockDB := databaseMock.NewMockInterface(ctrl)
+		mockSlackBot = slackbotMock.NewMockInterface(ctrl)
+		mockLLM = llmMock.NewMockInterface(ctrl)
+		testAgent = agent.NewAgent(mockDB, mockSlackBot, mockLLM, nil, nil, nil, nil, 1, 10)
+		testAgent.SetProjectPrompts(map[string]string{"sriov": "You answer OpenShift cluster admins, use YAML examples."})
+
+		mockDB.EXPECT().SetThreadTruncated(gomock.Any(), false).Return(nil).AnyTimes()
+		mockLLM.EXPECT().LastContext(gomock.Any()).Return(nil).AnyTimes()
+
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	It("should prepend the prompt of the project to the question", func() {
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "stored-slug",
+			"You answer OpenShift cluster admins, use YAML examples.\n\nHow do I create a VF?").Return("Create a SriovNetworkNodePolicy", nil)
+
+		Expect(followUp("sriov", "How do I create a VF?")).To(Succeed())
+	})
+
+	It("should send the question as is for a project without prompt", func() {
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.16", "stored-slug", "How do I configure BGP?").Return("Create a BGPPeer", nil)
+
+		Expect(followUp("metallb", "How do I configure BGP?")).To(Succeed())
+	})
+})
@@ -0,0 +1,39 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+)
+
+// ErrWorkExpired is returned when a work item waited in the queue longer than the maximum queue wait
+var ErrWorkExpired = errors.New("work item waited too long in the queue")
+
+// SetMaxQueueWait makes the workers skip the mentions that waited in the queue longer than maxWait, e.g. under
+// sustained overload when the user has given up, notify tells the thread they were skipped. 0 runs every mention.
+func (a *Agent) SetMaxQueueWait(maxWait time.Duration, notify bool) {
+	a.maxQueueWait = maxWait
+	a.notifyExpired = notify
+}
+
+// expired reports whether the work queued at enqueuedAt waited too long, the work without enqueue time never expires
+func (a *Agent) expired(enqueuedAt time.Time) bool {
+	return a.maxQueueWait > 0 && !enqueuedAt.IsZero() && time.Since(enqueuedAt) > a.maxQueueWait
+}
+
+// skipExpiredMention drops the mention that waited too long in the queue, telling its thread when notify is set
+func (a *Agent) skipExpiredMention(event *slackevents.AppMentionEvent, enqueuedAt time.Time) error {
+	threadTS := threadTimestamp(event)
+	fmt.Printf("⌛ Skipping the mention of %s in thread %s, it waited %s in the queue\n",
+		a.userLabel(event.User), threadTS, time.Since(enqueuedAt).Round(time.Second))
+	if a.notifyExpired {
+		if err := a.slackBot.PostMessage(event.Channel, threadTS, withEmoji(a.branding.ProcessingEmoji, i18n.T("error.expired"))); err != nil {
+			fmt.Printf("❌ Failed to post expired message: %v\n", err)
+		}
+	}
+	return ErrWorkExpired
+}
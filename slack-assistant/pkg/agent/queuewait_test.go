@@ -0,0 +1,113 @@
+package agent_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+	slackbot "github.com/SchSeba/slack-ai-assistant/pkg/slack-bot"
+)
+
+var _ = Describe("Max queue wait", func() {
+	const (
+		channel  = "C1234567890"
+		user     = "U123456"
+		threadTS = "1234567890.100000"
+		expired  = "⏳ Sorry, the system was too busy to get to this request in time, please ask again"
+	)
+
+	var (
+		ctrl         *gomock.Controller
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		testAgent    *agent.Agent
+		event        *slackevents.AppMentionEvent
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockDB = databaseMock.NewMockInterface(ctrl)
+		mockSlackBot = slackbotMock.NewMockInterface(ctrl)
+		testAgent = agent.NewAgent(mockDB, mockSlackBot, llmMock.NewMockInterface(ctrl), nil, nil, nil, nil, 1, 10)
+		testAgent.SetMaxQueueWait(30*time.Second, true)
+
+		event = &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            "<@BOT123> invalid",
+			Channel:         channel,
+			TimeStamp:       "1234567890.200000",
+			ThreadTimeStamp: threadTS,
+		}
+		mockSlackBot.EXPECT().GetBotUser().Return(nil).AnyTimes()
+		mockSlackBot.EXPECT().GetUserName(gomock.Any()).Return("alice", nil).AnyTimes()
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	// expectHandled expects the mention to run, the unknown command shows the usage
+	expectHandled := func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil)
+		mockDB.EXPECT().RecordCommand(gomock.Any()).Return(nil)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, gomock.Any()).Return(nil)
+	}
+
+	It("should skip a mention that waited longer than the max queue wait and tell the thread", func() {
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, expired).Return(nil)
+
+		workItem := agent.AppMentionWorkItem{Event: event, EnqueuedAt: time.Now().Add(-time.Minute)}
+		Expect(workItem.Process(context.Background(), testAgent)).To(MatchError(agent.ErrWorkExpired))
+	})
+
+	It("should skip an aged mention silently when the notification is disabled", func() {
+		testAgent.SetMaxQueueWait(30*time.Second, false)
+
+		workItem := agent.AppMentionWorkItem{Event: event, EnqueuedAt: time.Now().Add(-time.Minute)}
+		Expect(workItem.Process(context.Background(), testAgent)).To(MatchError(agent.ErrWorkExpired))
+	})
+
+	It("should run a mention picked up within the max queue wait", func() {
+		expectHandled()
+
+		workItem := agent.AppMentionWorkItem{Event: event, EnqueuedAt: time.Now().Add(-10 * time.Second)}
+		Expect(workItem.Process(context.Background(), testAgent)).To(Succeed())
+	})
+
+	It("should run a mention without enqueue time", func() {
+		expectHandled()
+
+		Expect(agent.AppMentionWorkItem{Event: event}.Process(context.Background(), testAgent)).To(Succeed())
+	})
+
+	It("should run every mention without a max queue wait", func() {
+		testAgent.SetMaxQueueWait(0, true)
+		expectHandled()
+
+		workItem := agent.AppMentionWorkItem{Event: event, EnqueuedAt: time.Now().Add(-time.Hour)}
+		Expect(workItem.Process(context.Background(), testAgent)).To(Succeed())
+	})
+
+	It("should skip the aged mentions queued in the worker pool", func() {
+		skipped := make(chan struct{})
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, expired).DoAndReturn(func(_, _, _ string, _ ...slackbot.MessageOption) error {
+			close(skipped)
+			return nil
+		})
+
+		pool := agent.NewWorkerPool(1, 10)
+		pool.Start(testAgent)
+		defer pool.Stop()
+		Expect(pool.Submit(agent.AppMentionWorkItem{Event: event, EnqueuedAt: time.Now().Add(-time.Minute)})).To(Succeed())
+
+		Eventually(skipped).Should(BeClosed())
+	})
+})
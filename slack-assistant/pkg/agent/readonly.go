@@ -0,0 +1,30 @@
+package agent
+
+import (
+	"fmt"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+)
+
+// SetReadOnlyProjects marks the project and version workspaces whose knowledge base is curated, the inject, export
+// and inject modal commands are rejected for them while the answers keep working
+func (a *Agent) SetReadOnlyProjects(projects ...ProjectVersion) {
+	readOnly := make(map[string]struct{}, len(projects))
+	for _, project := range projects {
+		readOnly[llm.WorkspaceSlug(project.Project, project.Version)] = struct{}{}
+	}
+	a.readOnlyProjects = readOnly
+}
+
+// isReadOnlyProject reports whether the knowledge base of the project and version must not be changed
+func (a *Agent) isReadOnlyProject(project, version string) bool {
+	_, ok := a.readOnlyProjects[llm.WorkspaceSlug(project, version)]
+	return ok
+}
+
+// readOnlyProject tells the user that the knowledge base of the project and version can't be changed
+func (a *Agent) readOnlyProject(channel, user, project, version string) error {
+	fmt.Printf("🔒 Rejected a change of the read-only %s %s knowledge base by %s\n", project, version, a.userLabel(user))
+	return a.slackBot.PostEphemeral(channel, user, i18n.T("projects.readOnly", project, version))
+}
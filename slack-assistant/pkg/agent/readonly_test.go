@@ -0,0 +1,103 @@
+package agent_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Read-only projects", func() {
+	const (
+		channel  = "C1234567890"
+		user     = "U123456"
+		threadTS = "1234567890.100000"
+		rejected = "🔒 The sriov 4.16 knowledge base is curated and read-only, it can't be changed with inject, export or correct"
+	)
+
+	var (
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	mention := func(text string) error {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            text,
+			Channel:         channel,
+			TimeStamp:       "1234567890.200000",
+			ThreadTimeStamp: threadTS,
+		}}.Process(context.Background(), testAgent)
+	}
+
+	BeforeEach(func() {
+		testAgent, mockDB, mockSlackBot, mockLLM = newTestAgent()
+		testAgent.SetReadOnlyProjects(agent.ProjectVersion{Project: "sriov", Version: "4.16"})
+	})
+
+	It("should reject an inject into a read-only workspace", func() {
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, rejected).Return(nil)
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Times(0)
+		mockLLM.EXPECT().Inject(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		Expect(mention("<@BOT123> inject sriov 4.16")).To(Succeed())
+	})
+
+	It("should reject an inject of a page into a read-only workspace", func() {
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, rejected).Return(nil)
+		mockLLM.EXPECT().Inject(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		Expect(mention("<@BOT123> inject sriov 4.16 <https://docs.example.com/sriov>")).To(Succeed())
+	})
+
+	It("should reject an export into a read-only workspace", func() {
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, rejected).Return(nil)
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Times(0)
+
+		Expect(mention("<@BOT123> export sriov 4.16")).To(Succeed())
+	})
+
+	It("should inject into a writable workspace", func() {
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+			{Msg: slack.Msg{Text: "Why is my BGP session down?", User: "U999"}},
+			{Msg: slack.Msg{Text: "BGP peers need a password", User: user}},
+			{Msg: slack.Msg{Text: "<@BOT123> inject sriov 4.18", User: user}},
+		}, nil)
+		mockSlackBot.EXPECT().GetPermalink(channel, threadTS).Return("", nil)
+		mockDB.EXPECT().WithTransaction(gomock.Any()).DoAndReturn(func(fn func(database.Interface) error) error {
+			return fn(mockDB)
+		})
+		mockDB.EXPECT().RecordInjection(gomock.Any()).Return(nil)
+		mockLLM.EXPECT().Inject(gomock.Any(), "sriov", "4.18", "BGP peers need a password", gomock.Any()).Return(nil)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Document injected for project sriov on version 4.18").Return(nil)
+
+		Expect(mention("<@BOT123> inject sriov 4.18")).To(Succeed())
+	})
+
+	It("should still answer from a read-only workspace", func() {
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+			{Msg: slack.Msg{Text: "How do I create a VF?", User: user}},
+			{Msg: slack.Msg{Text: "<@BOT123> answer sriov 4.16", User: user}},
+			{Msg: slack.Msg{Text: "Searching for answer...", User: "BOT123"}},
+		}, nil)
+		mockDB.EXPECT().GetSlugForThread(threadTS).Return("slug", true, nil)
+		mockDB.EXPECT().SetLastQuestion(threadTS, "How do I create a VF?").Return(nil)
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil).AnyTimes()
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "slug", "How do I create a VF?").Return("Use a policy", nil)
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+
+		Expect(mention("<@BOT123> answer sriov 4.16")).To(Succeed())
+	})
+})
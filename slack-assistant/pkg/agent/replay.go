@@ -0,0 +1,113 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+)
+
+var (
+	// replayPermalink matches the message timestamp of a permalink, e.g. https://team.slack.com/archives/C123/p1234567890123456
+	replayPermalink = regexp.MustCompile(`^https://[^/]+/archives/[A-Z0-9]+/p(\d{10})(\d{6})`)
+	// replayThreadTS matches the thread timestamp of the permalink of a reply
+	replayThreadTS = regexp.MustCompile(`[?&]thread_ts=(\d{10}\.\d{6})`)
+	// replayTimestamp matches a bare thread timestamp
+	replayTimestamp = regexp.MustCompile(`^\d{10}\.\d{6}$`)
+)
+
+// SetReplayChannel posts the replays to the channel, e.g. a scratch channel, instead of the channel of the command
+func (a *Agent) SetReplayChannel(channel string) {
+	a.replayChannel = channel
+}
+
+// Replay asks the last question of a past thread again in a new thread of the replay channel, so an admin can compare
+// the answers after a prompt change or an inject. The question is answered in a throwaway LLM thread with the settings
+// of the original thread, deleted once answered, and nothing is stored, the original thread keeps its LLM thread and
+// settings
+func (a *Agent) Replay(ctx context.Context, channel, user, target string) error {
+	if !a.isAdmin(user) {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("replay.notAdmin"))
+	}
+
+	threadTS, link, ok := parseReplayTarget(target)
+	if !ok {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("usage.replay"))
+	}
+
+	threadContext, exist, err := a.db.GetThreadContext(threadTS)
+	if err != nil {
+		fmt.Printf("❌ Failed to get thread context from database: %v\n", err)
+		return fmt.Errorf("failed to get thread context: %w", err)
+	}
+	if !exist || threadContext.LastQuestion == "" {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("replay.noQuestion", threadTS))
+	}
+
+	replayChannel := a.replayChannel
+	if replayChannel == "" {
+		replayChannel = channel
+	}
+	intro := i18n.T("replay.intro", link, threadContext.Project, threadContext.Version, historyQuestion(threadContext.LastQuestion))
+	replayTS, err := a.slackBot.PostMessageTS(replayChannel, "", intro)
+	if err != nil {
+		return fmt.Errorf("failed to post the replay: %w", err)
+	}
+
+	fmt.Printf("🔁 %s replays thread %s for project %s on version %s in channel %s\n", user, threadTS, threadContext.Project, threadContext.Version, replayChannel)
+	placeholder, err := a.postPlaceholder(replayChannel, replayTS)
+	if err != nil {
+		return err
+	}
+
+	slug, err := a.llmClient.CreateThread(ctx, threadContext.Project, threadContext.Version)
+	if err != nil {
+		a.postFailure(replayChannel, replayTS, "create replay thread", err)
+		return fmt.Errorf("failed to create the replay thread: %w", err)
+	}
+	err = a.replayAnswer(withThreadSettings(ctx, threadContext), replayChannel, replayTS, threadContext, slug)
+	// The throwaway thread is deleted even when the replay was canceled, a failed delete only leaves it behind
+	if deleteErr := a.llmClient.DeleteThread(context.WithoutCancel(ctx), threadContext.Project, threadContext.Version, slug); deleteErr != nil {
+		fmt.Printf("⚠️ Failed to delete the replay thread %s: %v\n", slug, deleteErr)
+	}
+	return a.deletePlaceholder(replayChannel, placeholder, err)
+}
+
+// replayAnswer asks the last question of the thread in the throwaway slug and posts the answer in the replay thread,
+// unlike generateAndPostResponse nothing is stored for the replay thread
+func (a *Agent) replayAnswer(ctx context.Context, channel, replayTS string, threadContext *database.SlackThreadToSlug, slug string) error {
+	prompt := a.withProjectPrompt(threadContext.Project, threadContext.LastQuestion)
+	response, err := a.llmClient.SendMessageToChat(ctx, threadContext.Project, threadContext.Version, slug, prompt)
+	if errors.Is(err, llm.ErrNoInformation) {
+		return a.postNoInformation(channel, replayTS, threadContext.Project, threadContext.Version)
+	}
+	if err != nil {
+		a.postFailure(channel, replayTS, "replay", err)
+		return fmt.Errorf("failed to replay the thread: %w", err)
+	}
+	return a.postAnswerWithRetry(ctx, channel, replayTS, a.branding.AnswerIntro, response, a.answerSources(slug), false)
+}
+
+// parseReplayTarget returns the thread timestamp of a thread permalink, as Slack formats it in a mention, or of a bare
+// timestamp, and how to show the thread in the replay
+func parseReplayTarget(target string) (threadTS, link string, ok bool) {
+	if replayTimestamp.MatchString(target) {
+		return target, target, true
+	}
+
+	permalink, _, _ := strings.Cut(strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">"), "|")
+	match := replayPermalink.FindStringSubmatch(permalink)
+	if match == nil {
+		return "", "", false
+	}
+	threadTS = match[1] + "." + match[2]
+	if reply := replayThreadTS.FindStringSubmatch(permalink); reply != nil {
+		threadTS = reply[1]
+	}
+	return threadTS, permalink, true
+}
@@ -0,0 +1,144 @@
+package agent_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Replay", func() {
+	const (
+		channel        = "C1234567890"
+		scratchChannel = "CSCRATCH"
+		originalTS     = "1234567890.100000"
+		replayTS       = "1234567899.000000"
+		commandTS      = "1234567890.900000"
+		admin          = "UADMIN"
+	)
+
+	var (
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+		original     *database.SlackThreadToSlug
+	)
+
+	mention := func(user, text string) error {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:      user,
+			Text:      "<@BOT123> " + text,
+			Channel:   channel,
+			TimeStamp: commandTS,
+		}}.Process(context.Background(), testAgent)
+	}
+
+	BeforeEach(func() {
+		testAgent, mockDB, mockSlackBot, mockLLM = newTestAgent()
+		testAgent.AddAdmins(admin)
+
+		original = &database.SlackThreadToSlug{
+			SlackThread:  originalTS,
+			ThreadSlug:   "original-slug",
+			Project:      "metallb",
+			Version:      "4.18",
+			LastQuestion: "How do I configure BGP?",
+			Mode:         "chat",
+		}
+
+		// The replay never stores anything, any other database call fails the test
+		mockDB.EXPECT().GetThreadContext(commandTS).Return(nil, false, nil).AnyTimes()
+	})
+
+	It("should answer the last question of the thread in a throwaway LLM thread of the replay channel", func() {
+		testAgent.SetReplayChannel(scratchChannel)
+		permalink := "https://team.slack.com/archives/C0ORIGIN/p1234567890100000"
+		mockDB.EXPECT().GetThreadContext(originalTS).Return(original, true, nil)
+
+		mockSlackBot.EXPECT().PostMessageTS(scratchChannel, "", "🔁 *Replay of* "+permalink+" (metallb 4.18)\n> How do I configure BGP?").Return(replayTS, nil)
+		mockSlackBot.EXPECT().PostMessage(scratchChannel, replayTS, "Searching for answer...").Return(nil)
+		mockLLM.EXPECT().CreateThread(gomock.Any(), "metallb", "4.18").Return("throwaway-slug", nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "throwaway-slug", "How do I configure BGP?").DoAndReturn(
+			func(ctx context.Context, _, _, _, _ string) (string, error) {
+				// The settings of the original thread are kept
+				Expect(llm.ChatModeFrom(ctx)).To(Equal(llm.ModeChat))
+				return "Create a BGPPeer", nil
+			})
+		mockSlackBot.EXPECT().PostBlocks(scratchChannel, replayTS, gomock.Any()).Return(nil)
+		mockLLM.EXPECT().DeleteThread(gomock.Any(), "metallb", "4.18", "throwaway-slug").Return(nil)
+
+		Expect(mention(admin, "replay <"+permalink+">")).To(Succeed())
+	})
+
+	It("should replay the thread of a reply permalink in the channel of the command", func() {
+		permalink := "https://team.slack.com/archives/C0ORIGIN/p1234567891000000?thread_ts=1234567890.100000&amp;cid=C0ORIGIN"
+		mockDB.EXPECT().GetThreadContext(originalTS).Return(original, true, nil)
+
+		mockSlackBot.EXPECT().PostMessageTS(channel, "", gomock.Any()).Return(replayTS, nil)
+		mockSlackBot.EXPECT().PostMessage(channel, replayTS, "Searching for answer...").Return(nil)
+		mockLLM.EXPECT().CreateThread(gomock.Any(), "metallb", "4.18").Return("throwaway-slug", nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "throwaway-slug", gomock.Any()).Return("Create a BGPPeer", nil)
+		mockSlackBot.EXPECT().PostBlocks(channel, replayTS, gomock.Any()).Return(nil)
+		mockLLM.EXPECT().DeleteThread(gomock.Any(), "metallb", "4.18", "throwaway-slug").Return(nil)
+
+		Expect(mention(admin, "replay <"+permalink+">")).To(Succeed())
+	})
+
+	It("should replay a thread given by its timestamp", func() {
+		mockDB.EXPECT().GetThreadContext(originalTS).Return(original, true, nil)
+
+		mockSlackBot.EXPECT().PostMessageTS(channel, "", "🔁 *Replay of* 1234567890.100000 (metallb 4.18)\n> How do I configure BGP?").Return(replayTS, nil)
+		mockSlackBot.EXPECT().PostMessage(channel, replayTS, "Searching for answer...").Return(nil)
+		mockLLM.EXPECT().CreateThread(gomock.Any(), "metallb", "4.18").Return("throwaway-slug", nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "throwaway-slug", gomock.Any()).Return("Create a BGPPeer", nil)
+		mockSlackBot.EXPECT().PostBlocks(channel, replayTS, gomock.Any()).Return(nil)
+		mockLLM.EXPECT().DeleteThread(gomock.Any(), "metallb", "4.18", "throwaway-slug").Return(nil)
+
+		Expect(mention(admin, "replay "+originalTS)).To(Succeed())
+	})
+
+	It("should post the replay when the throwaway LLM thread can't be deleted", func() {
+		mockDB.EXPECT().GetThreadContext(originalTS).Return(original, true, nil)
+
+		mockSlackBot.EXPECT().PostMessageTS(channel, "", gomock.Any()).Return(replayTS, nil)
+		mockSlackBot.EXPECT().PostMessage(channel, replayTS, "Searching for answer...").Return(nil)
+		mockLLM.EXPECT().CreateThread(gomock.Any(), "metallb", "4.18").Return("throwaway-slug", nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "throwaway-slug", gomock.Any()).Return("Create a BGPPeer", nil)
+		mockSlackBot.EXPECT().PostBlocks(channel, replayTS, gomock.Any()).Return(nil)
+		mockLLM.EXPECT().DeleteThread(gomock.Any(), "metallb", "4.18", "throwaway-slug").Return(errors.New("workspace not found"))
+
+		Expect(mention(admin, "replay "+originalTS)).To(Succeed())
+	})
+
+	It("should tell when the thread has no answered question", func() {
+		mockDB.EXPECT().GetThreadContext(originalTS).Return(nil, false, nil)
+		mockLLM.EXPECT().CreateThread(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostEphemeral(channel, admin, "Thread 1234567890.100000 has no answered question to replay").Return(nil)
+
+		Expect(mention(admin, "replay "+originalTS)).To(Succeed())
+	})
+
+	It("should reject a target that isn't a thread", func() {
+		mockSlackBot.EXPECT().PostEphemeral(channel, admin, "To replay a thread please use `replay <thread permalink>` or `replay <thread timestamp>`").Return(nil)
+
+		Expect(mention(admin, "replay <https://example.com/page>")).To(Succeed())
+	})
+
+	It("should only be available to the admins", func() {
+		mockLLM.EXPECT().CreateThread(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostEphemeral(channel, "U123456", "Only the bot admins can use replay").Return(nil)
+
+		Expect(mention("U123456", "replay "+originalTS)).To(Succeed())
+	})
+})
@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+)
+
+// CommandOutcome tells how a mention of the bot was handled
+type CommandOutcome int
+
+const (
+	// CommandHandled is a command that ran its handler without error
+	CommandHandled CommandOutcome = iota
+	// CommandUsage is a mention answered with the usage, e.g. an unknown command or missing arguments
+	CommandUsage
+	// CommandRejected is a command refused for its project, e.g. an unknown or read-only workspace
+	CommandRejected
+	// CommandFailed is a command that returned an error
+	CommandFailed
+	// CommandSkipped is a mention dropped without running, e.g. a coalesced command or one that waited too long
+	CommandSkipped
+)
+
+func (o CommandOutcome) String() string {
+	switch o {
+	case CommandUsage:
+		return "usage"
+	case CommandRejected:
+		return "rejected"
+	case CommandFailed:
+		return "failed"
+	case CommandSkipped:
+		return "skipped"
+	default:
+		return "handled"
+	}
+}
+
+// CommandResult describes a handled mention, returned with its error so the worker can record it
+type CommandResult struct {
+	Command  string
+	Outcome  CommandOutcome
+	Duration time.Duration
+}
+
+// CommandWorkItem is a work item running a command, the worker records the result of every command in the pool stats
+type CommandWorkItem interface {
+	WorkItem
+	// Run processes the work item like Process, the result is nil when no command was run
+	Run(ctx context.Context, agent *Agent) (*CommandResult, error)
+}
+
+// usage posts the usage message of the key to the user
+func (a *Agent) usage(channel, user, key string) (CommandOutcome, error) {
+	return CommandUsage, a.slackBot.PostEphemeral(channel, user, i18n.T(key))
+}
+
+// handled returns the outcome of a command handler, failed when it returned an error
+func handled(err error) (CommandOutcome, error) {
+	if err != nil {
+		return CommandFailed, err
+	}
+	return CommandHandled, nil
+}
+
+// rejected returns the outcome of a command refused for its project
+func rejected(err error) (CommandOutcome, error) {
+	return CommandRejected, err
+}
@@ -0,0 +1,149 @@
+package agent_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Command results", func() {
+	const (
+		channel  = "C1234567890"
+		user     = "U123456"
+		threadTS = "1234567890.100000"
+	)
+
+	var (
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	mentionItem := func(text string) agent.AppMentionWorkItem {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            text,
+			Channel:         channel,
+			TimeStamp:       "1234567890.200000",
+			ThreadTimeStamp: threadTS,
+		}}
+	}
+
+	run := func(text string) (*agent.CommandResult, error) {
+		return mentionItem(text).Run(context.Background(), testAgent)
+	}
+
+	BeforeEach(func() {
+		testAgent, mockDB, mockSlackBot, _ = newTestAgent()
+
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil).AnyTimes()
+	})
+
+	It("should return a handled result for a command that ran", func() {
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, gomock.Any()).Return(nil)
+
+		result, err := run("<@BOT123> status")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Command).To(Equal("status"))
+		Expect(result.Outcome).To(Equal(agent.CommandHandled))
+		Expect(result.Duration).To(BeNumerically(">", 0))
+	})
+
+	It("should return a usage result for an unknown command", func() {
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, gomock.Any()).Return(nil)
+
+		result, err := run("<@BOT123> dance")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Command).To(Equal("dance"))
+		Expect(result.Outcome).To(Equal(agent.CommandUsage))
+	})
+
+	It("should return a usage result for a command missing its arguments", func() {
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, gomock.Any()).Return(nil)
+
+		result, err := run("<@BOT123> answer sriov")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Outcome).To(Equal(agent.CommandUsage))
+	})
+
+	It("should return a rejected result for a command refused for its project", func() {
+		testAgent.SetReadOnlyProjects(agent.ProjectVersion{Project: "sriov", Version: "4.16"})
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, gomock.Any()).Return(nil)
+
+		result, err := run("<@BOT123> inject sriov 4.16")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Command).To(Equal("inject"))
+		Expect(result.Outcome).To(Equal(agent.CommandRejected))
+	})
+
+	It("should return a failed result with the error of the command", func() {
+		mockDB.EXPECT().ListAskedQuestions(channel, gomock.Any(), 10, 0).Return(nil, errors.New("database is locked"))
+
+		result, err := run("<@BOT123> history")
+		Expect(err).To(MatchError(ContainSubstring("failed to list questions")))
+		Expect(result.Command).To(Equal("history"))
+		Expect(result.Outcome).To(Equal(agent.CommandFailed))
+	})
+
+	It("should return a skipped result for a coalesced command", func() {
+		testAgent.SetCoalesceWindow(time.Minute)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, gomock.Any()).Return(nil)
+
+		_, err := run("<@BOT123> status")
+		Expect(err).NotTo(HaveOccurred())
+		result, err := run("<@BOT123> status")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Command).To(Equal("status"))
+		Expect(result.Outcome).To(Equal(agent.CommandSkipped))
+	})
+
+	It("should return a skipped result for a mention that waited too long in the queue", func() {
+		testAgent.SetMaxQueueWait(time.Minute, false)
+		item := mentionItem("<@BOT123> status")
+		item.EnqueuedAt = time.Now().Add(-time.Hour)
+
+		result, err := item.Run(context.Background(), testAgent)
+		Expect(err).To(MatchError(agent.ErrWorkExpired))
+		Expect(result.Outcome).To(Equal(agent.CommandSkipped))
+	})
+
+	It("should return the result of a durable mention", func() {
+		mockDB.EXPECT().MarkWorkInProgress(uint(7)).Return(nil)
+		mockDB.EXPECT().CompleteWork(uint(7)).Return(nil)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, gomock.Any()).Return(nil)
+
+		result, err := agent.DurableWorkItem{ID: 7, Item: mentionItem("<@BOT123> status")}.Run(context.Background(), testAgent)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Outcome).To(Equal(agent.CommandHandled))
+	})
+
+	It("should count the command results in the worker pool stats", func() {
+		workerPool := agent.NewWorkerPool(1, 10)
+		workerPool.Start(testAgent)
+		defer workerPool.Stop()
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, gomock.Any()).Return(nil)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, gomock.Any()).Return(nil).Times(2)
+
+		Expect(workerPool.Submit(mentionItem("<@BOT123> status"))).To(Succeed())
+		Expect(workerPool.Submit(mentionItem("<@BOT123> dance"))).To(Succeed())
+		Expect(workerPool.Submit(mentionItem("<@BOT123> sing"))).To(Succeed())
+		Expect(workerPool.Submit(TestWorkItem{ID: "not-a-command"})).To(Succeed())
+
+		Eventually(func() map[agent.CommandStatKey]uint64 {
+			return workerPool.Stats().Commands
+		}).Should(Equal(map[agent.CommandStatKey]uint64{
+			{Command: "status", Outcome: agent.CommandHandled}: 1,
+			// The unknown words share a single counter
+			{Command: "unknown", Outcome: agent.CommandUsage}: 2,
+		}))
+	})
+})
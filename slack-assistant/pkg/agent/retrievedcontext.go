@@ -0,0 +1,48 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+)
+
+// contextChunkLength is the most characters of a retrieved chunk shown by the context command
+const contextChunkLength = 500
+
+// RetrievedContext shows an admin the document chunks the LLM retrieved for the last question of the thread, so a
+// wrong answer can be told apart from a retrieval that missed the right document
+func (a *Agent) RetrievedContext(channel, threadTS, user string) error {
+	if !a.isAdmin(user) {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("context.notAdmin"))
+	}
+
+	threadContext, exist, err := a.db.GetThreadContext(threadTS)
+	if err != nil {
+		fmt.Printf("❌ Failed to get thread context from database: %v\n", err)
+		return fmt.Errorf("failed to get thread context: %w", err)
+	}
+	if !exist {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("context.notFound"))
+	}
+
+	chunks := a.llmClient.LastContext(threadContext.ThreadSlug)
+	if len(chunks) == 0 {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("context.none", threadContext.ThreadSlug))
+	}
+
+	lines := []string{i18n.T("context.header", len(chunks), threadContext.ThreadSlug)}
+	for i, chunk := range chunks {
+		lines = append(lines, i18n.T("context.chunk", i+1, chunk.Title, chunk.Score, contextChunkText(chunk.Text)))
+	}
+	return a.slackBot.PostEphemeral(channel, user, strings.Join(lines, "\n"))
+}
+
+// contextChunkText returns the chunk shortened to contextChunkLength, without the backticks ending its code block
+func contextChunkText(text string) string {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "```", "'''"))
+	if runes := []rune(text); len(runes) > contextChunkLength {
+		text = string(runes[:contextChunkLength-1]) + "…"
+	}
+	return text
+}
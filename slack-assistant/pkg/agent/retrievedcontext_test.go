@@ -0,0 +1,138 @@
+package agent_test
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+	slackbot "github.com/SchSeba/slack-ai-assistant/pkg/slack-bot"
+)
+
+var _ = Describe("RetrievedContext", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.100000"
+		admin    = "UADMIN"
+	)
+
+	var (
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	mention := func(user string) error {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            "<@BOT123> context",
+			Channel:         channel,
+			TimeStamp:       "1234567890.900000",
+			ThreadTimeStamp: threadTS,
+		}}.Process(context.Background(), testAgent)
+	}
+
+	// The retrieved chunks are checked by every spec so the mocks aren't the stubbed ones of newTestAgent
+	BeforeEach(func() {
+		ctrl := gomock.NewController(GinkgoT())
+		mockDB = databaseMock.NewMockInterface(ctrl)
+		mockSlackBot = slackbotMock.NewMockInterface(ctrl)
+		mockLLM = llmMock.NewMockInterface(ctrl)
+		testAgent = agent.NewAgent(mockDB, mockSlackBot, mockLLM, nil, nil, nil, nil, 1, 10)
+		testAgent.AddAdmins(admin)
+
+		mockSlackBot.EXPECT().GetBotUser().Return(nil).AnyTimes()
+		mockSlackBot.EXPECT().GetUserName(gomock.Any()).Return("alice", nil).AnyTimes()
+		mockDB.EXPECT().RecordCommand(gomock.Any()).Return(nil).AnyTimes()
+	})
+
+	It("should show the chunks retrieved for the last question", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(&database.SlackThreadToSlug{
+			SlackThread: threadTS,
+			ThreadSlug:  "stored-slug",
+			Project:     "metallb",
+			Version:     "4.18",
+		}, true, nil)
+		mockLLM.EXPECT().LastContext("stored-slug").Return([]llm.ContextChunk{
+			{Title: "metallb-faq.md", Text: "Configure a BGPPeer for each router", Score: 0.82},
+			{Title: "metallb-api.md", Text: "```yaml\nkind: BGPPeer\n```", Score: 0.614},
+		})
+		mockSlackBot.EXPECT().PostEphemeral(channel, admin, "📚 *2 chunks retrieved for the last question of LLM thread stored-slug*\n"+
+			"*1. metallb-faq.md* (score 0.82)\n```Configure a BGPPeer for each router```\n"+
+			"*2. metallb-api.md* (score 0.61)\n```'''yaml\nkind: BGPPeer\n'''```").Return(nil)
+
+		Expect(mention(admin)).To(Succeed())
+	})
+
+	It("should shorten the long chunks", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(&database.SlackThreadToSlug{ThreadSlug: "stored-slug"}, true, nil)
+		mockLLM.EXPECT().LastContext("stored-slug").Return([]llm.ContextChunk{
+			{Title: "metallb-faq.md", Text: strings.Repeat("a", 600), Score: 0.5},
+		})
+		mockSlackBot.EXPECT().PostEphemeral(channel, admin, gomock.Any()).DoAndReturn(func(_, _, message string) error {
+			Expect(message).To(ContainSubstring("```" + strings.Repeat("a", 499) + "…```"))
+			return nil
+		})
+
+		Expect(mention(admin)).To(Succeed())
+	})
+
+	It("should tell when the backend reported no chunk", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(&database.SlackThreadToSlug{ThreadSlug: "stored-slug"}, true, nil)
+		mockLLM.EXPECT().LastContext("stored-slug").Return(nil)
+		mockSlackBot.EXPECT().PostEphemeral(channel, admin, gomock.Any()).DoAndReturn(func(_, _, message string) error {
+			Expect(message).To(HavePrefix("No retrieved chunks for LLM thread stored-slug"))
+			return nil
+		})
+
+		Expect(mention(admin)).To(Succeed())
+	})
+
+	It("should tell when the thread has no LLM thread", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil)
+		mockLLM.EXPECT().LastContext(gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostEphemeral(channel, admin, "This thread has no LLM thread yet, ask a question first").Return(nil)
+
+		Expect(mention(admin)).To(Succeed())
+	})
+
+	It("should list the sources of the answer for its Sources button", func() {
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "stored-slug", gomock.Any()).Return("Create a BGPPeer", nil)
+		mockLLM.EXPECT().LastContext("stored-slug").Return([]llm.ContextChunk{
+			{Title: "metallb-faq.md", Text: "Configure a BGPPeer for each router", Score: 0.82},
+			{Title: "metallb-api.md", Text: "kind: BGPPeer", Score: 0.61},
+			{Title: "metallb-faq.md", Text: "The peer ASN", Score: 0.5},
+		})
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).DoAndReturn(func(_, _ string, blocks []slack.Block, _ ...slackbot.MessageOption) error {
+			Expect(slackbot.AnswerSources(slack.Blocks{BlockSet: blocks})).To(Equal([]string{"metallb-faq.md", "metallb-api.md"}))
+			return nil
+		})
+		mockDB.EXPECT().SetThreadTruncated(threadTS, false).Return(nil)
+
+		Expect(testAgent.FollowUp(context.Background(), channel, threadTS, &database.SlackThreadToSlug{
+			ThreadSlug: "stored-slug",
+			Project:    "metallb",
+			Version:    "4.18",
+		}, "How do I configure BGP?")).To(Succeed())
+	})
+
+	It("should only be available to the admins", func() {
+		mockDB.EXPECT().GetThreadContext(gomock.Any()).Times(0)
+		mockLLM.EXPECT().LastContext(gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostEphemeral(channel, "U123456", "Only the bot admins can use context").Return(nil)
+
+		Expect(mention("U123456")).To(Succeed())
+	})
+})
@@ -0,0 +1,30 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+)
+
+// Retry asks the last answered question of the thread again with the stored project, version and slug,
+// so an answer can be regenerated after new knowledge was injected
+func (a *Agent) Retry(ctx context.Context, channel, threadTS, user string) error {
+	threadContext := a.getThreadContext(threadTS)
+	if threadContext == nil || threadContext.LastQuestion == "" {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("retry.noQuestion"))
+	}
+
+	fmt.Printf("🔁 Retrying the last question of thread %s for project %s on version %s\n", threadTS, threadContext.Project, threadContext.Version)
+	placeholder, err := a.postPlaceholder(channel, threadTS)
+	if err != nil {
+		return err
+	}
+
+	slug, err := a.questionSlug(ctx, threadTS, threadContext, threadContext.ThreadSlug)
+	if err != nil {
+		a.postUnavailable(channel, threadTS, err)
+		return err
+	}
+	return a.deletePlaceholder(channel, placeholder, a.generateAndPostResponse(withThreadSettings(ctx, threadContext), channel, threadTS, threadContext.Project, threadContext.Version, slug, threadContext.LastQuestion, false))
+}
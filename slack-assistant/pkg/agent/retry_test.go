@@ -0,0 +1,80 @@
+package agent_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Retry", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.100000"
+		user     = "U123456"
+	)
+
+	var (
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	retry := func() error {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            "<@BOT123> retry",
+			Channel:         channel,
+			TimeStamp:       "1234567890.200000",
+			ThreadTimeStamp: threadTS,
+		}}.Process(context.Background(), testAgent)
+	}
+
+	BeforeEach(func() {
+		testAgent, mockDB, mockSlackBot, mockLLM = newTestAgent()
+	})
+
+	It("should ask the last question again with the stored slug and project", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(&database.SlackThreadToSlug{
+			SlackThread:  threadTS,
+			ThreadSlug:   "stored-slug",
+			Project:      "metallb",
+			Version:      "4.18",
+			LastQuestion: "How do I configure BGP peers?",
+		}, true, nil)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "stored-slug", "How do I configure BGP peers?").Return("Create a BGPPeer resource", nil)
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+
+		Expect(retry()).To(Succeed())
+	})
+
+	It("should tell the user when nothing was answered in the thread yet", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(&database.SlackThreadToSlug{
+			SlackThread: threadTS,
+			ThreadSlug:  "stored-slug",
+			Project:     "metallb",
+			Version:     "4.18",
+		}, true, nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "There is no question to retry in this thread yet, please use answer first").Return(nil)
+
+		Expect(retry()).To(Succeed())
+	})
+
+	It("should tell the user when the thread has no context", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "There is no question to retry in this thread yet, please use answer first").Return(nil)
+
+		Expect(retry()).To(Succeed())
+	})
+})
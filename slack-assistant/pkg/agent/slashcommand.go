@@ -0,0 +1,51 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+)
+
+// handleSlashCommand runs a slash command and replies through its response URL,
+// so it also works in channels the bot isn't a member of
+func (a *Agent) handleSlashCommand(ctx context.Context, command *slack.SlashCommand) error {
+	fmt.Printf("⌨️ Slash command %s %s from user %s in channel %s\n", command.Command, command.Text, a.userLabel(command.UserID), command.ChannelID)
+
+	parameters := strings.Fields(command.Text)
+	name := ""
+	if len(parameters) > 0 {
+		name = parameters[0]
+	}
+
+	switch name {
+	case "answer", "inject":
+		return a.openCommandModal(command, name)
+	case "status":
+		return a.slackBot.RespondViaResponseURL(command.ResponseURL, a.statusMessage(), false)
+	case "docs":
+		if len(parameters) < 3 {
+			return a.slackBot.RespondViaResponseURL(command.ResponseURL, i18n.T("usage.docs"), true)
+		}
+		project, version := parameters[1], parameters[2]
+		if !a.isKnownProject(project, version) {
+			return a.slackBot.RespondViaResponseURL(command.ResponseURL, a.unknownProjectMessage(project, version), true)
+		}
+
+		message, err := a.docsMessage(ctx, project, version)
+		if err != nil {
+			logFailure("list documents", err)
+			if respondErr := a.slackBot.RespondViaResponseURL(command.ResponseURL, a.branding.errorMessage(err), true); respondErr != nil {
+				fmt.Printf("❌ Failed to post error message: %v\n", respondErr)
+			}
+			return fmt.Errorf("failed to list documents: %w", err)
+		}
+		return a.slackBot.RespondViaResponseURL(command.ResponseURL, message, false)
+	}
+
+	// The other commands need a thread, which a slash command doesn't have
+	return a.slackBot.RespondViaResponseURL(command.ResponseURL, i18n.T("usage.slashCommands"), true)
+}
@@ -0,0 +1,81 @@
+package agent_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Slash commands", func() {
+	const responseURL = "https://hooks.slack.com/commands/T123/456/abc"
+
+	var (
+		ctrl         *gomock.Controller
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	newCommand := func(text string) agent.SlashCommandWorkItem {
+		return agent.SlashCommandWorkItem{Command: &slack.SlashCommand{
+			Command:     "/assistant",
+			Text:        text,
+			UserID:      "U123456",
+			ChannelID:   "C1234567890",
+			ResponseURL: responseURL,
+		}}
+	}
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockSlackBot = slackbotMock.NewMockInterface(ctrl)
+		mockLLM = llmMock.NewMockInterface(ctrl)
+		testAgent = agent.NewAgent(databaseMock.NewMockInterface(ctrl), mockSlackBot, mockLLM, nil, nil, nil, nil, 1, 10)
+
+		mockSlackBot.EXPECT().GetUserName(gomock.Any()).Return("alice", nil).AnyTimes()
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	It("should reply with the status in the channel through the response URL", func() {
+		var message string
+		mockSlackBot.EXPECT().RespondViaResponseURL(responseURL, gomock.Any(), false).DoAndReturn(func(_, text string, _ bool) error {
+			message = text
+			return nil
+		})
+
+		Expect(newCommand("status").Process(context.Background(), testAgent)).To(Succeed())
+		Expect(message).To(ContainSubstring("Slack AI Assistant status"))
+	})
+
+	It("should reply with the documents through the response URL", func() {
+		mockLLM.EXPECT().ListDocuments(gomock.Any(), "metallb", "4.18").Return([]llm.DocumentInfo{{ID: "doc-1", Title: "MetalLB BGP peers"}}, nil)
+		mockSlackBot.EXPECT().RespondViaResponseURL(responseURL, "📚 1 document(s) for project metallb on version 4.18:\n• MetalLB BGP peers (doc-1)", false).Return(nil)
+
+		Expect(newCommand("docs metallb 4.18").Process(context.Background(), testAgent)).To(Succeed())
+	})
+
+	It("should reply with the unavailable message only to the user when the LLM is down", func() {
+		mockLLM.EXPECT().ListDocuments(gomock.Any(), "metallb", "4.18").Return(nil, llm.ErrCircuitOpen)
+		mockSlackBot.EXPECT().RespondViaResponseURL(responseURL, "⏳ The assistant is temporarily unavailable, please try again in a few minutes", true).Return(nil)
+
+		Expect(newCommand("docs metallb 4.18").Process(context.Background(), testAgent)).To(MatchError(llm.ErrCircuitOpen))
+	})
+
+	It("should reply with the usage only to the user", func() {
+		mockSlackBot.EXPECT().RespondViaResponseURL(responseURL, "Please use one of the following commands (answer,docs,inject,status)", true).Return(nil)
+
+		Expect(newCommand("elaborate").Process(context.Background(), testAgent)).To(Succeed())
+	})
+})
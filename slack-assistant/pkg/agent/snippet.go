@@ -0,0 +1,39 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+
+	slackbot "github.com/SchSeba/slack-ai-assistant/pkg/slack-bot"
+)
+
+// snippetFilename is the name of the snippets holding the long answers, Slack renders them as markdown
+const snippetFilename = "answer.md"
+
+// SetSnippetThreshold uploads the answers longer than threshold characters as a text snippet instead of a message,
+// 0 always posts a message
+func (a *Agent) SetSnippetThreshold(threshold int) {
+	a.snippetThreshold = threshold
+}
+
+// postSnippet uploads the answer as a snippet when it is over the threshold, posted is false when the answer
+// must be posted as a message instead: it is short enough or the upload failed
+func (a *Agent) postSnippet(channel, threadTS, intro, answer string) (bool, error) {
+	if a.snippetThreshold <= 0 || len([]rune(answer)) <= a.snippetThreshold {
+		return false, nil
+	}
+
+	content := answer
+	if intro != "" {
+		content = fmt.Sprintf("%s\n\n%s", intro, answer)
+	}
+	err := a.slackBot.UploadSnippet(channel, threadTS, snippetFilename, a.branding.withFooter(content))
+	if errors.Is(err, slackbot.ErrThreadGone) {
+		return false, fmt.Errorf("failed to send response: %w", err)
+	}
+	if err != nil {
+		fmt.Printf("⚠️ Falling back to a message answer: %v\n", err)
+		return false, nil
+	}
+	return true, nil
+}
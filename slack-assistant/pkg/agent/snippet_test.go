@@ -0,0 +1,87 @@
+package agent_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Snippet answers", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.100000"
+	)
+
+	var (
+		ctrl         *gomock.Controller
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	// answerWith expects an answer of the thread, the LLM answers with the response
+	answerWith := func(response string) {
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+			{Msg: slack.Msg{Text: "How do I create a VF?", User: "U123"}},
+			{Msg: slack.Msg{Text: "<@BOT123> answer sriov 4.16", User: "U123"}},
+			{Msg: slack.Msg{Text: "Searching for answer...", User: "BOT123"}},
+		}, nil)
+		mockDB.EXPECT().GetSlugForThread(threadTS).Return("existing-slug", true, nil)
+		mockDB.EXPECT().SetLastQuestion(threadTS, gomock.Any()).Return(nil)
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil).AnyTimes()
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "existing-slug", gomock.Any()).Return(response, nil)
+	}
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockDB = databaseMock.NewMockInterface(ctrl)
+		mockSlackBot = slackbotMock.NewMockInterface(ctrl)
+		mockLLM = llmMock.NewMockInterface(ctrl)
+		testAgent = agent.NewAgent(mockDB, mockSlackBot, mockLLM, nil, nil, nil, nil, 1, 10)
+		testAgent.SetSnippetThreshold(100)
+
+		mockDB.EXPECT().SetThreadTruncated(gomock.Any(), false).Return(nil).AnyTimes()
+		mockLLM.EXPECT().LastContext(gomock.Any()).Return(nil).AnyTimes()
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	It("should upload an answer over the threshold as a snippet", func() {
+		longAnswer := strings.Repeat("kubectl get sriovnetworknodestates\n", 10)
+		answerWith(longAnswer)
+		mockSlackBot.EXPECT().UploadSnippet(channel, threadTS, "answer.md", "Here is the information I was able to find\n\n"+longAnswer).Return(nil)
+		mockSlackBot.EXPECT().PostBlocks(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		Expect(testAgent.AnswerQuestion(context.Background(), channel, threadTS, "sriov", "4.16", false, false)).To(Succeed())
+	})
+
+	It("should post a short answer as a message", func() {
+		answerWith("Use the SriovNetworkNodePolicy")
+		mockSlackBot.EXPECT().UploadSnippet(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+
+		Expect(testAgent.AnswerQuestion(context.Background(), channel, threadTS, "sriov", "4.16", false, false)).To(Succeed())
+	})
+
+	It("should post the answer as a message when the upload fails", func() {
+		answerWith(strings.Repeat("a long answer ", 10))
+		mockSlackBot.EXPECT().UploadSnippet(channel, threadTS, "answer.md", gomock.Any()).Return(errors.New("missing_scope"))
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+
+		Expect(testAgent.AnswerQuestion(context.Background(), channel, threadTS, "sriov", "4.16", false, false)).To(Succeed())
+	})
+})
@@ -0,0 +1,56 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+)
+
+// strictDescriptions are the message keys explaining the values the strict command accepts
+var strictDescriptions = map[string]string{
+	"on":  "strict.on",
+	"off": "strict.off",
+}
+
+// Strict sets whether the next answers of the thread only come from the retrieved documents (on), saying so when
+// none is relevant, or may also come from the knowledge of the model (off), the setting is stored with the thread context
+func (a *Agent) Strict(channel, threadTS, user, value string) error {
+	description, ok := strictDescriptions[value]
+	if !ok {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("usage.strict"))
+	}
+	if a.getThreadContext(threadTS) == nil {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("thread.noAnswer"))
+	}
+
+	if err := a.db.SetThreadStrict(threadTS, value == "on"); err != nil {
+		fmt.Printf("❌ Failed to store the strictness of thread %s: %v\n", threadTS, err)
+		return fmt.Errorf("failed to set thread strictness: %w", err)
+	}
+
+	if err := a.slackBot.PostMessage(channel, threadTS, i18n.T("strict.done", i18n.T(description))); err != nil {
+		return fmt.Errorf("failed to send response: %w", err)
+	}
+	return nil
+}
+
+// postNoInformation tells the thread the documents of the project have nothing to answer the question,
+// a strict answer is never made up from the knowledge of the model
+func (a *Agent) postNoInformation(channel, threadTS, project, version string) error {
+	if err := a.slackBot.PostMessage(channel, threadTS, i18n.T("strict.noInformation", project, version)); err != nil {
+		return fmt.Errorf("failed to send response: %w", err)
+	}
+	return nil
+}
+
+// withThreadStrict returns the context answering only from the documents when the thread is strict,
+// the context is returned as is otherwise
+func withThreadStrict(ctx context.Context, threadContext *database.SlackThreadToSlug) context.Context {
+	if threadContext == nil || !threadContext.Strict {
+		return ctx
+	}
+	return llm.WithStrict(ctx, true)
+}
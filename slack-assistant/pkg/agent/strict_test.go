@@ -0,0 +1,106 @@
+package agent_test
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Strict", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.100000"
+		user     = "U123456"
+		usage    = "To change where the answers come from please use `strict on` to answer only from the documents of the project or `strict off` to also use the knowledge of the model"
+	)
+
+	var (
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	mention := func(text string) error {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            text,
+			Channel:         channel,
+			TimeStamp:       "1234567890.200000",
+			ThreadTimeStamp: threadTS,
+		}}.Process(context.Background(), testAgent)
+	}
+
+	// storedThread is the thread context of a thread answered for metallb
+	storedThread := func(strict bool) *database.SlackThreadToSlug {
+		return &database.SlackThreadToSlug{
+			SlackThread: threadTS,
+			ThreadSlug:  "stored-slug",
+			Project:     "metallb",
+			Version:     "4.18",
+			Strict:      strict,
+		}
+	}
+
+	BeforeEach(func() {
+		testAgent, mockDB, mockSlackBot, mockLLM = newTestAgent()
+	})
+
+	DescribeTable("should pass the strictness of the thread to the LLM",
+		func(strict bool) {
+			mockDB.EXPECT().GetThreadContext(threadTS).Return(storedThread(strict), true, nil)
+			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+			mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "stored-slug", "how do I configure BGP?").DoAndReturn(
+				func(ctx context.Context, _, _, _, _ string) (string, error) {
+					Expect(llm.StrictFrom(ctx)).To(Equal(strict))
+					return "Create a BGPPeer", nil
+				})
+			mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+
+			Expect(mention("<@BOT123> how do I configure BGP?")).To(Succeed())
+		},
+		Entry("strict", true),
+		Entry("not strict", false),
+	)
+
+	It("should say nothing was found when the documents have nothing relevant", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(storedThread(true), true, nil)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "stored-slug", gomock.Any()).Return("", fmt.Errorf("failed to answer: %w", llm.ErrNoInformation))
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "🔍 I couldn't find anything relevant to this question in the documents of metallb 4.18").Return(nil)
+		mockSlackBot.EXPECT().PostBlocks(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		Expect(mention("<@BOT123> how do I configure OSPF?")).To(Succeed())
+	})
+
+	DescribeTable("should store the strictness of the thread",
+		func(value string, strict bool, confirmation string) {
+			mockDB.EXPECT().GetThreadContext(threadTS).Return(storedThread(!strict), true, nil)
+			mockDB.EXPECT().SetThreadStrict(threadTS, strict).Return(nil)
+			mockSlackBot.EXPECT().PostMessage(channel, threadTS, confirmation).Return(nil)
+
+			Expect(mention("<@BOT123> strict " + value)).To(Succeed())
+		},
+		Entry("on", "on", true, "📚 The next answers in this thread only come from the documents of the project, I'll say so when they have nothing relevant"),
+		Entry("off", "off", false, "📚 The next answers in this thread may also come from the knowledge of the model"),
+	)
+
+	It("should reject an unknown strict value", func() {
+		mockDB.EXPECT().SetThreadStrict(gomock.Any(), gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, usage).Return(nil).Times(2)
+
+		Expect(mention("<@BOT123> strict very")).To(Succeed())
+		Expect(mention("<@BOT123> strict")).To(Succeed())
+	})
+})
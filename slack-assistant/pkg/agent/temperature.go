@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+)
+
+// followUpCommand names the questions asked in a thread without a command, for their temperature
+const followUpCommand = "follow-up"
+
+// TemperatureCommands are the commands answering with the LLM, whose temperature can be configured
+var TemperatureCommands = []string{"answer", "answer-all", "answer-multi", "retry", "continue", followUpCommand, "elaborate"}
+
+// SetCommandTemperatures sets the temperature of the commands, the commands without one answer with the temperature
+// of the backend. An error is returned for a command that isn't in TemperatureCommands or a temperature out of the
+// [llm.MinTemperature, llm.MaxTemperature] range
+func (a *Agent) SetCommandTemperatures(temperatures map[string]float64) error {
+	for command, temperature := range temperatures {
+		if !slices.Contains(TemperatureCommands, command) {
+			return fmt.Errorf("unknown command %s for a temperature, expected one of %s", command, strings.Join(TemperatureCommands, ", "))
+		}
+		if !validTemperature(temperature) {
+			return fmt.Errorf("invalid temperature %v for command %s, expected a value between %v and %v", temperature, command, llm.MinTemperature, llm.MaxTemperature)
+		}
+	}
+	maps.Copy(a.commandTemperatures, temperatures)
+	return nil
+}
+
+// Temperature sets how creative the next answers of the thread are, the temperature is stored with the thread
+// context and overrides the temperature of the commands
+func (a *Agent) Temperature(channel, threadTS, user, value string) error {
+	temperature, err := strconv.ParseFloat(value, 64)
+	if err != nil || !validTemperature(temperature) {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("usage.temperature", llm.MinTemperature, llm.MaxTemperature))
+	}
+	if a.getThreadContext(threadTS) == nil {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("thread.noAnswer"))
+	}
+
+	if err := a.db.SetThreadTemperature(threadTS, temperature); err != nil {
+		fmt.Printf("❌ Failed to store the temperature of thread %s: %v\n", threadTS, err)
+		return fmt.Errorf("failed to set thread temperature: %w", err)
+	}
+
+	if err := a.slackBot.PostMessage(channel, threadTS, i18n.T("temperature.done", temperature)); err != nil {
+		return fmt.Errorf("failed to send response: %w", err)
+	}
+	return nil
+}
+
+// validTemperature reports whether the temperature is in range, NaN isn't
+func validTemperature(temperature float64) bool {
+	return temperature >= llm.MinTemperature && temperature <= llm.MaxTemperature
+}
+
+// withCommandTemperature returns the context answering with the temperature configured for the command,
+// the context is returned as is for the commands without one
+func (a *Agent) withCommandTemperature(ctx context.Context, command string) context.Context {
+	temperature, ok := a.commandTemperatures[command]
+	if !ok {
+		return ctx
+	}
+	return llm.WithTemperature(ctx, temperature)
+}
+
+// withThreadTemperature returns the context answering with the temperature chosen for the thread,
+// the context is returned as is when the thread has no temperature
+func withThreadTemperature(ctx context.Context, threadContext *database.SlackThreadToSlug) context.Context {
+	if threadContext == nil || threadContext.Temperature == nil {
+		return ctx
+	}
+	return llm.WithTemperature(ctx, *threadContext.Temperature)
+}
@@ -0,0 +1,158 @@
+package agent_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Temperature", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.100000"
+		user     = "U123456"
+		usage    = "To change how creative the answers are please use `temperature <t>` with a number between 0.0 (grounded) and 1.0 (creative)"
+	)
+
+	var (
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	mention := func(text string) error {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            text,
+			Channel:         channel,
+			TimeStamp:       "1234567890.200000",
+			ThreadTimeStamp: threadTS,
+		}}.Process(context.Background(), testAgent)
+	}
+
+	// storedThread is the thread context of a thread answered for metallb
+	storedThread := func(temperature *float64) *database.SlackThreadToSlug {
+		return &database.SlackThreadToSlug{
+			SlackThread: threadTS,
+			ThreadSlug:  "stored-slug",
+			Project:     "metallb",
+			Version:     "4.18",
+			Temperature: temperature,
+		}
+	}
+
+	// expectAnswer expects the question of the thread to be answered with the temperature
+	expectAnswer := func(question string, temperature float64) {
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "stored-slug", question).DoAndReturn(
+			func(ctx context.Context, _, _, _, _ string) (string, error) {
+				sent, ok := llm.TemperatureFrom(ctx)
+				Expect(ok).To(BeTrue())
+				Expect(sent).To(BeNumerically("==", temperature))
+				return "Create a BGPPeer", nil
+			})
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+	}
+
+	BeforeEach(func() {
+		testAgent, mockDB, mockSlackBot, mockLLM = newTestAgent()
+	})
+
+	It("should answer with the temperature of the backend when none is configured", func() {
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+			{Msg: slack.Msg{Timestamp: threadTS, Text: "how do I configure BGP?", User: "U999"}},
+			{Msg: slack.Msg{Timestamp: "1234567890.200000", Text: "<@BOT123> answer metallb 4.18", User: user}},
+			{Msg: slack.Msg{Timestamp: "1234567890.300000", Text: "Searching for answer...", User: "BOT123"}},
+		}, nil)
+		mockDB.EXPECT().GetSlugForThread(threadTS).Return("stored-slug", true, nil)
+		mockDB.EXPECT().SetLastQuestion(threadTS, "how do I configure BGP?").Return(nil)
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(storedThread(nil), true, nil).AnyTimes()
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "stored-slug", "how do I configure BGP?").DoAndReturn(
+			func(ctx context.Context, _, _, _, _ string) (string, error) {
+				_, ok := llm.TemperatureFrom(ctx)
+				Expect(ok).To(BeFalse())
+				return "Create a BGPPeer", nil
+			})
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+
+		Expect(mention("<@BOT123> answer metallb 4.18")).To(Succeed())
+	})
+
+	It("should answer with the configured temperature of the command", func() {
+		Expect(testAgent.SetCommandTemperatures(map[string]float64{"follow-up": 0.4})).To(Succeed())
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(storedThread(nil), true, nil)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		expectAnswer("how do I configure BGP?", 0.4)
+
+		Expect(mention("<@BOT123> how do I configure BGP?")).To(Succeed())
+	})
+
+	It("should answer with the temperature of the thread over the one of the command", func() {
+		temperature := 0.9
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(storedThread(&temperature), true, nil)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		expectAnswer("how do I configure BGP?", 0.9)
+
+		Expect(mention("<@BOT123> how do I configure BGP?")).To(Succeed())
+	})
+
+	It("should elaborate with the configured temperature of elaborate", func() {
+		Expect(testAgent.SetCommandTemperatures(map[string]float64{"elaborate": 0.7})).To(Succeed())
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Elaborating...").Return(nil)
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+			{Msg: slack.Msg{Timestamp: threadTS, Text: "Create a BGPPeer", User: "U999"}},
+			{Msg: slack.Msg{Timestamp: "1234567890.200000", Text: "<@BOT123> elaborate", User: user}},
+			{Msg: slack.Msg{Timestamp: "1234567890.300000", Text: "Elaborating...", User: "BOT123"}},
+		}, nil)
+		mockDB.EXPECT().GetElaborateSlugForThread(threadTS).Return("elaborate-slug", true, nil)
+		mockLLM.EXPECT().Elaborate(gomock.Any(), agent.DefaultElaborateWorkspace, "elaborate-slug", "Create a BGPPeer").DoAndReturn(
+			func(ctx context.Context, _, _, _ string) (string, error) {
+				sent, ok := llm.TemperatureFrom(ctx)
+				Expect(ok).To(BeTrue())
+				Expect(sent).To(BeNumerically("==", 0.7))
+				return "A BGPPeer connects MetalLB to a router", nil
+			})
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "A BGPPeer connects MetalLB to a router").Return(nil)
+
+		Expect(mention("<@BOT123> elaborate")).To(Succeed())
+	})
+
+	It("should store the temperature of the thread", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(storedThread(nil), true, nil)
+		mockDB.EXPECT().SetThreadTemperature(threadTS, 0.8).Return(nil)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "🌡️ The next answers in this thread are generated with a temperature of 0.80").Return(nil)
+
+		Expect(mention("<@BOT123> temperature 0.8")).To(Succeed())
+	})
+
+	It("should reject a temperature out of range", func() {
+		mockDB.EXPECT().SetThreadTemperature(gomock.Any(), gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, usage).Return(nil).Times(5)
+
+		Expect(mention("<@BOT123> temperature -0.1")).To(Succeed())
+		Expect(mention("<@BOT123> temperature 1.5")).To(Succeed())
+		Expect(mention("<@BOT123> temperature NaN")).To(Succeed())
+		Expect(mention("<@BOT123> temperature hot")).To(Succeed())
+		Expect(mention("<@BOT123> temperature")).To(Succeed())
+	})
+
+	It("should reject a configured temperature out of range", func() {
+		Expect(testAgent.SetCommandTemperatures(map[string]float64{"answer": 2})).NotTo(Succeed())
+	})
+
+	It("should reject the temperature of an unknown command", func() {
+		Expect(testAgent.SetCommandTemperatures(map[string]float64{"elaboate": 0.9})).To(MatchError(ContainSubstring("unknown command elaboate")))
+	})
+})
@@ -0,0 +1,48 @@
+package agent
+
+import (
+	"unicode/utf8"
+)
+
+// DefaultMaxThreadContext is the default number of characters of a thread sent to the LLM
+const DefaultMaxThreadContext = 30000
+
+// SetMaxThreadContext limits the characters of a thread sent to the LLM, 0 disables the limit.
+// When the thread is larger the most recent messages are kept, plus the first one if keepFirstMessage is set.
+func (a *Agent) SetMaxThreadContext(maxChars int, keepFirstMessage bool) {
+	a.maxThreadContext = maxChars
+	a.keepFirstMessage = keepFirstMessage
+}
+
+// limitThreadMessages returns the messages that fit in maxChars, keeping the most recent ones
+// and the first one when keepFirst is set and it fits
+func limitThreadMessages(messages []string, maxChars int, keepFirst bool) []string {
+	total := 0
+	for _, message := range messages {
+		total += utf8.RuneCountInString(message)
+	}
+	if maxChars <= 0 || total <= maxChars {
+		return messages
+	}
+
+	budget := maxChars
+	first := 0
+	if keepFirst && len(messages) > 0 && utf8.RuneCountInString(messages[0]) <= budget {
+		budget -= utf8.RuneCountInString(messages[0])
+		first = 1
+	}
+
+	start := len(messages)
+	for start > first {
+		size := utf8.RuneCountInString(messages[start-1])
+		if size > budget {
+			break
+		}
+		budget -= size
+		start--
+	}
+
+	kept := make([]string, 0, first+len(messages)-start)
+	kept = append(kept, messages[:first]...)
+	return append(kept, messages[start:]...)
+}
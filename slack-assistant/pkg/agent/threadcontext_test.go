@@ -0,0 +1,95 @@
+package agent_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Thread context budget", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.123456"
+	)
+
+	var (
+		ctrl         *gomock.Controller
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	thread := []slack.Message{
+		{Msg: slack.Msg{Text: "first question"}},
+		{Msg: slack.Msg{Text: "a long answer from the bot"}},
+		{Msg: slack.Msg{Text: "second"}},
+		{Msg: slack.Msg{Text: "third"}},
+	}
+
+	// answerAll answers the whole thread and returns the messages sent to the LLM
+	answerAll := func() string {
+		var sent string
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return(thread, nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "thread-slug", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _, _, message string) (string, error) {
+				sent = message
+				return "AI response", nil
+			})
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+
+		Expect(testAgent.AnswerQuestion(context.Background(), channel, threadTS, "sriov", "4.16", true, false)).To(Succeed())
+		return sent
+	}
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockDB := databaseMock.NewMockInterface(ctrl)
+		mockSlackBot = slackbotMock.NewMockInterface(ctrl)
+		mockLLM = llmMock.NewMockInterface(ctrl)
+		testAgent = agent.NewAgent(mockDB, mockSlackBot, mockLLM, nil, nil, nil, nil, 1, 10)
+
+		mockDB.EXPECT().SetThreadTruncated(gomock.Any(), false).Return(nil).AnyTimes()
+		mockLLM.EXPECT().LastContext(gomock.Any()).Return(nil).AnyTimes()
+
+		mockDB.EXPECT().GetSlugForThread(threadTS).Return("thread-slug", true, nil).AnyTimes()
+		mockDB.EXPECT().SetLastQuestion(threadTS, gomock.Any()).Return(nil).AnyTimes()
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil).AnyTimes()
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	It("should send the whole thread when it is under the budget", func() {
+		testAgent.SetMaxThreadContext(1000, true)
+
+		Expect(answerAll()).To(Equal("first question\na long answer from the bot\nsecond\nthird\n"))
+	})
+
+	It("should keep the first and the most recent messages when the thread is over the budget", func() {
+		testAgent.SetMaxThreadContext(30, true)
+
+		Expect(answerAll()).To(Equal("first question\nsecond\nthird\n"))
+	})
+
+	It("should keep only the most recent messages when the first one is not kept", func() {
+		testAgent.SetMaxThreadContext(30, false)
+
+		Expect(answerAll()).To(Equal("second\nthird\n"))
+	})
+
+	It("should send the whole thread when the budget is disabled", func() {
+		testAgent.SetMaxThreadContext(0, false)
+
+		Expect(answerAll()).To(Equal("first question\na long answer from the bot\nsecond\nthird\n"))
+	})
+})
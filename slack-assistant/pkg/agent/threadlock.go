@@ -0,0 +1,43 @@
+package agent
+
+import "sync"
+
+// threadLocks serializes the work of the workers on the same Slack thread, the lock of a thread
+// is dropped once no worker holds or waits for it so the map doesn't grow with every thread
+type threadLocks struct {
+	mu    sync.Mutex
+	locks map[string]*threadLock
+}
+
+type threadLock struct {
+	mu sync.Mutex
+	// holders counts the workers holding or waiting for the lock
+	holders int
+}
+
+// lock locks the thread and returns the function unlocking it
+func (l *threadLocks) lock(threadTS string) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = map[string]*threadLock{}
+	}
+	lock, ok := l.locks[threadTS]
+	if !ok {
+		lock = &threadLock{}
+		l.locks[threadTS] = lock
+	}
+	lock.holders++
+	l.mu.Unlock()
+
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		lock.holders--
+		if lock.holders == 0 {
+			delete(l.locks, threadTS)
+		}
+	}
+}
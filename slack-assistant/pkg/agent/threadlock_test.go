@@ -0,0 +1,83 @@
+package agent_test
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Thread slug creation", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.100000"
+	)
+
+	It("should create the LLM thread once when two answers race in a new thread", func() {
+		ctrl := gomock.NewController(GinkgoT())
+		defer ctrl.Finish()
+		mockDB := databaseMock.NewMockInterface(ctrl)
+		mockSlackBot := slackbotMock.NewMockInterface(ctrl)
+		mockLLM := llmMock.NewMockInterface(ctrl)
+		testAgent := agent.NewAgent(mockDB, mockSlackBot, mockLLM, nil, nil, nil, nil, 2, 10)
+
+		mockDB.EXPECT().SetThreadTruncated(gomock.Any(), false).Return(nil).AnyTimes()
+		mockLLM.EXPECT().LastContext(gomock.Any()).Return(nil).AnyTimes()
+
+		// The database mock keeps the slug like the real table, the primary key rejects a second thread
+		var (
+			mu         sync.Mutex
+			storedSlug string
+		)
+		mockDB.EXPECT().GetSlugForThread(threadTS).DoAndReturn(func(string) (string, bool, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return storedSlug, storedSlug != "", nil
+		}).Times(2)
+		mockDB.EXPECT().CreateSlackThreadWithSlug(threadTS, "new-slug", "sriov", "4.16").DoAndReturn(func(_, slug, _, _ string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			storedSlug = slug
+			return nil
+		})
+		mockDB.EXPECT().SetLastQuestion(threadTS, gomock.Any()).Return(nil).Times(2)
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil).AnyTimes()
+		mockLLM.EXPECT().CreateThread(gomock.Any(), "sriov", "4.16").DoAndReturn(func(context.Context, string, string) (string, error) {
+			// Leaves the other answer the time to look the slug up before it is stored
+			time.Sleep(50 * time.Millisecond)
+			return "new-slug", nil
+		})
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "new-slug", gomock.Any()).Return("AI response", nil).Times(2)
+
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil).Times(2)
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+			{Msg: slack.Msg{Text: "How do I create a VF?", User: "U123"}},
+			{Msg: slack.Msg{Text: "<@BOT123> answer sriov 4.16", User: "U123"}},
+			{Msg: slack.Msg{Text: "Searching for answer...", User: "BOT123"}},
+		}, nil).Times(2)
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil).Times(2)
+
+		var wg sync.WaitGroup
+		errs := make([]error, 2)
+		for i := range errs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer GinkgoRecover()
+				errs[i] = testAgent.AnswerQuestion(context.Background(), channel, threadTS, "sriov", "4.16", false, false)
+			}()
+		}
+		wg.Wait()
+
+		Expect(errs).To(HaveEach(Succeed()))
+	})
+})
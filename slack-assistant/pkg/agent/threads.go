@@ -0,0 +1,55 @@
+package agent
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+)
+
+// threadsPageSize is the number of threads listed on each page of the threads command
+const threadsPageSize = 20
+
+// Threads shows an admin a page of the Slack threads and the LLM slugs they map to, for debugging
+func (a *Agent) Threads(channel, user string, page int) error {
+	if !a.isAdmin(user) {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("threads.notAdmin"))
+	}
+
+	threads, err := a.db.ListThreads(threadsPageSize, (page-1)*threadsPageSize)
+	if err != nil {
+		fmt.Printf("❌ Failed to list threads: %v\n", err)
+		return fmt.Errorf("failed to list threads: %w", err)
+	}
+
+	if len(threads) == 0 {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("threads.none", page))
+	}
+
+	lines := make([]string, 0, len(threads)+2)
+	lines = append(lines, i18n.T("threads.page", page))
+	for _, thread := range threads {
+		line := fmt.Sprintf("• %s → %s (%s %s)", thread.SlackThread, thread.ThreadSlug, thread.Project, thread.Version)
+		if thread.ElaborateSlug != "" {
+			line += fmt.Sprintf(", elaborate %s", thread.ElaborateSlug)
+		}
+		lines = append(lines, line)
+	}
+	if len(threads) == threadsPageSize {
+		lines = append(lines, i18n.T("threads.next", page+1))
+	}
+	return a.slackBot.PostEphemeral(channel, user, strings.Join(lines, "\n"))
+}
+
+// threadsPage parses the optional page number of the threads command, pages start at 1
+func threadsPage(parameters []string) (int, bool) {
+	if len(parameters) < 3 || parameters[2] == "" {
+		return 1, true
+	}
+	page, err := strconv.Atoi(parameters[2])
+	if err != nil || page < 1 {
+		return 0, false
+	}
+	return page, true
+}
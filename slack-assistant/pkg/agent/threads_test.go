@@ -0,0 +1,99 @@
+package agent_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Threads", func() {
+	const (
+		channel = "C1234567890"
+		user    = "U123456"
+	)
+
+	var (
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	threads := func(text string) error {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:      user,
+			Text:      text,
+			Channel:   channel,
+			TimeStamp: "1234567890.200000",
+		}}.Process(context.Background(), testAgent)
+	}
+
+	BeforeEach(func() {
+		testAgent, mockDB, mockSlackBot, _ = newTestAgent()
+		testAgent.AddAdmins(user)
+	})
+
+	It("should list the first page of threads and their slugs", func() {
+		mockDB.EXPECT().ListThreads(20, 0).Return([]database.SlackThreadToSlug{
+			{SlackThread: "1700000002.000100", ThreadSlug: "slug-2", Project: "metallb", Version: "4.18", ElaborateSlug: "elaborate-2"},
+			{SlackThread: "1700000001.000100", ThreadSlug: "slug-1", Project: "sriov", Version: "4.16"},
+		}, nil)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "🧵 Threads on page 1:\n"+
+			"• 1700000002.000100 → slug-2 (metallb 4.18), elaborate elaborate-2\n"+
+			"• 1700000001.000100 → slug-1 (sriov 4.16)").Return(nil)
+
+		Expect(threads("<@BOT123> threads")).To(Succeed())
+	})
+
+	It("should point to the next page when the page is full", func() {
+		page := make([]database.SlackThreadToSlug, 20)
+		for i := range page {
+			page[i] = database.SlackThreadToSlug{SlackThread: fmt.Sprintf("17000000%02d.000100", i), ThreadSlug: "slug"}
+		}
+		mockDB.EXPECT().ListThreads(20, 20).Return(page, nil)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, gomock.Any()).DoAndReturn(func(_, _, message string) error {
+			Expect(message).To(HavePrefix("🧵 Threads on page 2:"))
+			Expect(message).To(HaveSuffix("Use `threads 3` for the next page"))
+			return nil
+		})
+
+		Expect(threads("<@BOT123> threads 2")).To(Succeed())
+	})
+
+	It("should tell the user when the page is empty", func() {
+		mockDB.EXPECT().ListThreads(20, 40).Return(nil, nil)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "There are no threads on page 3").Return(nil)
+
+		Expect(threads("<@BOT123> threads 3")).To(Succeed())
+	})
+
+	It("should reject an invalid page", func() {
+		mockDB.EXPECT().ListThreads(gomock.Any(), gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "To list the threads please provide a page number starting at 1, or no page for the first one").Return(nil)
+
+		Expect(threads("<@BOT123> threads 0")).To(Succeed())
+	})
+
+	It("should return the database error", func() {
+		mockDB.EXPECT().ListThreads(20, 0).Return(nil, errors.New("database error"))
+
+		Expect(threads("<@BOT123> threads")).To(MatchError(ContainSubstring("failed to list threads")))
+	})
+
+	It("should only be available to the admins", func() {
+		testAgent, mockDB, mockSlackBot, _ = newTestAgent()
+		mockDB.EXPECT().ListThreads(gomock.Any(), gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "Only the bot admins can use threads").Return(nil)
+
+		Expect(threads("<@BOT123> threads")).To(Succeed())
+	})
+})
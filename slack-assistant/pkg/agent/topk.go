@@ -0,0 +1,43 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+)
+
+// TopK sets how many sources the next answers of the thread are retrieved from,
+// the number is stored with the thread context
+func (a *Agent) TopK(channel, threadTS, user, value string) error {
+	topK, err := strconv.Atoi(value)
+	if err != nil || topK < llm.MinTopK || topK > llm.MaxTopK {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("usage.topK", llm.MinTopK, llm.MaxTopK))
+	}
+	if a.getThreadContext(threadTS) == nil {
+		return a.slackBot.PostEphemeral(channel, user, i18n.T("thread.noAnswer"))
+	}
+
+	if err := a.db.SetThreadTopK(threadTS, topK); err != nil {
+		fmt.Printf("❌ Failed to store the top-k of thread %s: %v\n", threadTS, err)
+		return fmt.Errorf("failed to set thread top-k: %w", err)
+	}
+
+	if err := a.slackBot.PostMessage(channel, threadTS, i18n.T("topK.done", topK)); err != nil {
+		return fmt.Errorf("failed to send response: %w", err)
+	}
+	return nil
+}
+
+// withThreadSettings returns the context answering with the mode, the strictness, the temperature and the number
+// of sources chosen for the thread
+func withThreadSettings(ctx context.Context, threadContext *database.SlackThreadToSlug) context.Context {
+	ctx = withThreadTemperature(withThreadStrict(withThreadMode(ctx, threadContext), threadContext), threadContext)
+	if threadContext == nil || threadContext.TopK == 0 {
+		return ctx
+	}
+	return llm.WithTopK(ctx, threadContext.TopK)
+}
@@ -0,0 +1,97 @@
+package agent_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	"github.com/SchSeba/slack-ai-assistant/pkg/llm"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("TopK", func() {
+	const (
+		channel  = "C1234567890"
+		threadTS = "1234567890.100000"
+		user     = "U123456"
+		usage    = "To change the number of sources please use `topk <n>` with a number between 1 and 20"
+	)
+
+	var (
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	mention := func(text string) error {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            text,
+			Channel:         channel,
+			TimeStamp:       "1234567890.200000",
+			ThreadTimeStamp: threadTS,
+		}}.Process(context.Background(), testAgent)
+	}
+
+	// storedThread is the thread context of a thread answered for metallb
+	storedThread := func(topK int) *database.SlackThreadToSlug {
+		return &database.SlackThreadToSlug{
+			SlackThread: threadTS,
+			ThreadSlug:  "stored-slug",
+			Project:     "metallb",
+			Version:     "4.18",
+			TopK:        topK,
+		}
+	}
+
+	BeforeEach(func() {
+		testAgent, mockDB, mockSlackBot, mockLLM = newTestAgent()
+	})
+
+	It("should store the number of sources of the thread", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(storedThread(0), true, nil)
+		mockDB.EXPECT().SetThreadTopK(threadTS, 8).Return(nil)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "🔎 The next answers in this thread are retrieved from the 8 most relevant sources").Return(nil)
+
+		Expect(mention("<@BOT123> topk 8")).To(Succeed())
+	})
+
+	It("should answer the next question with the stored number of sources", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(storedThread(8), true, nil)
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "metallb", "4.18", "stored-slug", "how do I configure BGP?").DoAndReturn(
+			func(ctx context.Context, _, _, _, _ string) (string, error) {
+				Expect(llm.TopKFrom(ctx)).To(Equal(8))
+				return "Create a BGPPeer", nil
+			})
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+
+		Expect(mention("<@BOT123> how do I configure BGP?")).To(Succeed())
+	})
+
+	It("should reject a number out of range", func() {
+		mockDB.EXPECT().SetThreadTopK(gomock.Any(), gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, usage).Return(nil).Times(4)
+
+		Expect(mention("<@BOT123> topk 0")).To(Succeed())
+		Expect(mention("<@BOT123> topk 21")).To(Succeed())
+		Expect(mention("<@BOT123> topk many")).To(Succeed())
+		Expect(mention("<@BOT123> topk")).To(Succeed())
+	})
+
+	It("should ask for an answer first in a thread without context", func() {
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil)
+		mockDB.EXPECT().SetThreadTopK(gomock.Any(), gomock.Any()).Times(0)
+		mockSlackBot.EXPECT().PostEphemeral(channel, user, "There is no answer in this thread yet, please use answer first").Return(nil)
+
+		Expect(mention("<@BOT123> topk 8")).To(Succeed())
+	})
+})
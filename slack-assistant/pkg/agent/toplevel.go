@@ -0,0 +1,67 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+)
+
+// ErrNoQuestion is returned when there is no message before the mention to answer, e.g. a mention at the top level
+// of the channel without a question
+var ErrNoQuestion = errors.New("no message to answer before the mention")
+
+// startsThread reports whether the mention is the root of its thread: a message at the top level of the channel,
+// without ThreadTimeStamp, or the parent of a thread, whose ThreadTimeStamp is its own TimeStamp
+func startsThread(event *slackevents.AppMentionEvent) bool {
+	return event.ThreadTimeStamp == "" || event.ThreadTimeStamp == event.TimeStamp
+}
+
+// isAnswerCommand reports whether the command answers the messages of the thread
+func isAnswerCommand(command string) bool {
+	switch strings.TrimSuffix(command, "!") {
+	case "answer", "answer-all":
+		return true
+	}
+	return false
+}
+
+type inlineQuestionKey struct{}
+
+// withInlineQuestion returns a context answering the question instead of the messages of the thread
+func withInlineQuestion(ctx context.Context, question string) context.Context {
+	return context.WithValue(ctx, inlineQuestionKey{}, question)
+}
+
+// inlineQuestionFrom returns the question set on the context with withInlineQuestion, empty when none was set
+func inlineQuestionFrom(ctx context.Context) string {
+	question, _ := ctx.Value(inlineQuestionKey{}).(string)
+	return question
+}
+
+// mentionQuestion returns the context of the command, answering the question written after the project and version
+// when the mention starts the thread, e.g. `@bot answer sriov 4.16 How do I create a VF?` at the top level of the
+// channel, as there is no message before it to answer
+func (a *Agent) mentionQuestion(ctx context.Context, event *slackevents.AppMentionEvent, command string, parameters []string) context.Context {
+	if !startsThread(event) || !isAnswerCommand(command) || len(parameters) < 5 {
+		return ctx
+	}
+	question := strings.TrimSpace(a.sanitize(strings.Join(parameters[4:], " ")))
+	if question == "" {
+		return ctx
+	}
+	return withInlineQuestion(ctx, question)
+}
+
+// noQuestion tells the thread how to ask the question when there is no message to answer
+func (a *Agent) noQuestion(channel, threadTS string) error {
+	fmt.Printf("⚠️ No question to answer in thread %s\n", threadTS)
+	if err := a.slackBot.PostMessage(channel, threadTS, i18n.T("answer.noQuestion")); err != nil {
+		fmt.Printf("❌ Failed to post error message: %v\n", err)
+	}
+	return ErrNoQuestion
+}
@@ -0,0 +1,110 @@
+package agent_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
+	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
+	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+)
+
+var _ = Describe("Top-level mentions", func() {
+	const (
+		channel   = "C1234567890"
+		user      = "U123456"
+		mentionTS = "1234567890.100000"
+		replyTS   = "1234567890.300000"
+	)
+
+	var (
+		mockDB       *databaseMock.MockInterface
+		mockSlackBot *slackbotMock.MockInterface
+		mockLLM      *llmMock.MockInterface
+		testAgent    *agent.Agent
+	)
+
+	mention := func(text, timestamp, threadTimestamp string) error {
+		return agent.AppMentionWorkItem{Event: &slackevents.AppMentionEvent{
+			User:            user,
+			Text:            text,
+			Channel:         channel,
+			TimeStamp:       timestamp,
+			ThreadTimeStamp: threadTimestamp,
+		}}.Process(context.Background(), testAgent)
+	}
+
+	// expectAnswer expects the question to be answered in the thread of the mention
+	expectAnswer := func(threadTS, question string) {
+		mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Searching for answer...").Return(nil)
+		mockDB.EXPECT().GetSlugForThread(threadTS).Return("", false, nil)
+		mockLLM.EXPECT().CreateThread(gomock.Any(), "sriov", "4.16").Return("new-slug", nil)
+		mockDB.EXPECT().CreateSlackThreadWithSlug(threadTS, "new-slug", "sriov", "4.16").Return(nil)
+		mockDB.EXPECT().SetLastQuestion(threadTS, question).Return(nil)
+		mockDB.EXPECT().GetThreadContext(threadTS).Return(nil, false, nil).AnyTimes()
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "new-slug", question).Return("Use the SriovNetworkNodePolicy", nil)
+		mockSlackBot.EXPECT().PostBlocks(channel, threadTS, gomock.Any()).Return(nil)
+	}
+
+	BeforeEach(func() {
+		testAgent, mockDB, mockSlackBot, mockLLM = newTestAgent()
+	})
+
+	It("should answer the question written in a new message and start a thread on it", func() {
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Times(0)
+		expectAnswer(mentionTS, "How do I create a VF?")
+
+		Expect(mention("<@BOT123> answer sriov 4.16 How do I create a VF?", mentionTS, "")).To(Succeed())
+	})
+
+	It("should answer the question of the parent message of a thread", func() {
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Times(0)
+		expectAnswer(mentionTS, "How do I create a VF?")
+
+		Expect(mention("<@BOT123> answer sriov 4.16 How do I create a VF?", mentionTS, mentionTS)).To(Succeed())
+	})
+
+	It("should ask for the question when a new message has none", func() {
+		mockSlackBot.EXPECT().PostMessage(channel, mentionTS, "Searching for answer...").Return(nil)
+		mockSlackBot.EXPECT().GetConversationReplies(&slack.GetConversationRepliesParameters{
+			ChannelID: channel,
+			Timestamp: mentionTS,
+			Inclusive: true,
+		}).Return([]slack.Message{
+			{Msg: slack.Msg{Text: "<@BOT123> answer sriov 4.16", User: user}},
+			{Msg: slack.Msg{Text: "Searching for answer...", User: "BOT123"}},
+		}, nil)
+		mockSlackBot.EXPECT().PostMessage(channel, mentionTS, "There is no question before your mention to answer, ask it after the project and version, e.g. `@bot answer sriov 4.16 How do I create a VF?`, or mention me in the thread of the question").Return(nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		Expect(mention("<@BOT123> answer sriov 4.16", mentionTS, "")).To(MatchError(agent.ErrNoQuestion))
+	})
+
+	It("should answer the message before a mention in a thread", func() {
+		mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return([]slack.Message{
+			{Msg: slack.Msg{Text: "How do I create a VF?", User: "U999"}},
+			{Msg: slack.Msg{Text: "<@BOT123> answer sriov 4.16 please", User: user}},
+			{Msg: slack.Msg{Text: "Searching for answer...", User: "BOT123"}},
+		}, nil)
+		expectAnswer(mentionTS, "How do I create a VF?")
+
+		Expect(mention("<@BOT123> answer sriov 4.16 please", replyTS, mentionTS)).To(Succeed())
+	})
+
+	It("should follow up in the thread started by a new message", func() {
+		threadContext := &database.SlackThreadToSlug{SlackThread: mentionTS, ThreadSlug: "new-slug", Project: "sriov", Version: "4.16"}
+		mockDB.EXPECT().GetThreadContext(mentionTS).Return(threadContext, true, nil).AnyTimes()
+		mockSlackBot.EXPECT().PostMessage(channel, mentionTS, "Searching for answer...").Return(nil)
+		mockLLM.EXPECT().SendMessageToChat(gomock.Any(), "sriov", "4.16", "new-slug", "and on 4.18?").Return("The same way", nil)
+		mockSlackBot.EXPECT().PostBlocks(channel, mentionTS, gomock.Any()).Return(nil)
+
+		Expect(mention("<@BOT123> and on 4.18?", replyTS, mentionTS)).To(Succeed())
+	})
+})
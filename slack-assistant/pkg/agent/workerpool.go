@@ -2,31 +2,95 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
 )
 
+var (
+	// ErrQueueFull is returned by Submit when the work queue has no room left
+	ErrQueueFull = errors.New("work queue is full")
+	// ErrPoolShuttingDown is returned by Submit when the worker pool is stopping
+	ErrPoolShuttingDown = errors.New("worker pool is shutting down")
+)
+
 // WorkItem represents a unit of work that can be processed by the worker pool
 type WorkItem interface {
-	Process(agent *Agent) error
+	Process(ctx context.Context, agent *Agent) error
 	String() string
 }
 
 // AppMentionWorkItem wraps an app mention event for processing
 type AppMentionWorkItem struct {
 	Event *slackevents.AppMentionEvent
+	// EnqueuedAt is when the mention was queued, the mentions that waited longer than the max queue wait are skipped
+	EnqueuedAt time.Time
 }
 
-func (w AppMentionWorkItem) Process(agent *Agent) error {
-	return agent.handleAppMentionEvent(w.Event)
+func (w AppMentionWorkItem) Process(ctx context.Context, agent *Agent) error {
+	_, err := w.Run(ctx, agent)
+	return err
+}
+
+// Run handles the mention and returns how its command was handled
+func (w AppMentionWorkItem) Run(ctx context.Context, agent *Agent) (*CommandResult, error) {
+	if agent.expired(w.EnqueuedAt) {
+		return &CommandResult{Outcome: CommandSkipped}, agent.skipExpiredMention(w.Event, w.EnqueuedAt)
+	}
+	result, err := agent.handleAppMentionEvent(ctx, w.Event)
+	return result, agent.threadGone(threadTimestamp(w.Event), err)
 }
 
 func (w AppMentionWorkItem) String() string {
 	return fmt.Sprintf("AppMention{User: %s, Channel: %s}", w.Event.User, w.Event.Channel)
 }
 
+// InteractionWorkItem wraps an interaction callback (button click) for processing
+type InteractionWorkItem struct {
+	Callback *slack.InteractionCallback
+}
+
+func (w InteractionWorkItem) Process(ctx context.Context, agent *Agent) error {
+	_, threadTS := interactionThread(w.Callback)
+	return agent.threadGone(threadTS, agent.handleInteraction(ctx, w.Callback))
+}
+
+func (w InteractionWorkItem) String() string {
+	return fmt.Sprintf("Interaction{User: %s, Channel: %s}", w.Callback.User.ID, w.Callback.Channel.ID)
+}
+
+// SlashCommandWorkItem wraps a slash command for processing
+type SlashCommandWorkItem struct {
+	Command *slack.SlashCommand
+}
+
+func (w SlashCommandWorkItem) Process(ctx context.Context, agent *Agent) error {
+	return agent.handleSlashCommand(ctx, w.Command)
+}
+
+func (w SlashCommandWorkItem) String() string {
+	return fmt.Sprintf("SlashCommand{User: %s, Channel: %s}", w.Command.UserID, w.Command.ChannelID)
+}
+
+// AppHomeOpenedWorkItem wraps an App Home opened event for processing
+type AppHomeOpenedWorkItem struct {
+	Event *slackevents.AppHomeOpenedEvent
+}
+
+func (w AppHomeOpenedWorkItem) Process(_ context.Context, agent *Agent) error {
+	return agent.handleAppHomeOpened(w.Event)
+}
+
+func (w AppHomeOpenedWorkItem) String() string {
+	return fmt.Sprintf("AppHomeOpened{User: %s}", w.Event.User)
+}
+
 // WorkerPool manages a pool of workers that process work items
 type WorkerPool struct {
 	workerCount int
@@ -35,6 +99,69 @@ type WorkerPool struct {
 	wg          sync.WaitGroup
 	ctx         context.Context
 	cancel      context.CancelFunc
+	commands    *commandStats
+	// queueMu guards the sends on the work queue against its close, stopped is set once it is closed
+	queueMu  sync.RWMutex
+	stopped  bool
+	stopOnce sync.Once
+}
+
+// WorkerPoolStats is a snapshot of the worker pool state
+type WorkerPoolStats struct {
+	Workers       int
+	QueueDepth    int
+	QueueCapacity int
+	// Commands counts the commands run by the workers by name and outcome
+	Commands map[CommandStatKey]uint64
+}
+
+// CommandStatKey identifies the commands counted in the worker pool stats
+type CommandStatKey struct {
+	Command string
+	Outcome CommandOutcome
+}
+
+// commandStats counts the command results recorded by the workers
+type commandStats struct {
+	mu     sync.Mutex
+	counts map[CommandStatKey]uint64
+}
+
+// statCommands are the mention commands counted under their own name
+var statCommands = map[string]bool{
+	"answer": true, "answer-all": true, "answer-multi": true, "inject": true, "export": true, "docs": true,
+	"debug": true, "whoami": true, "context": true, "alias": true, "replay": true, "backends": true, "backend": true,
+	"elaborate": true, "mode": true, "topk": true, "temperature": true, "strict": true, "memory": true, "cancel": true,
+	"pin": true, "unpin": true, "retry": true, "continue": true, "status": true, "refresh": true, "threads": true,
+	"correct": true, "history": true, "switch": true,
+}
+
+// statCommand returns the name the command is counted under, a mention that isn't a command is counted as a
+// follow-up question once it ran and as unknown when it got the usage, so the user input can't add counters
+func statCommand(result *CommandResult) string {
+	command := strings.TrimSuffix(result.Command, "!")
+	switch {
+	case statCommands[command]:
+		return command
+	case command != "" && result.Outcome != CommandUsage:
+		return followUpCommand
+	default:
+		return "unknown"
+	}
+}
+
+// record counts the result of a command under its normalized name
+func (s *commandStats) record(result *CommandResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts[CommandStatKey{Command: statCommand(result), Outcome: result.Outcome}]++
+}
+
+// snapshot returns a copy of the counts
+func (s *commandStats) snapshot() map[CommandStatKey]uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.counts)
 }
 
 // Worker represents a single worker in the pool
@@ -43,6 +170,7 @@ type Worker struct {
 	workQueue chan WorkItem
 	agent     *Agent
 	ctx       context.Context
+	commands  *commandStats
 }
 
 // NewWorkerPool creates a new worker pool with the specified number of workers
@@ -54,6 +182,7 @@ func NewWorkerPool(workerCount, queueSize int) *WorkerPool {
 		workers:     make([]Worker, workerCount),
 		ctx:         ctx,
 		cancel:      cancel,
+		commands:    &commandStats{counts: map[CommandStatKey]uint64{}},
 	}
 }
 
@@ -67,6 +196,7 @@ func (wp *WorkerPool) Start(agent *Agent) {
 			workQueue: wp.workQueue,
 			agent:     agent,
 			ctx:       wp.ctx,
+			commands:  wp.commands,
 		}
 		wp.workers[i] = worker
 
@@ -75,25 +205,55 @@ func (wp *WorkerPool) Start(agent *Agent) {
 	}
 }
 
-// Submit adds a work item to the queue for processing
-func (wp *WorkerPool) Submit(workItem WorkItem) {
+// Submit adds a work item to the queue for processing.
+// It returns ErrQueueFull or ErrPoolShuttingDown when the work item was dropped.
+func (wp *WorkerPool) Submit(workItem WorkItem) error {
+	// The queue is closed once the pool is stopped, never try to send on it
+	wp.queueMu.RLock()
+	defer wp.queueMu.RUnlock()
+	if wp.stopped || wp.ctx.Err() != nil {
+		fmt.Printf("❌ Worker pool is shutting down, cannot submit work: %s\n", workItem.String())
+		return ErrPoolShuttingDown
+	}
+
 	select {
 	case wp.workQueue <- workItem:
 		// Work item successfully queued
+		return nil
 	case <-wp.ctx.Done():
 		fmt.Printf("❌ Worker pool is shutting down, cannot submit work: %s\n", workItem.String())
+		return ErrPoolShuttingDown
 	default:
 		fmt.Printf("⚠️ Work queue is full, dropping work item: %s\n", workItem.String())
+		return ErrQueueFull
+	}
+}
+
+// Stats returns the number of workers, the current queue depth and capacity and the count of the commands run
+func (wp *WorkerPool) Stats() WorkerPoolStats {
+	return WorkerPoolStats{
+		Workers:       wp.workerCount,
+		QueueDepth:    len(wp.workQueue),
+		QueueCapacity: cap(wp.workQueue),
+		Commands:      wp.commands.snapshot(),
 	}
 }
 
-// Stop gracefully shuts down the worker pool
+// Stop gracefully shuts down the worker pool, it may be called more than once and returns once the workers exited
 func (wp *WorkerPool) Stop() {
-	fmt.Println("🛑 Stopping worker pool...")
-	wp.cancel()
-	close(wp.workQueue)
-	wp.wg.Wait()
-	fmt.Println("✅ Worker pool stopped")
+	wp.stopOnce.Do(func() {
+		fmt.Println("🛑 Stopping worker pool...")
+		wp.cancel()
+
+		// Submit never blocks on the queue, the running ones return before it is closed
+		wp.queueMu.Lock()
+		wp.stopped = true
+		close(wp.workQueue)
+		wp.queueMu.Unlock()
+
+		wp.wg.Wait()
+		fmt.Println("✅ Worker pool stopped")
+	})
 }
 
 // start begins the worker's processing loop
@@ -120,9 +280,22 @@ func (w *Worker) start(wg *sync.WaitGroup) {
 func (w *Worker) processWorkItem(workItem WorkItem) {
 	fmt.Printf("👷 Worker %d processing: %s\n", w.id, workItem.String())
 
-	if err := workItem.Process(w.agent); err != nil {
+	if err := w.process(workItem); err != nil {
 		fmt.Printf("❌ Worker %d failed to process %s: %v\n", w.id, workItem.String(), err)
 	} else {
 		fmt.Printf("✅ Worker %d completed: %s\n", w.id, workItem.String())
 	}
 }
+
+// process runs the work item, counting the result of the command it ran
+func (w *Worker) process(workItem WorkItem) error {
+	item, ok := workItem.(CommandWorkItem)
+	if !ok {
+		return workItem.Process(w.ctx, w.agent)
+	}
+	result, err := item.Run(w.ctx, w.agent)
+	if result != nil {
+		w.commands.record(result)
+	}
+	return err
+}
@@ -1,6 +1,7 @@
 package agent_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sync"
@@ -13,9 +14,11 @@ import (
 	"go.uber.org/mock/gomock"
 
 	"github.com/SchSeba/slack-ai-assistant/pkg/agent"
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
 	databaseMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/database"
 	llmMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/llm"
 	slackbotMock "github.com/SchSeba/slack-ai-assistant/pkg/mocks/slack-bot"
+	slackbot "github.com/SchSeba/slack-ai-assistant/pkg/slack-bot"
 )
 
 // TestWorkItem implements the WorkItem interface for testing
@@ -25,7 +28,7 @@ type TestWorkItem struct {
 	ProcessFunc func(*agent.Agent) error
 }
 
-func (t TestWorkItem) Process(agentProcess *agent.Agent) error {
+func (t TestWorkItem) Process(_ context.Context, agentProcess *agent.Agent) error {
 	if t.ProcessFunc != nil {
 		return t.ProcessFunc(agentProcess)
 	}
@@ -57,8 +60,10 @@ var _ = Describe("WorkerPool", func() {
 
 		appMentionChannel := make(chan *slackevents.AppMentionEvent, 10)
 		slashCommandChannel := make(chan *slack.SlashCommand, 10)
+		interactionChannel := make(chan *slack.InteractionCallback, 10)
+		appHomeChannel := make(chan *slackevents.AppHomeOpenedEvent, 10)
 
-		testAgent = agent.NewAgent(mockDB, mockSlackBot, mockLLM, appMentionChannel, slashCommandChannel, 2)
+		testAgent = agent.NewAgent(mockDB, mockSlackBot, mockLLM, appMentionChannel, slashCommandChannel, interactionChannel, appHomeChannel, 2, 10)
 		workerPool = agent.NewWorkerPool(2, 10)
 	})
 
@@ -77,6 +82,17 @@ var _ = Describe("WorkerPool", func() {
 		})
 	})
 
+	Describe("Stats", func() {
+		It("should report the workers and the queue depth and capacity", func() {
+			Expect(workerPool.Submit(TestWorkItem{ID: "queued"})).To(Succeed())
+
+			stats := workerPool.Stats()
+			Expect(stats.Workers).To(Equal(2))
+			Expect(stats.QueueDepth).To(Equal(1))
+			Expect(stats.QueueCapacity).To(Equal(10))
+		})
+	})
+
 	Describe("Start and Stop", func() {
 		It("should start workers and stop gracefully", func() {
 			workerPool.Start(testAgent)
@@ -100,6 +116,28 @@ var _ = Describe("WorkerPool", func() {
 
 			workerPool = nil // Prevent double stop in AfterEach
 		})
+
+		It("should allow stopping twice", func() {
+			workerPool.Start(testAgent)
+
+			Expect(workerPool.Stop).NotTo(Panic())
+			Expect(workerPool.Stop).NotTo(Panic())
+		})
+
+		It("should allow stopping concurrently", func() {
+			workerPool.Start(testAgent)
+
+			var wg sync.WaitGroup
+			for range 5 {
+				wg.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+					workerPool.Stop()
+				}()
+			}
+			wg.Wait()
+		})
 	})
 
 	Describe("Submit", func() {
@@ -117,7 +155,7 @@ var _ = Describe("WorkerPool", func() {
 				},
 			}
 
-			workerPool.Submit(workItem)
+			Expect(workerPool.Submit(workItem)).To(Succeed())
 
 			select {
 			case <-processed:
@@ -137,7 +175,7 @@ var _ = Describe("WorkerPool", func() {
 				},
 			}
 
-			workerPool.Submit(workItem)
+			Expect(workerPool.Submit(workItem)).To(Succeed())
 
 			select {
 			case <-processed:
@@ -163,7 +201,7 @@ var _ = Describe("WorkerPool", func() {
 						return nil
 					},
 				}
-				workerPool.Submit(workItem)
+				Expect(workerPool.Submit(workItem)).To(Succeed())
 			}
 
 			// Wait for all items to be processed or timeout
@@ -216,12 +254,113 @@ var _ = Describe("WorkerPool", func() {
 
 			// Set up mock expectations
 			mockSlackBot.EXPECT().GetBotUser().Return(botUser).AnyTimes()
+			mockSlackBot.EXPECT().GetUserName(gomock.Any()).Return("alice", nil).AnyTimes()
 			mockSlackBot.EXPECT().GetConversationReplies(gomock.Any()).Return(nil, nil).AnyTimes() // Return nil to simulate API unavailable
-			mockSlackBot.EXPECT().PostMessage(gomock.Any(), gomock.Any(), "Please use one of the following commands (answer,elaborate,inject)").Return(nil)
+			mockSlackBot.EXPECT().PostEphemeral(gomock.Any(), gomock.Any(), "Please use one of the following commands (answer,answer-multi,cancel,continue,correct,docs,elaborate,export,history,inject,memory,mode,pin,retry,status,strict,switch,temperature,topk,unpin)").Return(nil)
+			mockDB.EXPECT().GetThreadContext(gomock.Any()).Return(nil, false, nil)
+			mockDB.EXPECT().RecordCommand(gomock.Any()).Return(nil)
 
-			err := workItem.Process(testAgent)
+			err := workItem.Process(context.Background(), testAgent)
 			Expect(err).NotTo(HaveOccurred()) // The error is handled internally and a help message is posted
 		})
+
+		It("should record an audit entry for the processed command", func() {
+			botUser := &slack.AuthTestResponse{
+				User:   "slack-ai-assistant",
+				UserID: "BOT123",
+			}
+			mockSlackBot.EXPECT().GetBotUser().Return(botUser).AnyTimes()
+			mockSlackBot.EXPECT().GetUserName(gomock.Any()).Return("alice", nil).AnyTimes()
+			mockSlackBot.EXPECT().PostEphemeral(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("post failed"))
+			mockDB.EXPECT().GetThreadContext(gomock.Any()).Return(nil, false, nil)
+			mockDB.EXPECT().RecordCommand(gomock.Any()).DoAndReturn(func(audit *database.CommandAudit) error {
+				Expect(audit.User).To(Equal("U123456"))
+				Expect(audit.Channel).To(Equal("C1234567890"))
+				Expect(audit.SlackThread).To(Equal("1234567890.123456"))
+				Expect(audit.Command).To(Equal("invalid"))
+				Expect(audit.Success).To(BeFalse())
+				Expect(audit.Error).To(Equal("post failed"))
+				return nil
+			})
+
+			err := workItem.Process(context.Background(), testAgent)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should not fail the command when recording the audit fails", func() {
+			mockSlackBot.EXPECT().GetBotUser().Return(nil)
+			mockSlackBot.EXPECT().GetUserName(gomock.Any()).Return("alice", nil).AnyTimes()
+			mockSlackBot.EXPECT().PostEphemeral(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			mockDB.EXPECT().GetThreadContext(gomock.Any()).Return(nil, false, nil)
+			mockDB.EXPECT().RecordCommand(gomock.Any()).Return(errors.New("database error"))
+
+			err := workItem.Process(context.Background(), testAgent)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("InteractionWorkItem", func() {
+		var (
+			channel  = "C1234567890"
+			threadTS = "1234567890.123456"
+		)
+
+		newCallback := func(actionID string, sources []string) *slack.InteractionCallback {
+			callback := &slack.InteractionCallback{
+				Type:    slack.InteractionTypeBlockActions,
+				User:    slack.User{ID: "U123456"},
+				Channel: slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: channel}}},
+				Message: slack.Message{Msg: slack.Msg{
+					Timestamp:       "1234567890.999999",
+					ThreadTimestamp: threadTS,
+					Blocks:          slack.Blocks{BlockSet: slackbot.BuildAnswerBlocks("", "AI response", sources, "")},
+				}},
+			}
+			callback.ActionCallback.BlockActions = []*slack.BlockAction{{ActionID: actionID}}
+			return callback
+		}
+
+		BeforeEach(func() {
+			mockSlackBot.EXPECT().GetUserName("U123456").Return("alice", nil).AnyTimes()
+		})
+
+		It("should implement WorkItem interface correctly", func() {
+			str := agent.InteractionWorkItem{Callback: newCallback(slackbot.ActionElaborate, nil)}.String()
+			Expect(str).To(ContainSubstring("Interaction"))
+			Expect(str).To(ContainSubstring("U123456"))
+			Expect(str).To(ContainSubstring(channel))
+		})
+
+		It("should dispatch the elaborate button to the elaborate logic", func() {
+			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Elaborating...").Return(nil)
+			mockDB.EXPECT().GetElaborateSlugForThread(threadTS).Return("", false, nil)
+			mockLLM.EXPECT().CreateThread(gomock.Any(), "elaborate", "").Return("elaborate-thread-slug", nil)
+			mockDB.EXPECT().SetElaborateSlugForThread(threadTS, "elaborate-thread-slug").Return(nil)
+			mockLLM.EXPECT().Elaborate(gomock.Any(), "elaborate", "elaborate-thread-slug", "AI response").Return("Elaborated response", nil)
+			mockSlackBot.EXPECT().PostMessage(channel, threadTS, "Elaborated response").Return(nil)
+
+			workItem := agent.InteractionWorkItem{Callback: newCallback(slackbot.ActionElaborate, nil)}
+			Expect(workItem.Process(context.Background(), testAgent)).To(Succeed())
+		})
+
+		It("should dispatch the sources button to the sources logic", func() {
+			mockSlackBot.EXPECT().PostEphemeral(channel, "U123456", "Sources:\n• metallb-faq.md\n• metallb-api.md").Return(nil)
+
+			workItem := agent.InteractionWorkItem{Callback: newCallback(slackbot.ActionSources, []string{"metallb-faq.md", "metallb-api.md"})}
+			Expect(workItem.Process(context.Background(), testAgent)).To(Succeed())
+		})
+
+		It("should tell the user when the answer has no sources", func() {
+			mockSlackBot.EXPECT().PostEphemeral(channel, "U123456", "No sources were returned for this answer").Return(nil)
+
+			workItem := agent.InteractionWorkItem{Callback: newCallback(slackbot.ActionSources, nil)}
+			Expect(workItem.Process(context.Background(), testAgent)).To(Succeed())
+		})
+
+		It("should return an error for unknown actions", func() {
+			workItem := agent.InteractionWorkItem{Callback: newCallback("unknown", nil)}
+			Expect(workItem.Process(context.Background(), testAgent)).NotTo(Succeed())
+		})
 	})
 
 	Describe("Queue Management", func() {
@@ -242,13 +381,14 @@ var _ = Describe("WorkerPool", func() {
 			}
 
 			// Fill the queue beyond capacity
-			smallPool.Submit(blockingItem)
-			smallPool.Submit(blockingItem)
+			Expect(smallPool.Submit(blockingItem)).To(Succeed())
+			Expect(smallPool.Submit(blockingItem)).To(Succeed())
 
 			// This should not block - the item should be dropped
 			done := make(chan bool)
 			go func() {
-				smallPool.Submit(TestWorkItem{ID: "overflow"})
+				//nolint:errcheck // only checking that submit doesn't block
+				_ = smallPool.Submit(TestWorkItem{ID: "overflow"})
 				done <- true
 			}()
 
@@ -259,5 +399,52 @@ var _ = Describe("WorkerPool", func() {
 				Fail("Submit blocked when queue was full")
 			}
 		})
+
+		It("should report dropped work items when the queue is full", func() {
+			// Workers are not started, so nothing drains the queue
+			fullPool := agent.NewWorkerPool(1, 2)
+			defer fullPool.Stop()
+
+			Expect(fullPool.Submit(TestWorkItem{ID: "first"})).To(Succeed())
+			Expect(fullPool.Submit(TestWorkItem{ID: "second"})).To(Succeed())
+			Expect(fullPool.Submit(TestWorkItem{ID: "overflow"})).To(MatchError(agent.ErrQueueFull))
+		})
+
+		It("should report accepted work items", func() {
+			workerPool.Start(testAgent)
+			Expect(workerPool.Submit(TestWorkItem{ID: "accepted"})).To(Succeed())
+		})
+
+		It("should report dropped work items when the pool is shutting down", func() {
+			stoppedPool := agent.NewWorkerPool(1, 2)
+			stoppedPool.Start(testAgent)
+			stoppedPool.Stop()
+
+			Expect(stoppedPool.Submit(TestWorkItem{ID: "late"})).To(MatchError(agent.ErrPoolShuttingDown))
+		})
+
+		It("should never panic when submitting while the pool stops", func() {
+			stoppingPool := agent.NewWorkerPool(1, 100)
+			stoppingPool.Start(testAgent)
+
+			var wg sync.WaitGroup
+			for i := range 10 {
+				wg.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+					for j := range 50 {
+						Expect(func() {
+							//nolint:errcheck // only the absence of panic matters
+							_ = stoppingPool.Submit(TestWorkItem{ID: fmt.Sprintf("racing-%d-%d", i, j)})
+						}).NotTo(Panic())
+					}
+				}()
+			}
+			stoppingPool.Stop()
+			wg.Wait()
+
+			Expect(stoppingPool.Submit(TestWorkItem{ID: "late"})).To(MatchError(agent.ErrPoolShuttingDown))
+		})
 	})
 })
@@ -2,21 +2,130 @@
 package database
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // SlackThreadToSlug represents a table with slackThread and threadSlug as composite primary key
 type SlackThreadToSlug struct {
-	SlackThread string `gorm:"primaryKey"`
-	ThreadSlug  string
+	SlackThread   string `gorm:"primaryKey"`
+	ThreadSlug    string
+	Project       string
+	Version       string
+	ElaborateSlug string
+	// LastQuestion is the last question answered in the thread, asked again by retry
+	LastQuestion string
+	// Mode is the LLM answer mode chosen for the thread with the mode command, empty for the default mode
+	Mode string
+	// TopK is the number of sources retrieved for the answers of the thread, 0 for the backend default
+	TopK int
+	// Truncated is true when the last answer of the thread was cut off, so continue can ask for the rest
+	Truncated bool
+	// Temperature is the LLM temperature chosen for the answers of the thread, nil for the default of each command
+	Temperature *float64
+	// MemoryOff is true when each question of the thread is answered in a new LLM thread, without the previous ones
+	MemoryOff bool
+	// Strict is true when the answers of the thread only come from the retrieved documents, not the model knowledge
+	Strict bool
+}
+
+// CommandAudit represents a single command issued to the bot
+type CommandAudit struct {
+	ID          uint `gorm:"primaryKey"`
+	User        string
+	Channel     string
+	SlackThread string
+	Command     string
+	Args        string
+	Success     bool
+	Error       string
+	CreatedAt   time.Time `gorm:"index"`
+}
+
+// AskedQuestion is the last question answered in a thread, from the audit of its latest answer
+type AskedQuestion struct {
+	SlackThread string
+	User        string
+	Project     string
+	Version     string
+	Question    string
+	AskedAt     time.Time
+}
+
+// QueuedWork represents a work item persisted by the durable work queue
+type QueuedWork struct {
+	ID         uint `gorm:"primaryKey"`
+	Kind       string
+	Payload    string
+	InProgress bool
+	CreatedAt  time.Time
+}
+
+// InjectedDocument records a document added to a knowledge base by inject
+type InjectedDocument struct {
+	ID          uint   `gorm:"primaryKey"`
+	SlackThread string `gorm:"index"`
+	Project     string
+	Version     string
+	Content     string
+	Source      string
+	CreatedAt   time.Time
+}
+
+// Feedback records a correction of an answer given by a user with the correct command
+type Feedback struct {
+	ID          uint   `gorm:"primaryKey"`
+	SlackThread string `gorm:"index"`
+	User        string
+	Project     string
+	Version     string
+	// Question is the last question answered in the thread
+	Question string
+	// Answer is the answer of the bot that was corrected, empty when it wasn't found in the thread
+	Answer     string
+	Correction string
+	CreatedAt  time.Time
 }
 
 // Interface to abstracts database operations
 type Interface interface {
 	AutoMigrate() error
-	CreateSlackThreadWithSlug(thread string, slug string) error
+	CreateSlackThreadWithSlug(thread, slug, project, version string) error
 	GetSlugForThread(slackThread string) (string, bool, error)
+	GetThreadContext(slackThread string) (*SlackThreadToSlug, bool, error)
+	UpdateThreadContext(slackThread, slug, project, version string) error
+	SetElaborateSlugForThread(slackThread, slug string) error
+	SetLastQuestion(slackThread, question string) error
+	SetThreadMode(slackThread, mode string) error
+	SetThreadTopK(slackThread string, topK int) error
+	SetThreadTruncated(slackThread string, truncated bool) error
+	SetThreadTemperature(slackThread string, temperature float64) error
+	SetThreadMemoryOff(slackThread string, off bool) error
+	SetThreadStrict(slackThread string, strict bool) error
+	GetElaborateSlugForThread(slackThread string) (string, bool, error)
+	DeleteThread(slackThread string) error
+	ListThreads(limit, offset int) ([]SlackThreadToSlug, error)
+	RecordCommand(audit *CommandAudit) error
+	GetRecentCommands(limit int) ([]CommandAudit, error)
+	GetRecentCommandsForUser(user string, limit int) ([]CommandAudit, error)
+	ListAskedQuestions(channel string, commands []string, limit, offset int) ([]AskedQuestion, error)
+	EnqueueWork(kind, payload string) (uint, error)
+	MarkWorkInProgress(id uint) error
+	CompleteWork(id uint) error
+	GetQueuedWork() ([]QueuedWork, error)
+	RecordInjection(document *InjectedDocument) error
+	GetInjectedDocuments(slackThread string) ([]InjectedDocument, error)
+	RecordFeedback(feedback *Feedback) error
+	GetFeedback(slackThread string) ([]Feedback, error)
+	WithTransaction(fn func(tx Interface) error) error
 	Close() error
 }
 
@@ -25,8 +134,19 @@ type Database struct {
 	db *gorm.DB
 }
 
-// NewDatabase initializes a new sqlite database connection
-func NewDatabase(dsn string) (*Database, error) {
+// DefaultPragmas are the settings of every sqlite connection: WAL lets the reads run during a write, the busy timeout
+// (in milliseconds) makes a write wait for the lock instead of failing with "database is locked", and the immediate
+// transactions take the write lock when they begin so two transactions reading before writing can't deadlock
+var DefaultPragmas = []string{"journal_mode=WAL", "busy_timeout=5000", "txlock=immediate"}
+
+// NewDatabase initializes a new sqlite database connection with DefaultPragmas, the name=value pragmas given
+// override or extend them, e.g. synchronous=NORMAL. They are the go-sqlite3 connection parameters without the underscore.
+func NewDatabase(dsn string, pragmas ...string) (*Database, error) {
+	dsn, err := withPragmas(dsn, append(slices.Clone(DefaultPragmas), pragmas...))
+	if err != nil {
+		return nil, err
+	}
+
 	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
 	if err != nil {
 		return nil, err
@@ -34,14 +154,58 @@ func NewDatabase(dsn string) (*Database, error) {
 	return &Database{db: db}, nil
 }
 
-// AutoMigrate migrates the SlackThread schema
-func (g *Database) AutoMigrate() error {
-	return g.db.AutoMigrate(&SlackThreadToSlug{})
+// withPragmas adds the pragmas to the parameters of the DSN, so every connection of the pool is opened with them,
+// the last value of a pragma wins
+func withPragmas(dsn string, pragmas []string) (string, error) {
+	params := url.Values{}
+	for _, pragma := range pragmas {
+		name, value, found := strings.Cut(pragma, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !found || !isPragmaName(name) || value == "" {
+			return "", fmt.Errorf("invalid sqlite pragma %q, expected name=value", pragma)
+		}
+		params.Set("_"+name, value)
+	}
+	if len(params) == 0 {
+		return dsn, nil
+	}
+
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	return dsn + separator + params.Encode(), nil
+}
+
+// isPragmaName reports whether the name only has letters and underscores like the sqlite pragmas
+func isPragmaName(name string) bool {
+	return name != "" && strings.Trim(name, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ_") == ""
+}
+
+// Pragma returns the current value of the sqlite pragma, e.g. journal_mode
+func (g *Database) Pragma(name string) (string, error) {
+	if !isPragmaName(name) {
+		return "", fmt.Errorf("invalid sqlite pragma %q", name)
+	}
+	var value string
+	if err := g.db.Raw("PRAGMA " + name).Scan(&value).Error; err != nil {
+		return "", err
+	}
+	return value, nil
 }
 
-// CreateSlackThreadWithSlug inserts a new SlackThread record
-func (g *Database) CreateSlackThreadWithSlug(thread, slug string) error {
-	return g.db.Create(&SlackThreadToSlug{SlackThread: thread, ThreadSlug: slug}).Error
+// CreateSlackThreadWithSlug inserts a new SlackThread record with the project and version it was created for,
+// a record that only has an elaborate slug so far is completed instead
+func (g *Database) CreateSlackThreadWithSlug(thread, slug, project, version string) error {
+	result := g.db.Model(&SlackThreadToSlug{}).Where("slack_thread = ? AND thread_slug = ?", thread, "").
+		Updates(map[string]interface{}{"thread_slug": slug, "project": project, "version": version})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+	return g.db.Create(&SlackThreadToSlug{SlackThread: thread, ThreadSlug: slug, Project: project, Version: version}).Error
 }
 
 // GetSlugForThread retrieves a SlackThread by composite key
@@ -56,9 +220,251 @@ func (g *Database) GetSlugForThread(slackThread string) (string, bool, error) {
 		}
 		return "", false, result.Error
 	}
+	// The record may only hold the elaborate slug of the thread
+	if thread.ThreadSlug == "" {
+		return "", false, nil
+	}
 	return thread.ThreadSlug, true, nil
 }
 
+// GetThreadContext retrieves the slug, project and version stored for a SlackThread
+func (g *Database) GetThreadContext(slackThread string) (*SlackThreadToSlug, bool, error) {
+	var thread SlackThreadToSlug
+	result := g.db.First(&thread, "slack_thread = ?", slackThread)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, false, nil
+		}
+		return nil, false, result.Error
+	}
+	return &thread, true, nil
+}
+
+// UpdateThreadContext replaces the slug, project and version stored for a SlackThread
+func (g *Database) UpdateThreadContext(slackThread, slug, project, version string) error {
+	result := g.db.Model(&SlackThreadToSlug{}).Where("slack_thread = ?", slackThread).
+		Updates(map[string]interface{}{"thread_slug": slug, "project": project, "version": version})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SetLastQuestion stores the last question answered in a SlackThread, the answer of a new question isn't truncated yet
+func (g *Database) SetLastQuestion(slackThread, question string) error {
+	result := g.db.Model(&SlackThreadToSlug{}).Where("slack_thread = ?", slackThread).
+		Updates(map[string]interface{}{"last_question": question, "truncated": false})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SetThreadMode stores the LLM answer mode of a SlackThread
+func (g *Database) SetThreadMode(slackThread, mode string) error {
+	result := g.db.Model(&SlackThreadToSlug{}).Where("slack_thread = ?", slackThread).Update("mode", mode)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SetThreadTopK stores the number of sources retrieved for the answers of a SlackThread
+func (g *Database) SetThreadTopK(slackThread string, topK int) error {
+	result := g.db.Model(&SlackThreadToSlug{}).Where("slack_thread = ?", slackThread).Update("top_k", topK)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SetThreadTemperature stores the LLM temperature of the answers of a SlackThread
+func (g *Database) SetThreadTemperature(slackThread string, temperature float64) error {
+	result := g.db.Model(&SlackThreadToSlug{}).Where("slack_thread = ?", slackThread).Update("temperature", temperature)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SetThreadMemoryOff stores whether each question of a SlackThread is answered without the previous ones
+func (g *Database) SetThreadMemoryOff(slackThread string, off bool) error {
+	result := g.db.Model(&SlackThreadToSlug{}).Where("slack_thread = ?", slackThread).Update("memory_off", off)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SetThreadStrict stores whether the answers of a SlackThread only come from the retrieved documents
+func (g *Database) SetThreadStrict(slackThread string, strict bool) error {
+	result := g.db.Model(&SlackThreadToSlug{}).Where("slack_thread = ?", slackThread).Update("strict", strict)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SetThreadTruncated stores whether the last answer of a SlackThread was cut off
+func (g *Database) SetThreadTruncated(slackThread string, truncated bool) error {
+	result := g.db.Model(&SlackThreadToSlug{}).Where("slack_thread = ?", slackThread).Update("truncated", truncated)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SetElaborateSlugForThread stores the slug of the elaborate thread used by a SlackThread
+func (g *Database) SetElaborateSlugForThread(slackThread, slug string) error {
+	return g.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "slack_thread"}},
+		DoUpdates: clause.AssignmentColumns([]string{"elaborate_slug"}),
+	}).Create(&SlackThreadToSlug{SlackThread: slackThread, ElaborateSlug: slug}).Error
+}
+
+// GetElaborateSlugForThread retrieves the slug of the elaborate thread used by a SlackThread
+func (g *Database) GetElaborateSlugForThread(slackThread string) (string, bool, error) {
+	var thread SlackThreadToSlug
+	result := g.db.First(&thread, "slack_thread = ?", slackThread)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return "", false, nil
+		}
+		return "", false, result.Error
+	}
+	if thread.ElaborateSlug == "" {
+		return "", false, nil
+	}
+	return thread.ElaborateSlug, true, nil
+}
+
+// DeleteThread removes the slugs, project and version stored for a SlackThread, deleting a missing thread is not an error
+func (g *Database) DeleteThread(slackThread string) error {
+	return g.db.Delete(&SlackThreadToSlug{}, "slack_thread = ?", slackThread).Error
+}
+
+// ListThreads returns a page of the stored SlackThreads, newest first, a limit of 0 or less returns every thread
+func (g *Database) ListThreads(limit, offset int) ([]SlackThreadToSlug, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	var threads []SlackThreadToSlug
+	result := g.db.Order("slack_thread desc").Limit(limit).Offset(offset).Find(&threads)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return threads, nil
+}
+
+// RecordCommand inserts a new CommandAudit record
+func (g *Database) RecordCommand(audit *CommandAudit) error {
+	return g.db.Create(audit).Error
+}
+
+// GetRecentCommands returns the most recent CommandAudit records, newest first
+func (g *Database) GetRecentCommands(limit int) ([]CommandAudit, error) {
+	var audits []CommandAudit
+	result := g.db.Order("created_at desc").Order("id desc").Limit(limit).Find(&audits)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return audits, nil
+}
+
+// GetRecentCommandsForUser returns the most recent CommandAudit records of a user, newest first
+func (g *Database) GetRecentCommandsForUser(user string, limit int) ([]CommandAudit, error) {
+	var audits []CommandAudit
+	result := g.db.Where("user = ?", user).Order("created_at desc").Order("id desc").Limit(limit).Find(&audits)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return audits, nil
+}
+
+// ListAskedQuestions returns a page of the questions answered in the channel, newest first, from the successful audits
+// of the commands, each thread is listed once with its last question and the threads without one are skipped
+func (g *Database) ListAskedQuestions(channel string, commands []string, limit, offset int) ([]AskedQuestion, error) {
+	if len(commands) == 0 {
+		return nil, nil
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	latest := g.db.Model(&CommandAudit{}).Select("MAX(id)").
+		Where("channel = ? AND command IN ? AND success", channel, commands).Group("slack_thread")
+
+	var questions []AskedQuestion
+	result := g.db.Table("command_audits AS a").
+		Select("a.slack_thread, a.user, a.created_at AS asked_at, t.project, t.version, t.last_question AS question").
+		Joins("JOIN slack_thread_to_slugs AS t ON t.slack_thread = a.slack_thread").
+		Where("a.id IN (?) AND t.last_question <> ''", latest).
+		Order("a.created_at desc").Order("a.id desc").
+		Limit(limit).Offset(offset).
+		Scan(&questions)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return questions, nil
+}
+
+// EnqueueWork persists a new QueuedWork record and returns its ID
+func (g *Database) EnqueueWork(kind, payload string) (uint, error) {
+	work := &QueuedWork{Kind: kind, Payload: payload}
+	if err := g.db.Create(work).Error; err != nil {
+		return 0, err
+	}
+	return work.ID, nil
+}
+
+// MarkWorkInProgress flags a QueuedWork record as being processed
+func (g *Database) MarkWorkInProgress(id uint) error {
+	return g.db.Model(&QueuedWork{}).Where("id = ?", id).Update("in_progress", true).Error
+}
+
+// CompleteWork deletes a processed QueuedWork record
+func (g *Database) CompleteWork(id uint) error {
+	return g.db.Delete(&QueuedWork{}, id).Error
+}
+
+// GetQueuedWork returns every QueuedWork record that was not completed, oldest first
+func (g *Database) GetQueuedWork() ([]QueuedWork, error) {
+	var work []QueuedWork
+	result := g.db.Order("id asc").Find(&work)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return work, nil
+}
+
 // Close closes the database connection (noop for gorm v2, but included for interface)
 func (g *Database) Close() error {
 	sqlDB, err := g.db.DB()
@@ -67,3 +473,42 @@ func (g *Database) Close() error {
 	}
 	return sqlDB.Close()
 }
+
+// RecordInjection inserts a new InjectedDocument record
+func (g *Database) RecordInjection(document *InjectedDocument) error {
+	return g.db.Create(document).Error
+}
+
+// GetInjectedDocuments returns the InjectedDocument records of a SlackThread, oldest first
+func (g *Database) GetInjectedDocuments(slackThread string) ([]InjectedDocument, error) {
+	var documents []InjectedDocument
+	result := g.db.Where("slack_thread = ?", slackThread).Order("id").Find(&documents)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return documents, nil
+}
+
+// RecordFeedback inserts a new Feedback record
+func (g *Database) RecordFeedback(feedback *Feedback) error {
+	return g.db.Create(feedback).Error
+}
+
+// GetFeedback returns the Feedback records of a SlackThread, oldest first
+func (g *Database) GetFeedback(slackThread string) ([]Feedback, error) {
+	var feedback []Feedback
+	result := g.db.Where("slack_thread = ?", slackThread).Order("id").Find(&feedback)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return feedback, nil
+}
+
+// WithTransaction runs fn with a database bound to a transaction, committed when fn succeeds and rolled back when it
+// returns an error or panics. The transaction database must not be closed or used after fn returns. SQLite is locked
+// until fn returns, so fn must not block, slow work like an LLM call must run before or after the transaction.
+func (g *Database) WithTransaction(fn func(tx Interface) error) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&Database{db: tx})
+	})
+}
@@ -5,9 +5,21 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"go.uber.org/goleak"
 )
 
 func TestDatabase(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Database Suite")
 }
+
+// Fail any spec that leaves goroutines running after its teardown
+var ignoreGoroutines goleak.Option
+
+var _ = BeforeEach(func() {
+	ignoreGoroutines = goleak.IgnoreCurrent()
+})
+
+var _ = AfterEach(func() {
+	goleak.VerifyNone(GinkgoT(), ignoreGoroutines)
+})
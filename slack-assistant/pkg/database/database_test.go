@@ -1,8 +1,10 @@
 package database_test
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -94,53 +96,87 @@ var _ = Describe("Database", func() {
 		})
 	})
 
-	Describe("CreateSlackThreadWithSlug", func() {
-		Context("when creating a new slack thread record", func() {
-			It("should create the record successfully", func() {
-				err := db.CreateSlackThreadWithSlug("thread123", "slug456")
-				Expect(err).NotTo(HaveOccurred())
-			})
+	Describe("Pragmas", func() {
+		It("should open the database in WAL mode", func() {
+			mode, err := db.Pragma("journal_mode")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mode).To(Equal("wal"))
 
-			It("should allow creating multiple different records", func() {
-				err := db.CreateSlackThreadWithSlug("thread1", "slug1")
-				Expect(err).NotTo(HaveOccurred())
+			timeout, err := db.Pragma("busy_timeout")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(timeout).To(Equal("5000"))
+		})
 
-				err = db.CreateSlackThreadWithSlug("thread2", "slug2")
-				Expect(err).NotTo(HaveOccurred())
-			})
+		It("should apply the configured pragmas over the defaults", func() {
+			Expect(db.Close()).To(Succeed())
 
-			It("should fail when creating duplicate slack thread", func() {
-				err := db.CreateSlackThreadWithSlug("duplicate_thread", "slug1")
-				Expect(err).NotTo(HaveOccurred())
+			var err error
+			db, err = database.NewDatabase(dbPath, "busy_timeout=1000", "synchronous=OFF")
+			Expect(err).NotTo(HaveOccurred())
 
-				err = db.CreateSlackThreadWithSlug("duplicate_thread", "slug2")
-				Expect(err).To(HaveOccurred())
-			})
+			Expect(db.Pragma("busy_timeout")).To(Equal("1000"))
+			Expect(db.Pragma("synchronous")).To(Equal("0"))
+			Expect(db.Pragma("journal_mode")).To(Equal("wal"))
 		})
-	})
 
-	Describe("GetSlugForThread", func() {
-		Context("when retrieving an existing thread", func() {
-			BeforeEach(func() {
-				err := db.CreateSlackThreadWithSlug("existing_thread", "existing_slug")
-				Expect(err).NotTo(HaveOccurred())
-			})
+		It("should reject a malformed pragma", func() {
+			for _, pragma := range []string{"synchronous", "=OFF", "synchronous=", "sync-hronous=OFF"} {
+				testDB, err := database.NewDatabase(filepath.Join(tmpDir, "invalid.db"), pragma)
+				Expect(err).To(MatchError(ContainSubstring("invalid sqlite pragma")), pragma)
+				Expect(testDB).To(BeNil())
+			}
+		})
 
-			It("should return the correct slug and found=true", func() {
-				slug, found, err := db.GetSlugForThread("existing_thread")
-				Expect(err).NotTo(HaveOccurred())
-				Expect(found).To(BeTrue())
-				Expect(slug).To(Equal("existing_slug"))
-			})
+		It("should not fail concurrent transactions reading before writing", func() {
+			var (
+				wg   sync.WaitGroup
+				mu   sync.Mutex
+				errs []error
+			)
+			for worker := range 10 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := range 20 {
+						thread := fmt.Sprintf("thread-%d-%d", worker, i)
+						err := db.WithTransaction(func(tx database.Interface) error {
+							if _, _, err := tx.GetThreadContext("other"); err != nil {
+								return err
+							}
+							if err := tx.CreateSlackThreadWithSlug(thread, "slug", "sriov", "4.16"); err != nil {
+								return err
+							}
+							return tx.RecordCommand(&database.CommandAudit{User: "U123", Command: "answer", SlackThread: thread})
+						})
+						if err != nil {
+							mu.Lock()
+							errs = append(errs, err)
+							mu.Unlock()
+						}
+					}
+				}()
+			}
+			wg.Wait()
+
+			Expect(errs).To(BeEmpty())
 		})
+	})
 
-		Context("when retrieving a non-existing thread", func() {
-			It("should return empty slug and found=false", func() {
-				slug, found, err := db.GetSlugForThread("non_existing_thread")
-				Expect(err).NotTo(HaveOccurred())
-				Expect(found).To(BeFalse())
-				Expect(slug).To(BeEmpty())
-			})
+	interfaceSpecs(func() database.Interface { return db })
+
+	Describe("Durable work queue", func() {
+		It("should recover work after reopening the database", func() {
+			_, err := db.EnqueueWork("app_mention", "{}")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(db.Close()).To(Succeed())
+
+			db, err = database.NewDatabase(dbPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(db.AutoMigrate()).To(Succeed())
+
+			work, err := db.GetQueuedWork()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(work).To(HaveLen(1))
 		})
 	})
 
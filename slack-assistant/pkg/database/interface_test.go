@@ -0,0 +1,630 @@
+package database_test
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+)
+
+// interfaceSpecs are the specs every database implementation must pass, current returns the database of the spec
+func interfaceSpecs(current func() database.Interface) {
+	var db database.Interface
+
+	BeforeEach(func() {
+		db = current()
+	})
+
+	Describe("CreateSlackThreadWithSlug", func() {
+		Context("when creating a new slack thread record", func() {
+			It("should create the record successfully", func() {
+				err := db.CreateSlackThreadWithSlug("thread123", "slug456", "sriov", "4.16")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should allow creating multiple different records", func() {
+				err := db.CreateSlackThreadWithSlug("thread1", "slug1", "sriov", "4.16")
+				Expect(err).NotTo(HaveOccurred())
+
+				err = db.CreateSlackThreadWithSlug("thread2", "slug2", "sriov", "4.16")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should fail when creating duplicate slack thread", func() {
+				err := db.CreateSlackThreadWithSlug("duplicate_thread", "slug1", "sriov", "4.16")
+				Expect(err).NotTo(HaveOccurred())
+
+				err = db.CreateSlackThreadWithSlug("duplicate_thread", "slug2", "sriov", "4.16")
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("GetSlugForThread", func() {
+		Context("when retrieving an existing thread", func() {
+			BeforeEach(func() {
+				err := db.CreateSlackThreadWithSlug("existing_thread", "existing_slug", "sriov", "4.16")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should return the correct slug and found=true", func() {
+				slug, found, err := db.GetSlugForThread("existing_thread")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(found).To(BeTrue())
+				Expect(slug).To(Equal("existing_slug"))
+			})
+		})
+
+		Context("when retrieving a non-existing thread", func() {
+			It("should return empty slug and found=false", func() {
+				slug, found, err := db.GetSlugForThread("non_existing_thread")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(found).To(BeFalse())
+				Expect(slug).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("UpdateThreadContext", func() {
+		It("should replace the slug, project and version of the thread", func() {
+			Expect(db.CreateSlackThreadWithSlug("switched_thread", "old_slug", "sriov", "4.16")).To(Succeed())
+			Expect(db.UpdateThreadContext("switched_thread", "new_slug", "metallb", "4.18")).To(Succeed())
+
+			threadContext, found, err := db.GetThreadContext("switched_thread")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(threadContext.ThreadSlug).To(Equal("new_slug"))
+			Expect(threadContext.Project).To(Equal("metallb"))
+			Expect(threadContext.Version).To(Equal("4.18"))
+		})
+
+		It("should fail for a non-existing thread", func() {
+			Expect(db.UpdateThreadContext("non_existing_thread", "new_slug", "metallb", "4.18")).NotTo(Succeed())
+		})
+	})
+
+	Describe("SetLastQuestion", func() {
+		It("should store the last question of the thread", func() {
+			Expect(db.CreateSlackThreadWithSlug("retried_thread", "slug", "sriov", "4.16")).To(Succeed())
+			Expect(db.SetLastQuestion("retried_thread", "How do I create a VF?")).To(Succeed())
+
+			threadContext, found, err := db.GetThreadContext("retried_thread")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(threadContext.LastQuestion).To(Equal("How do I create a VF?"))
+			Expect(threadContext.ThreadSlug).To(Equal("slug"))
+		})
+
+		It("should fail for a non-existing thread", func() {
+			Expect(db.SetLastQuestion("non_existing_thread", "How do I create a VF?")).NotTo(Succeed())
+		})
+	})
+
+	Describe("SetThreadMode", func() {
+		It("should store the answer mode of the thread", func() {
+			Expect(db.CreateSlackThreadWithSlug("mode_thread", "slug", "sriov", "4.16")).To(Succeed())
+			Expect(db.SetThreadMode("mode_thread", "chat")).To(Succeed())
+
+			threadContext, found, err := db.GetThreadContext("mode_thread")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(threadContext.Mode).To(Equal("chat"))
+		})
+
+		It("should fail for a non-existing thread", func() {
+			Expect(db.SetThreadMode("non_existing_thread", "chat")).NotTo(Succeed())
+		})
+	})
+
+	Describe("SetThreadTopK", func() {
+		It("should store the number of sources of the thread", func() {
+			Expect(db.CreateSlackThreadWithSlug("topk_thread", "slug", "sriov", "4.16")).To(Succeed())
+			Expect(db.SetThreadTopK("topk_thread", 8)).To(Succeed())
+
+			threadContext, found, err := db.GetThreadContext("topk_thread")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(threadContext.TopK).To(Equal(8))
+		})
+
+		It("should fail for a non-existing thread", func() {
+			Expect(db.SetThreadTopK("non_existing_thread", 8)).NotTo(Succeed())
+		})
+	})
+
+	Describe("SetThreadTemperature", func() {
+		It("should store the temperature of the thread", func() {
+			Expect(db.CreateSlackThreadWithSlug("temperature_thread", "slug", "sriov", "4.16")).To(Succeed())
+
+			threadContext, found, err := db.GetThreadContext("temperature_thread")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(threadContext.Temperature).To(BeNil())
+
+			Expect(db.SetThreadTemperature("temperature_thread", 0)).To(Succeed())
+			threadContext, found, err = db.GetThreadContext("temperature_thread")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(threadContext.Temperature).To(HaveValue(BeNumerically("==", 0)))
+		})
+
+		It("should fail for a non-existing thread", func() {
+			Expect(db.SetThreadTemperature("non_existing_thread", 0.5)).NotTo(Succeed())
+		})
+	})
+
+	Describe("SetThreadMemoryOff", func() {
+		It("should store whether the thread keeps its memory", func() {
+			Expect(db.CreateSlackThreadWithSlug("memory_thread", "slug", "sriov", "4.16")).To(Succeed())
+
+			threadContext, found, err := db.GetThreadContext("memory_thread")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(threadContext.MemoryOff).To(BeFalse())
+
+			Expect(db.SetThreadMemoryOff("memory_thread", true)).To(Succeed())
+			threadContext, found, err = db.GetThreadContext("memory_thread")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(threadContext.MemoryOff).To(BeTrue())
+
+			Expect(db.SetThreadMemoryOff("memory_thread", false)).To(Succeed())
+			threadContext, found, err = db.GetThreadContext("memory_thread")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(threadContext.MemoryOff).To(BeFalse())
+		})
+
+		It("should fail for a non-existing thread", func() {
+			Expect(db.SetThreadMemoryOff("non_existing_thread", true)).NotTo(Succeed())
+		})
+	})
+
+	Describe("SetThreadStrict", func() {
+		It("should store whether the thread answers only from the documents", func() {
+			Expect(db.CreateSlackThreadWithSlug("strict_thread", "slug", "sriov", "4.16")).To(Succeed())
+
+			threadContext, found, err := db.GetThreadContext("strict_thread")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(threadContext.Strict).To(BeFalse())
+
+			Expect(db.SetThreadStrict("strict_thread", true)).To(Succeed())
+			threadContext, found, err = db.GetThreadContext("strict_thread")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(threadContext.Strict).To(BeTrue())
+		})
+
+		It("should fail for a non-existing thread", func() {
+			Expect(db.SetThreadStrict("non_existing_thread", true)).NotTo(Succeed())
+		})
+	})
+
+	Describe("SetThreadTruncated", func() {
+		It("should store that the last answer of the thread was cut off", func() {
+			Expect(db.CreateSlackThreadWithSlug("truncated_thread", "slug", "sriov", "4.16")).To(Succeed())
+			Expect(db.SetThreadTruncated("truncated_thread", true)).To(Succeed())
+
+			threadContext, found, err := db.GetThreadContext("truncated_thread")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(threadContext.Truncated).To(BeTrue())
+		})
+
+		It("should be reset by a new question", func() {
+			Expect(db.CreateSlackThreadWithSlug("truncated_thread", "slug", "sriov", "4.16")).To(Succeed())
+			Expect(db.SetThreadTruncated("truncated_thread", true)).To(Succeed())
+			Expect(db.SetLastQuestion("truncated_thread", "How do I create a VF?")).To(Succeed())
+
+			threadContext, found, err := db.GetThreadContext("truncated_thread")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(threadContext.Truncated).To(BeFalse())
+		})
+
+		It("should fail for a non-existing thread", func() {
+			Expect(db.SetThreadTruncated("non_existing_thread", true)).NotTo(Succeed())
+		})
+	})
+
+	Describe("ElaborateSlugForThread", func() {
+		It("should store and return the elaborate slug of a thread", func() {
+			Expect(db.SetElaborateSlugForThread("elaborate_thread", "elaborate_slug")).To(Succeed())
+
+			slug, found, err := db.GetElaborateSlugForThread("elaborate_thread")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(slug).To(Equal("elaborate_slug"))
+		})
+
+		It("should return found=false when the thread has no elaborate slug", func() {
+			Expect(db.CreateSlackThreadWithSlug("answer_thread", "answer_slug", "sriov", "4.16")).To(Succeed())
+
+			slug, found, err := db.GetElaborateSlugForThread("answer_thread")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeFalse())
+			Expect(slug).To(BeEmpty())
+		})
+
+		It("should keep the answer slug, project and version of the thread", func() {
+			Expect(db.CreateSlackThreadWithSlug("shared_thread", "answer_slug", "sriov", "4.16")).To(Succeed())
+			Expect(db.SetElaborateSlugForThread("shared_thread", "elaborate_slug")).To(Succeed())
+
+			threadContext, found, err := db.GetThreadContext("shared_thread")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(threadContext.ThreadSlug).To(Equal("answer_slug"))
+			Expect(threadContext.Project).To(Equal("sriov"))
+			Expect(threadContext.ElaborateSlug).To(Equal("elaborate_slug"))
+		})
+
+		It("should let the answer slug be created after the elaborate slug", func() {
+			Expect(db.SetElaborateSlugForThread("elaborated_first", "elaborate_slug")).To(Succeed())
+
+			slug, found, err := db.GetSlugForThread("elaborated_first")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeFalse())
+			Expect(slug).To(BeEmpty())
+
+			Expect(db.CreateSlackThreadWithSlug("elaborated_first", "answer_slug", "metallb", "4.18")).To(Succeed())
+
+			threadContext, found, err := db.GetThreadContext("elaborated_first")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(threadContext.ThreadSlug).To(Equal("answer_slug"))
+			Expect(threadContext.Version).To(Equal("4.18"))
+			Expect(threadContext.ElaborateSlug).To(Equal("elaborate_slug"))
+		})
+	})
+
+	Describe("GetThreadContext", func() {
+		It("should return the slug, project and version stored for the thread", func() {
+			Expect(db.CreateSlackThreadWithSlug("existing_thread", "existing_slug", "metallb", "4.18")).To(Succeed())
+
+			threadContext, found, err := db.GetThreadContext("existing_thread")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(threadContext.ThreadSlug).To(Equal("existing_slug"))
+			Expect(threadContext.Project).To(Equal("metallb"))
+			Expect(threadContext.Version).To(Equal("4.18"))
+		})
+
+		It("should return found=false for a non-existing thread", func() {
+			threadContext, found, err := db.GetThreadContext("non_existing_thread")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeFalse())
+			Expect(threadContext).To(BeNil())
+		})
+	})
+
+	Describe("DeleteThread", func() {
+		It("should remove the thread mapping", func() {
+			Expect(db.CreateSlackThreadWithSlug("deleted_thread", "deleted_slug", "metallb", "4.18")).To(Succeed())
+			Expect(db.DeleteThread("deleted_thread")).To(Succeed())
+
+			_, found, err := db.GetSlugForThread("deleted_thread")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeFalse())
+		})
+
+		It("should succeed for a non-existing thread", func() {
+			Expect(db.DeleteThread("non_existing_thread")).To(Succeed())
+		})
+	})
+
+	Describe("RecordCommand", func() {
+		It("should record a command audit successfully", func() {
+			err := db.RecordCommand(&database.CommandAudit{
+				User:        "U123",
+				Channel:     "C123",
+				SlackThread: "thread123",
+				Command:     "answer",
+				Args:        "sriov 4.16",
+				Success:     true,
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("ListThreads", func() {
+		Context("when threads exist", func() {
+			BeforeEach(func() {
+				Expect(db.CreateSlackThreadWithSlug("1700000001.000100", "slug-1", "sriov", "4.16")).To(Succeed())
+				Expect(db.CreateSlackThreadWithSlug("1700000003.000100", "slug-3", "metallb", "4.18")).To(Succeed())
+				Expect(db.CreateSlackThreadWithSlug("1700000002.000100", "slug-2", "sriov", "4.18")).To(Succeed())
+			})
+
+			It("should return every thread newest first without a limit", func() {
+				threads, err := db.ListThreads(0, 0)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(threads).To(HaveLen(3))
+				Expect(threads[0].SlackThread).To(Equal("1700000003.000100"))
+				Expect(threads[0].ThreadSlug).To(Equal("slug-3"))
+				Expect(threads[0].Project).To(Equal("metallb"))
+				Expect(threads[2].SlackThread).To(Equal("1700000001.000100"))
+			})
+
+			It("should return the requested page", func() {
+				threads, err := db.ListThreads(2, 0)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(threads).To(HaveLen(2))
+				Expect(threads[1].ThreadSlug).To(Equal("slug-2"))
+
+				threads, err = db.ListThreads(2, 2)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(threads).To(HaveLen(1))
+				Expect(threads[0].ThreadSlug).To(Equal("slug-1"))
+			})
+
+			It("should skip the offset without a limit", func() {
+				threads, err := db.ListThreads(0, 1)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(threads).To(HaveLen(2))
+				Expect(threads[0].ThreadSlug).To(Equal("slug-2"))
+			})
+
+			It("should return an empty page past the last thread", func() {
+				threads, err := db.ListThreads(2, 10)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(threads).To(BeEmpty())
+			})
+
+			It("should start from the first thread with a negative offset", func() {
+				threads, err := db.ListThreads(1, -1)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(threads).To(HaveLen(1))
+				Expect(threads[0].ThreadSlug).To(Equal("slug-3"))
+			})
+		})
+
+		Context("when no threads exist", func() {
+			It("should return an empty list", func() {
+				threads, err := db.ListThreads(10, 0)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(threads).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("Feedback", func() {
+		It("should return the feedback of the thread, oldest first", func() {
+			Expect(db.RecordFeedback(&database.Feedback{SlackThread: "thread1", User: "U1", Project: "sriov", Version: "4.16",
+				Question: "How do I create a VF?", Answer: "Edit the node", Correction: "Use a SriovNetworkNodePolicy"})).To(Succeed())
+			Expect(db.RecordFeedback(&database.Feedback{SlackThread: "thread2", Correction: "other thread"})).To(Succeed())
+			Expect(db.RecordFeedback(&database.Feedback{SlackThread: "thread1", Correction: "Set numVfs too"})).To(Succeed())
+
+			feedback, err := db.GetFeedback("thread1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(feedback).To(HaveLen(2))
+			Expect(feedback[0].User).To(Equal("U1"))
+			Expect(feedback[0].Answer).To(Equal("Edit the node"))
+			Expect(feedback[0].Correction).To(Equal("Use a SriovNetworkNodePolicy"))
+			Expect(feedback[0].CreatedAt).NotTo(BeZero())
+			Expect(feedback[1].Correction).To(Equal("Set numVfs too"))
+		})
+
+		It("should return no feedback for a thread without corrections", func() {
+			feedback, err := db.GetFeedback("unknown")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(feedback).To(BeEmpty())
+		})
+	})
+
+	Describe("WithTransaction", func() {
+		It("should commit the changes when the function succeeds", func() {
+			err := db.WithTransaction(func(tx database.Interface) error {
+				if err := tx.CreateSlackThreadWithSlug("tx_thread", "tx_slug", "sriov", "4.16"); err != nil {
+					return err
+				}
+				return tx.RecordInjection(&database.InjectedDocument{SlackThread: "tx_thread", Project: "sriov", Version: "4.16", Content: "fact"})
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			slug, found, err := db.GetSlugForThread("tx_thread")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(slug).To(Equal("tx_slug"))
+			documents, err := db.GetInjectedDocuments("tx_thread")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(documents).To(HaveLen(1))
+			Expect(documents[0].Content).To(Equal("fact"))
+			Expect(documents[0].CreatedAt).NotTo(BeZero())
+		})
+
+		It("should roll back the changes and return the error when the function fails", func() {
+			err := db.WithTransaction(func(tx database.Interface) error {
+				if err := tx.RecordInjection(&database.InjectedDocument{SlackThread: "tx_thread", Content: "fact"}); err != nil {
+					return err
+				}
+				return errors.New("inject failed")
+			})
+			Expect(err).To(MatchError("inject failed"))
+
+			documents, err := db.GetInjectedDocuments("tx_thread")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(documents).To(BeEmpty())
+		})
+
+		It("should roll back the changes when the function panics", func() {
+			Expect(func() {
+				//nolint:errcheck // the function panics
+				_ = db.WithTransaction(func(tx database.Interface) error {
+					Expect(tx.RecordInjection(&database.InjectedDocument{SlackThread: "tx_thread"})).To(Succeed())
+					panic("boom")
+				})
+			}).To(PanicWith("boom"))
+
+			documents, err := db.GetInjectedDocuments("tx_thread")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(documents).To(BeEmpty())
+		})
+	})
+
+	Describe("GetRecentCommands", func() {
+		Context("when audits exist", func() {
+			BeforeEach(func() {
+				Expect(db.RecordCommand(&database.CommandAudit{User: "U1", Command: "answer", Args: "sriov 4.16", Success: true})).To(Succeed())
+				Expect(db.RecordCommand(&database.CommandAudit{User: "U2", Command: "inject", Args: "metallb 4.18", Success: false, Error: "injection failed"})).To(Succeed())
+				Expect(db.RecordCommand(&database.CommandAudit{User: "U3", Command: "elaborate", Success: true})).To(Succeed())
+			})
+
+			It("should return the most recent audits first", func() {
+				audits, err := db.GetRecentCommands(10)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(audits).To(HaveLen(3))
+				Expect(audits[0].Command).To(Equal("elaborate"))
+				Expect(audits[1].Command).To(Equal("inject"))
+				Expect(audits[1].Success).To(BeFalse())
+				Expect(audits[1].Error).To(Equal("injection failed"))
+				Expect(audits[2].Command).To(Equal("answer"))
+				Expect(audits[2].CreatedAt).NotTo(BeZero())
+			})
+
+			It("should respect the limit", func() {
+				audits, err := db.GetRecentCommands(2)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(audits).To(HaveLen(2))
+				Expect(audits[0].User).To(Equal("U3"))
+			})
+		})
+
+		Context("when no audits exist", func() {
+			It("should return an empty list", func() {
+				audits, err := db.GetRecentCommands(10)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(audits).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("GetRecentCommandsForUser", func() {
+		BeforeEach(func() {
+			Expect(db.RecordCommand(&database.CommandAudit{User: "U1", Command: "answer", Success: true})).To(Succeed())
+			Expect(db.RecordCommand(&database.CommandAudit{User: "U2", Command: "inject", Success: true})).To(Succeed())
+			Expect(db.RecordCommand(&database.CommandAudit{User: "U1", Command: "elaborate", Success: true})).To(Succeed())
+		})
+
+		It("should only return the audits of the requested user", func() {
+			audits, err := db.GetRecentCommandsForUser("U1", 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(audits).To(HaveLen(2))
+			Expect(audits[0].Command).To(Equal("elaborate"))
+			Expect(audits[1].Command).To(Equal("answer"))
+		})
+
+		It("should return an empty list for users without audits", func() {
+			audits, err := db.GetRecentCommandsForUser("U3", 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(audits).To(BeEmpty())
+		})
+	})
+
+	Describe("ListAskedQuestions", func() {
+		answers := []string{"answer", "answer-all"}
+
+		// ask answers the question in a new thread of the channel and audits it
+		ask := func(channel, thread, user, question string) {
+			Expect(db.CreateSlackThreadWithSlug(thread, "slug-"+thread, "sriov", "4.16")).To(Succeed())
+			Expect(db.SetLastQuestion(thread, question)).To(Succeed())
+			Expect(db.RecordCommand(&database.CommandAudit{User: user, Channel: channel, SlackThread: thread, Command: "answer", Success: true})).To(Succeed())
+		}
+
+		It("should return an empty list for channels without answers", func() {
+			questions, err := db.ListAskedQuestions("C1", answers, 10, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(questions).To(BeEmpty())
+		})
+
+		It("should list the questions of the channel newest first", func() {
+			ask("C1", "1.000001", "U1", "How do I create a VF?")
+			ask("C2", "1.000002", "U2", "Elsewhere?")
+			ask("C1", "1.000003", "U2", "Which NICs are supported?")
+
+			questions, err := db.ListAskedQuestions("C1", answers, 10, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(questions).To(HaveLen(2))
+			Expect(questions[0].SlackThread).To(Equal("1.000003"))
+			Expect(questions[0].User).To(Equal("U2"))
+			Expect(questions[0].Question).To(Equal("Which NICs are supported?"))
+			Expect(questions[0].Project).To(Equal("sriov"))
+			Expect(questions[0].Version).To(Equal("4.16"))
+			Expect(questions[0].AskedAt).NotTo(BeZero())
+			Expect(questions[1].SlackThread).To(Equal("1.000001"))
+		})
+
+		It("should list every thread once and skip the failed and other commands", func() {
+			ask("C1", "1.000001", "U1", "How do I create a VF?")
+			Expect(db.RecordCommand(&database.CommandAudit{User: "U1", Channel: "C1", SlackThread: "1.000001", Command: "answer-all", Success: true})).To(Succeed())
+			Expect(db.CreateSlackThreadWithSlug("1.000002", "slug", "sriov", "4.16")).To(Succeed())
+			Expect(db.SetLastQuestion("1.000002", "Failed?")).To(Succeed())
+			Expect(db.RecordCommand(&database.CommandAudit{User: "U1", Channel: "C1", SlackThread: "1.000002", Command: "answer", Error: "boom"})).To(Succeed())
+			Expect(db.RecordCommand(&database.CommandAudit{User: "U1", Channel: "C1", SlackThread: "1.000002", Command: "inject", Success: true})).To(Succeed())
+
+			questions, err := db.ListAskedQuestions("C1", answers, 10, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(questions).To(HaveLen(1))
+			Expect(questions[0].SlackThread).To(Equal("1.000001"))
+		})
+
+		It("should return the requested page", func() {
+			for i := 1; i <= 5; i++ {
+				ask("C1", fmt.Sprintf("1.00000%d", i), "U1", fmt.Sprintf("Question %d?", i))
+			}
+
+			questions, err := db.ListAskedQuestions("C1", answers, 2, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(questions).To(HaveLen(2))
+			Expect(questions[0].Question).To(Equal("Question 5?"))
+
+			questions, err = db.ListAskedQuestions("C1", answers, 2, 4)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(questions).To(HaveLen(1))
+			Expect(questions[0].Question).To(Equal("Question 1?"))
+
+			questions, err = db.ListAskedQuestions("C1", answers, 2, 6)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(questions).To(BeEmpty())
+		})
+	})
+
+	Describe("Durable work queue", func() {
+		It("should enqueue work and return it until completed", func() {
+			firstID, err := db.EnqueueWork("app_mention", `{"text": "first"}`)
+			Expect(err).NotTo(HaveOccurred())
+			secondID, err := db.EnqueueWork("interaction", `{"text": "second"}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(secondID).To(BeNumerically(">", firstID))
+
+			work, err := db.GetQueuedWork()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(work).To(HaveLen(2))
+			Expect(work[0].ID).To(Equal(firstID))
+			Expect(work[0].Kind).To(Equal("app_mention"))
+			Expect(work[0].Payload).To(Equal(`{"text": "first"}`))
+			Expect(work[0].InProgress).To(BeFalse())
+
+			Expect(db.CompleteWork(firstID)).To(Succeed())
+
+			work, err = db.GetQueuedWork()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(work).To(HaveLen(1))
+			Expect(work[0].ID).To(Equal(secondID))
+		})
+
+		It("should recover work that was in progress", func() {
+			id, err := db.EnqueueWork("app_mention", "{}")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(db.MarkWorkInProgress(id)).To(Succeed())
+
+			work, err := db.GetQueuedWork()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(work).To(HaveLen(1))
+			Expect(work[0].ID).To(Equal(id))
+			Expect(work[0].InProgress).To(BeTrue())
+		})
+	})
+}
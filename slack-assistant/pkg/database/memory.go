@@ -0,0 +1,408 @@
+package database
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryDatabase implements Interface with maps instead of sqlite, for tests and stateless deployments,
+// everything is lost when the process stops
+type MemoryDatabase struct {
+	mu    sync.Mutex
+	state memoryState
+}
+
+// memoryState holds the tables of the memory database
+type memoryState struct {
+	threads   map[string]SlackThreadToSlug
+	audits    []CommandAudit
+	work      []QueuedWork
+	documents []InjectedDocument
+	feedback  []Feedback
+	// lastID is the last primary key given to a record, shared by the tables with an auto-increment ID
+	lastID uint
+}
+
+// clone returns a copy of the state that can be changed without changing the original
+func (s memoryState) clone() memoryState {
+	return memoryState{
+		threads:   maps.Clone(s.threads),
+		audits:    slices.Clone(s.audits),
+		work:      slices.Clone(s.work),
+		documents: slices.Clone(s.documents),
+		feedback:  slices.Clone(s.feedback),
+		lastID:    s.lastID,
+	}
+}
+
+// nextID returns the primary key of a new record
+func (s *memoryState) nextID() uint {
+	s.lastID++
+	return s.lastID
+}
+
+// NewMemoryDatabase returns an empty memory database
+func NewMemoryDatabase() *MemoryDatabase {
+	return &MemoryDatabase{state: memoryState{threads: map[string]SlackThreadToSlug{}}}
+}
+
+// AutoMigrate does nothing, the memory database has no schema
+func (m *MemoryDatabase) AutoMigrate() error {
+	return nil
+}
+
+// CreateSlackThreadWithSlug inserts a new SlackThread record with the project and version it was created for,
+// a record that only has an elaborate slug so far is completed instead, like the sqlite primary key an existing
+// thread is rejected with gorm.ErrDuplicatedKey
+func (m *MemoryDatabase) CreateSlackThreadWithSlug(thread, slug, project, version string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record, exist := m.state.threads[thread]
+	if exist && record.ThreadSlug != "" {
+		return gorm.ErrDuplicatedKey
+	}
+	record.SlackThread = thread
+	record.ThreadSlug = slug
+	record.Project = project
+	record.Version = version
+	m.state.threads[thread] = record
+	return nil
+}
+
+// GetSlugForThread retrieves the slug of a SlackThread
+func (m *MemoryDatabase) GetSlugForThread(slackThread string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// The record may only hold the elaborate slug of the thread
+	record, exist := m.state.threads[slackThread]
+	if !exist || record.ThreadSlug == "" {
+		return "", false, nil
+	}
+	return record.ThreadSlug, true, nil
+}
+
+// GetThreadContext retrieves the slug, project and version stored for a SlackThread
+func (m *MemoryDatabase) GetThreadContext(slackThread string) (*SlackThreadToSlug, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record, exist := m.state.threads[slackThread]
+	if !exist {
+		return nil, false, nil
+	}
+	return &record, true, nil
+}
+
+// UpdateThreadContext replaces the slug, project and version stored for a SlackThread
+func (m *MemoryDatabase) UpdateThreadContext(slackThread, slug, project, version string) error {
+	return m.updateThread(slackThread, func(record *SlackThreadToSlug) {
+		record.ThreadSlug = slug
+		record.Project = project
+		record.Version = version
+	})
+}
+
+// SetLastQuestion stores the last question answered in a SlackThread, the answer of a new question isn't truncated yet
+func (m *MemoryDatabase) SetLastQuestion(slackThread, question string) error {
+	return m.updateThread(slackThread, func(record *SlackThreadToSlug) {
+		record.LastQuestion = question
+		record.Truncated = false
+	})
+}
+
+// SetThreadMode stores the LLM answer mode of a SlackThread
+func (m *MemoryDatabase) SetThreadMode(slackThread, mode string) error {
+	return m.updateThread(slackThread, func(record *SlackThreadToSlug) { record.Mode = mode })
+}
+
+// SetThreadTopK stores the number of sources retrieved for the answers of a SlackThread
+func (m *MemoryDatabase) SetThreadTopK(slackThread string, topK int) error {
+	return m.updateThread(slackThread, func(record *SlackThreadToSlug) { record.TopK = topK })
+}
+
+// SetThreadTemperature stores the LLM temperature of the answers of a SlackThread
+func (m *MemoryDatabase) SetThreadTemperature(slackThread string, temperature float64) error {
+	return m.updateThread(slackThread, func(record *SlackThreadToSlug) { record.Temperature = &temperature })
+}
+
+// SetThreadMemoryOff stores whether each question of a SlackThread is answered without the previous ones
+func (m *MemoryDatabase) SetThreadMemoryOff(slackThread string, off bool) error {
+	return m.updateThread(slackThread, func(record *SlackThreadToSlug) { record.MemoryOff = off })
+}
+
+// SetThreadStrict stores whether the answers of a SlackThread only come from the retrieved documents
+func (m *MemoryDatabase) SetThreadStrict(slackThread string, strict bool) error {
+	return m.updateThread(slackThread, func(record *SlackThreadToSlug) { record.Strict = strict })
+}
+
+// SetThreadTruncated stores whether the last answer of a SlackThread was cut off
+func (m *MemoryDatabase) SetThreadTruncated(slackThread string, truncated bool) error {
+	return m.updateThread(slackThread, func(record *SlackThreadToSlug) { record.Truncated = truncated })
+}
+
+// updateThread changes the record of a SlackThread, gorm.ErrRecordNotFound is returned when the thread isn't stored
+func (m *MemoryDatabase) updateThread(slackThread string, update func(record *SlackThreadToSlug)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record, exist := m.state.threads[slackThread]
+	if !exist {
+		return gorm.ErrRecordNotFound
+	}
+	update(&record)
+	m.state.threads[slackThread] = record
+	return nil
+}
+
+// SetElaborateSlugForThread stores the slug of the elaborate thread used by a SlackThread
+func (m *MemoryDatabase) SetElaborateSlugForThread(slackThread, slug string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record := m.state.threads[slackThread]
+	record.SlackThread = slackThread
+	record.ElaborateSlug = slug
+	m.state.threads[slackThread] = record
+	return nil
+}
+
+// GetElaborateSlugForThread retrieves the slug of the elaborate thread used by a SlackThread
+func (m *MemoryDatabase) GetElaborateSlugForThread(slackThread string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record, exist := m.state.threads[slackThread]
+	if !exist || record.ElaborateSlug == "" {
+		return "", false, nil
+	}
+	return record.ElaborateSlug, true, nil
+}
+
+// DeleteThread removes the slugs, project and version stored for a SlackThread, deleting a missing thread is not an error
+func (m *MemoryDatabase) DeleteThread(slackThread string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.state.threads, slackThread)
+	return nil
+}
+
+// ListThreads returns a page of the stored SlackThreads, newest first, a limit of 0 or less returns every thread
+func (m *MemoryDatabase) ListThreads(limit, offset int) ([]SlackThreadToSlug, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	threads := slices.SortedFunc(maps.Values(m.state.threads), func(a, b SlackThreadToSlug) int {
+		return strings.Compare(b.SlackThread, a.SlackThread)
+	})
+	if limit <= 0 {
+		limit = -1
+	}
+	return page(threads, limit, offset), nil
+}
+
+// RecordCommand inserts a new CommandAudit record
+func (m *MemoryDatabase) RecordCommand(audit *CommandAudit) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	audit.ID = m.state.nextID()
+	if audit.CreatedAt.IsZero() {
+		audit.CreatedAt = time.Now()
+	}
+	m.state.audits = append(m.state.audits, *audit)
+	return nil
+}
+
+// GetRecentCommands returns the most recent CommandAudit records, newest first
+func (m *MemoryDatabase) GetRecentCommands(limit int) ([]CommandAudit, error) {
+	return m.recentCommands(func(CommandAudit) bool { return true }, limit), nil
+}
+
+// GetRecentCommandsForUser returns the most recent CommandAudit records of a user, newest first
+func (m *MemoryDatabase) GetRecentCommandsForUser(user string, limit int) ([]CommandAudit, error) {
+	return m.recentCommands(func(audit CommandAudit) bool { return audit.User == user }, limit), nil
+}
+
+// recentCommands returns the most recent CommandAudit records matching the filter, newest first
+func (m *MemoryDatabase) recentCommands(filter func(CommandAudit) bool, limit int) []CommandAudit {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var audits []CommandAudit
+	for _, audit := range m.state.audits {
+		if filter(audit) {
+			audits = append(audits, audit)
+		}
+	}
+	slices.SortFunc(audits, func(a, b CommandAudit) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.ID, a.ID))
+	})
+	return page(audits, limit, 0)
+}
+
+// ListAskedQuestions returns a page of the questions answered in the channel, newest first, from the successful audits
+// of the commands, each thread is listed once with its last question and the threads without one are skipped
+func (m *MemoryDatabase) ListAskedQuestions(channel string, commands []string, limit, offset int) ([]AskedQuestion, error) {
+	audits := m.recentCommands(func(audit CommandAudit) bool {
+		return audit.Channel == channel && audit.Success && slices.Contains(commands, audit.Command)
+	}, -1)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var questions []AskedQuestion
+	listed := map[string]bool{}
+	for _, audit := range audits {
+		thread, ok := m.state.threads[audit.SlackThread]
+		if listed[audit.SlackThread] || !ok || thread.LastQuestion == "" {
+			continue
+		}
+		listed[audit.SlackThread] = true
+		questions = append(questions, AskedQuestion{
+			SlackThread: audit.SlackThread,
+			User:        audit.User,
+			Project:     thread.Project,
+			Version:     thread.Version,
+			Question:    thread.LastQuestion,
+			AskedAt:     audit.CreatedAt,
+		})
+	}
+	return page(questions, limit, offset), nil
+}
+
+// EnqueueWork persists a new QueuedWork record and returns its ID
+func (m *MemoryDatabase) EnqueueWork(kind, payload string) (uint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	work := QueuedWork{ID: m.state.nextID(), Kind: kind, Payload: payload, CreatedAt: time.Now()}
+	m.state.work = append(m.state.work, work)
+	return work.ID, nil
+}
+
+// MarkWorkInProgress flags a QueuedWork record as being processed
+func (m *MemoryDatabase) MarkWorkInProgress(id uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if i := slices.IndexFunc(m.state.work, func(work QueuedWork) bool { return work.ID == id }); i >= 0 {
+		m.state.work[i].InProgress = true
+	}
+	return nil
+}
+
+// CompleteWork deletes a processed QueuedWork record
+func (m *MemoryDatabase) CompleteWork(id uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.state.work = slices.DeleteFunc(m.state.work, func(work QueuedWork) bool { return work.ID == id })
+	return nil
+}
+
+// GetQueuedWork returns every QueuedWork record that was not completed, oldest first
+func (m *MemoryDatabase) GetQueuedWork() ([]QueuedWork, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// The IDs only grow, the records are kept in insertion order
+	return slices.Clone(m.state.work), nil
+}
+
+// RecordInjection inserts a new InjectedDocument record
+func (m *MemoryDatabase) RecordInjection(document *InjectedDocument) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	document.ID = m.state.nextID()
+	if document.CreatedAt.IsZero() {
+		document.CreatedAt = time.Now()
+	}
+	m.state.documents = append(m.state.documents, *document)
+	return nil
+}
+
+// GetInjectedDocuments returns the InjectedDocument records of a SlackThread, oldest first
+func (m *MemoryDatabase) GetInjectedDocuments(slackThread string) ([]InjectedDocument, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var documents []InjectedDocument
+	for _, document := range m.state.documents {
+		if document.SlackThread == slackThread {
+			documents = append(documents, document)
+		}
+	}
+	return documents, nil
+}
+
+// RecordFeedback inserts a new Feedback record
+func (m *MemoryDatabase) RecordFeedback(feedback *Feedback) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	feedback.ID = m.state.nextID()
+	if feedback.CreatedAt.IsZero() {
+		feedback.CreatedAt = time.Now()
+	}
+	m.state.feedback = append(m.state.feedback, *feedback)
+	return nil
+}
+
+// GetFeedback returns the Feedback records of a SlackThread, oldest first
+func (m *MemoryDatabase) GetFeedback(slackThread string) ([]Feedback, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var feedback []Feedback
+	for _, record := range m.state.feedback {
+		if record.SlackThread == slackThread {
+			feedback = append(feedback, record)
+		}
+	}
+	return feedback, nil
+}
+
+// WithTransaction runs fn with a copy of the database, the copy replaces the database when fn succeeds and is dropped
+// when it returns an error or panics. The database is locked until fn returns, so fn must only use the transaction
+// database, which must not be used after fn returns, and must not block: every other call waits for it, so slow work
+// like an LLM call must run before or after the transaction.
+func (m *MemoryDatabase) WithTransaction(fn func(tx Interface) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tx := &MemoryDatabase{state: m.state.clone()}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	m.state = tx.state
+	return nil
+}
+
+// Close does nothing, the memory database holds no connection
+func (m *MemoryDatabase) Close() error {
+	return nil
+}
+
+// page returns the records of the page like the LIMIT and OFFSET clauses, a negative limit returns every record
+// after the offset
+func page[T any](records []T, limit, offset int) []T {
+	offset = max(offset, 0)
+	if offset >= len(records) {
+		return nil
+	}
+	records = records[offset:]
+	if limit >= 0 && limit < len(records) {
+		records = records[:limit]
+	}
+	return records
+}
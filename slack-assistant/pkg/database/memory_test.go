@@ -0,0 +1,71 @@
+package database_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+)
+
+var _ = Describe("MemoryDatabase", func() {
+	var db *database.MemoryDatabase
+
+	BeforeEach(func() {
+		db = database.NewMemoryDatabase()
+	})
+
+	interfaceSpecs(func() database.Interface { return db })
+
+	It("should reject a duplicate thread with the gorm duplicate key error", func() {
+		Expect(db.CreateSlackThreadWithSlug("thread", "slug1", "sriov", "4.16")).To(Succeed())
+		Expect(db.CreateSlackThreadWithSlug("thread", "slug2", "sriov", "4.16")).To(MatchError(gorm.ErrDuplicatedKey))
+		Expect(db.SetThreadMode("missing_thread", "chat")).To(MatchError(gorm.ErrRecordNotFound))
+	})
+
+	It("should not share the returned thread context", func() {
+		Expect(db.CreateSlackThreadWithSlug("thread", "slug", "sriov", "4.16")).To(Succeed())
+		threadContext, _, err := db.GetThreadContext("thread")
+		Expect(err).NotTo(HaveOccurred())
+		threadContext.Project = "metallb"
+
+		threadContext, _, err = db.GetThreadContext("thread")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(threadContext.Project).To(Equal("sriov"))
+	})
+
+	It("should create each thread once when used concurrently", func() {
+		var (
+			wg       sync.WaitGroup
+			mu       sync.Mutex
+			failures int
+		)
+		for i := range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer GinkgoRecover()
+				err := db.CreateSlackThreadWithSlug(fmt.Sprintf("thread-%d", i%10), "slug", "sriov", "4.16")
+				if errors.Is(err, gorm.ErrDuplicatedKey) {
+					mu.Lock()
+					failures++
+					mu.Unlock()
+				}
+				Expect(db.RecordCommand(&database.CommandAudit{User: "U1", Command: "answer"})).To(Succeed())
+			}()
+		}
+		wg.Wait()
+
+		Expect(failures).To(Equal(10))
+		threads, err := db.ListThreads(0, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(threads).To(HaveLen(10))
+		audits, err := db.GetRecentCommands(100)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(audits).To(HaveLen(20))
+	})
+})
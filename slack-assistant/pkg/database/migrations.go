@@ -0,0 +1,340 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SchemaMigration records a migration applied to the database, the last applied migration is the schema version
+type SchemaMigration struct {
+	ID        string `gorm:"primaryKey"`
+	AppliedAt time.Time
+}
+
+// migration is a schema change applied once and in order, Rollback reverts it.
+// Migrations use snapshots of the models at the time they were written so they stay deterministic when the models change,
+// and skip the changes already made by the AutoMigrate of the releases before versioning.
+type migration struct {
+	ID       string
+	Migrate  func(tx *gorm.DB) error
+	Rollback func(tx *gorm.DB) error
+}
+
+// The model snapshots used by the migrations
+
+type slackThreadToSlugV1 struct {
+	SlackThread string `gorm:"primaryKey"`
+	ThreadSlug  string
+}
+
+func (slackThreadToSlugV1) TableName() string { return "slack_thread_to_slugs" }
+
+type slackThreadToSlugV2 struct {
+	Project string
+	Version string
+}
+
+func (slackThreadToSlugV2) TableName() string { return "slack_thread_to_slugs" }
+
+type slackThreadToSlugV3 struct {
+	ElaborateSlug string
+}
+
+func (slackThreadToSlugV3) TableName() string { return "slack_thread_to_slugs" }
+
+type slackThreadToSlugV4 struct {
+	LastQuestion string
+}
+
+func (slackThreadToSlugV4) TableName() string { return "slack_thread_to_slugs" }
+
+type slackThreadToSlugV5 struct {
+	Mode string
+}
+
+func (slackThreadToSlugV5) TableName() string { return "slack_thread_to_slugs" }
+
+type slackThreadToSlugV6 struct {
+	TopK int
+}
+
+func (slackThreadToSlugV6) TableName() string { return "slack_thread_to_slugs" }
+
+type slackThreadToSlugV7 struct {
+	Truncated bool
+}
+
+func (slackThreadToSlugV7) TableName() string { return "slack_thread_to_slugs" }
+
+type slackThreadToSlugV8 struct {
+	Temperature *float64
+}
+
+func (slackThreadToSlugV8) TableName() string { return "slack_thread_to_slugs" }
+
+type slackThreadToSlugV9 struct {
+	MemoryOff bool
+}
+
+func (slackThreadToSlugV9) TableName() string { return "slack_thread_to_slugs" }
+
+type slackThreadToSlugV10 struct {
+	Strict bool
+}
+
+func (slackThreadToSlugV10) TableName() string { return "slack_thread_to_slugs" }
+
+type commandAuditV1 struct {
+	ID          uint `gorm:"primaryKey"`
+	User        string
+	Channel     string
+	SlackThread string
+	Command     string
+	Args        string
+	Success     bool
+	Error       string
+	CreatedAt   time.Time `gorm:"index"`
+}
+
+func (commandAuditV1) TableName() string { return "command_audits" }
+
+type queuedWorkV1 struct {
+	ID         uint `gorm:"primaryKey"`
+	Kind       string
+	Payload    string
+	InProgress bool
+	CreatedAt  time.Time
+}
+
+func (queuedWorkV1) TableName() string { return "queued_works" }
+
+type injectedDocumentV1 struct {
+	ID          uint   `gorm:"primaryKey"`
+	SlackThread string `gorm:"index"`
+	Project     string
+	Version     string
+	Content     string
+	Source      string
+	CreatedAt   time.Time
+}
+
+func (injectedDocumentV1) TableName() string { return "injected_documents" }
+
+type feedbackV1 struct {
+	ID          uint   `gorm:"primaryKey"`
+	SlackThread string `gorm:"index"`
+	User        string
+	Project     string
+	Version     string
+	Question    string
+	Answer      string
+	Correction  string
+	CreatedAt   time.Time
+}
+
+func (feedbackV1) TableName() string { return "feedbacks" }
+
+// migrations are applied in this order, new migrations must be appended
+var migrations = []migration{
+	{
+		ID:       "0001_create_slack_thread_to_slugs",
+		Migrate:  createTable(&slackThreadToSlugV1{}),
+		Rollback: dropTable(&slackThreadToSlugV1{}),
+	},
+	{
+		ID:       "0002_create_command_audits",
+		Migrate:  createTable(&commandAuditV1{}),
+		Rollback: dropTable(&commandAuditV1{}),
+	},
+	{
+		ID:       "0003_add_thread_project_and_version",
+		Migrate:  addColumns(&slackThreadToSlugV2{}, "Project", "Version"),
+		Rollback: dropColumns(&slackThreadToSlugV2{}, "Project", "Version"),
+	},
+	{
+		ID:       "0004_create_queued_works",
+		Migrate:  createTable(&queuedWorkV1{}),
+		Rollback: dropTable(&queuedWorkV1{}),
+	},
+	{
+		ID:       "0005_add_thread_elaborate_slug",
+		Migrate:  addColumns(&slackThreadToSlugV3{}, "ElaborateSlug"),
+		Rollback: dropColumns(&slackThreadToSlugV3{}, "ElaborateSlug"),
+	},
+	{
+		ID:       "0006_add_thread_last_question",
+		Migrate:  addColumns(&slackThreadToSlugV4{}, "LastQuestion"),
+		Rollback: dropColumns(&slackThreadToSlugV4{}, "LastQuestion"),
+	},
+	{
+		ID:       "0007_create_injected_documents",
+		Migrate:  createTable(&injectedDocumentV1{}),
+		Rollback: dropTable(&injectedDocumentV1{}),
+	},
+	{
+		ID:       "0008_add_thread_mode",
+		Migrate:  addColumns(&slackThreadToSlugV5{}, "Mode"),
+		Rollback: dropColumns(&slackThreadToSlugV5{}, "Mode"),
+	},
+	{
+		ID:       "0009_add_thread_top_k",
+		Migrate:  addColumns(&slackThreadToSlugV6{}, "TopK"),
+		Rollback: dropColumns(&slackThreadToSlugV6{}, "TopK"),
+	},
+	{
+		ID:       "0010_add_thread_truncated",
+		Migrate:  addColumns(&slackThreadToSlugV7{}, "Truncated"),
+		Rollback: dropColumns(&slackThreadToSlugV7{}, "Truncated"),
+	},
+	{
+		ID:       "0011_create_feedbacks",
+		Migrate:  createTable(&feedbackV1{}),
+		Rollback: dropTable(&feedbackV1{}),
+	},
+	{
+		ID:       "0012_add_thread_temperature",
+		Migrate:  addColumns(&slackThreadToSlugV8{}, "Temperature"),
+		Rollback: dropColumns(&slackThreadToSlugV8{}, "Temperature"),
+	},
+	{
+		ID:       "0013_add_thread_memory_off",
+		Migrate:  addColumns(&slackThreadToSlugV9{}, "MemoryOff"),
+		Rollback: dropColumns(&slackThreadToSlugV9{}, "MemoryOff"),
+	},
+	{
+		ID:       "0014_add_thread_strict",
+		Migrate:  addColumns(&slackThreadToSlugV10{}, "Strict"),
+		Rollback: dropColumns(&slackThreadToSlugV10{}, "Strict"),
+	},
+}
+
+// AutoMigrate applies the migrations missing from the database in order, each one in its own transaction
+func (g *Database) AutoMigrate() error {
+	return migrate(g.db, migrations)
+}
+
+// RollbackMigration reverts the last applied migration and returns its ID, or an empty ID when none was applied
+func (g *Database) RollbackMigration() (string, error) {
+	return rollback(g.db, migrations)
+}
+
+// SchemaVersion returns the ID of the last applied migration, or an empty string when none was applied
+func (g *Database) SchemaVersion() (string, error) {
+	return schemaVersion(g.db)
+}
+
+func migrate(db *gorm.DB, migrations []migration) error {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return fmt.Errorf("failed to create the schema migrations table: %w", err)
+	}
+
+	var applied []SchemaMigration
+	if err := db.Find(&applied).Error; err != nil {
+		return fmt.Errorf("failed to get the applied migrations: %w", err)
+	}
+	appliedIDs := make(map[string]struct{}, len(applied))
+	for _, schemaMigration := range applied {
+		appliedIDs[schemaMigration.ID] = struct{}{}
+	}
+
+	for _, m := range migrations {
+		if _, ok := appliedIDs[m.ID]; ok {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Migrate(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{ID: m.ID, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("failed to apply migration %s: %w", m.ID, err)
+		}
+	}
+	return nil
+}
+
+func rollback(db *gorm.DB, migrations []migration) (string, error) {
+	version, err := schemaVersion(db)
+	if err != nil || version == "" {
+		return "", err
+	}
+
+	for _, m := range migrations {
+		if m.ID != version {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Rollback(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, "id = ?", m.ID).Error
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to roll back migration %s: %w", m.ID, err)
+		}
+		return m.ID, nil
+	}
+	return "", fmt.Errorf("unknown migration %s", version)
+}
+
+func schemaVersion(db *gorm.DB) (string, error) {
+	if !db.Migrator().HasTable(&SchemaMigration{}) {
+		return "", nil
+	}
+
+	var last SchemaMigration
+	result := db.Order("id desc").First(&last)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if result.Error != nil {
+		return "", result.Error
+	}
+	return last.ID, nil
+}
+
+// createTable creates the table of the model, a table created by AutoMigrate before versioning is kept
+func createTable(model interface{}) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		if tx.Migrator().HasTable(model) {
+			return nil
+		}
+		return tx.Migrator().CreateTable(model)
+	}
+}
+
+func dropTable(model interface{}) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(model)
+	}
+}
+
+// addColumns adds the fields of the model to its table, columns added by AutoMigrate before versioning are kept
+func addColumns(model interface{}, fields ...string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		for _, field := range fields {
+			if tx.Migrator().HasColumn(model, field) {
+				continue
+			}
+			if err := tx.Migrator().AddColumn(model, field); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func dropColumns(model interface{}, fields ...string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		for _, field := range fields {
+			if err := tx.Migrator().DropColumn(model, field); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
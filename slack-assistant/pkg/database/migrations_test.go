@@ -0,0 +1,168 @@
+package database_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/database"
+)
+
+const latestMigration = "0014_add_thread_strict"
+
+var _ = Describe("Migrations", func() {
+	var (
+		tmpDir string
+		dbPath string
+		// closers close the databases opened by the spec before the goroutine leak check
+		closers []func() error
+	)
+
+	// openRaw opens the database file without migrating it, to build an older schema
+	openRaw := func() *gorm.DB {
+		raw, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
+		Expect(err).NotTo(HaveOccurred())
+		sqlDB, err := raw.DB()
+		Expect(err).NotTo(HaveOccurred())
+		closers = append(closers, sqlDB.Close)
+		return raw
+	}
+
+	// migrated opens the database file and applies the migrations
+	migrated := func() *database.Database {
+		db, err := database.NewDatabase(dbPath)
+		Expect(err).NotTo(HaveOccurred())
+		closers = append(closers, db.Close)
+		Expect(db.AutoMigrate()).To(Succeed())
+		return db
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "slack-ai-migrations-*")
+		Expect(err).NotTo(HaveOccurred())
+		dbPath = filepath.Join(tmpDir, "test.db")
+		closers = nil
+	})
+
+	AfterEach(func() {
+		for _, closeDB := range closers {
+			Expect(closeDB()).To(Succeed())
+		}
+		Expect(os.RemoveAll(tmpDir)).To(Succeed())
+	})
+
+	It("should migrate a fresh database to the latest version", func() {
+		db := migrated()
+
+		version, err := db.SchemaVersion()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(version).To(Equal(latestMigration))
+
+		Expect(db.CreateSlackThreadWithSlug("thread", "slug", "sriov", "4.16")).To(Succeed())
+		Expect(db.SetElaborateSlugForThread("thread", "elaborate-slug")).To(Succeed())
+		Expect(db.SetLastQuestion("thread", "How do I create a VF?")).To(Succeed())
+		Expect(db.RecordCommand(&database.CommandAudit{User: "U1", Command: "answer"})).To(Succeed())
+		_, err = db.EnqueueWork("app_mention", "{}")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(db.RecordInjection(&database.InjectedDocument{SlackThread: "thread", Project: "sriov", Version: "4.16"})).To(Succeed())
+		Expect(db.SetThreadMode("thread", "chat")).To(Succeed())
+		Expect(db.SetThreadTopK("thread", 8)).To(Succeed())
+		Expect(db.SetThreadTruncated("thread", true)).To(Succeed())
+		Expect(db.RecordFeedback(&database.Feedback{SlackThread: "thread", Correction: "Use a policy"})).To(Succeed())
+		Expect(db.SetThreadTemperature("thread", 0.7)).To(Succeed())
+		Expect(db.SetThreadMemoryOff("thread", true)).To(Succeed())
+		Expect(db.SetThreadStrict("thread", true)).To(Succeed())
+	})
+
+	It("should not apply a migration twice", func() {
+		migrated()
+		db := migrated()
+
+		var applied []database.SchemaMigration
+		raw := openRaw()
+		Expect(raw.Order("id").Find(&applied).Error).To(Succeed())
+		Expect(applied).To(HaveLen(14))
+		Expect(applied[0].ID).To(Equal("0001_create_slack_thread_to_slugs"))
+		Expect(applied[13].ID).To(Equal(latestMigration))
+
+		version, err := db.SchemaVersion()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(version).To(Equal(latestMigration))
+	})
+
+	It("should migrate the first schema forward and keep its threads", func() {
+		raw := openRaw()
+		Expect(raw.Exec("CREATE TABLE slack_thread_to_slugs (slack_thread text, thread_slug text, PRIMARY KEY (slack_thread))").Error).To(Succeed())
+		Expect(raw.Exec("INSERT INTO slack_thread_to_slugs (slack_thread, thread_slug) VALUES ('old_thread', 'old_slug')").Error).To(Succeed())
+
+		db := migrated()
+
+		slug, found, err := db.GetSlugForThread("old_thread")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(slug).To(Equal("old_slug"))
+
+		Expect(db.UpdateThreadContext("old_thread", "new_slug", "metallb", "4.18")).To(Succeed())
+		Expect(db.SetLastQuestion("old_thread", "How do I configure BGP?")).To(Succeed())
+		threadContext, found, err := db.GetThreadContext("old_thread")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(threadContext.Project).To(Equal("metallb"))
+		Expect(threadContext.LastQuestion).To(Equal("How do I configure BGP?"))
+	})
+
+	It("should adopt a database created by AutoMigrate before versioning", func() {
+		raw := openRaw()
+		Expect(raw.AutoMigrate(&database.SlackThreadToSlug{}, &database.CommandAudit{}, &database.QueuedWork{})).To(Succeed())
+		Expect(raw.Create(&database.SlackThreadToSlug{SlackThread: "thread", ThreadSlug: "slug", Project: "sriov", Version: "4.16"}).Error).To(Succeed())
+
+		db := migrated()
+
+		version, err := db.SchemaVersion()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(version).To(Equal(latestMigration))
+		threadContext, found, err := db.GetThreadContext("thread")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(threadContext.Project).To(Equal("sriov"))
+	})
+
+	It("should roll back the last migration and apply it again", func() {
+		db := migrated()
+		Expect(db.CreateSlackThreadWithSlug("thread", "slug", "sriov", "4.16")).To(Succeed())
+
+		rolledBack, err := db.RollbackMigration()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rolledBack).To(Equal(latestMigration))
+		version, err := db.SchemaVersion()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(version).To(Equal("0013_add_thread_memory_off"))
+		Expect(db.SetThreadStrict("thread", true)).NotTo(Succeed())
+
+		Expect(db.AutoMigrate()).To(Succeed())
+		Expect(db.SetThreadStrict("thread", true)).To(Succeed())
+		slug, found, err := db.GetSlugForThread("thread")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(slug).To(Equal("slug"))
+	})
+
+	It("should report no version before the first migration", func() {
+		db, err := database.NewDatabase(dbPath)
+		Expect(err).NotTo(HaveOccurred())
+		closers = append(closers, db.Close)
+
+		version, err := db.SchemaVersion()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(version).To(BeEmpty())
+
+		rolledBack, err := db.RollbackMigration()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rolledBack).To(BeEmpty())
+	})
+})
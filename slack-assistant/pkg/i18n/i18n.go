@@ -0,0 +1,89 @@
+// Package i18n translates the user-facing messages of the assistant, the catalogs are embedded JSON files keyed by locale
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// DefaultLocale is the locale used unless configured otherwise, its catalog is the fallback of the missing keys
+const DefaultLocale = "en"
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// catalog maps the message keys to the messages of a locale
+type catalog map[string]string
+
+var (
+	catalogs = mustLoadCatalogs()
+	active   atomic.Pointer[catalog]
+)
+
+func init() {
+	english := catalogs[DefaultLocale]
+	active.Store(&english)
+}
+
+// mustLoadCatalogs loads the embedded catalogs, they are part of the binary so a malformed one is a build error
+func mustLoadCatalogs() map[string]catalog {
+	entries, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(fmt.Sprintf("failed to read the locale catalogs: %v", err))
+	}
+
+	loaded := map[string]catalog{}
+	for _, entry := range entries {
+		data, err := localeFiles.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("failed to read the locale catalog %s: %v", entry.Name(), err))
+		}
+		messages := catalog{}
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("failed to parse the locale catalog %s: %v", entry.Name(), err))
+		}
+		loaded[strings.TrimSuffix(entry.Name(), ".json")] = messages
+	}
+	return loaded
+}
+
+// Locales returns the available locales, sorted
+func Locales() []string {
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	slices.Sort(locales)
+	return locales
+}
+
+// SetLocale selects the locale of the messages returned by T
+func SetLocale(locale string) error {
+	messages, ok := catalogs[locale]
+	if !ok {
+		return fmt.Errorf("unknown locale %q, available locales: %s", locale, strings.Join(Locales(), ", "))
+	}
+	active.Store(&messages)
+	return nil
+}
+
+// T returns the message of the key in the active locale, formatted with the args like fmt.Sprintf.
+// Keys missing from the locale fall back to English, unknown keys are returned as is.
+func T(key string, args ...any) string {
+	message, ok := (*active.Load())[key]
+	if !ok {
+		message, ok = catalogs[DefaultLocale][key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
@@ -0,0 +1,70 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+)
+
+// useLocale selects the locale for the test and restores the default one afterwards
+func useLocale(t *testing.T, locale string) {
+	t.Helper()
+	if err := SetLocale(locale); err != nil {
+		t.Fatalf("Expected the %s locale to exist, got %v", locale, err)
+	}
+	t.Cleanup(func() {
+		if err := SetLocale(DefaultLocale); err != nil {
+			t.Errorf("Failed to restore the default locale: %v", err)
+		}
+	})
+}
+
+func TestT(t *testing.T) {
+	if message := T("progress.searching"); message != "Searching for answer..." {
+		t.Errorf("Expected the English message by default, got '%s'", message)
+	}
+	if message := T("threads.none", 3); message != "There are no threads on page 3" {
+		t.Errorf("Expected the message formatted with its args, got '%s'", message)
+	}
+	if message := T("no.such.key"); message != "no.such.key" {
+		t.Errorf("Expected an unknown key to be returned as is, got '%s'", message)
+	}
+
+	useLocale(t, "es")
+	if message := T("threads.none", 3); message != "No hay hilos en la página 3" {
+		t.Errorf("Expected the Spanish message, got '%s'", message)
+	}
+}
+
+func TestT_FallsBackToEnglish(t *testing.T) {
+	catalogs["test"] = catalog{"progress.searching": "Recherche..."}
+	t.Cleanup(func() { delete(catalogs, "test") })
+	useLocale(t, "test")
+
+	if message := T("progress.searching"); message != "Recherche..." {
+		t.Errorf("Expected the message of the locale, got '%s'", message)
+	}
+	if message := T("progress.elaborating"); message != "Elaborating..." {
+		t.Errorf("Expected the English message for a missing key, got '%s'", message)
+	}
+}
+
+func TestSetLocale_Unknown(t *testing.T) {
+	err := SetLocale("xx")
+	if err == nil || !strings.Contains(err.Error(), "en, es") {
+		t.Errorf("Expected an error listing the available locales, got %v", err)
+	}
+	if message := T("progress.searching"); message != "Searching for answer..." {
+		t.Errorf("Expected the active locale to be kept, got '%s'", message)
+	}
+}
+
+func TestCatalogs(t *testing.T) {
+	// Every message of a locale must translate an English message
+	for locale, messages := range catalogs {
+		for key := range messages {
+			if _, ok := catalogs[DefaultLocale][key]; !ok {
+				t.Errorf("Locale %s has the key %s that English doesn't have", locale, key)
+			}
+		}
+	}
+}
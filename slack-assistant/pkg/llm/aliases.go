@@ -0,0 +1,97 @@
+package llm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// WorkspaceAliases routes projects and versions to the workspaces whose slug doesn't follow WorkspaceSlug, e.g. a
+// workspace created by hand with another name. It is shared by the AnythingLLM client and the admin alias command
+type WorkspaceAliases struct {
+	mu sync.RWMutex
+	// slugs are the workspace slugs by project/version
+	slugs map[string]string
+}
+
+// NewWorkspaceAliases returns the aliases of the project/version=slug values, e.g. sriov/4.16=team-sriov
+func NewWorkspaceAliases(values ...string) (*WorkspaceAliases, error) {
+	aliases := &WorkspaceAliases{slugs: make(map[string]string, len(values))}
+	for _, value := range values {
+		projectVersion, slug, found := strings.Cut(strings.TrimSpace(value), "=")
+		project, version, hasVersion := strings.Cut(strings.TrimSpace(projectVersion), "/")
+		slug = strings.TrimSpace(slug)
+		if !found || !hasVersion || project == "" || version == "" || slug == "" {
+			return nil, fmt.Errorf("invalid workspace alias %q, expected project/version=slug", value)
+		}
+		aliases.Set(project, version, slug)
+	}
+	return aliases, nil
+}
+
+// Set routes the project and version to the workspace slug, an empty slug removes the alias
+func (w *WorkspaceAliases) Set(project, version, slug string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	key := project + "/" + version
+	if slug == "" {
+		delete(w.slugs, key)
+		return
+	}
+	w.slugs[key] = slug
+}
+
+// Slug returns the workspace slug of the project and version, the alias when there is one and the WorkspaceSlug
+// derived from them otherwise, nil aliases always derive it
+func (w *WorkspaceAliases) Slug(project, version string) string {
+	if w == nil {
+		return WorkspaceSlug(project, version)
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if slug, ok := w.slugs[project+"/"+version]; ok {
+		return slug
+	}
+	return WorkspaceSlug(project, version)
+}
+
+// List returns the aliases as sorted project/version=slug values
+func (w *WorkspaceAliases) List() []string {
+	if w == nil {
+		return nil
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	aliases := make([]string, 0, len(w.slugs))
+	for projectVersion, slug := range w.slugs {
+		aliases = append(aliases, projectVersion+"="+slug)
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// derived returns the sorted workspace slug with the WorkspaceSlugs of every project and version aliased to it, so
+// the aliased workspaces are listed like the others while the workspace stays known under its own slug
+func (w *WorkspaceAliases) derived(slug string) []string {
+	if w == nil {
+		return []string{slug}
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	derived := []string{slug}
+	for projectVersion, alias := range w.slugs {
+		if alias == slug {
+			project, version, _ := strings.Cut(projectVersion, "/")
+			if projectSlug := WorkspaceSlug(project, version); projectSlug != slug {
+				derived = append(derived, projectSlug)
+			}
+		}
+	}
+	sort.Strings(derived)
+	return derived
+}
@@ -0,0 +1,68 @@
+package llm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewWorkspaceAliases(t *testing.T) {
+	aliases, err := NewWorkspaceAliases("sriov/4.16=team-sriov", " metallb/4.18 = metallb-prod ")
+	if err != nil {
+		t.Fatalf("NewWorkspaceAliases failed: %v", err)
+	}
+
+	tests := []struct {
+		project, version, slug string
+	}{
+		{project: "sriov", version: "4.16", slug: "team-sriov"},
+		{project: "metallb", version: "4.18", slug: "metallb-prod"},
+		{project: "sriov", version: "4.18", slug: "sriov-4-dot-18"},
+	}
+	for _, tt := range tests {
+		if slug := aliases.Slug(tt.project, tt.version); slug != tt.slug {
+			t.Errorf("Expected %s/%s to route to %q, got %q", tt.project, tt.version, tt.slug, slug)
+		}
+	}
+	if list := aliases.List(); !reflect.DeepEqual(list, []string{"metallb/4.18=metallb-prod", "sriov/4.16=team-sriov"}) {
+		t.Errorf("Unexpected aliases: %v", list)
+	}
+
+	for _, value := range []string{"sriov/4.16", "sriov=team-sriov", "/4.16=team-sriov", "sriov/=team-sriov", "sriov/4.16="} {
+		if _, err := NewWorkspaceAliases(value); err == nil {
+			t.Errorf("Expected %q to be rejected", value)
+		}
+	}
+}
+
+func TestWorkspaceAliases_Nil(t *testing.T) {
+	var aliases *WorkspaceAliases
+	if slug := aliases.Slug("sriov", "4.16"); slug != "sriov-4-dot-16" {
+		t.Errorf("Expected the derived slug, got %q", slug)
+	}
+	if slugs := aliases.derived("team-sriov"); !reflect.DeepEqual(slugs, []string{"team-sriov"}) {
+		t.Errorf("Expected the slug as is, got %v", slugs)
+	}
+}
+
+func TestWorkspaceAliases_Derived(t *testing.T) {
+	aliases, err := NewWorkspaceAliases("sriov/4.18=team-sriov", "sriov/4.16=team-sriov", "metallb/4.18=metallb-prod",
+		"nmstate/4.17=nmstate-4-dot-16", "nmstate/4.16=nmstate-4-dot-16")
+	if err != nil {
+		t.Fatalf("NewWorkspaceAliases failed: %v", err)
+	}
+
+	// Every project and version sharing the workspace is listed with the workspace itself
+	if slugs := aliases.derived("team-sriov"); !reflect.DeepEqual(slugs, []string{"sriov-4-dot-16", "sriov-4-dot-18", "team-sriov"}) {
+		t.Errorf("Unexpected derived slugs: %v", slugs)
+	}
+	if slugs := aliases.derived("metallb-prod"); !reflect.DeepEqual(slugs, []string{"metallb-4-dot-18", "metallb-prod"}) {
+		t.Errorf("Unexpected derived slugs: %v", slugs)
+	}
+	// A workspace named after a project stays known when another version is aliased to it
+	if slugs := aliases.derived("nmstate-4-dot-16"); !reflect.DeepEqual(slugs, []string{"nmstate-4-dot-16", "nmstate-4-dot-17"}) {
+		t.Errorf("Unexpected derived slugs: %v", slugs)
+	}
+	if slugs := aliases.derived("ovn-4-dot-16"); !reflect.DeepEqual(slugs, []string{"ovn-4-dot-16"}) {
+		t.Errorf("Expected the slug as is, got %v", slugs)
+	}
+}
@@ -0,0 +1,178 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultBreakerFailureThreshold is the number of consecutive failures that opens the circuit breaker
+	DefaultBreakerFailureThreshold = 5
+	// DefaultBreakerCoolDown is how long the circuit breaker stays open before probing the backend again
+	DefaultBreakerCoolDown = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned without calling the LLM backend while the circuit breaker is open
+var ErrCircuitOpen = errors.New("the assistant is temporarily unavailable")
+
+// breakerState is the state of the circuit breaker
+type breakerState int
+
+const (
+	// breakerClosed lets every call through
+	breakerClosed breakerState = iota
+	// breakerOpen rejects every call until the cool-down is over
+	breakerOpen
+	// breakerHalfOpen lets a single probe through to check whether the backend recovered
+	breakerHalfOpen
+)
+
+// CircuitBreaker wraps an LLM client and stops calling it after consecutive failures,
+// so queued questions fail fast while the backend is down instead of waiting on doomed HTTP calls
+type CircuitBreaker struct {
+	client           Interface
+	failureThreshold int
+	coolDown         time.Duration
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+	now      func() time.Time
+}
+
+// NewCircuitBreaker wraps the client in a circuit breaker that opens after failureThreshold consecutive failures
+// and probes the backend again once coolDown has passed
+func NewCircuitBreaker(client Interface, failureThreshold int, coolDown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		client:           client,
+		failureThreshold: failureThreshold,
+		coolDown:         coolDown,
+		now:              time.Now,
+	}
+}
+
+// CreateThread creates a thread through the circuit breaker
+func (b *CircuitBreaker) CreateThread(ctx context.Context, project, version string) (string, error) {
+	return guard(b, func() (string, error) { return b.client.CreateThread(ctx, project, version) })
+}
+
+// SendMessageToChat sends the message through the circuit breaker
+func (b *CircuitBreaker) SendMessageToChat(ctx context.Context, project, version, threadSlug, message string) (string, error) {
+	return guard(b, func() (string, error) {
+		return b.client.SendMessageToChat(ctx, project, version, threadSlug, message)
+	})
+}
+
+// Elaborate elaborates the message through the circuit breaker
+func (b *CircuitBreaker) Elaborate(ctx context.Context, workspace, threadSlug, message string) (string, error) {
+	return guard(b, func() (string, error) { return b.client.Elaborate(ctx, workspace, threadSlug, message) })
+}
+
+// Inject injects the message through the circuit breaker
+func (b *CircuitBreaker) Inject(ctx context.Context, project, version, message string, metadata DocumentMetadata) error {
+	_, err := guard(b, func() (struct{}, error) {
+		return struct{}{}, b.client.Inject(ctx, project, version, message, metadata)
+	})
+	return err
+}
+
+// InjectBatch injects the documents through the circuit breaker
+func (b *CircuitBreaker) InjectBatch(ctx context.Context, project, version string, docs []Document) error {
+	_, err := guard(b, func() (struct{}, error) {
+		return struct{}{}, b.client.InjectBatch(ctx, project, version, docs)
+	})
+	return err
+}
+
+// ListProjects lists the projects through the circuit breaker
+func (b *CircuitBreaker) ListProjects(ctx context.Context) ([]string, error) {
+	return guard(b, func() ([]string, error) { return b.client.ListProjects(ctx) })
+}
+
+// ListDocuments lists the documents through the circuit breaker
+func (b *CircuitBreaker) ListDocuments(ctx context.Context, project, version string) ([]DocumentInfo, error) {
+	return guard(b, func() ([]DocumentInfo, error) { return b.client.ListDocuments(ctx, project, version) })
+}
+
+// Ping checks the backend through the circuit breaker, an open circuit breaker reports the backend as down
+func (b *CircuitBreaker) Ping(ctx context.Context) error {
+	_, err := guard(b, func() (struct{}, error) { return struct{}{}, b.client.Ping(ctx) })
+	return err
+}
+
+// DeleteThread deletes a thread through the circuit breaker
+func (b *CircuitBreaker) DeleteThread(ctx context.Context, project, version, threadSlug string) error {
+	_, err := guard(b, func() (struct{}, error) {
+		return struct{}{}, b.client.DeleteThread(ctx, project, version, threadSlug)
+	})
+	return err
+}
+
+// LastContext returns the last context of the thread, it isn't a backend call and bypasses the circuit breaker
+func (b *CircuitBreaker) LastContext(threadSlug string) []ContextChunk {
+	return b.client.LastContext(threadSlug)
+}
+
+// guard runs the call when the circuit breaker allows it and records its outcome
+func guard[T any](b *CircuitBreaker, call func() (T, error)) (T, error) {
+	if err := b.allow(); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	result, err := call()
+	b.record(err)
+	return result, err
+}
+
+// allow reports whether a call may reach the backend, the first call after the cool-down becomes the probe
+func (b *CircuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.coolDown {
+			return ErrCircuitOpen
+		}
+		fmt.Println("🔌 LLM circuit breaker half-open, probing the backend")
+		b.state = breakerHalfOpen
+	case breakerHalfOpen:
+		// Only the probe reaches the backend until it completes
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// record updates the circuit breaker with the outcome of a call,
+// cancelled calls say nothing about the backend and are not counted as failures
+func (b *CircuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	// A strict answer without relevant documents or an expired thread is still an answer of the backend
+	case err == nil, errors.Is(err, ErrNoInformation), errors.Is(err, ErrThreadNotFound):
+		if b.state != breakerClosed {
+			fmt.Println("✅ LLM backend recovered, circuit breaker closed")
+		}
+		b.state = breakerClosed
+		b.failures = 0
+	case errors.Is(err, context.Canceled):
+		if b.state == breakerHalfOpen {
+			// Let the next call probe again, the cool-down is already over
+			b.state = breakerOpen
+		}
+	default:
+		b.failures++
+		if b.state == breakerHalfOpen || b.failures >= b.failureThreshold {
+			fmt.Printf("🔌 LLM circuit breaker open for %s after %d consecutive failures: %v\n", b.coolDown, b.failures, err)
+			b.state = breakerOpen
+			b.openedAt = b.now()
+		}
+	}
+}
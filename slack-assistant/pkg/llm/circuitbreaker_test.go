@@ -0,0 +1,165 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// flakyClient is an LLM client whose calls fail with err, it only implements the calls used by the tests
+type flakyClient struct {
+	Interface
+	err   error
+	calls int
+}
+
+func (c *flakyClient) SendMessageToChat(_ context.Context, _, _, _, message string) (string, error) {
+	c.calls++
+	if c.err != nil {
+		return "", c.err
+	}
+	return "answer to: " + message, nil
+}
+
+func (c *flakyClient) Inject(_ context.Context, _, _, _ string, _ DocumentMetadata) error {
+	c.calls++
+	return c.err
+}
+
+func (c *flakyClient) Ping(_ context.Context) error {
+	c.calls++
+	return c.err
+}
+
+// newTestBreaker returns a circuit breaker around the client with a clock controlled by the test
+func newTestBreaker(client Interface) (*CircuitBreaker, *time.Time) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	breaker := NewCircuitBreaker(client, 3, time.Minute)
+	breaker.now = func() time.Time { return now }
+	return breaker, &now
+}
+
+func TestCircuitBreaker_Lifecycle(t *testing.T) {
+	client := &flakyClient{err: errors.New("connection refused")}
+	breaker, now := newTestBreaker(client)
+	ctx := context.Background()
+
+	// Closed: the failures reach the backend until the threshold is hit
+	for i := 0; i < 3; i++ {
+		if _, err := breaker.SendMessageToChat(ctx, "sriov", "4.16", "thread", "question"); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Expected call %d to reach the backend, got %v", i+1, err)
+		}
+	}
+
+	// Open: calls are short-circuited during the cool-down
+	if _, err := breaker.SendMessageToChat(ctx, "sriov", "4.16", "thread", "question"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected the circuit to be open, got %v", err)
+	}
+	if client.calls != 3 {
+		t.Fatalf("Expected 3 backend calls, got %d", client.calls)
+	}
+
+	// Half-open: a failed probe opens the circuit again for a new cool-down
+	*now = now.Add(time.Minute)
+	if _, err := breaker.SendMessageToChat(ctx, "sriov", "4.16", "thread", "question"); errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected the probe to reach the backend, got %v", err)
+	}
+	if _, err := breaker.SendMessageToChat(ctx, "sriov", "4.16", "thread", "question"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected the failed probe to open the circuit, got %v", err)
+	}
+
+	// Half-open: a successful probe closes the circuit
+	*now = now.Add(time.Minute)
+	client.err = nil
+	if _, err := breaker.SendMessageToChat(ctx, "sriov", "4.16", "thread", "question"); err != nil {
+		t.Fatalf("Expected the probe to succeed, got %v", err)
+	}
+
+	// Closed: the failure count starts over
+	client.err = errors.New("connection refused")
+	for i := 0; i < 2; i++ {
+		//nolint:errcheck // only the breaker state matters here
+		_ = breaker.Inject(ctx, "sriov", "4.16", "content", DocumentMetadata{})
+	}
+	if _, err := breaker.SendMessageToChat(ctx, "sriov", "4.16", "thread", "question"); errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected the circuit to stay closed below the threshold, got %v", err)
+	}
+	if client.calls != 8 {
+		t.Errorf("Expected 8 backend calls, got %d", client.calls)
+	}
+}
+
+func TestCircuitBreaker_SuccessResetsFailures(t *testing.T) {
+	client := &flakyClient{err: errors.New("connection refused")}
+	breaker, _ := newTestBreaker(client)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		//nolint:errcheck // only the breaker state matters here
+		_ = breaker.Inject(ctx, "sriov", "4.16", "content", DocumentMetadata{})
+	}
+	client.err = nil
+	if err := breaker.Inject(ctx, "sriov", "4.16", "content", DocumentMetadata{}); err != nil {
+		t.Fatalf("Inject failed: %v", err)
+	}
+
+	client.err = errors.New("connection refused")
+	for i := 0; i < 2; i++ {
+		if err := breaker.Inject(ctx, "sriov", "4.16", "content", DocumentMetadata{}); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Expected the circuit to be closed after a success, got %v", err)
+		}
+	}
+}
+
+func TestCircuitBreaker_IgnoresCanceledCalls(t *testing.T) {
+	client := &flakyClient{err: context.Canceled}
+	breaker, _ := newTestBreaker(client)
+
+	for i := 0; i < 5; i++ {
+		if err := breaker.Inject(context.Background(), "sriov", "4.16", "content", DocumentMetadata{}); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Expected cancelled calls to leave the circuit closed, got %v", err)
+		}
+	}
+}
+
+func TestCircuitBreaker_IgnoresAnswersWithoutInformation(t *testing.T) {
+	client := &flakyClient{err: fmt.Errorf("failed to answer: %w", ErrNoInformation)}
+	breaker, _ := newTestBreaker(client)
+
+	for i := 0; i < 5; i++ {
+		if _, err := breaker.SendMessageToChat(context.Background(), "sriov", "4.16", "slug", "question"); !errors.Is(err, ErrNoInformation) {
+			t.Fatalf("Expected strict answers without information to leave the circuit closed, got %v", err)
+		}
+	}
+}
+
+func TestCircuitBreaker_IgnoresExpiredThreads(t *testing.T) {
+	client := &flakyClient{err: fmt.Errorf("failed to send message: %w", ErrThreadNotFound)}
+	breaker, _ := newTestBreaker(client)
+
+	for i := 0; i < 5; i++ {
+		if _, err := breaker.SendMessageToChat(context.Background(), "sriov", "4.16", "slug", "question"); !errors.Is(err, ErrThreadNotFound) {
+			t.Fatalf("Expected expired threads to leave the circuit closed, got %v", err)
+		}
+	}
+}
+
+func TestCircuitBreaker_PingReportsOpenCircuit(t *testing.T) {
+	client := &flakyClient{err: errors.New("connection refused")}
+	breaker, _ := newTestBreaker(client)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if err := breaker.Ping(ctx); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Expected ping %d to reach the backend, got %v", i+1, err)
+		}
+	}
+	if err := breaker.Ping(ctx); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected the open circuit to report the backend as down, got %v", err)
+	}
+	if client.calls != 3 {
+		t.Fatalf("Expected 3 backend calls, got %d", client.calls)
+	}
+}
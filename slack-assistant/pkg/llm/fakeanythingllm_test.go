@@ -0,0 +1,346 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeChat is a chat message received by the fake AnythingLLM server
+type fakeChat struct {
+	Workspace string
+	Thread    string
+	Message   string
+	Mode      string
+}
+
+// fakeAttachment is an attachment of a chat message received by the fake AnythingLLM server
+type fakeAttachment struct {
+	Name          string `json:"name"`
+	Mime          string `json:"mime"`
+	ContentString string `json:"contentString"`
+}
+
+// fakeWorkspaceUpdate is a workspace settings update received by the fake AnythingLLM server
+type fakeWorkspaceUpdate struct {
+	Workspace string
+	Settings  map[string]interface{}
+}
+
+// fakeAnythingLLM is an in-memory AnythingLLM server implementing the endpoints used by LLMClient
+type fakeAnythingLLM struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu               sync.Mutex
+	workspaces       map[string]bool
+	workspaceLookups int
+	threads          int
+	chats            []fakeChat
+	attachments      []fakeAttachment
+	documents        []map[string]interface{}
+	// failures makes the matching request path answer with the given status code
+	failures map[string]int
+	// failuresLeft is how many more requests to the path fail, the failures without a count never stop
+	failuresLeft map[string]int
+	// rateLimits makes the next requests to the path answer 429 Too Many Requests, with the Retry-After header
+	rateLimits  map[string]int
+	retryAfter  string
+	rateLimited int
+	// expiredThreads are the thread slugs whose chats are rejected as not existing
+	expiredThreads map[string]bool
+	// workspaceUpdates are the workspace settings updates received, before the chats they were sent for
+	workspaceUpdates []fakeWorkspaceUpdate
+	// noSources makes the chats answer a refusal without sources, like a query finding no relevant document
+	noSources bool
+	// deletedThreads are the workspace/thread slugs of the deleted threads
+	deletedThreads []string
+}
+
+// newFakeAnythingLLM starts a fake AnythingLLM server serving the given workspaces
+func newFakeAnythingLLM(t *testing.T, workspaces ...string) *fakeAnythingLLM {
+	t.Helper()
+
+	fake := &fakeAnythingLLM{
+		t:            t,
+		workspaces:   make(map[string]bool),
+		failures:     make(map[string]int),
+		failuresLeft: make(map[string]int),
+		rateLimits:   make(map[string]int),
+	}
+	for _, workspace := range workspaces {
+		fake.workspaces[workspace] = true
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/auth", fake.auth)
+	mux.HandleFunc("GET /api/v1/workspaces", fake.listWorkspaces)
+	mux.HandleFunc("GET /api/v1/workspace/{slug}", fake.getWorkspace)
+	mux.HandleFunc("POST /api/v1/workspace/{slug}/update", fake.updateWorkspace)
+	mux.HandleFunc("POST /api/v1/workspace/{slug}/thread/new", fake.newThread)
+	mux.HandleFunc("POST /api/v1/workspace/{slug}/thread/{threadSlug}/chat", fake.chat)
+	mux.HandleFunc("DELETE /api/v1/workspace/{slug}/thread/{threadSlug}", fake.deleteThread)
+	mux.HandleFunc("POST /api/v1/document/raw-text", fake.rawText)
+	fake.server = httptest.NewServer(fake.failOrServe(mux))
+	t.Cleanup(fake.server.Close)
+
+	return fake
+}
+
+// client returns an LLMClient pointed at the fake server
+func (f *fakeAnythingLLM) client(workspaceCacheTTL time.Duration) *LLMClient {
+	client := newLLMClient(strings.TrimPrefix(f.server.URL, "http://"), "test-key", workspaceCacheTTL)
+	client.apiClient.GetConfig().Debug = false
+	client.injectBackoff = time.Millisecond
+	return client
+}
+
+// removeWorkspace deletes the workspace from the fake server
+func (f *fakeAnythingLLM) removeWorkspace(slug string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.workspaces, slug)
+}
+
+// lookups returns the number of workspace existence checks received
+func (f *fakeAnythingLLM) lookups() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.workspaceLookups
+}
+
+// failWith makes every request to the path answer with the status code
+func (f *fakeAnythingLLM) failWith(path string, statusCode int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures[path] = statusCode
+}
+
+// expireThread makes the chats of the thread fail like an AnythingLLM thread that no longer exists
+func (f *fakeAnythingLLM) expireThread(threadSlug string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.expiredThreads == nil {
+		f.expiredThreads = map[string]bool{}
+	}
+	f.expiredThreads[threadSlug] = true
+}
+
+// findNothing makes the chats answer like a query that found no relevant document
+func (f *fakeAnythingLLM) findNothing() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.noSources = true
+}
+
+// failTimes makes the next times requests to the path answer with the status code
+func (f *fakeAnythingLLM) failTimes(path string, statusCode, times int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures[path] = statusCode
+	f.failuresLeft[path] = times
+}
+
+// rateLimit makes the next times requests to the path answer 429 Too Many Requests with the Retry-After header,
+// no header is sent when retryAfter is empty
+func (f *fakeAnythingLLM) rateLimit(path string, times int, retryAfter string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rateLimits[path] = times
+	f.retryAfter = retryAfter
+}
+
+// rateLimitedRequests returns the number of requests answered with 429 Too Many Requests
+func (f *fakeAnythingLLM) rateLimitedRequests() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rateLimited
+}
+
+func (f *fakeAnythingLLM) failOrServe(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-key" {
+			f.t.Errorf("Unexpected authorization header %q", r.Header.Get("Authorization"))
+		}
+
+		f.mu.Lock()
+		statusCode, ok := f.failures[r.URL.Path]
+		if left, counted := f.failuresLeft[r.URL.Path]; ok && counted {
+			if left <= 1 {
+				delete(f.failures, r.URL.Path)
+				delete(f.failuresLeft, r.URL.Path)
+			} else {
+				f.failuresLeft[r.URL.Path]--
+			}
+		}
+		if !ok && f.rateLimits[r.URL.Path] > 0 {
+			f.rateLimits[r.URL.Path]--
+			f.rateLimited++
+			statusCode, ok = http.StatusTooManyRequests, true
+			if f.retryAfter != "" {
+				w.Header().Set("Retry-After", f.retryAfter)
+			}
+		}
+		f.mu.Unlock()
+		if ok {
+			f.writeJSON(w, statusCode, map[string]interface{}{"error": http.StatusText(statusCode)})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (f *fakeAnythingLLM) auth(w http.ResponseWriter, _ *http.Request) {
+	f.writeJSON(w, http.StatusOK, map[string]interface{}{"authenticated": true})
+}
+
+func (f *fakeAnythingLLM) listWorkspaces(w http.ResponseWriter, _ *http.Request) {
+	f.mu.Lock()
+	workspaces := make([]map[string]interface{}, 0, len(f.workspaces))
+	for slug := range f.workspaces {
+		workspaces = append(workspaces, map[string]interface{}{"id": len(workspaces) + 1, "name": slug, "slug": slug})
+	}
+	f.mu.Unlock()
+
+	f.writeJSON(w, http.StatusOK, map[string]interface{}{"workspaces": workspaces})
+}
+
+func (f *fakeAnythingLLM) getWorkspace(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.workspaceLookups++
+	slug := r.PathValue("slug")
+	exists := f.workspaces[slug]
+	// Like AnythingLLM, the embedded documents carry their metadata as a JSON encoded string
+	documents := []map[string]interface{}{}
+	for index, document := range f.documents {
+		if document["addToWorkspaces"] != slug {
+			continue
+		}
+		metadata, err := json.Marshal(document["metadata"])
+		if err != nil {
+			f.t.Errorf("Failed to encode document metadata: %v", err)
+		}
+		documents = append(documents, map[string]interface{}{
+			"id":       index + 1,
+			"docId":    fmt.Sprintf("doc-%d", index+1),
+			"filename": fmt.Sprintf("raw-document-%d.json", index+1),
+			"metadata": string(metadata),
+		})
+	}
+	f.mu.Unlock()
+
+	if !exists {
+		f.writeJSON(w, http.StatusNotFound, map[string]interface{}{"workspace": nil})
+		return
+	}
+	f.writeJSON(w, http.StatusOK, map[string]interface{}{"workspace": []map[string]interface{}{{"slug": slug, "documents": documents}}})
+}
+
+func (f *fakeAnythingLLM) updateWorkspace(w http.ResponseWriter, r *http.Request) {
+	var settings map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		f.t.Errorf("Failed to decode workspace update request: %v", err)
+	}
+
+	f.mu.Lock()
+	f.workspaceUpdates = append(f.workspaceUpdates, fakeWorkspaceUpdate{Workspace: r.PathValue("slug"), Settings: settings})
+	f.mu.Unlock()
+
+	f.writeJSON(w, http.StatusOK, map[string]interface{}{"workspace": map[string]interface{}{"slug": r.PathValue("slug")}, "message": nil})
+}
+
+func (f *fakeAnythingLLM) newThread(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.threads++
+	id := f.threads
+	f.mu.Unlock()
+
+	f.writeJSON(w, http.StatusOK, map[string]interface{}{"thread": map[string]interface{}{
+		"id":          id,
+		"slug":        "thread-" + r.PathValue("slug"),
+		"name":        "Thread",
+		"workspaceId": r.PathValue("slug"),
+	}})
+}
+
+func (f *fakeAnythingLLM) deleteThread(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.deletedThreads = append(f.deletedThreads, r.PathValue("slug")+"/"+r.PathValue("threadSlug"))
+	f.mu.Unlock()
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (f *fakeAnythingLLM) chat(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Message     string           `json:"message"`
+		Mode        string           `json:"mode"`
+		Attachments []fakeAttachment `json:"attachments"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		f.t.Errorf("Failed to decode chat request: %v", err)
+	}
+
+	f.mu.Lock()
+	if f.expiredThreads[r.PathValue("threadSlug")] {
+		f.mu.Unlock()
+		f.writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"type":  "abort",
+			"error": "Workspace " + r.PathValue("slug") + " or thread " + r.PathValue("threadSlug") + " is not valid.",
+		})
+		return
+	}
+	f.attachments = append(f.attachments, request.Attachments...)
+	f.chats = append(f.chats, fakeChat{
+		Workspace: r.PathValue("slug"),
+		Thread:    r.PathValue("threadSlug"),
+		Message:   request.Message,
+		Mode:      request.Mode,
+	})
+	noSources := f.noSources
+	f.mu.Unlock()
+
+	if noSources {
+		f.writeJSON(w, http.StatusOK, map[string]interface{}{
+			"id":           "chat-id",
+			"type":         "textResponse",
+			"textResponse": "There is no relevant information in this workspace to answer your query.",
+			"sources":      []interface{}{},
+			"error":        nil,
+		})
+		return
+	}
+	f.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":           "chat-id",
+		"type":         "textResponse",
+		"textResponse": "answer to: " + request.Message,
+		"sources":      []interface{}{map[string]interface{}{"title": "sriov-faq.md", "text": "chunk", "score": 0.82}},
+		"error":        nil,
+	})
+}
+
+func (f *fakeAnythingLLM) rawText(w http.ResponseWriter, r *http.Request) {
+	var document map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&document); err != nil {
+		f.t.Errorf("Failed to decode raw text request: %v", err)
+	}
+
+	f.mu.Lock()
+	f.documents = append(f.documents, document)
+	f.mu.Unlock()
+
+	f.writeJSON(w, http.StatusOK, map[string]interface{}{"success": true, "error": nil, "documents": []interface{}{}})
+}
+
+func (f *fakeAnythingLLM) writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		f.t.Errorf("Failed to encode response: %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package llm
+
+import "context"
+
+// InjectLimiter wraps an LLM client and bounds the injects in flight with slots of their own, the other calls go
+// straight to the client so a bulk ingestion doesn't hold back the answers
+type InjectLimiter struct {
+	client Interface
+	// slots holds a token per inject in flight
+	slots chan struct{}
+}
+
+// NewInjectLimiter wraps the client in a limiter letting at most limit injects run at the same time
+func NewInjectLimiter(client Interface, limit int) *InjectLimiter {
+	return &InjectLimiter{
+		client: client,
+		slots:  make(chan struct{}, limit),
+	}
+}
+
+// CreateThread creates a thread without waiting for an inject slot
+func (l *InjectLimiter) CreateThread(ctx context.Context, project, version string) (string, error) {
+	return l.client.CreateThread(ctx, project, version)
+}
+
+// SendMessageToChat sends the message without waiting for an inject slot
+func (l *InjectLimiter) SendMessageToChat(ctx context.Context, project, version, threadSlug, message string) (string, error) {
+	return l.client.SendMessageToChat(ctx, project, version, threadSlug, message)
+}
+
+// Elaborate elaborates the message without waiting for an inject slot
+func (l *InjectLimiter) Elaborate(ctx context.Context, workspace, threadSlug, message string) (string, error) {
+	return l.client.Elaborate(ctx, workspace, threadSlug, message)
+}
+
+// Inject injects the message once an inject slot is free
+func (l *InjectLimiter) Inject(ctx context.Context, project, version, message string, metadata DocumentMetadata) error {
+	_, err := limit(ctx, l.slots, func() (struct{}, error) {
+		return struct{}{}, l.client.Inject(ctx, project, version, message, metadata)
+	})
+	return err
+}
+
+// InjectBatch injects the documents once an inject slot is free, the whole batch takes a single slot
+func (l *InjectLimiter) InjectBatch(ctx context.Context, project, version string, docs []Document) error {
+	_, err := limit(ctx, l.slots, func() (struct{}, error) {
+		return struct{}{}, l.client.InjectBatch(ctx, project, version, docs)
+	})
+	return err
+}
+
+// ListProjects lists the projects without waiting for an inject slot
+func (l *InjectLimiter) ListProjects(ctx context.Context) ([]string, error) {
+	return l.client.ListProjects(ctx)
+}
+
+// ListDocuments lists the documents without waiting for an inject slot
+func (l *InjectLimiter) ListDocuments(ctx context.Context, project, version string) ([]DocumentInfo, error) {
+	return l.client.ListDocuments(ctx, project, version)
+}
+
+// Ping checks the backend without waiting for an inject slot
+func (l *InjectLimiter) Ping(ctx context.Context) error {
+	return l.client.Ping(ctx)
+}
+
+// DeleteThread deletes a thread without waiting for an inject slot
+func (l *InjectLimiter) DeleteThread(ctx context.Context, project, version, threadSlug string) error {
+	return l.client.DeleteThread(ctx, project, version, threadSlug)
+}
+
+// LastContext returns the last context of the thread
+func (l *InjectLimiter) LastContext(threadSlug string) []ContextChunk {
+	return l.client.LastContext(threadSlug)
+}
@@ -0,0 +1,87 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ingestingClient is an LLM client whose injects run until release is closed, recording the highest number of
+// injects in flight, it only implements the calls used by the tests
+type ingestingClient struct {
+	Interface
+	release     chan struct{}
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+}
+
+func (c *ingestingClient) Inject(_ context.Context, _, _, _ string, _ DocumentMetadata) error {
+	current := c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+	for {
+		highest := c.maxInFlight.Load()
+		if current <= highest || c.maxInFlight.CompareAndSwap(highest, current) {
+			break
+		}
+	}
+	<-c.release
+	return nil
+}
+
+func (c *ingestingClient) SendMessageToChat(_ context.Context, _, _, _, message string) (string, error) {
+	return "answer to: " + message, nil
+}
+
+func TestInjectLimiter_BoundsInjectsWhileAnswersProceed(t *testing.T) {
+	const limit = 2
+	client := &ingestingClient{release: make(chan struct{})}
+	limiter := NewInjectLimiter(client, limit)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := limiter.Inject(context.Background(), "sriov", "4.16", "document", DocumentMetadata{}); err != nil {
+				t.Errorf("Expected the inject to succeed, got %v", err)
+			}
+		}()
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for client.inFlight.Load() < limit && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Every inject slot is taken, the answers don't wait for them
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := limiter.SendMessageToChat(ctx, "sriov", "4.16", "thread", "question"); err != nil {
+		t.Fatalf("Expected the answer not to wait for the injects, got %v", err)
+	}
+
+	close(client.release)
+	wg.Wait()
+	if highest := client.maxInFlight.Load(); highest != limit {
+		t.Fatalf("Expected at most %d injects in flight, got %d", limit, highest)
+	}
+}
+
+func TestInjectLimiter_CancelledWhileWaiting(t *testing.T) {
+	client := &ingestingClient{release: make(chan struct{})}
+	limiter := NewInjectLimiter(client, 1)
+	// Takes the only slot so the next inject has to wait
+	limiter.slots <- struct{}{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := limiter.Inject(ctx, "sriov", "4.16", "document", DocumentMetadata{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the wait to be abandoned, got %v", err)
+	}
+	if client.maxInFlight.Load() != 0 {
+		t.Fatal("Expected the inject not to reach the backend")
+	}
+}
@@ -0,0 +1,73 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	// maxInjectAttempts is how many times an inject is sent before failing, injects aren't interactive
+	// and carry larger payloads so they are retried longer than the answers
+	maxInjectAttempts = 5
+	// DefaultInjectBackoff is the wait before retrying a failed inject, doubled on every retry
+	DefaultInjectBackoff = 2 * time.Second
+)
+
+// InjectRetryFunc is called before an inject is retried with the attempt that failed, e.g. to tell the user
+type InjectRetryFunc func(attempt, maxAttempts int, wait time.Duration)
+
+type injectRetryKey struct{}
+
+// WithInjectRetryNotifier returns a context calling notify every time Inject or InjectBatch retries a document
+func WithInjectRetryNotifier(ctx context.Context, notify InjectRetryFunc) context.Context {
+	return context.WithValue(ctx, injectRetryKey{}, notify)
+}
+
+// InjectRetryNotifierFrom returns the function set on the context with WithInjectRetryNotifier, nil when none was set
+func InjectRetryNotifierFrom(ctx context.Context) InjectRetryFunc {
+	notify, _ := ctx.Value(injectRetryKey{}).(InjectRetryFunc)
+	return notify
+}
+
+// withInjectRetry sends the inject again while it fails before the backend could store it, waiting for an exponential
+// backoff, the last error is returned once the attempts are exhausted. The inject isn't idempotent, so a failure the
+// document may have survived, e.g. a 5xx or a timeout, isn't retried to not inject it twice
+func withInjectRetry(ctx context.Context, backoff time.Duration, inject func() error) error {
+	notify := InjectRetryNotifierFrom(ctx)
+	for attempt := 1; ; attempt++ {
+		err := inject()
+		if err == nil || attempt == maxInjectAttempts || ctx.Err() != nil || !isTransientInjectError(err) {
+			return err
+		}
+
+		wait := backoff << (attempt - 1)
+		fmt.Printf("⏳ Inject attempt %d/%d failed: %v, retrying in %s\n", attempt, maxInjectAttempts, err, wait)
+		if notify != nil {
+			notify(attempt, maxInjectAttempts, wait)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// isTransientInjectError reports whether an inject failure is worth retrying: the backend rejected it with 429 Too
+// Many Requests, or the connection couldn't be opened so the request was never sent
+func isTransientInjectError(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
@@ -0,0 +1,63 @@
+package llm
+
+import "sync"
+
+// MaxLastContexts is how many LLM threads the chunks of the last answer are kept for, the oldest is forgotten first
+const MaxLastContexts = 1000
+
+// lastContexts remembers the chunks the last answer of the most recently answered LLM threads was retrieved from,
+// the zero value is ready to use
+type lastContexts struct {
+	mu     sync.Mutex
+	chunks map[string][]ContextChunk
+	// order is the answered threads, the least recent first
+	order []string
+}
+
+// set remembers the chunks of the last answer of the thread
+func (c *lastContexts) set(threadSlug string, chunks []ContextChunk) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.chunks == nil {
+		c.chunks = make(map[string][]ContextChunk)
+	}
+	if _, ok := c.chunks[threadSlug]; ok {
+		for i, slug := range c.order {
+			if slug == threadSlug {
+				c.order = append(c.order[:i], c.order[i+1:]...)
+				break
+			}
+		}
+	}
+	c.chunks[threadSlug] = chunks
+	c.order = append(c.order, threadSlug)
+	if len(c.order) > MaxLastContexts {
+		delete(c.chunks, c.order[0])
+		c.order = c.order[1:]
+	}
+}
+
+// forget drops the chunks of the thread, e.g. once it is deleted
+func (c *lastContexts) forget(threadSlug string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.chunks[threadSlug]; !ok {
+		return
+	}
+	delete(c.chunks, threadSlug)
+	for i, slug := range c.order {
+		if slug == threadSlug {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// get returns the chunks of the last answer of the thread, nil when it wasn't answered since the start
+func (c *lastContexts) get(threadSlug string) []ContextChunk {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.chunks[threadSlug]
+}
@@ -0,0 +1,93 @@
+package llm
+
+import "context"
+
+// ConcurrencyLimiter wraps an LLM client and bounds the calls in flight across all the workers,
+// so a burst of questions queues in the bot instead of overloading the LLM backend
+type ConcurrencyLimiter struct {
+	client Interface
+	// slots holds a token per call in flight
+	slots chan struct{}
+}
+
+// NewConcurrencyLimiter wraps the client in a limiter letting at most limit calls run at the same time
+func NewConcurrencyLimiter(client Interface, limit int) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{
+		client: client,
+		slots:  make(chan struct{}, limit),
+	}
+}
+
+// CreateThread creates a thread once a slot is free
+func (l *ConcurrencyLimiter) CreateThread(ctx context.Context, project, version string) (string, error) {
+	return limit(ctx, l.slots, func() (string, error) { return l.client.CreateThread(ctx, project, version) })
+}
+
+// SendMessageToChat sends the message once a slot is free
+func (l *ConcurrencyLimiter) SendMessageToChat(ctx context.Context, project, version, threadSlug, message string) (string, error) {
+	return limit(ctx, l.slots, func() (string, error) {
+		return l.client.SendMessageToChat(ctx, project, version, threadSlug, message)
+	})
+}
+
+// Elaborate elaborates the message once a slot is free
+func (l *ConcurrencyLimiter) Elaborate(ctx context.Context, workspace, threadSlug, message string) (string, error) {
+	return limit(ctx, l.slots, func() (string, error) { return l.client.Elaborate(ctx, workspace, threadSlug, message) })
+}
+
+// Inject injects the message once a slot is free
+func (l *ConcurrencyLimiter) Inject(ctx context.Context, project, version, message string, metadata DocumentMetadata) error {
+	_, err := limit(ctx, l.slots, func() (struct{}, error) {
+		return struct{}{}, l.client.Inject(ctx, project, version, message, metadata)
+	})
+	return err
+}
+
+// InjectBatch injects the documents once a slot is free
+func (l *ConcurrencyLimiter) InjectBatch(ctx context.Context, project, version string, docs []Document) error {
+	_, err := limit(ctx, l.slots, func() (struct{}, error) {
+		return struct{}{}, l.client.InjectBatch(ctx, project, version, docs)
+	})
+	return err
+}
+
+// ListProjects lists the projects once a slot is free
+func (l *ConcurrencyLimiter) ListProjects(ctx context.Context) ([]string, error) {
+	return limit(ctx, l.slots, func() ([]string, error) { return l.client.ListProjects(ctx) })
+}
+
+// ListDocuments lists the documents once a slot is free
+func (l *ConcurrencyLimiter) ListDocuments(ctx context.Context, project, version string) ([]DocumentInfo, error) {
+	return limit(ctx, l.slots, func() ([]DocumentInfo, error) { return l.client.ListDocuments(ctx, project, version) })
+}
+
+// Ping checks the backend without waiting for a slot, so a busy bot isn't reported as down
+func (l *ConcurrencyLimiter) Ping(ctx context.Context) error {
+	return l.client.Ping(ctx)
+}
+
+// DeleteThread deletes a thread once a slot is free
+func (l *ConcurrencyLimiter) DeleteThread(ctx context.Context, project, version, threadSlug string) error {
+	_, err := limit(ctx, l.slots, func() (struct{}, error) {
+		return struct{}{}, l.client.DeleteThread(ctx, project, version, threadSlug)
+	})
+	return err
+}
+
+// LastContext returns the last context of the thread, it isn't a backend call and doesn't wait for a slot
+func (l *ConcurrencyLimiter) LastContext(threadSlug string) []ContextChunk {
+	return l.client.LastContext(threadSlug)
+}
+
+// limit waits for a free slot and runs the call, the wait is abandoned when the context is cancelled
+func limit[T any](ctx context.Context, slots chan struct{}, call func() (T, error)) (T, error) {
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+	defer func() { <-slots }()
+
+	return call()
+}
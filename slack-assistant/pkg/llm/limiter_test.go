@@ -0,0 +1,81 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// slowClient is an LLM client recording the highest number of calls in flight, it only implements the calls used by the tests
+type slowClient struct {
+	Interface
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+}
+
+func (c *slowClient) SendMessageToChat(_ context.Context, _, _, _, message string) (string, error) {
+	current := c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+	for {
+		highest := c.maxInFlight.Load()
+		if current <= highest || c.maxInFlight.CompareAndSwap(highest, current) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	return "answer to: " + message, nil
+}
+
+func TestConcurrencyLimiter_BoundsCallsInFlight(t *testing.T) {
+	const limit = 3
+	client := &slowClient{}
+	limiter := NewConcurrencyLimiter(client, limit)
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := limiter.SendMessageToChat(context.Background(), "sriov", "4.16", "thread", "question"); err != nil {
+				t.Errorf("Expected the call to succeed, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if highest := client.maxInFlight.Load(); highest != limit {
+		t.Fatalf("Expected at most %d calls in flight, got %d", limit, highest)
+	}
+}
+
+func TestConcurrencyLimiter_CancelledWhileWaiting(t *testing.T) {
+	client := &slowClient{}
+	limiter := NewConcurrencyLimiter(client, 1)
+	// Takes the only slot so the next call has to wait
+	limiter.slots <- struct{}{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.SendMessageToChat(ctx, "sriov", "4.16", "thread", "question"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the wait to be abandoned, got %v", err)
+	}
+	if client.maxInFlight.Load() != 0 {
+		t.Fatal("Expected the call not to reach the backend")
+	}
+}
+
+func TestConcurrencyLimiter_PingDoesNotWait(t *testing.T) {
+	client := &flakyClient{}
+	limiter := NewConcurrencyLimiter(client, 1)
+	// Takes the only slot, like a long answer in flight
+	limiter.slots <- struct{}{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := limiter.Ping(ctx); err != nil {
+		t.Fatalf("Expected the ping to skip the limit, got %v", err)
+	}
+}
@@ -3,44 +3,83 @@ package llm
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// DefaultLlamaIndexHost is the LlamaIndex server used when LLAMAINDEX_HOST is not set
+const DefaultLlamaIndexHost = "http://localhost:5000"
+
 // LlamaIndexClient implements the LLMClientInterface for LlamaIndex server
 type LlamaIndexClient struct {
 	baseURL    string
 	httpClient *http.Client
+	// injectBackoff is the first wait before retrying an inject that failed with a transient error
+	injectBackoff time.Duration
+	// contexts are the chunks the last answer of the threads was retrieved from
+	contexts lastContexts
 }
 
-// NewLlamaIndexClient creates a new LlamaIndex client
-func NewLlamaIndexClient() Interface {
-	host := os.Getenv("LLAMAINDEX_HOST")
-	if host == "" {
-		host = "http://localhost:5000"
+// NewLlamaIndexClient creates a new LlamaIndex client for the LLAMAINDEX_HOST server, DefaultLlamaIndexHost when it
+// is not set, an error is returned when the host isn't an http(s) URL so a typo fails at startup
+func NewLlamaIndexClient() (Interface, error) {
+	baseURL, err := parseLlamaIndexHost(os.Getenv("LLAMAINDEX_HOST"))
+	if err != nil {
+		return nil, err
 	}
 
 	return &LlamaIndexClient{
-		baseURL:    host,
-		httpClient: &http.Client{},
+		baseURL:       baseURL,
+		httpClient:    &http.Client{},
+		injectBackoff: DefaultInjectBackoff,
+	}, nil
+}
+
+// parseLlamaIndexHost validates the LlamaIndex server URL and returns it without a trailing slash,
+// the endpoint paths are appended to it
+func parseLlamaIndexHost(host string) (string, error) {
+	if host == "" {
+		return DefaultLlamaIndexHost, nil
 	}
+
+	parsed, err := url.Parse(host)
+	if err != nil {
+		return "", fmt.Errorf("invalid LLAMAINDEX_HOST %q: %w", host, err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", fmt.Errorf("invalid LLAMAINDEX_HOST %q: the scheme must be http or https", host)
+	}
+	if parsed.Host == "" {
+		return "", fmt.Errorf("invalid LLAMAINDEX_HOST %q: the host is missing", host)
+	}
+	return strings.TrimSuffix(host, "/"), nil
 }
 
 // CreateThread generates a UUID thread slug locally (no server call needed)
-func (c *LlamaIndexClient) CreateThread(project, version string) (string, error) {
+func (c *LlamaIndexClient) CreateThread(_ context.Context, project, version string) (string, error) {
 	// Generate UUID locally
 	threadSlug := uuid.New().String()
 	fmt.Printf("Generated thread slug: %s for project=%s, version=%s\n", threadSlug, project, version)
 	return threadSlug, nil
 }
 
+// DeleteThread forgets the last context of the thread, the threads only exist in the requests of the server
+func (c *LlamaIndexClient) DeleteThread(_ context.Context, _, _, threadSlug string) error {
+	c.contexts.forget(threadSlug)
+	return nil
+}
+
 // SendMessageToChat sends a message to the /v1/answer endpoint
-func (c *LlamaIndexClient) SendMessageToChat(project, version, threadSlug, message string) (string, error) {
+func (c *LlamaIndexClient) SendMessageToChat(ctx context.Context, project, version, threadSlug, message string) (string, error) {
 	url := fmt.Sprintf("%s/v1/answer", c.baseURL)
 
 	requestBody := map[string]interface{}{
@@ -49,13 +88,29 @@ func (c *LlamaIndexClient) SendMessageToChat(project, version, threadSlug, messa
 		"thread_slug": threadSlug,
 		"message":     message,
 	}
+	if topK := TopKFrom(ctx); topK > 0 {
+		requestBody["top_k"] = topK
+	}
+	if temperature, ok := TemperatureFrom(ctx); ok {
+		requestBody["temperature"] = temperature
+	}
+	strict := StrictFrom(ctx)
+	if strict {
+		requestBody["strict"] = true
+	}
 
 	jsonData, err := json.Marshal(requestBody)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := c.httpClient.Post(url, "application/json", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to send request: %w", err)
 	}
@@ -70,35 +125,51 @@ func (c *LlamaIndexClient) SendMessageToChat(project, version, threadSlug, messa
 		if readErr != nil {
 			return "", fmt.Errorf("server returned status %d (failed to read body: %w)", resp.StatusCode, readErr)
 		}
-		return "", fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+		return "", &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var response struct {
-		TextResponse string `json:"textResponse"`
+		TextResponse string         `json:"textResponse"`
+		Sources      []ContextChunk `json:"sources"`
+		// Abstained is set when no retrieved document was relevant enough to answer
+		Abstained bool `json:"abstained"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
 		return "", fmt.Errorf("failed to decode response: %w", err)
 	}
+	c.contexts.set(threadSlug, response.Sources)
+	if strict && response.Abstained {
+		return "", fmt.Errorf("failed to answer from project %s version %s: %w", project, version, ErrNoInformation)
+	}
 
 	return response.TextResponse, nil
 }
 
-// Elaborate sends a message to the /v1/elaborate endpoint
-func (c *LlamaIndexClient) Elaborate(threadSlug, message string) (string, error) {
+// Elaborate sends a message to the /v1/elaborate endpoint, the server has no elaborate workspace
+func (c *LlamaIndexClient) Elaborate(ctx context.Context, _, threadSlug, message string) (string, error) {
 	url := fmt.Sprintf("%s/v1/elaborate", c.baseURL)
 
 	requestBody := map[string]interface{}{
 		"thread_slug": threadSlug,
 		"message":     message,
 	}
+	if temperature, ok := TemperatureFrom(ctx); ok {
+		requestBody["temperature"] = temperature
+	}
 
 	jsonData, err := json.Marshal(requestBody)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := c.httpClient.Post(url, "application/json", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to send request: %w", err)
 	}
@@ -113,7 +184,7 @@ func (c *LlamaIndexClient) Elaborate(threadSlug, message string) (string, error)
 		if readErr != nil {
 			return "", fmt.Errorf("server returned status %d (failed to read body: %w)", resp.StatusCode, readErr)
 		}
-		return "", fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+		return "", &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var response struct {
@@ -128,7 +199,7 @@ func (c *LlamaIndexClient) Elaborate(threadSlug, message string) (string, error)
 }
 
 // Inject sends content to the /v1/inject endpoint
-func (c *LlamaIndexClient) Inject(project, version, message string) error {
+func (c *LlamaIndexClient) Inject(ctx context.Context, project, version, message string, metadata DocumentMetadata) error {
 	url := fmt.Sprintf("%s/v1/inject", c.baseURL)
 
 	requestBody := map[string]interface{}{
@@ -136,13 +207,39 @@ func (c *LlamaIndexClient) Inject(project, version, message string) error {
 		"version":     version,
 		"textContent": message,
 	}
+	documentMetadata := map[string]string{}
+	if metadata.Title != "" {
+		documentMetadata["title"] = metadata.Title
+	}
+	if metadata.Source != "" {
+		documentMetadata["source"] = metadata.Source
+	}
+	if metadata.Correction {
+		documentMetadata["description"] = CorrectionDescription
+	}
+	if len(documentMetadata) > 0 {
+		requestBody["metadata"] = documentMetadata
+	}
 
 	jsonData, err := json.Marshal(requestBody)
 	if err != nil {
 		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := c.httpClient.Post(url, "application/json", bytes.NewBuffer(jsonData))
+	return withInjectRetry(ctx, c.injectBackoff, func() error {
+		return c.postInject(ctx, url, jsonData)
+	})
+}
+
+// postInject sends a single inject request, a new request is created for every attempt so the body is sent again
+func (c *LlamaIndexClient) postInject(ctx context.Context, url string, jsonData []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
@@ -157,8 +254,134 @@ func (c *LlamaIndexClient) Inject(project, version, message string) error {
 		if readErr != nil {
 			return fmt.Errorf("server returned status %d (failed to read body: %w)", resp.StatusCode, readErr)
 		}
-		return fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	return nil
 }
+
+// InjectBatch injects every document separately so each one is indexed on its own,
+// it stops at the first failure
+func (c *LlamaIndexClient) InjectBatch(ctx context.Context, project, version string, docs []Document) error {
+	for index, doc := range docs {
+		if err := c.Inject(ctx, project, version, doc.Content, doc.Metadata); err != nil {
+			return fmt.Errorf("failed to inject document %d of %d: %w", index+1, len(docs), err)
+		}
+	}
+	return nil
+}
+
+// ListProjects returns the slugs of the indexes served by the /v1/projects endpoint
+func (c *LlamaIndexClient) ListProjects(ctx context.Context) ([]string, error) {
+	url := fmt.Sprintf("%s/v1/projects", c.baseURL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return nil, fmt.Errorf("server returned status %d (failed to read body: %w)", resp.StatusCode, readErr)
+		}
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var response struct {
+		Projects []string `json:"projects"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return response.Projects, nil
+}
+
+// Ping checks that the server is up with its /health endpoint
+func (c *LlamaIndexClient) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/health", http.NoBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		//nolint:errcheck // response body close in defer
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return fmt.Errorf("server returned status %d (failed to read body: %w)", resp.StatusCode, readErr)
+		}
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+	return nil
+}
+
+// LastContext returns the nodes the server retrieved for the last answer of the thread
+func (c *LlamaIndexClient) LastContext(threadSlug string) []ContextChunk {
+	return c.contexts.get(threadSlug)
+}
+
+// ListDocuments returns the documents injected into the project and version, served by the /v1/documents endpoint
+func (c *LlamaIndexClient) ListDocuments(ctx context.Context, project, version string) ([]DocumentInfo, error) {
+	query := url.Values{"project": {project}, "version": {version}}
+	endpoint := fmt.Sprintf("%s/v1/documents?%s", c.baseURL, query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return nil, fmt.Errorf("server returned status %d (failed to read body: %w)", resp.StatusCode, readErr)
+		}
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var response struct {
+		Documents []struct {
+			ID    string `json:"id"`
+			Title string `json:"title"`
+		} `json:"documents"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	documents := make([]DocumentInfo, 0, len(response.Documents))
+	for _, doc := range response.Documents {
+		documents = append(documents, DocumentInfo{ID: doc.ID, Title: doc.Title})
+	}
+	return documents, nil
+}
@@ -1,19 +1,63 @@
 package llm
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
+func TestNewLlamaIndexClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		host    string
+		baseURL string
+	}{
+		{name: "empty uses the default", host: "", baseURL: DefaultLlamaIndexHost},
+		{name: "http", host: "http://llamaindex-server:5000", baseURL: "http://llamaindex-server:5000"},
+		{name: "https with a path", host: "https://rag.example.com/api/", baseURL: "https://rag.example.com/api"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LLAMAINDEX_HOST", tt.host)
+
+			client, err := NewLlamaIndexClient()
+			if err != nil {
+				t.Fatalf("NewLlamaIndexClient failed: %v", err)
+			}
+			if baseURL := client.(*LlamaIndexClient).baseURL; baseURL != tt.baseURL {
+				t.Errorf("Expected base URL %q, got %q", tt.baseURL, baseURL)
+			}
+		})
+	}
+}
+
+func TestNewLlamaIndexClient_Malformed(t *testing.T) {
+	for _, host := range []string{"llamaindex-server:5000", "localhost", "ftp://llamaindex-server", "http://", "http://bad host:5000"} {
+		t.Run(host, func(t *testing.T) {
+			t.Setenv("LLAMAINDEX_HOST", host)
+
+			client, err := NewLlamaIndexClient()
+			if err == nil {
+				t.Fatalf("Expected an error, got %+v", client)
+			}
+		})
+	}
+}
+
 func TestLlamaIndexClient_CreateThread(t *testing.T) {
 	client := &LlamaIndexClient{
 		baseURL:    "http://test",
 		httpClient: &http.Client{},
 	}
 
-	threadSlug, err := client.CreateThread("sriov", "4.16")
+	threadSlug, err := client.CreateThread(context.Background(), "sriov", "4.16")
 	if err != nil {
 		t.Fatalf("CreateThread failed: %v", err)
 	}
@@ -57,7 +101,7 @@ func TestLlamaIndexClient_SendMessageToChat(t *testing.T) {
 		httpClient: &http.Client{},
 	}
 
-	response, err := client.SendMessageToChat("sriov", "4.16", "test-thread", "test message")
+	response, err := client.SendMessageToChat(context.Background(), "sriov", "4.16", "test-thread", "test message")
 	if err != nil {
 		t.Fatalf("SendMessageToChat failed: %v", err)
 	}
@@ -67,6 +111,197 @@ func TestLlamaIndexClient_SendMessageToChat(t *testing.T) {
 	}
 }
 
+func TestLlamaIndexClient_SendMessageToChat_TopK(t *testing.T) {
+	var requests []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		requests = append(requests, req)
+
+		w.Header().Set("Content-Type", "application/json")
+		//nolint:errcheck // test mock
+		_ = json.NewEncoder(w).Encode(map[string]string{"textResponse": "Test response"})
+	}))
+	defer server.Close()
+
+	client := &LlamaIndexClient{
+		baseURL:    server.URL,
+		httpClient: &http.Client{},
+	}
+
+	if _, err := client.SendMessageToChat(WithTopK(context.Background(), 8), "sriov", "4.16", "test-thread", "test message"); err != nil {
+		t.Fatalf("SendMessageToChat failed: %v", err)
+	}
+	if _, err := client.SendMessageToChat(context.Background(), "sriov", "4.16", "test-thread", "test message"); err != nil {
+		t.Fatalf("SendMessageToChat failed: %v", err)
+	}
+
+	// JSON numbers are decoded as float64
+	if requests[0]["top_k"] != float64(8) {
+		t.Errorf("Expected top_k 8, got %v", requests[0]["top_k"])
+	}
+	if _, ok := requests[1]["top_k"]; ok {
+		t.Errorf("Expected no top_k without a thread setting, got %v", requests[1]["top_k"])
+	}
+}
+
+func TestLlamaIndexClient_Temperature(t *testing.T) {
+	var requests []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		requests = append(requests, req)
+
+		w.Header().Set("Content-Type", "application/json")
+		//nolint:errcheck // test mock
+		_ = json.NewEncoder(w).Encode(map[string]string{"textResponse": "Test response"})
+	}))
+	defer server.Close()
+
+	client := &LlamaIndexClient{
+		baseURL:    server.URL,
+		httpClient: &http.Client{},
+	}
+
+	if _, err := client.SendMessageToChat(WithTemperature(context.Background(), 0.2), "sriov", "4.16", "test-thread", "test message"); err != nil {
+		t.Fatalf("SendMessageToChat failed: %v", err)
+	}
+	if _, err := client.Elaborate(WithTemperature(context.Background(), 0.7), "", "test-thread", "test message"); err != nil {
+		t.Fatalf("Elaborate failed: %v", err)
+	}
+	if _, err := client.SendMessageToChat(context.Background(), "sriov", "4.16", "test-thread", "test message"); err != nil {
+		t.Fatalf("SendMessageToChat failed: %v", err)
+	}
+
+	if requests[0]["temperature"] != 0.2 {
+		t.Errorf("Expected temperature 0.2, got %v", requests[0]["temperature"])
+	}
+	if requests[1]["temperature"] != 0.7 {
+		t.Errorf("Expected elaborate temperature 0.7, got %v", requests[1]["temperature"])
+	}
+	if _, ok := requests[2]["temperature"]; ok {
+		t.Errorf("Expected no temperature without a setting, got %v", requests[2]["temperature"])
+	}
+}
+
+func TestLlamaIndexClient_Strict(t *testing.T) {
+	var requests []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		requests = append(requests, req)
+
+		w.Header().Set("Content-Type", "application/json")
+		//nolint:errcheck // test mock
+		_ = json.NewEncoder(w).Encode(map[string]string{"textResponse": "Test response"})
+	}))
+	defer server.Close()
+
+	client := &LlamaIndexClient{
+		baseURL:    server.URL,
+		httpClient: &http.Client{},
+	}
+
+	if _, err := client.SendMessageToChat(WithStrict(context.Background(), true), "sriov", "4.16", "test-thread", "test message"); err != nil {
+		t.Fatalf("SendMessageToChat failed: %v", err)
+	}
+	if _, err := client.SendMessageToChat(context.Background(), "sriov", "4.16", "test-thread", "test message"); err != nil {
+		t.Fatalf("SendMessageToChat failed: %v", err)
+	}
+
+	if requests[0]["strict"] != true {
+		t.Errorf("Expected strict true, got %v", requests[0]["strict"])
+	}
+	if _, ok := requests[1]["strict"]; ok {
+		t.Errorf("Expected no strict without a setting, got %v", requests[1]["strict"])
+	}
+}
+
+func TestLlamaIndexClient_StrictWithoutAnswer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		//nolint:errcheck // test mock
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"textResponse": "", "abstained": true})
+	}))
+	defer server.Close()
+
+	client := &LlamaIndexClient{
+		baseURL:    server.URL,
+		httpClient: &http.Client{},
+	}
+
+	_, err := client.SendMessageToChat(WithStrict(context.Background(), true), "sriov", "4.16", "test-thread", "test message")
+	if !errors.Is(err, ErrNoInformation) {
+		t.Errorf("Expected ErrNoInformation, got %v", err)
+	}
+}
+
+func TestLlamaIndexClient_AbstainedWithoutStrict(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		//nolint:errcheck // test mock
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"textResponse": "I don't know.", "abstained": true})
+	}))
+	defer server.Close()
+
+	client := &LlamaIndexClient{
+		baseURL:    server.URL,
+		httpClient: &http.Client{},
+	}
+
+	answer, err := client.SendMessageToChat(context.Background(), "sriov", "4.16", "test-thread", "test message")
+	if err != nil {
+		t.Fatalf("SendMessageToChat failed: %v", err)
+	}
+	if answer != "I don't know." {
+		t.Errorf("Expected the abstention answer, got %q", answer)
+	}
+}
+
+func TestLlamaIndexClient_LastContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		//nolint:errcheck // test mock
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"textResponse": "Test response",
+			"sources": []map[string]interface{}{
+				{"title": "sriov-faq.md", "text": "Enable SR-IOV in the BIOS first", "score": 0.82},
+				{"title": "sriov-api.md", "text": "The SriovNetworkNodePolicy selects the NICs", "score": 0.61},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := &LlamaIndexClient{
+		baseURL:    server.URL,
+		httpClient: &http.Client{},
+	}
+
+	if chunks := client.LastContext("test-thread"); chunks != nil {
+		t.Errorf("Expected no context before the first answer, got %+v", chunks)
+	}
+	if _, err := client.SendMessageToChat(context.Background(), "sriov", "4.16", "test-thread", "test message"); err != nil {
+		t.Fatalf("SendMessageToChat failed: %v", err)
+	}
+
+	expected := []ContextChunk{
+		{Title: "sriov-faq.md", Text: "Enable SR-IOV in the BIOS first", Score: 0.82},
+		{Title: "sriov-api.md", Text: "The SriovNetworkNodePolicy selects the NICs", Score: 0.61},
+	}
+	if chunks := client.LastContext("test-thread"); !reflect.DeepEqual(chunks, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, chunks)
+	}
+	if chunks := client.LastContext("other-thread"); chunks != nil {
+		t.Errorf("Expected no context for another thread, got %+v", chunks)
+	}
+}
+
 func TestLlamaIndexClient_SendMessageToChat_Error(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNotFound)
@@ -80,12 +315,44 @@ func TestLlamaIndexClient_SendMessageToChat_Error(t *testing.T) {
 		httpClient: &http.Client{},
 	}
 
-	_, err := client.SendMessageToChat("unknown", "1.0", "test-thread", "test message")
+	_, err := client.SendMessageToChat(context.Background(), "unknown", "1.0", "test-thread", "test message")
 	if err == nil {
 		t.Error("Expected error for 404 response")
 	}
 }
 
+func TestLlamaIndexClient_Ping(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/health" {
+			t.Errorf("Expected GET /health, got %s %s", r.Method, r.URL.Path)
+		}
+		//nolint:errcheck // test mock
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok"})
+	}))
+	defer server.Close()
+
+	client := &LlamaIndexClient{baseURL: server.URL, httpClient: &http.Client{}}
+
+	if err := client.Ping(context.Background()); err != nil {
+		t.Errorf("Ping failed: %v", err)
+	}
+}
+
+func TestLlamaIndexClient_Ping_Unreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	client := &LlamaIndexClient{baseURL: server.URL, httpClient: &http.Client{}}
+
+	var statusErr *StatusError
+	if err := client.Ping(context.Background()); !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected a 404 status error, got %v", err)
+	}
+
+	server.Close()
+	if err := client.Ping(context.Background()); err == nil {
+		t.Error("Expected error for a stopped server")
+	}
+}
+
 func TestLlamaIndexClient_Elaborate(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/v1/elaborate" {
@@ -113,7 +380,7 @@ func TestLlamaIndexClient_Elaborate(t *testing.T) {
 		httpClient: &http.Client{},
 	}
 
-	response, err := client.Elaborate("test-thread", "elaborate this")
+	response, err := client.Elaborate(context.Background(), "", "test-thread", "elaborate this")
 	if err != nil {
 		t.Fatalf("Elaborate failed: %v", err)
 	}
@@ -152,12 +419,75 @@ func TestLlamaIndexClient_Inject(t *testing.T) {
 		httpClient: &http.Client{},
 	}
 
-	err := client.Inject("metallb", "4.18", "injected content")
+	err := client.Inject(context.Background(), "metallb", "4.18", "injected content", DocumentMetadata{})
 	if err != nil {
 		t.Fatalf("Inject failed: %v", err)
 	}
 }
 
+func TestLlamaIndexClient_Inject_Metadata(t *testing.T) {
+	var metadata map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		//nolint:errcheck // test mock
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		metadata, _ = req["metadata"].(map[string]interface{})
+
+		w.WriteHeader(http.StatusOK)
+		//nolint:errcheck // test mock
+		_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	}))
+	defer server.Close()
+
+	client := &LlamaIndexClient{
+		baseURL:    server.URL,
+		httpClient: &http.Client{},
+	}
+
+	err := client.Inject(context.Background(), "metallb", "4.18", "injected content", DocumentMetadata{Source: "https://example.slack.com/archives/C1/p1"})
+	if err != nil {
+		t.Fatalf("Inject failed: %v", err)
+	}
+	if metadata["source"] != "https://example.slack.com/archives/C1/p1" {
+		t.Errorf("Expected the source in the metadata, got %+v", metadata)
+	}
+	if _, ok := metadata["title"]; ok {
+		t.Errorf("Expected no title in the metadata, got %+v", metadata)
+	}
+	if _, ok := metadata["description"]; ok {
+		t.Errorf("Expected no description in the metadata, got %+v", metadata)
+	}
+}
+
+func TestLlamaIndexClient_InjectBatch(t *testing.T) {
+	var contents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		//nolint:errcheck // test mock
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		content, _ := req["textContent"].(string)
+		contents = append(contents, content)
+
+		w.WriteHeader(http.StatusOK)
+		//nolint:errcheck // test mock
+		_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	}))
+	defer server.Close()
+
+	client := &LlamaIndexClient{
+		baseURL:    server.URL,
+		httpClient: &http.Client{},
+	}
+
+	err := client.InjectBatch(context.Background(), "metallb", "4.18", []Document{{Content: "first message"}, {Content: "second message"}})
+	if err != nil {
+		t.Fatalf("InjectBatch failed: %v", err)
+	}
+	if len(contents) != 2 || contents[0] != "first message" || contents[1] != "second message" {
+		t.Errorf("Expected each document to be injected separately, got %v", contents)
+	}
+}
+
 func TestLlamaIndexClient_Inject_Error(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
@@ -171,8 +501,147 @@ func TestLlamaIndexClient_Inject_Error(t *testing.T) {
 		httpClient: &http.Client{},
 	}
 
-	err := client.Inject("test", "1.0", "content")
+	err := client.Inject(context.Background(), "test", "1.0", "content", DocumentMetadata{})
 	if err == nil {
 		t.Error("Expected error for 400 response")
 	}
 }
+
+func TestLlamaIndexClient_Inject_RetriesTransientFailure(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		//nolint:errcheck // test mock
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["textContent"] != "injected content" {
+			t.Errorf("Expected every attempt to send the content, got %v", body["textContent"])
+		}
+
+		// The first request is rate limited
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		//nolint:errcheck // test mock
+		_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	}))
+	defer server.Close()
+
+	client := &LlamaIndexClient{
+		baseURL:       server.URL,
+		httpClient:    &http.Client{},
+		injectBackoff: time.Millisecond,
+	}
+
+	retried := 0
+	ctx := WithInjectRetryNotifier(context.Background(), func(int, int, time.Duration) { retried++ })
+	if err := client.Inject(ctx, "metallb", "4.18", "injected content", DocumentMetadata{}); err != nil {
+		t.Fatalf("Expected the failed inject to be retried, got %v", err)
+	}
+	if requests.Load() != 2 || retried != 1 {
+		t.Errorf("Expected 2 requests and 1 retry, got %d and %d", requests.Load(), retried)
+	}
+}
+
+func TestLlamaIndexClient_ListProjects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/projects" {
+			t.Errorf("Expected path /v1/projects, got %s", r.URL.Path)
+		}
+
+		if r.Method != http.MethodGet {
+			t.Errorf("Expected GET method, got %s", r.Method)
+		}
+
+		//nolint:errcheck // test mock
+		_ = json.NewEncoder(w).Encode(map[string][]string{"projects": {"metallb-4-dot-20", "sriov-4-dot-16"}})
+	}))
+	defer server.Close()
+
+	client := &LlamaIndexClient{
+		baseURL:    server.URL,
+		httpClient: &http.Client{},
+	}
+
+	projects, err := client.ListProjects(context.Background())
+	if err != nil {
+		t.Fatalf("ListProjects failed: %v", err)
+	}
+
+	if len(projects) != 2 || projects[0] != "metallb-4-dot-20" || projects[1] != "sriov-4-dot-16" {
+		t.Errorf("Unexpected projects: %v", projects)
+	}
+}
+
+func TestLlamaIndexClient_ListDocuments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/documents" {
+			t.Errorf("Expected path /v1/documents, got %s", r.URL.Path)
+		}
+
+		if r.URL.Query().Get("project") != "metallb" || r.URL.Query().Get("version") != "4.18" {
+			t.Errorf("Unexpected query: %s", r.URL.RawQuery)
+		}
+
+		//nolint:errcheck // test mock
+		_, _ = w.Write([]byte(`{"documents": [{"id": "5f0c", "title": "MetalLB BGP peers"}, {"id": "9a1b", "title": ""}]}`))
+	}))
+	defer server.Close()
+
+	client := &LlamaIndexClient{
+		baseURL:    server.URL,
+		httpClient: &http.Client{},
+	}
+
+	documents, err := client.ListDocuments(context.Background(), "metallb", "4.18")
+	if err != nil {
+		t.Fatalf("ListDocuments failed: %v", err)
+	}
+
+	if len(documents) != 2 || documents[0] != (DocumentInfo{ID: "5f0c", Title: "MetalLB BGP peers"}) || documents[1].ID != "9a1b" {
+		t.Errorf("Unexpected documents: %+v", documents)
+	}
+}
+
+func TestWorkspaceSlug(t *testing.T) {
+	if slug := WorkspaceSlug("sriov", "4.16"); slug != "sriov-4-dot-16" {
+		t.Errorf("Expected 'sriov-4-dot-16', got '%s'", slug)
+	}
+
+	if slug := WorkspaceSlug("elaborate", ""); slug != "elaborate" {
+		t.Errorf("Expected 'elaborate', got '%s'", slug)
+	}
+}
+
+// blockingTransport holds every request until its context is canceled
+type blockingTransport struct{}
+
+func (blockingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	<-r.Context().Done()
+	return nil, r.Context().Err()
+}
+
+func TestLlamaIndexClient_SendMessageToChat_Canceled(t *testing.T) {
+	client := &LlamaIndexClient{
+		baseURL:    "http://test",
+		httpClient: &http.Client{Transport: blockingTransport{}},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.SendMessageToChat(ctx, "sriov", "4.16", "test-thread", "test message")
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected context deadline exceeded, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("SendMessageToChat didn't return when the context was canceled")
+	}
+}
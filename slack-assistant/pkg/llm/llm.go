@@ -3,65 +3,97 @@ package llm
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"math/rand"
+	"net/http"
 	"os"
 	"strings"
+	"sync"
+	"time"
 
 	anythingllm "github.com/SchSeba/anythingllm-go-sdk"
 )
 
 // LLMClient implements the LLMClientInterface
 type LLMClient struct {
-	apiClient *anythingllm.APIClient
+	apiClient  *anythingllm.APIClient
+	workspaces *workspaceCache
+	// rateLimitBackoff is the first wait before retrying a rate limited call without Retry-After
+	rateLimitBackoff time.Duration
+	// injectBackoff is the first wait before retrying an inject that failed with a transient error
+	injectBackoff time.Duration
+	// settings are the chat settings last set on the workspaces, so a chat only updates a workspace on a change
+	settings   map[string]*workspaceSettings
+	settingsMu sync.Mutex
+	// contexts are the chunks the last answer of the threads was retrieved from
+	contexts lastContexts
+	// aliases route the projects and versions to workspaces not named after WorkspaceSlug, nil when none is set
+	aliases *WorkspaceAliases
 }
 
+// NewLLMClient creates a new AnythingLLM client, ANYTHINGLLM_WORKSPACE_CACHE_TTL sets how long
+// a workspace lookup is cached (e.g. 30s, 0 disables the cache)
 func NewLLMClient() Interface {
+	ttl := DefaultWorkspaceCacheTTL
+	if value := os.Getenv("ANYTHINGLLM_WORKSPACE_CACHE_TTL"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			fmt.Printf("⚠️ Invalid ANYTHINGLLM_WORKSPACE_CACHE_TTL %q, using %s: %v\n", value, ttl, err)
+		} else {
+			ttl = parsed
+		}
+	}
+
+	return newLLMClient(os.Getenv("ANYTHINGLLM_HOST"), os.Getenv("ANYTHINGLLM_API_KEY"), ttl)
+}
+
+func newLLMClient(host, apiKey string, workspaceCacheTTL time.Duration) *LLMClient {
 	config := anythingllm.NewConfiguration()
-	config.Host = os.Getenv("ANYTHINGLLM_HOST")
+	config.Host = host
 	config.Scheme = "http"
 	config.Debug = true
 	config.DefaultHeader = map[string]string{
-		"Authorization": "Bearer " + os.Getenv("ANYTHINGLLM_API_KEY"),
+		"Authorization": "Bearer " + apiKey,
 	}
 	return &LLMClient{
-		apiClient: anythingllm.NewAPIClient(config),
+		apiClient:        anythingllm.NewAPIClient(config),
+		workspaces:       newWorkspaceCache(workspaceCacheTTL),
+		rateLimitBackoff: DefaultRateLimitBackoff,
+		injectBackoff:    DefaultInjectBackoff,
+		settings:         make(map[string]*workspaceSettings),
 	}
 }
 
-func (c *LLMClient) CreateThread(project, version string) (string, error) {
-	slug := project
-	if version != "" {
-		version = strings.ReplaceAll(version, ".", "-dot-")
-		slug = fmt.Sprintf("%s-%s", project, version)
-	}
+// SetWorkspaceAliases routes the projects and versions of the aliases to their workspace instead of WorkspaceSlug,
+// the aliases may change while the client is used
+func (c *LLMClient) SetWorkspaceAliases(aliases *WorkspaceAliases) {
+	c.aliases = aliases
+}
 
-	// Check if the slug exist
-	workspaceInfoRequest := c.apiClient.WorkspacesAPI.V1WorkspaceSlugGet(context.Background(), slug)
-	workspaceInfo, response, err := workspaceInfoRequest.Execute()
-	if response != nil && response.Body != nil {
-		defer func() {
-			//nolint:errcheck // response body close in defer
-			_ = response.Body.Close()
-		}()
-	}
-	if err != nil {
-		fmt.Printf("❌ Failed to get workspace info: %v\n, %s", err, response.Status)
-		return "", err
+func (c *LLMClient) CreateThread(ctx context.Context, project, version string) (string, error) {
+	slug := c.aliases.Slug(project, version)
+
+	// Check if the slug exist, unless it was seen recently
+	if !c.workspaces.has(slug) {
+		if err := c.getWorkspace(ctx, slug); err != nil {
+			return "", err
+		}
+		c.workspaces.add(slug)
 	}
-	fmt.Printf("Workspace info: %+v\n", workspaceInfo)
 
-	request := c.apiClient.WorkspaceThreadsAPI.V1WorkspaceSlugThreadNewPost(context.Background(), slug)
-	slugThreadInfo, response, err := request.Execute()
+	request := c.apiClient.WorkspaceThreadsAPI.V1WorkspaceSlugThreadNewPost(ctx, slug)
+	slugThreadInfo, response, err := withRateLimitRetry(ctx, c.rateLimitBackoff, request.Execute)
 	if response != nil && response.Body != nil {
 		defer func() {
 			//nolint:errcheck // response body close in defer
 			_ = response.Body.Close()
 		}()
 	}
-	fmt.Printf("HTTP Response Status: %s\n", response.Status)
+	fmt.Printf("HTTP Response Status: %s\n", responseStatus(response))
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("failed to create thread in workspace %s: %w", slug, err)
 	}
 
 	threadResponse, err := ConvertMapToWorkspaceThread(slugThreadInfo["thread"])
@@ -74,32 +106,68 @@ func (c *LLMClient) CreateThread(project, version string) (string, error) {
 	return threadResponse.Slug, nil
 }
 
-func (c *LLMClient) SendMessageToChat(project, version, threadSlug, message string) (string, error) {
-	slug := project
-	if version != "" {
-		version = strings.ReplaceAll(version, ".", "-dot-")
-		slug = fmt.Sprintf("%s-%s", project, version)
+// DeleteThread deletes the thread of the project and version workspace with its chats
+func (c *LLMClient) DeleteThread(ctx context.Context, project, version, threadSlug string) error {
+	slug := c.aliases.Slug(project, version)
+	request := c.apiClient.WorkspaceThreadsAPI.V1WorkspaceSlugThreadThreadSlugDelete(ctx, slug, threadSlug)
+	_, response, err := withRateLimitRetry(ctx, c.rateLimitBackoff, func() (struct{}, *http.Response, error) {
+		response, err := request.Execute()
+		return struct{}{}, response, err
+	})
+	if response != nil && response.Body != nil {
+		defer func() {
+			//nolint:errcheck // response body close in defer
+			_ = response.Body.Close()
+		}()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete thread %s of workspace %s: %w", threadSlug, slug, err)
 	}
+	c.contexts.forget(threadSlug)
+	return nil
+}
 
-	return c.sendMessageToChatWithMode(slug, threadSlug, message, "query")
+func (c *LLMClient) SendMessageToChat(ctx context.Context, project, version, threadSlug, message string) (string, error) {
+	mode := ChatModeFrom(ctx)
+	if StrictFrom(ctx) {
+		mode = ModeQuery
+	}
+	return c.sendMessageToChatWithMode(ctx, c.aliases.Slug(project, version), threadSlug, message, string(mode))
 }
 
-func (c *LLMClient) Elaborate(threadSlug, message string) (string, error) {
-	return c.sendMessageToChatWithMode("elaborate", threadSlug, message, "chat")
+// Elaborate chats with the thread of the elaborate workspace
+func (c *LLMClient) Elaborate(ctx context.Context, workspace, threadSlug, message string) (string, error) {
+	return c.sendMessageToChatWithMode(ctx, workspace, threadSlug, message, string(ModeChat))
 }
 
-func (c *LLMClient) Inject(project, version, message string) error {
-	version = strings.ReplaceAll(version, ".", "-dot-")
-	wokerspace := fmt.Sprintf("%s-%s", project, version)
-	request := c.apiClient.DocumentsAPI.V1DocumentRawTextPost(context.Background()).Body(map[string]interface{}{
+func (c *LLMClient) Inject(ctx context.Context, project, version, message string, metadata DocumentMetadata) error {
+	wokerspace := c.aliases.Slug(project, version)
+	title := metadata.Title
+	if title == "" {
+		//nolint:gosec // use of weak random number generator is acceptable for document title
+		title = fmt.Sprintf("Document-%d", rand.Intn(1000000))
+	}
+	documentMetadata := map[string]interface{}{"title": title}
+	if metadata.Source != "" {
+		documentMetadata["docSource"] = metadata.Source
+	}
+	if metadata.Correction {
+		documentMetadata["description"] = CorrectionDescription
+	}
+	body := map[string]interface{}{
 		"textContent":     message,
 		"addToWorkspaces": wokerspace,
-		"metadata": map[string]interface{}{
-			//nolint:gosec // use of weak random number generator is acceptable for document title
-			"title": fmt.Sprintf("Document-%d", rand.Intn(1000000)),
-		},
+		"metadata":        documentMetadata,
+	}
+	return withInjectRetry(ctx, c.injectBackoff, func() error {
+		return c.postInject(ctx, body)
 	})
-	documentInjectInfo, response, err := request.Execute()
+}
+
+// postInject sends a single raw text document, the failed responses are returned as StatusError so they can be retried.
+// The 429 responses are retried by withInjectRetry, not withRateLimitRetry, so the two retry loops don't stack
+func (c *LLMClient) postInject(ctx context.Context, body map[string]interface{}) error {
+	documentInjectInfo, response, err := c.apiClient.DocumentsAPI.V1DocumentRawTextPost(ctx).Body(body).Execute()
 	if response != nil && response.Body != nil {
 		defer func() {
 			//nolint:errcheck // response body close in defer
@@ -107,33 +175,143 @@ func (c *LLMClient) Inject(project, version, message string) error {
 		}()
 	}
 	if err != nil {
+		if response != nil && response.StatusCode >= http.StatusBadRequest {
+			err = &StatusError{StatusCode: response.StatusCode, Body: err.Error()}
+		}
 		return fmt.Errorf("failed to inject messages: %w", err)
 	}
-	fmt.Printf("HTTP Response Status: %s\n", response.Status)
+	fmt.Printf("HTTP Response Status: %s\n", responseStatus(response))
 	fmt.Printf("Document inject info: %+v\n", documentInjectInfo)
 	return nil
 }
 
-func (c *LLMClient) sendMessageToChatWithMode(slug, threadSlug, message, mode string) (string, error) {
+// InjectBatch injects every document separately so each one is retrieved on its own,
+// it stops at the first failure
+func (c *LLMClient) InjectBatch(ctx context.Context, project, version string, docs []Document) error {
+	for index, doc := range docs {
+		if err := c.Inject(ctx, project, version, doc.Content, doc.Metadata); err != nil {
+			return fmt.Errorf("failed to inject document %d of %d: %w", index+1, len(docs), err)
+		}
+	}
+	return nil
+}
+
+// getWorkspace checks that the workspace exists
+func (c *LLMClient) getWorkspace(ctx context.Context, slug string) error {
+	workspaceInfoRequest := c.apiClient.WorkspacesAPI.V1WorkspaceSlugGet(ctx, slug)
+	workspaceInfo, response, err := withRateLimitRetry(ctx, c.rateLimitBackoff, workspaceInfoRequest.Execute)
+	if response != nil && response.Body != nil {
+		defer func() {
+			//nolint:errcheck // response body close in defer
+			_ = response.Body.Close()
+		}()
+	}
+	if err != nil {
+		fmt.Printf("❌ Failed to get workspace info: %v, %s\n", err, responseStatus(response))
+		return fmt.Errorf("failed to get workspace %s: %w", slug, err)
+	}
+	fmt.Printf("Workspace info: %+v\n", workspaceInfo)
+	return nil
+}
+
+// ListProjects returns the slugs of the workspaces available in AnythingLLM, an aliased workspace is listed with the
+// WorkspaceSlug of its project and version, the workspace cache is refreshed with the result
+func (c *LLMClient) ListProjects(ctx context.Context) ([]string, error) {
+	workspacesInfo, response, err := withRateLimitRetry(ctx, c.rateLimitBackoff, c.apiClient.WorkspacesAPI.V1WorkspacesGet(ctx).Execute)
+	if response != nil && response.Body != nil {
+		defer func() {
+			//nolint:errcheck // response body close in defer
+			_ = response.Body.Close()
+		}()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+
+	workspaces, err := ConvertMapToWorkspaces(workspacesInfo["workspaces"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert response to struct: %w", err)
+	}
+
+	slugs := make([]string, 0, len(workspaces))
+	projects := make([]string, 0, len(workspaces))
+	for _, workspace := range workspaces {
+		slugs = append(slugs, workspace.Slug)
+		projects = append(projects, c.aliases.derived(workspace.Slug)...)
+	}
+	c.workspaces.reset(slugs)
+	return projects, nil
+}
+
+// Ping checks that the server is up and accepts the API key with the lightweight /v1/auth endpoint
+func (c *LLMClient) Ping(ctx context.Context) error {
+	_, response, err := c.apiClient.AuthenticationAPI.V1AuthGet(ctx).Execute()
+	if response != nil && response.Body != nil {
+		defer func() {
+			//nolint:errcheck // response body close in defer
+			_ = response.Body.Close()
+		}()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to reach AnythingLLM: %w", err)
+	}
+	return nil
+}
+
+// LastContext returns the sources AnythingLLM reported for the last answer of the thread
+func (c *LLMClient) LastContext(threadSlug string) []ContextChunk {
+	return c.contexts.get(threadSlug)
+}
+
+// ListDocuments returns the documents embedded in the workspace of the project and version
+func (c *LLMClient) ListDocuments(ctx context.Context, project, version string) ([]DocumentInfo, error) {
+	slug := c.aliases.Slug(project, version)
+	workspaceInfo, response, err := withRateLimitRetry(ctx, c.rateLimitBackoff, c.apiClient.WorkspacesAPI.V1WorkspaceSlugGet(ctx, slug).Execute)
+	if response != nil && response.Body != nil {
+		defer func() {
+			//nolint:errcheck // response body close in defer
+			_ = response.Body.Close()
+		}()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace %s: %w", slug, err)
+	}
+
+	documents, err := ConvertMapToDocuments(workspaceInfo["workspace"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert response to struct: %w", err)
+	}
+	return documents, nil
+}
+
+func (c *LLMClient) sendMessageToChatWithMode(ctx context.Context, slug, threadSlug, message, mode string) (string, error) {
+	// The answer is still worth having with the settings of the workspace
+	if err := c.applyWorkspaceSettings(ctx, slug); err != nil {
+		fmt.Printf("⚠️ Failed to set the chat settings of workspace %s, answering with its current ones: %v\n", slug, err)
+	}
 	request := c.apiClient.WorkspaceThreadsAPI.V1WorkspaceSlugThreadThreadSlugChatPost(
-		context.Background(),
+		ctx,
 		slug,
 		threadSlug,
 	).V1WorkspaceSlugThreadThreadSlugChatPostRequest(anythingllm.V1WorkspaceSlugThreadThreadSlugChatPostRequest{
-		Message: message,
-		Mode:    &mode,
-		UserId:  *anythingllm.NewNullableInt32(anythingllm.PtrInt32(2)),
+		Message:     message,
+		Mode:        &mode,
+		UserId:      *anythingllm.NewNullableInt32(anythingllm.PtrInt32(2)),
+		Attachments: chatAttachments(ImagesFrom(ctx)),
 	})
-	chatInfo, response, err := request.Execute()
+	chatInfo, response, err := withRateLimitRetry(ctx, c.rateLimitBackoff, request.Execute)
 	if response != nil && response.Body != nil {
 		defer func() {
 			//nolint:errcheck // response body close in defer
 			_ = response.Body.Close()
 		}()
 	}
-	fmt.Printf("HTTP Response Status: %s\n", response.Status)
+	fmt.Printf("HTTP Response Status: %s\n", responseStatus(response))
+	if isThreadNotFound(response, err, threadSlug) {
+		return "", fmt.Errorf("failed to send message to thread %s of workspace %s: %w", threadSlug, slug, ErrThreadNotFound)
+	}
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("failed to send message to workspace %s: %w", slug, err)
 	}
 	fmt.Printf("Chat response: %+v\n", chatInfo)
 	chatResponse, err := ConvertMapToChatResponse(chatInfo)
@@ -141,5 +319,41 @@ func (c *LLMClient) sendMessageToChatWithMode(slug, threadSlug, message, mode st
 		return "", fmt.Errorf("failed to convert response to struct: %w", err)
 	}
 	fmt.Printf("Chat response: %+v\n", chatResponse)
+	c.contexts.set(threadSlug, chatResponse.Sources)
+	// In query mode AnythingLLM answers a refusal without sources when no document is relevant
+	if StrictFrom(ctx) && len(chatResponse.Sources) == 0 {
+		return "", fmt.Errorf("failed to answer from workspace %s: %w", slug, ErrNoInformation)
+	}
 	return chatResponse.TextResponse, nil
 }
+
+// isThreadNotFound reports whether the chat failed because its thread doesn't exist, AnythingLLM rejects it with
+// 400 Bad Request and the error "Workspace <slug> or thread <thread slug> is not valid."
+func isThreadNotFound(response *http.Response, err error, threadSlug string) bool {
+	if err == nil || response == nil || response.StatusCode != http.StatusBadRequest {
+		return false
+	}
+	var apiErr *anythingllm.GenericOpenAPIError
+	return errors.As(err, &apiErr) && strings.Contains(string(apiErr.Body()), "or thread "+threadSlug+" is not valid")
+}
+
+// chatAttachments returns the images as chat attachments, AnythingLLM takes them as base64 data URLs
+func chatAttachments(images []Image) []anythingllm.V1WorkspaceSlugThreadThreadSlugChatPostRequestAttachmentsInner {
+	var attachments []anythingllm.V1WorkspaceSlugThreadThreadSlugChatPostRequestAttachmentsInner
+	for _, image := range images {
+		attachments = append(attachments, anythingllm.V1WorkspaceSlugThreadThreadSlugChatPostRequestAttachmentsInner{
+			Name:          image.Name,
+			Mime:          image.MimeType,
+			ContentString: fmt.Sprintf("data:%s;base64,%s", image.MimeType, base64.StdEncoding.EncodeToString(image.Data)),
+		})
+	}
+	return attachments
+}
+
+// responseStatus returns the HTTP status of the response, the SDK returns no response on transport errors
+func responseStatus(response *http.Response) string {
+	if response == nil {
+		return "no response"
+	}
+	return response.Status
+}
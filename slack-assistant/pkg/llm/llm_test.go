@@ -0,0 +1,833 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLLMClient_CreateThread(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "sriov-4-dot-16")
+
+	threadSlug, err := fake.client(time.Minute).CreateThread(context.Background(), "sriov", "4.16")
+	if err != nil {
+		t.Fatalf("CreateThread failed: %v", err)
+	}
+
+	if threadSlug != "thread-sriov-4-dot-16" {
+		t.Errorf("Expected 'thread-sriov-4-dot-16', got '%s'", threadSlug)
+	}
+}
+
+func TestLLMClient_CreateThread_UnknownWorkspace(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "sriov-4-dot-16")
+
+	_, err := fake.client(time.Minute).CreateThread(context.Background(), "metallb", "4.18")
+	if err == nil || !strings.Contains(err.Error(), "failed to get workspace metallb-4-dot-18") {
+		t.Errorf("Expected a workspace lookup error, got %v", err)
+	}
+}
+
+func TestLLMClient_CreateThread_Error(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "sriov-4-dot-16")
+	fake.failWith("/api/v1/workspace/sriov-4-dot-16/thread/new", http.StatusInternalServerError)
+
+	_, err := fake.client(time.Minute).CreateThread(context.Background(), "sriov", "4.16")
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("Expected a 500 error, got %v", err)
+	}
+}
+
+func TestLLMClient_DeleteThread(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "sriov-4-dot-16")
+
+	if err := fake.client(time.Minute).DeleteThread(context.Background(), "sriov", "4.16", "thread-slug"); err != nil {
+		t.Fatalf("DeleteThread failed: %v", err)
+	}
+	if len(fake.deletedThreads) != 1 || fake.deletedThreads[0] != "sriov-4-dot-16/thread-slug" {
+		t.Errorf("Expected the thread-slug thread of sriov-4-dot-16 to be deleted, got %v", fake.deletedThreads)
+	}
+}
+
+func TestLLMClient_DeleteThread_Error(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "sriov-4-dot-16")
+	fake.failWith("/api/v1/workspace/sriov-4-dot-16/thread/thread-slug", http.StatusInternalServerError)
+
+	err := fake.client(time.Minute).DeleteThread(context.Background(), "sriov", "4.16", "thread-slug")
+	if err == nil || !strings.Contains(err.Error(), "failed to delete thread thread-slug of workspace sriov-4-dot-16") {
+		t.Errorf("Expected a delete error, got %v", err)
+	}
+}
+
+func TestLLMClient_CreateThread_CachesWorkspaceLookup(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "sriov-4-dot-16")
+
+	client := fake.client(time.Minute)
+	for i := 0; i < 2; i++ {
+		if _, err := client.CreateThread(context.Background(), "sriov", "4.16"); err != nil {
+			t.Fatalf("CreateThread failed: %v", err)
+		}
+	}
+
+	if fake.lookups() != 1 {
+		t.Errorf("Expected 1 workspace lookup, got %d", fake.lookups())
+	}
+}
+
+func TestLLMClient_CreateThread_LooksUpExpiredWorkspace(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "sriov-4-dot-16")
+
+	client := fake.client(time.Minute)
+	now := time.Now()
+	client.workspaces.now = func() time.Time { return now }
+
+	if _, err := client.CreateThread(context.Background(), "sriov", "4.16"); err != nil {
+		t.Fatalf("CreateThread failed: %v", err)
+	}
+	now = now.Add(2 * time.Minute)
+	if _, err := client.CreateThread(context.Background(), "sriov", "4.16"); err != nil {
+		t.Fatalf("CreateThread failed: %v", err)
+	}
+
+	if fake.lookups() != 2 {
+		t.Errorf("Expected 2 workspace lookups, got %d", fake.lookups())
+	}
+}
+
+func TestLLMClient_CreateThread_CacheDisabled(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "sriov-4-dot-16")
+
+	client := fake.client(0)
+	for i := 0; i < 2; i++ {
+		if _, err := client.CreateThread(context.Background(), "sriov", "4.16"); err != nil {
+			t.Fatalf("CreateThread failed: %v", err)
+		}
+	}
+
+	if fake.lookups() != 2 {
+		t.Errorf("Expected 2 workspace lookups, got %d", fake.lookups())
+	}
+}
+
+func TestLLMClient_ListProjects_RefreshesWorkspaceCache(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "sriov-4-dot-16", "metallb-4-dot-18")
+
+	client := fake.client(time.Minute)
+	if _, err := client.CreateThread(context.Background(), "metallb", "4.18"); err != nil {
+		t.Fatalf("CreateThread failed: %v", err)
+	}
+
+	fake.removeWorkspace("metallb-4-dot-18")
+	projects, err := client.ListProjects(context.Background())
+	if err != nil {
+		t.Fatalf("ListProjects failed: %v", err)
+	}
+	if len(projects) != 1 || projects[0] != "sriov-4-dot-16" {
+		t.Errorf("Unexpected projects: %v", projects)
+	}
+
+	// Listed workspaces are cached, the ones missing from the list are looked up again
+	if _, err := client.CreateThread(context.Background(), "sriov", "4.16"); err != nil {
+		t.Fatalf("CreateThread failed: %v", err)
+	}
+	if _, err := client.CreateThread(context.Background(), "metallb", "4.18"); err == nil {
+		t.Error("Expected an error for the removed workspace")
+	}
+
+	if fake.lookups() != 2 {
+		t.Errorf("Expected 2 workspace lookups, got %d", fake.lookups())
+	}
+}
+
+func TestLLMClient_WorkspaceAliases(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "team-sriov", "metallb-4-dot-18")
+	aliases, err := NewWorkspaceAliases("sriov/4.16=team-sriov")
+	if err != nil {
+		t.Fatalf("NewWorkspaceAliases failed: %v", err)
+	}
+	client := fake.client(time.Minute)
+	client.SetWorkspaceAliases(aliases)
+
+	// The aliased project routes to its workspace, the other ones to the derived slug
+	for _, project := range []struct{ project, version, workspace string }{
+		{project: "sriov", version: "4.16", workspace: "team-sriov"},
+		{project: "metallb", version: "4.18", workspace: "metallb-4-dot-18"},
+	} {
+		threadSlug, err := client.CreateThread(context.Background(), project.project, project.version)
+		if err != nil {
+			t.Fatalf("CreateThread failed for %s: %v", project.workspace, err)
+		}
+		if threadSlug != "thread-"+project.workspace {
+			t.Errorf("Expected 'thread-%s', got '%s'", project.workspace, threadSlug)
+		}
+		if _, err := client.SendMessageToChat(context.Background(), project.project, project.version, threadSlug, "question"); err != nil {
+			t.Fatalf("SendMessageToChat failed for %s: %v", project.workspace, err)
+		}
+		if err := client.Inject(context.Background(), project.project, project.version, "document", DocumentMetadata{Title: "doc"}); err != nil {
+			t.Fatalf("Inject failed for %s: %v", project.workspace, err)
+		}
+	}
+
+	if len(fake.chats) != 2 || fake.chats[0].Workspace != "team-sriov" || fake.chats[1].Workspace != "metallb-4-dot-18" {
+		t.Errorf("Unexpected chats: %+v", fake.chats)
+	}
+	if len(fake.documents) != 2 || fake.documents[0]["addToWorkspaces"] != "team-sriov" || fake.documents[1]["addToWorkspaces"] != "metallb-4-dot-18" {
+		t.Errorf("Unexpected documents: %+v", fake.documents)
+	}
+
+	// The aliased workspace is listed like a derived one, so the project passes the allowlist, and under its own slug
+	projects, err := client.ListProjects(context.Background())
+	if err != nil {
+		t.Fatalf("ListProjects failed: %v", err)
+	}
+	sort.Strings(projects)
+	if !reflect.DeepEqual(projects, []string{"metallb-4-dot-18", "sriov-4-dot-16", "team-sriov"}) {
+		t.Errorf("Unexpected projects: %v", projects)
+	}
+
+	// Removing the alias falls back to the derived slug
+	aliases.Set("sriov", "4.16", "")
+	if _, err := client.CreateThread(context.Background(), "sriov", "4.16"); err == nil || !strings.Contains(err.Error(), "sriov-4-dot-16") {
+		t.Errorf("Expected the derived workspace to be looked up, got %v", err)
+	}
+}
+
+func TestLLMClient_ListProjects_Error(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "sriov-4-dot-16")
+	fake.failWith("/api/v1/workspaces", http.StatusForbidden)
+
+	if _, err := fake.client(time.Minute).ListProjects(context.Background()); err == nil {
+		t.Error("Expected error for 403 response")
+	}
+}
+
+func TestLLMClient_Ping(t *testing.T) {
+	fake := newFakeAnythingLLM(t)
+
+	if err := fake.client(time.Minute).Ping(context.Background()); err != nil {
+		t.Errorf("Ping failed: %v", err)
+	}
+}
+
+func TestLLMClient_Ping_Error(t *testing.T) {
+	fake := newFakeAnythingLLM(t)
+	fake.failWith("/api/v1/auth", http.StatusForbidden)
+
+	if err := fake.client(time.Minute).Ping(context.Background()); err == nil {
+		t.Error("Expected error for 403 response")
+	}
+}
+
+func TestLLMClient_SendMessageToChat(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "sriov-4-dot-16")
+
+	response, err := fake.client(time.Minute).SendMessageToChat(context.Background(), "sriov", "4.16", "thread-slug", "test message")
+	if err != nil {
+		t.Fatalf("SendMessageToChat failed: %v", err)
+	}
+
+	if response != "answer to: test message" {
+		t.Errorf("Expected 'answer to: test message', got '%s'", response)
+	}
+
+	expected := fakeChat{Workspace: "sriov-4-dot-16", Thread: "thread-slug", Message: "test message", Mode: "query"}
+	if len(fake.chats) != 1 || fake.chats[0] != expected {
+		t.Errorf("Unexpected chats: %+v", fake.chats)
+	}
+}
+
+func TestLLMClient_SendMessageToChat_ChatMode(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "sriov-4-dot-16")
+
+	ctx := WithChatMode(context.Background(), ModeChat)
+	if _, err := fake.client(time.Minute).SendMessageToChat(ctx, "sriov", "4.16", "thread-slug", "test message"); err != nil {
+		t.Fatalf("SendMessageToChat failed: %v", err)
+	}
+
+	expected := fakeChat{Workspace: "sriov-4-dot-16", Thread: "thread-slug", Message: "test message", Mode: "chat"}
+	if len(fake.chats) != 1 || fake.chats[0] != expected {
+		t.Errorf("Unexpected chats: %+v", fake.chats)
+	}
+}
+
+func TestLLMClient_SendMessageToChat_StrictQueriesTheDocuments(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "sriov-4-dot-16")
+
+	ctx := WithStrict(WithChatMode(context.Background(), ModeChat), true)
+	response, err := fake.client(time.Minute).SendMessageToChat(ctx, "sriov", "4.16", "thread-slug", "test message")
+	if err != nil {
+		t.Fatalf("SendMessageToChat failed: %v", err)
+	}
+	if response != "answer to: test message" {
+		t.Errorf("Expected 'answer to: test message', got '%s'", response)
+	}
+
+	expected := fakeChat{Workspace: "sriov-4-dot-16", Thread: "thread-slug", Message: "test message", Mode: "query"}
+	if len(fake.chats) != 1 || fake.chats[0] != expected {
+		t.Errorf("Unexpected chats: %+v", fake.chats)
+	}
+}
+
+func TestLLMClient_SendMessageToChat_StrictWithoutSources(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "sriov-4-dot-16")
+	fake.findNothing()
+	client := fake.client(time.Minute)
+
+	_, err := client.SendMessageToChat(WithStrict(context.Background(), true), "sriov", "4.16", "thread-slug", "test message")
+	if !errors.Is(err, ErrNoInformation) {
+		t.Errorf("Expected ErrNoInformation, got %v", err)
+	}
+
+	// Without strict the refusal is the answer
+	response, err := client.SendMessageToChat(context.Background(), "sriov", "4.16", "thread-slug", "test message")
+	if err != nil {
+		t.Fatalf("SendMessageToChat failed: %v", err)
+	}
+	if response != "There is no relevant information in this workspace to answer your query." {
+		t.Errorf("Unexpected response: %s", response)
+	}
+}
+
+func TestLLMClient_LastContext(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "sriov-4-dot-16")
+	client := fake.client(time.Minute)
+
+	if _, err := client.SendMessageToChat(context.Background(), "sriov", "4.16", "thread-slug", "test message"); err != nil {
+		t.Fatalf("SendMessageToChat failed: %v", err)
+	}
+	expected := []ContextChunk{{Title: "sriov-faq.md", Text: "chunk", Score: 0.82}}
+	if chunks := client.LastContext("thread-slug"); !reflect.DeepEqual(chunks, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, chunks)
+	}
+
+	// The next answer replaces the context, even when nothing was retrieved
+	fake.findNothing()
+	if _, err := client.SendMessageToChat(context.Background(), "sriov", "4.16", "thread-slug", "test message"); err != nil {
+		t.Fatalf("SendMessageToChat failed: %v", err)
+	}
+	if chunks := client.LastContext("thread-slug"); len(chunks) != 0 {
+		t.Errorf("Expected no context, got %+v", chunks)
+	}
+}
+
+func TestLLMClient_SendMessageToChat_Images(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "sriov-4-dot-16")
+
+	ctx := WithImages(context.Background(), []Image{{Name: "error.png", MimeType: "image/png", Data: []byte("png")}})
+	if _, err := fake.client(time.Minute).SendMessageToChat(ctx, "sriov", "4.16", "thread-slug", "what is this error?"); err != nil {
+		t.Fatalf("SendMessageToChat failed: %v", err)
+	}
+
+	expected := []fakeAttachment{{Name: "error.png", Mime: "image/png", ContentString: "data:image/png;base64,cG5n"}}
+	if !reflect.DeepEqual(fake.attachments, expected) {
+		t.Errorf("Unexpected attachments: %+v", fake.attachments)
+	}
+}
+
+func TestLLMClient_SendMessageToChat_Temperature(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "sriov-4-dot-16")
+	client := fake.client(time.Minute)
+
+	for _, ctx := range []context.Context{
+		WithTemperature(context.Background(), 0.2),
+		WithTemperature(context.Background(), 0.2),
+		context.Background(),
+		WithTemperature(context.Background(), 0),
+	} {
+		if _, err := client.SendMessageToChat(ctx, "sriov", "4.16", "thread-slug", "test message"); err != nil {
+			t.Fatalf("SendMessageToChat failed: %v", err)
+		}
+	}
+
+	// The workspace is only updated when the temperature changes
+	expected := []fakeWorkspaceUpdate{
+		{Workspace: "sriov-4-dot-16", Settings: map[string]interface{}{"openAiTemp": 0.2}},
+		{Workspace: "sriov-4-dot-16", Settings: map[string]interface{}{"openAiTemp": float64(0)}},
+	}
+	if !reflect.DeepEqual(fake.workspaceUpdates, expected) {
+		t.Errorf("Unexpected workspace updates: %+v", fake.workspaceUpdates)
+	}
+	if len(fake.chats) != 4 {
+		t.Errorf("Expected 4 chats, got %d", len(fake.chats))
+	}
+}
+
+func TestLLMClient_SendMessageToChat_TopK(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "sriov-4-dot-16")
+	client := fake.client(time.Minute)
+
+	for _, ctx := range []context.Context{
+		WithTopK(context.Background(), 8),
+		WithTopK(context.Background(), 8),
+		context.Background(),
+		WithTemperature(WithTopK(context.Background(), 3), 0.2),
+	} {
+		if _, err := client.SendMessageToChat(ctx, "sriov", "4.16", "thread-slug", "test message"); err != nil {
+			t.Fatalf("SendMessageToChat failed: %v", err)
+		}
+	}
+
+	// The number of sources is set as the topN of the workspace on a change, with the temperature in one update
+	expected := []fakeWorkspaceUpdate{
+		{Workspace: "sriov-4-dot-16", Settings: map[string]interface{}{"topN": float64(8)}},
+		{Workspace: "sriov-4-dot-16", Settings: map[string]interface{}{"topN": float64(3), "openAiTemp": 0.2}},
+	}
+	if !reflect.DeepEqual(fake.workspaceUpdates, expected) {
+		t.Errorf("Unexpected workspace updates: %+v", fake.workspaceUpdates)
+	}
+}
+
+func TestLLMClient_SendMessageToChat_TemperatureUpdateFails(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "sriov-4-dot-16")
+	fake.failTimes("/api/v1/workspace/sriov-4-dot-16/update", http.StatusInternalServerError, 1)
+	client := fake.client(time.Minute)
+	ctx := WithTemperature(context.Background(), 0.7)
+
+	// The answer is still sent with the temperature of the workspace
+	response, err := client.SendMessageToChat(ctx, "sriov", "4.16", "thread-slug", "test message")
+	if err != nil || response != "answer to: test message" {
+		t.Fatalf("Expected the answer despite the failed update, got %q, %v", response, err)
+	}
+	if len(fake.workspaceUpdates) != 0 {
+		t.Errorf("Expected no workspace update, got %+v", fake.workspaceUpdates)
+	}
+
+	// The failed temperature isn't remembered, so the next chat sets it
+	if _, err := client.SendMessageToChat(ctx, "sriov", "4.16", "thread-slug", "test message"); err != nil {
+		t.Fatalf("SendMessageToChat failed: %v", err)
+	}
+	if len(fake.workspaceUpdates) != 1 {
+		t.Errorf("Expected the temperature to be set on the next chat, got %+v", fake.workspaceUpdates)
+	}
+}
+
+func TestLLMClient_SendMessageToChat_Error(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "sriov-4-dot-16")
+	fake.failWith("/api/v1/workspace/sriov-4-dot-16/thread/thread-slug/chat", http.StatusBadRequest)
+
+	_, err := fake.client(time.Minute).SendMessageToChat(context.Background(), "sriov", "4.16", "thread-slug", "test message")
+	if err == nil || !strings.Contains(err.Error(), "failed to send message to workspace sriov-4-dot-16") {
+		t.Errorf("Expected a chat error, got %v", err)
+	}
+}
+
+func TestLLMClient_SendMessageToChat_ThreadNotFound(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "sriov-4-dot-16")
+	fake.expireThread("thread-slug")
+
+	_, err := fake.client(time.Minute).SendMessageToChat(context.Background(), "sriov", "4.16", "thread-slug", "test message")
+	if !errors.Is(err, ErrThreadNotFound) {
+		t.Errorf("Expected ErrThreadNotFound, got %v", err)
+	}
+}
+
+func TestLLMClient_SendMessageToChat_ClientErrorIsNotAThread(t *testing.T) {
+	for _, statusCode := range []int{http.StatusBadRequest, http.StatusNotFound} {
+		fake := newFakeAnythingLLM(t, "sriov-4-dot-16")
+		fake.failWith("/api/v1/workspace/sriov-4-dot-16/thread/thread-slug/chat", statusCode)
+
+		_, err := fake.client(time.Minute).SendMessageToChat(context.Background(), "sriov", "4.16", "thread-slug", "test message")
+		if err == nil || errors.Is(err, ErrThreadNotFound) {
+			t.Errorf("Expected an error other than ErrThreadNotFound for status %d, got %v", statusCode, err)
+		}
+	}
+}
+
+func TestLLMClient_Elaborate(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "elaborate")
+
+	response, err := fake.client(time.Minute).Elaborate(context.Background(), "elaborate", "thread-slug", "elaborate this")
+	if err != nil {
+		t.Fatalf("Elaborate failed: %v", err)
+	}
+
+	if response != "answer to: elaborate this" {
+		t.Errorf("Expected 'answer to: elaborate this', got '%s'", response)
+	}
+
+	expected := fakeChat{Workspace: "elaborate", Thread: "thread-slug", Message: "elaborate this", Mode: "chat"}
+	if len(fake.chats) != 1 || fake.chats[0] != expected {
+		t.Errorf("Unexpected chats: %+v", fake.chats)
+	}
+}
+
+func TestLLMClient_Elaborate_Error(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "elaborate")
+	fake.failWith("/api/v1/workspace/elaborate/thread/thread-slug/chat", http.StatusInternalServerError)
+
+	if _, err := fake.client(time.Minute).Elaborate(context.Background(), "elaborate", "thread-slug", "elaborate this"); err == nil {
+		t.Error("Expected error for 500 response")
+	}
+}
+
+func TestLLMClient_Inject(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "metallb-4-dot-18")
+
+	if err := fake.client(time.Minute).Inject(context.Background(), "metallb", "4.18", "injected content", DocumentMetadata{}); err != nil {
+		t.Fatalf("Inject failed: %v", err)
+	}
+
+	if len(fake.documents) != 1 {
+		t.Fatalf("Expected 1 document, got %d", len(fake.documents))
+	}
+	document := fake.documents[0]
+	if document["textContent"] != "injected content" || document["addToWorkspaces"] != "metallb-4-dot-18" {
+		t.Errorf("Unexpected document: %+v", document)
+	}
+}
+
+func TestLLMClient_Inject_Metadata(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "metallb-4-dot-18")
+	metadata := DocumentMetadata{Title: "MetalLB BGP peers", Source: "https://example.slack.com/archives/C1/p1"}
+
+	if err := fake.client(time.Minute).Inject(context.Background(), "metallb", "4.18", "injected content", metadata); err != nil {
+		t.Fatalf("Inject failed: %v", err)
+	}
+
+	documentMetadata, ok := fake.documents[0]["metadata"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected document metadata, got %+v", fake.documents[0])
+	}
+	if documentMetadata["title"] != metadata.Title || documentMetadata["docSource"] != metadata.Source {
+		t.Errorf("Unexpected document metadata: %+v", documentMetadata)
+	}
+}
+
+func TestLLMClient_Inject_Correction(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "metallb-4-dot-18")
+
+	if err := fake.client(time.Minute).Inject(context.Background(), "metallb", "4.18", "the right answer", DocumentMetadata{Correction: true}); err != nil {
+		t.Fatalf("Inject failed: %v", err)
+	}
+
+	documentMetadata, ok := fake.documents[0]["metadata"].(map[string]interface{})
+	if !ok || documentMetadata["description"] != CorrectionDescription {
+		t.Errorf("Expected the correction description, got %+v", fake.documents[0])
+	}
+}
+
+func TestLLMClient_InjectBatch(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "metallb-4-dot-18")
+	docs := []Document{
+		{Content: "first message", Metadata: DocumentMetadata{Source: "https://example.slack.com/archives/C1/p1"}},
+		{Content: "second message", Metadata: DocumentMetadata{Source: "https://example.slack.com/archives/C1/p1"}},
+	}
+
+	if err := fake.client(time.Minute).InjectBatch(context.Background(), "metallb", "4.18", docs); err != nil {
+		t.Fatalf("InjectBatch failed: %v", err)
+	}
+
+	if len(fake.documents) != 2 {
+		t.Fatalf("Expected 2 documents, got %d", len(fake.documents))
+	}
+	for index, doc := range docs {
+		if fake.documents[index]["textContent"] != doc.Content {
+			t.Errorf("Expected document %d to be %q, got %+v", index, doc.Content, fake.documents[index])
+		}
+	}
+}
+
+func TestLLMClient_InjectBatch_Error(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "metallb-4-dot-18")
+	fake.failWith("/api/v1/document/raw-text", http.StatusUnprocessableEntity)
+
+	err := fake.client(time.Minute).InjectBatch(context.Background(), "metallb", "4.18", []Document{{Content: "first message"}, {Content: "second message"}})
+	if err == nil || !strings.Contains(err.Error(), "document 1 of 2") {
+		t.Errorf("Expected the first document to fail, got %v", err)
+	}
+}
+
+func TestLLMClient_ListDocuments(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "metallb-4-dot-18")
+	client := fake.client(time.Minute)
+	if err := client.Inject(context.Background(), "metallb", "4.18", "injected content", DocumentMetadata{Title: "MetalLB BGP peers"}); err != nil {
+		t.Fatalf("Inject failed: %v", err)
+	}
+
+	documents, err := client.ListDocuments(context.Background(), "metallb", "4.18")
+	if err != nil {
+		t.Fatalf("ListDocuments failed: %v", err)
+	}
+
+	if len(documents) != 1 || documents[0].ID != "doc-1" || documents[0].Title != "MetalLB BGP peers" {
+		t.Errorf("Unexpected documents: %+v", documents)
+	}
+}
+
+func TestLLMClient_ListDocuments_UnknownWorkspace(t *testing.T) {
+	fake := newFakeAnythingLLM(t)
+
+	if _, err := fake.client(time.Minute).ListDocuments(context.Background(), "metallb", "4.18"); err == nil {
+		t.Error("Expected error for an unknown workspace")
+	}
+}
+
+func TestLLMClient_Inject_Error(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "metallb-4-dot-18")
+	fake.failWith("/api/v1/document/raw-text", http.StatusUnprocessableEntity)
+
+	if err := fake.client(time.Minute).Inject(context.Background(), "metallb", "4.18", "injected content", DocumentMetadata{}); err == nil {
+		t.Error("Expected error for 422 response")
+	}
+}
+
+func TestLLMClient_Inject_Canceled(t *testing.T) {
+	client := newLLMClient("test", "test-key", time.Minute)
+	client.apiClient.GetConfig().Debug = false
+	client.apiClient.GetConfig().HTTPClient = &http.Client{Transport: blockingTransport{}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- client.Inject(ctx, "sriov", "4.16", "injected content", DocumentMetadata{})
+	}()
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Inject didn't return when the context was canceled")
+	}
+}
+
+// failingTransport fails every request before any response is received
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+// dialFailingTransport fails every request while opening the connection, so none is sent
+type dialFailingTransport struct{}
+
+func (dialFailingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+}
+
+func newFailingLLMClient() *LLMClient {
+	client := newLLMClient("test", "test-key", time.Minute)
+	client.apiClient.GetConfig().Debug = false
+	client.apiClient.GetConfig().HTTPClient = &http.Client{Transport: failingTransport{}}
+	client.injectBackoff = 0
+	return client
+}
+
+func TestLLMClient_TransportErrorWithoutResponse(t *testing.T) {
+	t.Run("workspace lookup", func(t *testing.T) {
+		_, err := newFailingLLMClient().CreateThread(context.Background(), "sriov", "4.16")
+		if err == nil || !strings.Contains(err.Error(), "failed to get workspace sriov-4-dot-16") {
+			t.Errorf("Expected a workspace lookup error, got %v", err)
+		}
+	})
+
+	t.Run("thread creation", func(t *testing.T) {
+		client := newFailingLLMClient()
+		client.workspaces.add("sriov-4-dot-16")
+
+		_, err := client.CreateThread(context.Background(), "sriov", "4.16")
+		if err == nil || !strings.Contains(err.Error(), "failed to create thread in workspace sriov-4-dot-16") {
+			t.Errorf("Expected a thread creation error, got %v", err)
+		}
+	})
+
+	t.Run("chat", func(t *testing.T) {
+		_, err := newFailingLLMClient().SendMessageToChat(context.Background(), "sriov", "4.16", "thread-slug", "test message")
+		if err == nil || !strings.Contains(err.Error(), "failed to send message to workspace sriov-4-dot-16") {
+			t.Errorf("Expected a chat error, got %v", err)
+		}
+	})
+
+	t.Run("elaborate", func(t *testing.T) {
+		_, err := newFailingLLMClient().Elaborate(context.Background(), "elaborate", "thread-slug", "test message")
+		if err == nil || !strings.Contains(err.Error(), "connection refused") {
+			t.Errorf("Expected the transport error, got %v", err)
+		}
+	})
+
+	t.Run("inject", func(t *testing.T) {
+		err := newFailingLLMClient().Inject(context.Background(), "sriov", "4.16", "injected content", DocumentMetadata{})
+		if err == nil || !strings.Contains(err.Error(), "connection refused") {
+			t.Errorf("Expected the transport error, got %v", err)
+		}
+	})
+
+	t.Run("ping", func(t *testing.T) {
+		err := newFailingLLMClient().Ping(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "failed to reach AnythingLLM") {
+			t.Errorf("Expected an unreachable backend error, got %v", err)
+		}
+	})
+}
+
+func TestLLMClient_SendMessageToChat_RateLimited(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "sriov-4-dot-16")
+	fake.rateLimit("/api/v1/workspace/sriov-4-dot-16/thread/thread-slug/chat", 1, "0")
+
+	response, err := fake.client(time.Minute).SendMessageToChat(context.Background(), "sriov", "4.16", "thread-slug", "test message")
+	if err != nil {
+		t.Fatalf("Expected the rate limited chat to be retried, got %v", err)
+	}
+	if response != "answer to: test message" {
+		t.Errorf("Expected the answer of the retry, got '%s'", response)
+	}
+	if fake.rateLimitedRequests() != 1 || len(fake.chats) != 1 {
+		t.Errorf("Expected 1 rate limited request and 1 chat, got %d and %d", fake.rateLimitedRequests(), len(fake.chats))
+	}
+}
+
+func TestLLMClient_Inject_RateLimitedWithBackoff(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "sriov-4-dot-16")
+	fake.rateLimit("/api/v1/document/raw-text", 2, "")
+	client := fake.client(time.Minute)
+
+	retried := 0
+	ctx := WithInjectRetryNotifier(context.Background(), func(int, int, time.Duration) { retried++ })
+	if err := client.Inject(ctx, "sriov", "4.16", "injected content", DocumentMetadata{}); err != nil {
+		t.Fatalf("Expected the rate limited inject to be retried, got %v", err)
+	}
+	if len(fake.documents) != 1 {
+		t.Errorf("Expected 1 document, got %d", len(fake.documents))
+	}
+	// The inject retries are the only ones, the rate limit retries of the other calls don't stack on them
+	if retried != 2 || fake.rateLimitedRequests() != 2 {
+		t.Errorf("Expected 2 inject retries of 2 rate limited requests, got %d and %d", retried, fake.rateLimitedRequests())
+	}
+}
+
+func TestLLMClient_Inject_RetriesConnectFailure(t *testing.T) {
+	client := newLLMClient("test", "test-key", time.Minute)
+	client.apiClient.GetConfig().Debug = false
+	client.apiClient.GetConfig().HTTPClient = &http.Client{Transport: dialFailingTransport{}}
+	client.injectBackoff = 0
+
+	var retries []int
+	ctx := WithInjectRetryNotifier(context.Background(), func(attempt, maxAttempts int, _ time.Duration) {
+		if maxAttempts != maxInjectAttempts {
+			t.Errorf("Expected %d attempts at most, got %d", maxInjectAttempts, maxAttempts)
+		}
+		retries = append(retries, attempt)
+	})
+	if err := client.Inject(ctx, "sriov", "4.16", "injected content", DocumentMetadata{}); err == nil {
+		t.Fatal("Expected the inject to fail")
+	}
+	if len(retries) != maxInjectAttempts-1 {
+		t.Errorf("Expected the connect failures to be retried %d times, got %v", maxInjectAttempts-1, retries)
+	}
+}
+
+func TestLLMClient_Inject_ServerErrorNotRetried(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "sriov-4-dot-16")
+	fake.failTimes("/api/v1/document/raw-text", http.StatusBadGateway, 1)
+
+	retried := 0
+	ctx := WithInjectRetryNotifier(context.Background(), func(int, int, time.Duration) { retried++ })
+	err := fake.client(time.Minute).Inject(ctx, "sriov", "4.16", "injected content", DocumentMetadata{})
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadGateway {
+		t.Errorf("Expected the 502 status error, got %v", err)
+	}
+	// The backend may have stored the document before failing, a retry could inject it twice
+	if retried != 0 {
+		t.Errorf("Expected no retry, got %d", retried)
+	}
+}
+
+func TestIsTransientInjectError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		transient bool
+	}{
+		{name: "rate limited", err: &StatusError{StatusCode: http.StatusTooManyRequests}, transient: true},
+		{name: "connect failure", err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, transient: true},
+		{name: "server error", err: &StatusError{StatusCode: http.StatusInternalServerError}},
+		{name: "client error", err: &StatusError{StatusCode: http.StatusBadRequest}},
+		{name: "read timeout", err: &net.OpError{Op: "read", Err: errors.New("i/o timeout")}},
+		{name: "other", err: errors.New("connection reset")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if transient := isTransientInjectError(fmt.Errorf("failed to inject messages: %w", tt.err)); transient != tt.transient {
+				t.Errorf("Expected transient to be %v, got %v", tt.transient, transient)
+			}
+		})
+	}
+}
+
+func TestLLMClient_Inject_ClientErrorNotRetried(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "sriov-4-dot-16")
+	fake.failTimes("/api/v1/document/raw-text", http.StatusUnprocessableEntity, 1)
+
+	err := fake.client(time.Minute).Inject(context.Background(), "sriov", "4.16", "injected content", DocumentMetadata{})
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("Expected the 422 status error, got %v", err)
+	}
+	if len(fake.documents) != 0 {
+		t.Errorf("Expected no document, got %d", len(fake.documents))
+	}
+}
+
+func TestLLMClient_RateLimitRetriesExhausted(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "sriov-4-dot-16")
+	fake.rateLimit("/api/v1/workspace/sriov-4-dot-16/thread/new", 10, "0")
+	client := fake.client(time.Minute)
+
+	_, err := client.CreateThread(context.Background(), "sriov", "4.16")
+	if err == nil || !strings.Contains(err.Error(), "429") {
+		t.Errorf("Expected a 429 error, got %v", err)
+	}
+	if fake.rateLimitedRequests() != maxRateLimitRetries+1 {
+		t.Errorf("Expected %d attempts, got %d", maxRateLimitRetries+1, fake.rateLimitedRequests())
+	}
+}
+
+func TestLLMClient_RateLimitWaitCanceled(t *testing.T) {
+	fake := newFakeAnythingLLM(t, "sriov-4-dot-16")
+	fake.rateLimit("/api/v1/workspaces", 1, "20")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := fake.client(time.Minute).ListProjects(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the context deadline, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the Retry-After wait to stop with the context, took %s", elapsed)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		retryAfter string
+		expected   time.Duration
+	}{
+		{name: "missing", retryAfter: "", expected: time.Second},
+		{name: "seconds", retryAfter: "5", expected: 5 * time.Second},
+		{name: "capped", retryAfter: "3600", expected: maxRateLimitWait},
+		{name: "past date", retryAfter: "Wed, 21 Oct 2015 07:28:00 GMT", expected: 0},
+		{name: "invalid", retryAfter: "soon", expected: time.Second},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			response := &http.Response{Header: http.Header{}}
+			if test.retryAfter != "" {
+				response.Header.Set("Retry-After", test.retryAfter)
+			}
+			if wait := retryAfter(response, time.Second); wait != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, wait)
+			}
+		})
+	}
+}
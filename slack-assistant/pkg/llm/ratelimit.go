@@ -0,0 +1,63 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// maxRateLimitRetries is how many times a call rate limited by AnythingLLM is retried
+	maxRateLimitRetries = 3
+	// DefaultRateLimitBackoff is the wait before retrying a rate limited call without Retry-After, doubled on every retry
+	DefaultRateLimitBackoff = time.Second
+	// maxRateLimitWait caps the wait asked by Retry-After, so a call doesn't outlive the Slack interaction
+	maxRateLimitWait = 30 * time.Second
+)
+
+// withRateLimitRetry runs the SDK call again while AnythingLLM answers 429 Too Many Requests, waiting for the
+// Retry-After of the response or an exponential backoff, the last response is returned once the retries are exhausted
+func withRateLimitRetry[T any](ctx context.Context, backoff time.Duration, call func() (T, *http.Response, error)) (T, *http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		result, response, err := call()
+		if response == nil || response.StatusCode != http.StatusTooManyRequests || attempt == maxRateLimitRetries {
+			return result, response, err
+		}
+
+		wait := retryAfter(response, backoff<<attempt)
+		fmt.Printf("⏳ AnythingLLM rate limited the request, retrying in %s (%d/%d)\n", wait, attempt+1, maxRateLimitRetries)
+		if response.Body != nil {
+			//nolint:errcheck // the rate limited response is discarded
+			_ = response.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryAfter returns the wait asked by the Retry-After header of the response, in seconds or as an HTTP date,
+// or the fallback when the header is missing or invalid
+func retryAfter(response *http.Response, fallback time.Duration) time.Duration {
+	value := response.Header.Get("Retry-After")
+	if value == "" {
+		return fallback
+	}
+
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		wait = time.Until(date)
+	} else {
+		return fallback
+	}
+	return min(max(wait, 0), maxRateLimitWait)
+}
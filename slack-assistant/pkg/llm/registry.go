@@ -0,0 +1,134 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// ErrUnknownBackend is returned when switching to a backend that isn't configured
+var ErrUnknownBackend = errors.New("unknown LLM backend")
+
+// Backend is an LLM backend the registry can answer with
+type Backend struct {
+	// Name selects the backend, e.g. anythingllm
+	Name string
+	// Provider and Host describe the backend in the status messages
+	Provider string
+	Host     string
+	Client   Interface
+}
+
+// Registry holds the configured LLM backends and forwards every call to the active one,
+// so the backend can be switched at runtime without restarting the bot
+type Registry struct {
+	mu       sync.RWMutex
+	backends []Backend
+	active   int
+}
+
+// NewRegistry returns a registry answering with the active backend, an error is returned when it isn't one of the
+// backends or a name is configured twice
+func NewRegistry(active string, backends ...Backend) (*Registry, error) {
+	for i, backend := range backends {
+		if slices.ContainsFunc(backends[:i], func(other Backend) bool { return other.Name == backend.Name }) {
+			return nil, fmt.Errorf("LLM backend %s is configured twice", backend.Name)
+		}
+	}
+	registry := &Registry{backends: backends}
+	if err := registry.Use(active); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// Use switches the calls to the named backend, the calls in flight finish on the previous one
+func (r *Registry) Use(name string) error {
+	index := slices.IndexFunc(r.backends, func(backend Backend) bool { return backend.Name == name })
+	if index < 0 {
+		return fmt.Errorf("%w %q, expected one of %v", ErrUnknownBackend, name, r.Names())
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.active = index
+	return nil
+}
+
+// Active returns the backend answering the calls
+func (r *Registry) Active() Backend {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.backends[r.active]
+}
+
+// Backends returns the configured backends in configuration order
+func (r *Registry) Backends() []Backend {
+	return slices.Clone(r.backends)
+}
+
+// Names returns the names of the configured backends
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.backends))
+	for _, backend := range r.backends {
+		names = append(names, backend.Name)
+	}
+	return names
+}
+
+// client returns the client of the active backend
+func (r *Registry) client() Interface {
+	return r.Active().Client
+}
+
+// CreateThread creates a thread with the active backend
+func (r *Registry) CreateThread(ctx context.Context, project, version string) (string, error) {
+	return r.client().CreateThread(ctx, project, version)
+}
+
+// SendMessageToChat sends the message to the active backend
+func (r *Registry) SendMessageToChat(ctx context.Context, project, version, threadSlug, message string) (string, error) {
+	return r.client().SendMessageToChat(ctx, project, version, threadSlug, message)
+}
+
+// Elaborate elaborates the message with the active backend
+func (r *Registry) Elaborate(ctx context.Context, workspace, threadSlug, message string) (string, error) {
+	return r.client().Elaborate(ctx, workspace, threadSlug, message)
+}
+
+// Inject injects the message into the knowledge base of the active backend
+func (r *Registry) Inject(ctx context.Context, project, version, message string, metadata DocumentMetadata) error {
+	return r.client().Inject(ctx, project, version, message, metadata)
+}
+
+// InjectBatch injects the documents into the knowledge base of the active backend
+func (r *Registry) InjectBatch(ctx context.Context, project, version string, docs []Document) error {
+	return r.client().InjectBatch(ctx, project, version, docs)
+}
+
+// ListProjects lists the projects of the active backend
+func (r *Registry) ListProjects(ctx context.Context) ([]string, error) {
+	return r.client().ListProjects(ctx)
+}
+
+// ListDocuments lists the documents of the active backend
+func (r *Registry) ListDocuments(ctx context.Context, project, version string) ([]DocumentInfo, error) {
+	return r.client().ListDocuments(ctx, project, version)
+}
+
+// Ping checks the active backend can be reached
+func (r *Registry) Ping(ctx context.Context) error {
+	return r.client().Ping(ctx)
+}
+
+// DeleteThread deletes a thread with the active backend
+func (r *Registry) DeleteThread(ctx context.Context, project, version, threadSlug string) error {
+	return r.client().DeleteThread(ctx, project, version, threadSlug)
+}
+
+// LastContext returns the last context of the thread on the active backend
+func (r *Registry) LastContext(threadSlug string) []ContextChunk {
+	return r.client().LastContext(threadSlug)
+}
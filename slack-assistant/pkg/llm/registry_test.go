@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// namedClient is an LLM client answering with its name, it only implements the calls used by the tests
+type namedClient struct {
+	Interface
+	name string
+}
+
+func (c *namedClient) SendMessageToChat(_ context.Context, _, _, _, _ string) (string, error) {
+	return c.name, nil
+}
+
+func newTestRegistry(t *testing.T) *Registry {
+	t.Helper()
+	registry, err := NewRegistry("anythingllm",
+		Backend{Name: "anythingllm", Provider: "AnythingLLM", Client: &namedClient{name: "anythingllm"}},
+		Backend{Name: "llamaindex", Provider: "LlamaIndex", Client: &namedClient{name: "llamaindex"}},
+	)
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+	return registry
+}
+
+func answeredBy(t *testing.T, registry *Registry) string {
+	t.Helper()
+	name, err := registry.SendMessageToChat(context.Background(), "sriov", "4.16", "slug", "question")
+	if err != nil {
+		t.Fatalf("SendMessageToChat failed: %v", err)
+	}
+	return name
+}
+
+func TestRegistry_Switch(t *testing.T) {
+	registry := newTestRegistry(t)
+	if name := answeredBy(t, registry); name != "anythingllm" {
+		t.Errorf("Expected the initial backend to answer, got %s", name)
+	}
+
+	if err := registry.Use("llamaindex"); err != nil {
+		t.Fatalf("Use failed: %v", err)
+	}
+	if name := answeredBy(t, registry); name != "llamaindex" {
+		t.Errorf("Expected the llamaindex backend to answer, got %s", name)
+	}
+	if active := registry.Active(); active.Name != "llamaindex" || active.Provider != "LlamaIndex" {
+		t.Errorf("Unexpected active backend: %+v", active)
+	}
+}
+
+func TestRegistry_UnknownBackend(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	if err := registry.Use("openai"); !errors.Is(err, ErrUnknownBackend) {
+		t.Errorf("Expected ErrUnknownBackend, got %v", err)
+	}
+	if name := answeredBy(t, registry); name != "anythingllm" {
+		t.Errorf("Expected the active backend to be kept, got %s", name)
+	}
+}
+
+func TestNewRegistry_Validation(t *testing.T) {
+	client := &namedClient{name: "anythingllm"}
+
+	if _, err := NewRegistry("llamaindex", Backend{Name: "anythingllm", Client: client}); !errors.Is(err, ErrUnknownBackend) {
+		t.Errorf("Expected ErrUnknownBackend for an active backend that isn't configured, got %v", err)
+	}
+	if _, err := NewRegistry("anythingllm", Backend{Name: "anythingllm", Client: client}, Backend{Name: "anythingllm", Client: client}); err == nil {
+		t.Error("Expected an error for a backend configured twice")
+	}
+}
+
+func TestRegistry_ConcurrentSwitch(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_ = registry.Use(registry.Names()[i%2])
+		}()
+		go func() {
+			defer wg.Done()
+			if name := answeredBy(t, registry); name != "anythingllm" && name != "llamaindex" {
+				t.Errorf("Unexpected backend %s", name)
+			}
+		}()
+	}
+	wg.Wait()
+}
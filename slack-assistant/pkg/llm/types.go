@@ -2,16 +2,189 @@
 package llm
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 )
 
+// ErrMalformedResponse is returned when an LLM response is missing required fields
+var ErrMalformedResponse = errors.New("malformed LLM response")
+
+// ErrThreadNotFound is returned when the thread of a chat no longer exists, e.g. some AnythingLLM deployments expire
+// the idle threads
+var ErrThreadNotFound = errors.New("LLM thread not found")
+
+// ErrNoInformation is returned by a strict SendMessageToChat when the documents of the workspace have nothing
+// relevant to answer the question
+var ErrNoInformation = errors.New("no relevant information found in the documents")
+
+// StatusError is returned when the LLM backend answers a request with an unexpected HTTP status
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("server returned status %d: %s", e.StatusCode, e.Body)
+}
+
 // Interface defines the interface for LLM client operations
 type Interface interface {
-	CreateThread(project, version string) (string, error)
-	SendMessageToChat(project, version, threadSlug, message string) (string, error)
-	Elaborate(threadSlug, message string) (string, error)
-	Inject(project, version, message string) error
+	CreateThread(ctx context.Context, project, version string) (string, error)
+	// DeleteThread deletes the LLM thread of the project and version workspace, e.g. a throwaway one
+	DeleteThread(ctx context.Context, project, version, threadSlug string) error
+	SendMessageToChat(ctx context.Context, project, version, threadSlug, message string) (string, error)
+	Elaborate(ctx context.Context, workspace, threadSlug, message string) (string, error)
+	Inject(ctx context.Context, project, version, message string, metadata DocumentMetadata) error
+	InjectBatch(ctx context.Context, project, version string, docs []Document) error
+	ListProjects(ctx context.Context) ([]string, error)
+	ListDocuments(ctx context.Context, project, version string) ([]DocumentInfo, error)
+	Ping(ctx context.Context) error
+	// LastContext returns the document chunks the last answer of the LLM thread was retrieved from,
+	// nil when the thread wasn't answered since the start or the backend doesn't report them
+	LastContext(threadSlug string) []ContextChunk
+}
+
+// ChatMode is how the LLM answers a question sent by SendMessageToChat
+type ChatMode string
+
+const (
+	// ModeQuery only answers from the documents of the workspace, it is the default mode
+	ModeQuery ChatMode = "query"
+	// ModeChat also answers from the knowledge of the model, for more free-form answers
+	ModeChat ChatMode = "chat"
+)
+
+type chatModeKey struct{}
+
+// WithChatMode returns a context making SendMessageToChat answer in the mode,
+// the LlamaIndex backend has a single mode and ignores it
+func WithChatMode(ctx context.Context, mode ChatMode) context.Context {
+	return context.WithValue(ctx, chatModeKey{}, mode)
+}
+
+// ChatModeFrom returns the mode set on the context with WithChatMode, ModeQuery when none was set
+func ChatModeFrom(ctx context.Context) ChatMode {
+	if mode, ok := ctx.Value(chatModeKey{}).(ChatMode); ok && mode != "" {
+		return mode
+	}
+	return ModeQuery
+}
+
+type strictKey struct{}
+
+// WithStrict returns a context making SendMessageToChat answer only from the retrieved documents, failing with
+// ErrNoInformation when none is relevant instead of answering from the knowledge of the model. AnythingLLM answers
+// in query mode whatever the chat mode, LlamaIndex receives it as the strict flag of the answer request
+func WithStrict(ctx context.Context, strict bool) context.Context {
+	return context.WithValue(ctx, strictKey{}, strict)
+}
+
+// StrictFrom reports whether the context was set strict with WithStrict
+func StrictFrom(ctx context.Context) bool {
+	strict, _ := ctx.Value(strictKey{}).(bool)
+	return strict
+}
+
+const (
+	// MinTopK is the fewest sources an answer may be retrieved from
+	MinTopK = 1
+	// MaxTopK is the most sources an answer may be retrieved from
+	MaxTopK = 20
+)
+
+type topKKey struct{}
+
+// WithTopK returns a context making SendMessageToChat retrieve topK sources for the answer,
+// AnythingLLM sets it as the topN of the workspace, shared by every thread of the workspace
+func WithTopK(ctx context.Context, topK int) context.Context {
+	return context.WithValue(ctx, topKKey{}, topK)
+}
+
+// TopKFrom returns the number of sources set on the context with WithTopK, 0 when the backend default is used
+func TopKFrom(ctx context.Context) int {
+	if topK, ok := ctx.Value(topKKey{}).(int); ok && topK > 0 {
+		return topK
+	}
+	return 0
+}
+
+const (
+	// MinTemperature is the most grounded temperature an answer may be generated with
+	MinTemperature = 0.0
+	// MaxTemperature is the most creative temperature an answer may be generated with
+	MaxTemperature = 1.0
+)
+
+type temperatureKey struct{}
+
+// WithTemperature returns a context making SendMessageToChat and Elaborate answer with the temperature,
+// AnythingLLM has no chat temperature and sets it on the workspace before the chat instead
+func WithTemperature(ctx context.Context, temperature float64) context.Context {
+	return context.WithValue(ctx, temperatureKey{}, temperature)
+}
+
+// TemperatureFrom returns the temperature set on the context with WithTemperature,
+// false when none was set and the backend default is used
+func TemperatureFrom(ctx context.Context) (float64, bool) {
+	temperature, ok := ctx.Value(temperatureKey{}).(float64)
+	return temperature, ok
+}
+
+// Image is an image attached to a question, e.g. a screenshot of an error
+type Image struct {
+	Name     string
+	MimeType string
+	Data     []byte
+}
+
+type imagesKey struct{}
+
+// WithImages returns a context making SendMessageToChat send the images with the question to a model with vision,
+// the LlamaIndex backend has no vision and ignores them
+func WithImages(ctx context.Context, images []Image) context.Context {
+	return context.WithValue(ctx, imagesKey{}, images)
+}
+
+// ImagesFrom returns the images set on the context with WithImages
+func ImagesFrom(ctx context.Context) []Image {
+	images, _ := ctx.Value(imagesKey{}).([]Image)
+	return images
+}
+
+// DocumentMetadata describes an injected document, empty fields are left out
+type DocumentMetadata struct {
+	// Title is the document title, a random one is generated when empty
+	Title string
+	// Source is where the document comes from, e.g. the permalink of the Slack thread
+	Source string
+	// Correction marks the document as a correction written by a user, sent as its description
+	Correction bool
+}
+
+// CorrectionDescription is the description of the documents injected as a correction
+const CorrectionDescription = "Human correction of an assistant answer"
+
+// Document is a single document injected by InjectBatch
+type Document struct {
+	Content  string
+	Metadata DocumentMetadata
+}
+
+// DocumentInfo describes a document stored in a workspace
+type DocumentInfo struct {
+	ID    string
+	Title string
+}
+
+// WorkspaceSlug returns the workspace slug for a project and version (e.g. sriov-4-dot-16)
+func WorkspaceSlug(project, version string) string {
+	if version == "" {
+		return project
+	}
+	return fmt.Sprintf("%s-%s", project, strings.ReplaceAll(version, ".", "-dot-"))
 }
 
 // WorkspaceThreadResponse represents the response from creating a new thread
@@ -24,14 +197,44 @@ type WorkspaceThreadResponse struct {
 	UpdatedAt   string `json:"updatedAt"`
 }
 
+// WorkspaceResponse represents a workspace returned when listing workspaces
+type WorkspaceResponse struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// WorkspaceDocumentResponse represents a document embedded in a workspace,
+// the metadata is a JSON encoded string holding the document title
+type WorkspaceDocumentResponse struct {
+	ID       json.Number `json:"id"`
+	DocID    string      `json:"docId"`
+	Filename string      `json:"filename"`
+	Metadata string      `json:"metadata"`
+}
+
 type ChatResponse struct {
 	ID           string `json:"id"`
 	TextResponse string `json:"textResponse"`
-	// Sources      []string `json:"sources"`
+	Error        string `json:"error"`
+	// Sources are the document chunks the answer was retrieved from
+	Sources []ContextChunk `json:"sources"`
+}
+
+// ContextChunk is a document chunk retrieved to answer a question, as sent to the model
+type ContextChunk struct {
+	Title string  `json:"title"`
+	Text  string  `json:"text"`
+	Score float64 `json:"score"`
 }
 
-// ConvertMapToWorkspaceThread converts map[string]interface{} to WorkspaceThreadResponse
+// ConvertMapToWorkspaceThread converts map[string]interface{} to WorkspaceThreadResponse,
+// the thread slug is required
 func ConvertMapToWorkspaceThread(data interface{}) (*WorkspaceThreadResponse, error) {
+	if data == nil {
+		return nil, fmt.Errorf("%w: no thread in the response", ErrMalformedResponse)
+	}
+
 	// You can use a JSON marshaling approach for type safety
 	jsonData, err := json.Marshal(data)
 	if err != nil {
@@ -43,10 +246,20 @@ func ConvertMapToWorkspaceThread(data interface{}) (*WorkspaceThreadResponse, er
 		return nil, fmt.Errorf("failed to unmarshal JSON to struct: %w", err)
 	}
 
+	if thread.Slug == "" {
+		return nil, fmt.Errorf("%w: thread is missing the slug", ErrMalformedResponse)
+	}
+
 	return &thread, nil
 }
 
+// ConvertMapToChatResponse converts the chat map to ChatResponse,
+// it fails when the chat reports an error or has no text response
 func ConvertMapToChatResponse(data interface{}) (*ChatResponse, error) {
+	if data == nil {
+		return nil, fmt.Errorf("%w: no chat in the response", ErrMalformedResponse)
+	}
+
 	jsonData, err := json.Marshal(data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal map to JSON: %w", err)
@@ -57,5 +270,75 @@ func ConvertMapToChatResponse(data interface{}) (*ChatResponse, error) {
 		return nil, fmt.Errorf("failed to unmarshal JSON to struct: %w", err)
 	}
 
+	if chat.Error != "" {
+		return nil, fmt.Errorf("chat failed: %s", chat.Error)
+	}
+	if chat.TextResponse == "" {
+		return nil, fmt.Errorf("%w: chat is missing the text response", ErrMalformedResponse)
+	}
+
 	return &chat, nil
 }
+
+// ConvertMapToWorkspaces converts the workspaces list to a slice of WorkspaceResponse
+func ConvertMapToWorkspaces(data interface{}) ([]WorkspaceResponse, error) {
+	if data == nil {
+		return nil, fmt.Errorf("%w: no workspaces in the response", ErrMalformedResponse)
+	}
+
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal map to JSON: %w", err)
+	}
+
+	var workspaces []WorkspaceResponse
+	if err := json.Unmarshal(jsonData, &workspaces); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON to struct: %w", err)
+	}
+
+	return workspaces, nil
+}
+
+// ConvertMapToDocuments converts the workspace returned by the workspace endpoint to its documents,
+// AnythingLLM returns the workspace either as an object or as a single element list
+func ConvertMapToDocuments(data interface{}) ([]DocumentInfo, error) {
+	if data == nil {
+		return nil, fmt.Errorf("%w: no workspace in the response", ErrMalformedResponse)
+	}
+
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal map to JSON: %w", err)
+	}
+
+	type workspace struct {
+		Documents []WorkspaceDocumentResponse `json:"documents"`
+	}
+	var workspaces []workspace
+	if err := json.Unmarshal(jsonData, &workspaces); err != nil {
+		var single workspace
+		if err := json.Unmarshal(jsonData, &single); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal JSON to struct: %w", err)
+		}
+		workspaces = []workspace{single}
+	}
+
+	documents := []DocumentInfo{}
+	for _, ws := range workspaces {
+		for _, doc := range ws.Documents {
+			info := DocumentInfo{ID: doc.DocID, Title: doc.Filename}
+			if info.ID == "" {
+				info.ID = doc.ID.String()
+			}
+			var metadata struct {
+				Title string `json:"title"`
+			}
+			// The metadata is best effort, the filename is kept as title when it can't be parsed
+			if doc.Metadata != "" && json.Unmarshal([]byte(doc.Metadata), &metadata) == nil && metadata.Title != "" {
+				info.Title = metadata.Title
+			}
+			documents = append(documents, info)
+		}
+	}
+	return documents, nil
+}
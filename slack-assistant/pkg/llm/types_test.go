@@ -0,0 +1,159 @@
+package llm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestConvertMapToWorkspaceThread(t *testing.T) {
+	thread, err := ConvertMapToWorkspaceThread(map[string]interface{}{"id": 1, "slug": "thread-slug", "name": "Thread"})
+	if err != nil {
+		t.Fatalf("ConvertMapToWorkspaceThread failed: %v", err)
+	}
+
+	if thread.ID != 1 || thread.Slug != "thread-slug" || thread.Name != "Thread" {
+		t.Errorf("Unexpected thread: %+v", thread)
+	}
+}
+
+func TestConvertMapToWorkspaceThread_Malformed(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      interface{}
+		malformed bool
+	}{
+		{name: "nil", data: nil, malformed: true},
+		{name: "empty map", data: map[string]interface{}{}, malformed: true},
+		{name: "missing slug", data: map[string]interface{}{"id": 1, "name": "Thread"}, malformed: true},
+		{name: "string id", data: map[string]interface{}{"id": "one", "slug": "thread-slug"}},
+		{name: "not an object", data: "thread-slug"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thread, err := ConvertMapToWorkspaceThread(tt.data)
+			if err == nil {
+				t.Fatalf("Expected an error, got %+v", thread)
+			}
+			if errors.Is(err, ErrMalformedResponse) != tt.malformed {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestConvertMapToChatResponse(t *testing.T) {
+	chat, err := ConvertMapToChatResponse(map[string]interface{}{"id": "chat-id", "textResponse": "Test response"})
+	if err != nil {
+		t.Fatalf("ConvertMapToChatResponse failed: %v", err)
+	}
+
+	if chat.ID != "chat-id" || chat.TextResponse != "Test response" {
+		t.Errorf("Unexpected chat: %+v", chat)
+	}
+}
+
+func TestConvertMapToChatResponse_Sources(t *testing.T) {
+	chat, err := ConvertMapToChatResponse(map[string]interface{}{
+		"id":           "chat-id",
+		"textResponse": "Test response",
+		"sources": []interface{}{
+			map[string]interface{}{"id": "source-id", "title": "sriov-faq.md", "text": "Enable SR-IOV in the BIOS first", "score": 0.82, "_distance": 0.18},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ConvertMapToChatResponse failed: %v", err)
+	}
+
+	expected := []ContextChunk{{Title: "sriov-faq.md", Text: "Enable SR-IOV in the BIOS first", Score: 0.82}}
+	if !reflect.DeepEqual(chat.Sources, expected) {
+		t.Errorf("Expected sources %+v, got %+v", expected, chat.Sources)
+	}
+}
+
+func TestConvertMapToChatResponse_Malformed(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      interface{}
+		malformed bool
+	}{
+		{name: "nil", data: nil, malformed: true},
+		{name: "empty map", data: map[string]interface{}{}, malformed: true},
+		{name: "null text response", data: map[string]interface{}{"id": "chat-id", "textResponse": nil}, malformed: true},
+		{name: "numeric text response", data: map[string]interface{}{"id": "chat-id", "textResponse": 42}},
+		{name: "chat error", data: map[string]interface{}{"id": "chat-id", "textResponse": nil, "error": "workspace not found"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chat, err := ConvertMapToChatResponse(tt.data)
+			if err == nil {
+				t.Fatalf("Expected an error, got %+v", chat)
+			}
+			if errors.Is(err, ErrMalformedResponse) != tt.malformed {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestConvertMapToWorkspaces_Malformed(t *testing.T) {
+	if _, err := ConvertMapToWorkspaces(nil); !errors.Is(err, ErrMalformedResponse) {
+		t.Errorf("Expected a malformed response error, got %v", err)
+	}
+
+	if _, err := ConvertMapToWorkspaces(map[string]interface{}{"slug": "sriov-4-dot-16"}); err == nil {
+		t.Error("Expected an error for a workspace object instead of a list")
+	}
+}
+
+func TestConvertMapToDocuments(t *testing.T) {
+	workspace := []interface{}{map[string]interface{}{
+		"slug": "metallb-4-dot-18",
+		"documents": []interface{}{
+			map[string]interface{}{"id": 1, "docId": "doc-1", "filename": "raw-bgp.json", "metadata": `{"title":"MetalLB BGP peers"}`},
+			map[string]interface{}{"id": 2, "docId": "doc-2", "filename": "raw-l2.json", "metadata": "not json"},
+			map[string]interface{}{"id": 3, "filename": "raw-frr.json"},
+		},
+	}}
+
+	documents, err := ConvertMapToDocuments(workspace)
+	if err != nil {
+		t.Fatalf("ConvertMapToDocuments failed: %v", err)
+	}
+
+	expected := []DocumentInfo{
+		{ID: "doc-1", Title: "MetalLB BGP peers"},
+		{ID: "doc-2", Title: "raw-l2.json"},
+		{ID: "3", Title: "raw-frr.json"},
+	}
+	if len(documents) != len(expected) {
+		t.Fatalf("Expected %d documents, got %+v", len(expected), documents)
+	}
+	for index := range expected {
+		if documents[index] != expected[index] {
+			t.Errorf("Expected document %d to be %+v, got %+v", index, expected[index], documents[index])
+		}
+	}
+}
+
+func TestConvertMapToDocuments_WorkspaceObject(t *testing.T) {
+	documents, err := ConvertMapToDocuments(map[string]interface{}{
+		"slug":      "metallb-4-dot-18",
+		"documents": []interface{}{map[string]interface{}{"docId": "doc-1", "filename": "raw-bgp.json"}},
+	})
+	if err != nil {
+		t.Fatalf("ConvertMapToDocuments failed: %v", err)
+	}
+
+	if len(documents) != 1 || documents[0].ID != "doc-1" {
+		t.Errorf("Unexpected documents: %+v", documents)
+	}
+}
+
+func TestConvertMapToDocuments_Malformed(t *testing.T) {
+	if _, err := ConvertMapToDocuments(nil); !errors.Is(err, ErrMalformedResponse) {
+		t.Errorf("Expected a malformed response error, got %v", err)
+	}
+}
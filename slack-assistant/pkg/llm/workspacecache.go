@@ -0,0 +1,63 @@
+package llm
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultWorkspaceCacheTTL is how long a workspace is trusted to exist before it is looked up again
+const DefaultWorkspaceCacheTTL = 5 * time.Minute
+
+// workspaceCache remembers the workspaces known to exist until their entry expires
+type workspaceCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	expires map[string]time.Time
+	now     func() time.Time
+}
+
+func newWorkspaceCache(ttl time.Duration) *workspaceCache {
+	return &workspaceCache{
+		ttl:     ttl,
+		expires: make(map[string]time.Time),
+		now:     time.Now,
+	}
+}
+
+// has reports whether the workspace was seen within the TTL
+func (c *workspaceCache) has(slug string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires, ok := c.expires[slug]
+	if !ok {
+		return false
+	}
+	if !c.now().Before(expires) {
+		delete(c.expires, slug)
+		return false
+	}
+	return true
+}
+
+// add remembers the workspace, a zero TTL disables the cache
+func (c *workspaceCache) add(slug string) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expires[slug] = c.now().Add(c.ttl)
+}
+
+// reset replaces the cached workspaces with the given ones
+func (c *workspaceCache) reset(slugs []string) {
+	c.mu.Lock()
+	c.expires = make(map[string]time.Time, len(slugs))
+	c.mu.Unlock()
+
+	for _, slug := range slugs {
+		c.add(slug)
+	}
+}
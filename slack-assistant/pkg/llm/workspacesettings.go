@@ -0,0 +1,117 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// workspaceSettings are the chat settings last set on a workspace, its lock only guards the values and isn't held
+// while the workspace is updated
+type workspaceSettings struct {
+	mu             sync.Mutex
+	temperature    float64
+	temperatureSet bool
+	topN           int
+}
+
+// workspaceSettings returns the chat settings last set on the workspace, the lock of the client is only held to
+// find them so the chats of other workspaces don't wait
+func (c *LLMClient) workspaceSettings(slug string) *workspaceSettings {
+	c.settingsMu.Lock()
+	defer c.settingsMu.Unlock()
+
+	current, ok := c.settings[slug]
+	if !ok {
+		current = &workspaceSettings{}
+		c.settings[slug] = current
+	}
+	return current
+}
+
+// applyWorkspaceSettings sets the temperature and the number of sources of the context on the workspace before a
+// chat, the settings the context doesn't have are left as they are. AnythingLLM only has workspace-wide settings
+// (openAiTemp and topN) so they are shared by the concurrent chats of the workspace, the last update wins
+func (c *LLMClient) applyWorkspaceSettings(ctx context.Context, slug string) error {
+	temperature, hasTemperature := TemperatureFrom(ctx)
+	topN := TopKFrom(ctx)
+
+	current := c.workspaceSettings(slug)
+	settings := make(map[string]interface{})
+	current.mu.Lock()
+	if hasTemperature && (!current.temperatureSet || current.temperature != temperature) {
+		settings["openAiTemp"] = temperature
+	}
+	if topN > 0 && current.topN != topN {
+		settings["topN"] = topN
+	}
+	current.mu.Unlock()
+	if len(settings) == 0 {
+		return nil
+	}
+
+	_, response, err := withRateLimitRetry(ctx, c.rateLimitBackoff, func() (struct{}, *http.Response, error) {
+		response, err := c.postWorkspaceUpdate(ctx, slug, settings)
+		return struct{}{}, response, err
+	})
+	if response != nil && response.Body != nil {
+		defer func() {
+			//nolint:errcheck // response body close in defer
+			_ = response.Body.Close()
+		}()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update workspace %s: %w", slug, err)
+	}
+	current.mu.Lock()
+	if _, ok := settings["openAiTemp"]; ok {
+		current.temperature, current.temperatureSet = temperature, true
+	}
+	if _, ok := settings["topN"]; ok {
+		current.topN = topN
+	}
+	current.mu.Unlock()
+	fmt.Printf("🎛️ Set %v on workspace %s\n", settings, slug)
+	return nil
+}
+
+// postWorkspaceUpdate posts the settings to the workspace update endpoint, the request of the SDK has no body
+func (c *LLMClient) postWorkspaceUpdate(ctx context.Context, slug string, settings map[string]interface{}) (*http.Response, error) {
+	jsonData, err := json.Marshal(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal settings: %w", err)
+	}
+
+	config := c.apiClient.GetConfig()
+	endpoint := fmt.Sprintf("%s://%s/api/v1/workspace/%s/update", config.Scheme, config.Host, url.PathEscape(slug))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, value := range config.DefaultHeader {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	response, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	if response.StatusCode >= http.StatusMultipleChoices {
+		body, readErr := io.ReadAll(response.Body)
+		if readErr != nil {
+			return response, fmt.Errorf("server returned status %d (failed to read body: %w)", response.StatusCode, readErr)
+		}
+		return response, &StatusError{StatusCode: response.StatusCode, Body: string(body)}
+	}
+	return response, nil
+}
@@ -0,0 +1,126 @@
+// Package metrics records the latency of the bot commands and exposes it in the Prometheus text format.
+package metrics
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CommandDurationMetric is the name of the command latency histograms
+const CommandDurationMetric = "slack_assistant_command_duration_seconds"
+
+// DefaultLatencyBuckets are the upper bounds in seconds of the command latency histograms,
+// the answers and injects wait for the LLM so they range from seconds to minutes
+var DefaultLatencyBuckets = []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+// LatencyKey identifies the histogram of a command, the project is empty for commands without one
+type LatencyKey struct {
+	Command string
+	Project string
+}
+
+// Histogram is a snapshot of the observations of a command, Counts[i] is the number of observations
+// up to Buckets[i] seconds, the observations above the last bucket are only in Count
+type Histogram struct {
+	Buckets []float64
+	Counts  []uint64
+	Count   uint64
+	Sum     time.Duration
+}
+
+// LatencyRecorder keeps a latency histogram per command and project
+type LatencyRecorder struct {
+	mu         sync.Mutex
+	buckets    []float64
+	histograms map[LatencyKey]*Histogram
+}
+
+// NewLatencyRecorder creates a recorder with the bucket upper bounds in seconds, DefaultLatencyBuckets when none are given
+func NewLatencyRecorder(buckets ...float64) *LatencyRecorder {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+	return &LatencyRecorder{buckets: buckets, histograms: map[LatencyKey]*Histogram{}}
+}
+
+// Observe records the duration of a command run for the project
+func (r *LatencyRecorder) Observe(command, project string, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := LatencyKey{Command: command, Project: project}
+	histogram, ok := r.histograms[key]
+	if !ok {
+		histogram = &Histogram{Buckets: r.buckets, Counts: make([]uint64, len(r.buckets))}
+		r.histograms[key] = histogram
+	}
+	// The buckets are cumulative like the Prometheus ones
+	for i, bound := range r.buckets {
+		if duration.Seconds() <= bound {
+			histogram.Counts[i]++
+		}
+	}
+	histogram.Count++
+	histogram.Sum += duration
+}
+
+// Snapshot returns a copy of the histograms by command and project
+func (r *LatencyRecorder) Snapshot() map[LatencyKey]Histogram {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot := make(map[LatencyKey]Histogram, len(r.histograms))
+	for key, histogram := range r.histograms {
+		copied := *histogram
+		copied.Counts = slices.Clone(histogram.Counts)
+		snapshot[key] = copied
+	}
+	return snapshot
+}
+
+// WriteTo writes the histograms in the Prometheus text format, sorted by command and project
+func (r *LatencyRecorder) WriteTo(w io.Writer) (int64, error) {
+	snapshot := r.Snapshot()
+	keys := make([]LatencyKey, 0, len(snapshot))
+	for key := range snapshot {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b LatencyKey) int {
+		return cmp.Or(cmp.Compare(a.Command, b.Command), cmp.Compare(a.Project, b.Project))
+	})
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "# HELP %s Duration of the bot commands by command and project.\n", CommandDurationMetric)
+	fmt.Fprintf(&out, "# TYPE %s histogram\n", CommandDurationMetric)
+	for _, key := range keys {
+		histogram := snapshot[key]
+		labels := fmt.Sprintf("command=%q,project=%q", key.Command, key.Project)
+		for i, bound := range histogram.Buckets {
+			le := strconv.FormatFloat(bound, 'g', -1, 64)
+			fmt.Fprintf(&out, "%s_bucket{%s,le=%q} %d\n", CommandDurationMetric, labels, le, histogram.Counts[i])
+		}
+		fmt.Fprintf(&out, "%s_bucket{%s,le=\"+Inf\"} %d\n", CommandDurationMetric, labels, histogram.Count)
+		fmt.Fprintf(&out, "%s_sum{%s} %g\n", CommandDurationMetric, labels, histogram.Sum.Seconds())
+		fmt.Fprintf(&out, "%s_count{%s} %d\n", CommandDurationMetric, labels, histogram.Count)
+	}
+
+	written, err := io.WriteString(w, out.String())
+	return int64(written), err
+}
+
+// ServeHTTP serves the histograms in the Prometheus text format, e.g. on /metrics
+func (r *LatencyRecorder) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if _, err := r.WriteTo(w); err != nil {
+		fmt.Printf("⚠️ Failed to write the metrics: %v\n", err)
+	}
+}
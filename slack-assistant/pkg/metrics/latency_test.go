@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLatencyRecorder_Observe(t *testing.T) {
+	recorder := NewLatencyRecorder(1, 10)
+	recorder.Observe("answer", "sriov", 500*time.Millisecond)
+	recorder.Observe("answer", "sriov", 5*time.Second)
+	recorder.Observe("answer", "sriov", time.Minute)
+	recorder.Observe("inject", "metallb", 2*time.Second)
+
+	snapshot := recorder.Snapshot()
+	if len(snapshot) != 2 {
+		t.Fatalf("Expected a histogram per command and project, got %v", snapshot)
+	}
+
+	answer := snapshot[LatencyKey{Command: "answer", Project: "sriov"}]
+	if answer.Count != 3 || answer.Sum != 65500*time.Millisecond {
+		t.Errorf("Expected 3 answers taking 65.5s, got %d taking %s", answer.Count, answer.Sum)
+	}
+	if answer.Counts[0] != 1 || answer.Counts[1] != 2 {
+		t.Errorf("Expected 1 answer up to 1s and 2 up to 10s, got %v", answer.Counts)
+	}
+
+	inject := snapshot[LatencyKey{Command: "inject", Project: "metallb"}]
+	if inject.Count != 1 || inject.Counts[0] != 0 || inject.Counts[1] != 1 {
+		t.Errorf("Expected 1 inject between 1s and 10s, got %+v", inject)
+	}
+}
+
+func TestLatencyRecorder_SnapshotIsACopy(t *testing.T) {
+	recorder := NewLatencyRecorder()
+	recorder.Observe("answer", "sriov", time.Second)
+
+	snapshot := recorder.Snapshot()
+	recorder.Observe("answer", "sriov", time.Second)
+
+	if histogram := snapshot[LatencyKey{Command: "answer", Project: "sriov"}]; histogram.Count != 1 || histogram.Counts[len(histogram.Counts)-1] != 1 {
+		t.Errorf("Expected the snapshot to keep 1 observation, got %+v", histogram)
+	}
+}
+
+func TestLatencyRecorder_ServeHTTP(t *testing.T) {
+	recorder := NewLatencyRecorder(1, 10)
+	recorder.Observe("inject", "metallb", 2*time.Second)
+	recorder.Observe("elaborate", "", 500*time.Millisecond)
+
+	response := httptest.NewRecorder()
+	recorder.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+
+	expected := strings.Join([]string{
+		"# HELP slack_assistant_command_duration_seconds Duration of the bot commands by command and project.",
+		"# TYPE slack_assistant_command_duration_seconds histogram",
+		`slack_assistant_command_duration_seconds_bucket{command="elaborate",project="",le="1"} 1`,
+		`slack_assistant_command_duration_seconds_bucket{command="elaborate",project="",le="10"} 1`,
+		`slack_assistant_command_duration_seconds_bucket{command="elaborate",project="",le="+Inf"} 1`,
+		`slack_assistant_command_duration_seconds_sum{command="elaborate",project=""} 0.5`,
+		`slack_assistant_command_duration_seconds_count{command="elaborate",project=""} 1`,
+		`slack_assistant_command_duration_seconds_bucket{command="inject",project="metallb",le="1"} 0`,
+		`slack_assistant_command_duration_seconds_bucket{command="inject",project="metallb",le="10"} 1`,
+		`slack_assistant_command_duration_seconds_bucket{command="inject",project="metallb",le="+Inf"} 1`,
+		`slack_assistant_command_duration_seconds_sum{command="inject",project="metallb"} 2`,
+		`slack_assistant_command_duration_seconds_count{command="inject",project="metallb"} 1`,
+		"",
+	}, "\n")
+	if response.Body.String() != expected {
+		t.Errorf("Expected the Prometheus histograms:\n%s\ngot:\n%s", expected, response.Body.String())
+	}
+	if contentType := response.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain") {
+		t.Errorf("Expected a text content type, got %q", contentType)
+	}
+}
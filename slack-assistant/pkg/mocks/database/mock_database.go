@@ -12,6 +12,7 @@ package database
 import (
 	reflect "reflect"
 
+	database "github.com/SchSeba/slack-ai-assistant/pkg/database"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -67,18 +68,152 @@ func (mr *MockInterfaceMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockInterface)(nil).Close))
 }
 
+// CompleteWork mocks base method.
+func (m *MockInterface) CompleteWork(id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteWork", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteWork indicates an expected call of CompleteWork.
+func (mr *MockInterfaceMockRecorder) CompleteWork(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteWork", reflect.TypeOf((*MockInterface)(nil).CompleteWork), id)
+}
+
 // CreateSlackThreadWithSlug mocks base method.
-func (m *MockInterface) CreateSlackThreadWithSlug(thread, slug string) error {
+func (m *MockInterface) CreateSlackThreadWithSlug(thread, slug, project, version string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateSlackThreadWithSlug", thread, slug)
+	ret := m.ctrl.Call(m, "CreateSlackThreadWithSlug", thread, slug, project, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateSlackThreadWithSlug indicates an expected call of CreateSlackThreadWithSlug.
-func (mr *MockInterfaceMockRecorder) CreateSlackThreadWithSlug(thread, slug any) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) CreateSlackThreadWithSlug(thread, slug, project, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSlackThreadWithSlug", reflect.TypeOf((*MockInterface)(nil).CreateSlackThreadWithSlug), thread, slug, project, version)
+}
+
+// DeleteThread mocks base method.
+func (m *MockInterface) DeleteThread(slackThread string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteThread", slackThread)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteThread indicates an expected call of DeleteThread.
+func (mr *MockInterfaceMockRecorder) DeleteThread(slackThread any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteThread", reflect.TypeOf((*MockInterface)(nil).DeleteThread), slackThread)
+}
+
+// EnqueueWork mocks base method.
+func (m *MockInterface) EnqueueWork(kind, payload string) (uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueWork", kind, payload)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnqueueWork indicates an expected call of EnqueueWork.
+func (mr *MockInterfaceMockRecorder) EnqueueWork(kind, payload any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueWork", reflect.TypeOf((*MockInterface)(nil).EnqueueWork), kind, payload)
+}
+
+// GetElaborateSlugForThread mocks base method.
+func (m *MockInterface) GetElaborateSlugForThread(slackThread string) (string, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetElaborateSlugForThread", slackThread)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetElaborateSlugForThread indicates an expected call of GetElaborateSlugForThread.
+func (mr *MockInterfaceMockRecorder) GetElaborateSlugForThread(slackThread any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetElaborateSlugForThread", reflect.TypeOf((*MockInterface)(nil).GetElaborateSlugForThread), slackThread)
+}
+
+// GetFeedback mocks base method.
+func (m *MockInterface) GetFeedback(slackThread string) ([]database.Feedback, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeedback", slackThread)
+	ret0, _ := ret[0].([]database.Feedback)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeedback indicates an expected call of GetFeedback.
+func (mr *MockInterfaceMockRecorder) GetFeedback(slackThread any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeedback", reflect.TypeOf((*MockInterface)(nil).GetFeedback), slackThread)
+}
+
+// GetInjectedDocuments mocks base method.
+func (m *MockInterface) GetInjectedDocuments(slackThread string) ([]database.InjectedDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInjectedDocuments", slackThread)
+	ret0, _ := ret[0].([]database.InjectedDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInjectedDocuments indicates an expected call of GetInjectedDocuments.
+func (mr *MockInterfaceMockRecorder) GetInjectedDocuments(slackThread any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInjectedDocuments", reflect.TypeOf((*MockInterface)(nil).GetInjectedDocuments), slackThread)
+}
+
+// GetQueuedWork mocks base method.
+func (m *MockInterface) GetQueuedWork() ([]database.QueuedWork, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQueuedWork")
+	ret0, _ := ret[0].([]database.QueuedWork)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQueuedWork indicates an expected call of GetQueuedWork.
+func (mr *MockInterfaceMockRecorder) GetQueuedWork() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQueuedWork", reflect.TypeOf((*MockInterface)(nil).GetQueuedWork))
+}
+
+// GetRecentCommands mocks base method.
+func (m *MockInterface) GetRecentCommands(limit int) ([]database.CommandAudit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecentCommands", limit)
+	ret0, _ := ret[0].([]database.CommandAudit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecentCommands indicates an expected call of GetRecentCommands.
+func (mr *MockInterfaceMockRecorder) GetRecentCommands(limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecentCommands", reflect.TypeOf((*MockInterface)(nil).GetRecentCommands), limit)
+}
+
+// GetRecentCommandsForUser mocks base method.
+func (m *MockInterface) GetRecentCommandsForUser(user string, limit int) ([]database.CommandAudit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecentCommandsForUser", user, limit)
+	ret0, _ := ret[0].([]database.CommandAudit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecentCommandsForUser indicates an expected call of GetRecentCommandsForUser.
+func (mr *MockInterfaceMockRecorder) GetRecentCommandsForUser(user, limit any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSlackThreadWithSlug", reflect.TypeOf((*MockInterface)(nil).CreateSlackThreadWithSlug), thread, slug)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecentCommandsForUser", reflect.TypeOf((*MockInterface)(nil).GetRecentCommandsForUser), user, limit)
 }
 
 // GetSlugForThread mocks base method.
@@ -96,3 +231,245 @@ func (mr *MockInterfaceMockRecorder) GetSlugForThread(slackThread any) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSlugForThread", reflect.TypeOf((*MockInterface)(nil).GetSlugForThread), slackThread)
 }
+
+// GetThreadContext mocks base method.
+func (m *MockInterface) GetThreadContext(slackThread string) (*database.SlackThreadToSlug, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetThreadContext", slackThread)
+	ret0, _ := ret[0].(*database.SlackThreadToSlug)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetThreadContext indicates an expected call of GetThreadContext.
+func (mr *MockInterfaceMockRecorder) GetThreadContext(slackThread any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetThreadContext", reflect.TypeOf((*MockInterface)(nil).GetThreadContext), slackThread)
+}
+
+// ListAskedQuestions mocks base method.
+func (m *MockInterface) ListAskedQuestions(channel string, commands []string, limit, offset int) ([]database.AskedQuestion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAskedQuestions", channel, commands, limit, offset)
+	ret0, _ := ret[0].([]database.AskedQuestion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAskedQuestions indicates an expected call of ListAskedQuestions.
+func (mr *MockInterfaceMockRecorder) ListAskedQuestions(channel, commands, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAskedQuestions", reflect.TypeOf((*MockInterface)(nil).ListAskedQuestions), channel, commands, limit, offset)
+}
+
+// ListThreads mocks base method.
+func (m *MockInterface) ListThreads(limit, offset int) ([]database.SlackThreadToSlug, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListThreads", limit, offset)
+	ret0, _ := ret[0].([]database.SlackThreadToSlug)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListThreads indicates an expected call of ListThreads.
+func (mr *MockInterfaceMockRecorder) ListThreads(limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListThreads", reflect.TypeOf((*MockInterface)(nil).ListThreads), limit, offset)
+}
+
+// MarkWorkInProgress mocks base method.
+func (m *MockInterface) MarkWorkInProgress(id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkWorkInProgress", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkWorkInProgress indicates an expected call of MarkWorkInProgress.
+func (mr *MockInterfaceMockRecorder) MarkWorkInProgress(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkWorkInProgress", reflect.TypeOf((*MockInterface)(nil).MarkWorkInProgress), id)
+}
+
+// RecordCommand mocks base method.
+func (m *MockInterface) RecordCommand(audit *database.CommandAudit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordCommand", audit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordCommand indicates an expected call of RecordCommand.
+func (mr *MockInterfaceMockRecorder) RecordCommand(audit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordCommand", reflect.TypeOf((*MockInterface)(nil).RecordCommand), audit)
+}
+
+// RecordFeedback mocks base method.
+func (m *MockInterface) RecordFeedback(feedback *database.Feedback) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordFeedback", feedback)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordFeedback indicates an expected call of RecordFeedback.
+func (mr *MockInterfaceMockRecorder) RecordFeedback(feedback any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordFeedback", reflect.TypeOf((*MockInterface)(nil).RecordFeedback), feedback)
+}
+
+// RecordInjection mocks base method.
+func (m *MockInterface) RecordInjection(document *database.InjectedDocument) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordInjection", document)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordInjection indicates an expected call of RecordInjection.
+func (mr *MockInterfaceMockRecorder) RecordInjection(document any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordInjection", reflect.TypeOf((*MockInterface)(nil).RecordInjection), document)
+}
+
+// SetElaborateSlugForThread mocks base method.
+func (m *MockInterface) SetElaborateSlugForThread(slackThread, slug string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetElaborateSlugForThread", slackThread, slug)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetElaborateSlugForThread indicates an expected call of SetElaborateSlugForThread.
+func (mr *MockInterfaceMockRecorder) SetElaborateSlugForThread(slackThread, slug any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetElaborateSlugForThread", reflect.TypeOf((*MockInterface)(nil).SetElaborateSlugForThread), slackThread, slug)
+}
+
+// SetLastQuestion mocks base method.
+func (m *MockInterface) SetLastQuestion(slackThread, question string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLastQuestion", slackThread, question)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLastQuestion indicates an expected call of SetLastQuestion.
+func (mr *MockInterfaceMockRecorder) SetLastQuestion(slackThread, question any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLastQuestion", reflect.TypeOf((*MockInterface)(nil).SetLastQuestion), slackThread, question)
+}
+
+// SetThreadMemoryOff mocks base method.
+func (m *MockInterface) SetThreadMemoryOff(slackThread string, off bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetThreadMemoryOff", slackThread, off)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetThreadMemoryOff indicates an expected call of SetThreadMemoryOff.
+func (mr *MockInterfaceMockRecorder) SetThreadMemoryOff(slackThread, off any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetThreadMemoryOff", reflect.TypeOf((*MockInterface)(nil).SetThreadMemoryOff), slackThread, off)
+}
+
+// SetThreadMode mocks base method.
+func (m *MockInterface) SetThreadMode(slackThread, mode string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetThreadMode", slackThread, mode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetThreadMode indicates an expected call of SetThreadMode.
+func (mr *MockInterfaceMockRecorder) SetThreadMode(slackThread, mode any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetThreadMode", reflect.TypeOf((*MockInterface)(nil).SetThreadMode), slackThread, mode)
+}
+
+// SetThreadStrict mocks base method.
+func (m *MockInterface) SetThreadStrict(slackThread string, strict bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetThreadStrict", slackThread, strict)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetThreadStrict indicates an expected call of SetThreadStrict.
+func (mr *MockInterfaceMockRecorder) SetThreadStrict(slackThread, strict any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetThreadStrict", reflect.TypeOf((*MockInterface)(nil).SetThreadStrict), slackThread, strict)
+}
+
+// SetThreadTemperature mocks base method.
+func (m *MockInterface) SetThreadTemperature(slackThread string, temperature float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetThreadTemperature", slackThread, temperature)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetThreadTemperature indicates an expected call of SetThreadTemperature.
+func (mr *MockInterfaceMockRecorder) SetThreadTemperature(slackThread, temperature any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetThreadTemperature", reflect.TypeOf((*MockInterface)(nil).SetThreadTemperature), slackThread, temperature)
+}
+
+// SetThreadTopK mocks base method.
+func (m *MockInterface) SetThreadTopK(slackThread string, topK int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetThreadTopK", slackThread, topK)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetThreadTopK indicates an expected call of SetThreadTopK.
+func (mr *MockInterfaceMockRecorder) SetThreadTopK(slackThread, topK any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetThreadTopK", reflect.TypeOf((*MockInterface)(nil).SetThreadTopK), slackThread, topK)
+}
+
+// SetThreadTruncated mocks base method.
+func (m *MockInterface) SetThreadTruncated(slackThread string, truncated bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetThreadTruncated", slackThread, truncated)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetThreadTruncated indicates an expected call of SetThreadTruncated.
+func (mr *MockInterfaceMockRecorder) SetThreadTruncated(slackThread, truncated any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetThreadTruncated", reflect.TypeOf((*MockInterface)(nil).SetThreadTruncated), slackThread, truncated)
+}
+
+// UpdateThreadContext mocks base method.
+func (m *MockInterface) UpdateThreadContext(slackThread, slug, project, version string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateThreadContext", slackThread, slug, project, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateThreadContext indicates an expected call of UpdateThreadContext.
+func (mr *MockInterfaceMockRecorder) UpdateThreadContext(slackThread, slug, project, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateThreadContext", reflect.TypeOf((*MockInterface)(nil).UpdateThreadContext), slackThread, slug, project, version)
+}
+
+// WithTransaction mocks base method.
+func (m *MockInterface) WithTransaction(fn func(database.Interface) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTransaction", fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithTransaction indicates an expected call of WithTransaction.
+func (mr *MockInterfaceMockRecorder) WithTransaction(fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTransaction", reflect.TypeOf((*MockInterface)(nil).WithTransaction), fn)
+}
@@ -10,8 +10,10 @@
 package llm
 
 import (
+	context "context"
 	reflect "reflect"
 
+	llm "github.com/SchSeba/slack-ai-assistant/pkg/llm"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,60 +42,146 @@ func (m *MockInterface) EXPECT() *MockInterfaceMockRecorder {
 }
 
 // CreateThread mocks base method.
-func (m *MockInterface) CreateThread(project, version string) (string, error) {
+func (m *MockInterface) CreateThread(ctx context.Context, project, version string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateThread", project, version)
+	ret := m.ctrl.Call(m, "CreateThread", ctx, project, version)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateThread indicates an expected call of CreateThread.
-func (mr *MockInterfaceMockRecorder) CreateThread(project, version any) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) CreateThread(ctx, project, version any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateThread", reflect.TypeOf((*MockInterface)(nil).CreateThread), project, version)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateThread", reflect.TypeOf((*MockInterface)(nil).CreateThread), ctx, project, version)
+}
+
+// DeleteThread mocks base method.
+func (m *MockInterface) DeleteThread(ctx context.Context, project, version, threadSlug string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteThread", ctx, project, version, threadSlug)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteThread indicates an expected call of DeleteThread.
+func (mr *MockInterfaceMockRecorder) DeleteThread(ctx, project, version, threadSlug any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteThread", reflect.TypeOf((*MockInterface)(nil).DeleteThread), ctx, project, version, threadSlug)
 }
 
 // Elaborate mocks base method.
-func (m *MockInterface) Elaborate(threadSlug, message string) (string, error) {
+func (m *MockInterface) Elaborate(ctx context.Context, workspace, threadSlug, message string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Elaborate", threadSlug, message)
+	ret := m.ctrl.Call(m, "Elaborate", ctx, workspace, threadSlug, message)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Elaborate indicates an expected call of Elaborate.
-func (mr *MockInterfaceMockRecorder) Elaborate(threadSlug, message any) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) Elaborate(ctx, workspace, threadSlug, message any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Elaborate", reflect.TypeOf((*MockInterface)(nil).Elaborate), threadSlug, message)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Elaborate", reflect.TypeOf((*MockInterface)(nil).Elaborate), ctx, workspace, threadSlug, message)
 }
 
 // Inject mocks base method.
-func (m *MockInterface) Inject(project, version, message string) error {
+func (m *MockInterface) Inject(ctx context.Context, project, version, message string, metadata llm.DocumentMetadata) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Inject", project, version, message)
+	ret := m.ctrl.Call(m, "Inject", ctx, project, version, message, metadata)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Inject indicates an expected call of Inject.
-func (mr *MockInterfaceMockRecorder) Inject(project, version, message any) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) Inject(ctx, project, version, message, metadata any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Inject", reflect.TypeOf((*MockInterface)(nil).Inject), ctx, project, version, message, metadata)
+}
+
+// InjectBatch mocks base method.
+func (m *MockInterface) InjectBatch(ctx context.Context, project, version string, docs []llm.Document) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InjectBatch", ctx, project, version, docs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InjectBatch indicates an expected call of InjectBatch.
+func (mr *MockInterfaceMockRecorder) InjectBatch(ctx, project, version, docs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InjectBatch", reflect.TypeOf((*MockInterface)(nil).InjectBatch), ctx, project, version, docs)
+}
+
+// LastContext mocks base method.
+func (m *MockInterface) LastContext(threadSlug string) []llm.ContextChunk {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastContext", threadSlug)
+	ret0, _ := ret[0].([]llm.ContextChunk)
+	return ret0
+}
+
+// LastContext indicates an expected call of LastContext.
+func (mr *MockInterfaceMockRecorder) LastContext(threadSlug any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastContext", reflect.TypeOf((*MockInterface)(nil).LastContext), threadSlug)
+}
+
+// ListDocuments mocks base method.
+func (m *MockInterface) ListDocuments(ctx context.Context, project, version string) ([]llm.DocumentInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDocuments", ctx, project, version)
+	ret0, _ := ret[0].([]llm.DocumentInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDocuments indicates an expected call of ListDocuments.
+func (mr *MockInterfaceMockRecorder) ListDocuments(ctx, project, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDocuments", reflect.TypeOf((*MockInterface)(nil).ListDocuments), ctx, project, version)
+}
+
+// ListProjects mocks base method.
+func (m *MockInterface) ListProjects(ctx context.Context) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProjects", ctx)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListProjects indicates an expected call of ListProjects.
+func (mr *MockInterfaceMockRecorder) ListProjects(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProjects", reflect.TypeOf((*MockInterface)(nil).ListProjects), ctx)
+}
+
+// Ping mocks base method.
+func (m *MockInterface) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockInterfaceMockRecorder) Ping(ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Inject", reflect.TypeOf((*MockInterface)(nil).Inject), project, version, message)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockInterface)(nil).Ping), ctx)
 }
 
 // SendMessageToChat mocks base method.
-func (m *MockInterface) SendMessageToChat(project, version, threadSlug, message string) (string, error) {
+func (m *MockInterface) SendMessageToChat(ctx context.Context, project, version, threadSlug, message string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendMessageToChat", project, version, threadSlug, message)
+	ret := m.ctrl.Call(m, "SendMessageToChat", ctx, project, version, threadSlug, message)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SendMessageToChat indicates an expected call of SendMessageToChat.
-func (mr *MockInterfaceMockRecorder) SendMessageToChat(project, version, threadSlug, message any) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) SendMessageToChat(ctx, project, version, threadSlug, message any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessageToChat", reflect.TypeOf((*MockInterface)(nil).SendMessageToChat), project, version, threadSlug, message)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessageToChat", reflect.TypeOf((*MockInterface)(nil).SendMessageToChat), ctx, project, version, threadSlug, message)
 }
@@ -13,6 +13,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	slackbot "github.com/SchSeba/slack-ai-assistant/pkg/slack-bot"
 	slack "github.com/slack-go/slack"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -41,6 +42,20 @@ func (m *MockInterface) EXPECT() *MockInterfaceMockRecorder {
 	return m.recorder
 }
 
+// DeleteMessage mocks base method.
+func (m *MockInterface) DeleteMessage(channel, timestamp string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMessage", channel, timestamp)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMessage indicates an expected call of DeleteMessage.
+func (mr *MockInterfaceMockRecorder) DeleteMessage(channel, timestamp any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMessage", reflect.TypeOf((*MockInterface)(nil).DeleteMessage), channel, timestamp)
+}
+
 // GetBotUser mocks base method.
 func (m *MockInterface) GetBotUser() *slack.AuthTestResponse {
 	m.ctrl.T.Helper()
@@ -70,18 +85,177 @@ func (mr *MockInterfaceMockRecorder) GetConversationReplies(params any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConversationReplies", reflect.TypeOf((*MockInterface)(nil).GetConversationReplies), params)
 }
 
+// GetImages mocks base method.
+func (m *MockInterface) GetImages(channel string, message slack.Message) ([]slackbot.Image, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImages", channel, message)
+	ret0, _ := ret[0].([]slackbot.Image)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImages indicates an expected call of GetImages.
+func (mr *MockInterfaceMockRecorder) GetImages(channel, message any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImages", reflect.TypeOf((*MockInterface)(nil).GetImages), channel, message)
+}
+
+// GetPermalink mocks base method.
+func (m *MockInterface) GetPermalink(channel, timestamp string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPermalink", channel, timestamp)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPermalink indicates an expected call of GetPermalink.
+func (mr *MockInterfaceMockRecorder) GetPermalink(channel, timestamp any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPermalink", reflect.TypeOf((*MockInterface)(nil).GetPermalink), channel, timestamp)
+}
+
+// GetUserName mocks base method.
+func (m *MockInterface) GetUserName(userID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserName", userID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserName indicates an expected call of GetUserName.
+func (mr *MockInterfaceMockRecorder) GetUserName(userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserName", reflect.TypeOf((*MockInterface)(nil).GetUserName), userID)
+}
+
+// OpenView mocks base method.
+func (m *MockInterface) OpenView(userID, triggerID string, view slack.ModalViewRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenView", userID, triggerID, view)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// OpenView indicates an expected call of OpenView.
+func (mr *MockInterfaceMockRecorder) OpenView(userID, triggerID, view any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenView", reflect.TypeOf((*MockInterface)(nil).OpenView), userID, triggerID, view)
+}
+
+// PinMessage mocks base method.
+func (m *MockInterface) PinMessage(channel, timestamp string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PinMessage", channel, timestamp)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PinMessage indicates an expected call of PinMessage.
+func (mr *MockInterfaceMockRecorder) PinMessage(channel, timestamp any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PinMessage", reflect.TypeOf((*MockInterface)(nil).PinMessage), channel, timestamp)
+}
+
+// PostBlocks mocks base method.
+func (m *MockInterface) PostBlocks(channel, threadTS string, blocks []slack.Block, options ...slackbot.MessageOption) error {
+	m.ctrl.T.Helper()
+	varargs := []any{channel, threadTS, blocks}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PostBlocks", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PostBlocks indicates an expected call of PostBlocks.
+func (mr *MockInterfaceMockRecorder) PostBlocks(channel, threadTS, blocks any, options ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{channel, threadTS, blocks}, options...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostBlocks", reflect.TypeOf((*MockInterface)(nil).PostBlocks), varargs...)
+}
+
+// PostEphemeral mocks base method.
+func (m *MockInterface) PostEphemeral(channel, userID, message string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostEphemeral", channel, userID, message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PostEphemeral indicates an expected call of PostEphemeral.
+func (mr *MockInterfaceMockRecorder) PostEphemeral(channel, userID, message any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostEphemeral", reflect.TypeOf((*MockInterface)(nil).PostEphemeral), channel, userID, message)
+}
+
 // PostMessage mocks base method.
-func (m *MockInterface) PostMessage(channel, threadTS, message string) error {
+func (m *MockInterface) PostMessage(channel, threadTS, message string, options ...slackbot.MessageOption) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PostMessage", channel, threadTS, message)
+	varargs := []any{channel, threadTS, message}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PostMessage", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // PostMessage indicates an expected call of PostMessage.
-func (mr *MockInterfaceMockRecorder) PostMessage(channel, threadTS, message any) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) PostMessage(channel, threadTS, message any, options ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{channel, threadTS, message}, options...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostMessage", reflect.TypeOf((*MockInterface)(nil).PostMessage), varargs...)
+}
+
+// PostMessageTS mocks base method.
+func (m *MockInterface) PostMessageTS(channel, threadTS, message string, options ...slackbot.MessageOption) (string, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{channel, threadTS, message}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PostMessageTS", varargs...)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostMessageTS indicates an expected call of PostMessageTS.
+func (mr *MockInterfaceMockRecorder) PostMessageTS(channel, threadTS, message any, options ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{channel, threadTS, message}, options...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostMessageTS", reflect.TypeOf((*MockInterface)(nil).PostMessageTS), varargs...)
+}
+
+// PublishHomeView mocks base method.
+func (m *MockInterface) PublishHomeView(userID string, view slack.HomeTabViewRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishHomeView", userID, view)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishHomeView indicates an expected call of PublishHomeView.
+func (mr *MockInterfaceMockRecorder) PublishHomeView(userID, view any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishHomeView", reflect.TypeOf((*MockInterface)(nil).PublishHomeView), userID, view)
+}
+
+// RespondViaResponseURL mocks base method.
+func (m *MockInterface) RespondViaResponseURL(url, text string, ephemeral bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RespondViaResponseURL", url, text, ephemeral)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RespondViaResponseURL indicates an expected call of RespondViaResponseURL.
+func (mr *MockInterfaceMockRecorder) RespondViaResponseURL(url, text, ephemeral any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostMessage", reflect.TypeOf((*MockInterface)(nil).PostMessage), channel, threadTS, message)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RespondViaResponseURL", reflect.TypeOf((*MockInterface)(nil).RespondViaResponseURL), url, text, ephemeral)
 }
 
 // Start mocks base method.
@@ -95,3 +269,70 @@ func (mr *MockInterfaceMockRecorder) Start(ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockInterface)(nil).Start), ctx)
 }
+
+// UnpinMessage mocks base method.
+func (m *MockInterface) UnpinMessage(channel, timestamp string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnpinMessage", channel, timestamp)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnpinMessage indicates an expected call of UnpinMessage.
+func (mr *MockInterfaceMockRecorder) UnpinMessage(channel, timestamp any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnpinMessage", reflect.TypeOf((*MockInterface)(nil).UnpinMessage), channel, timestamp)
+}
+
+// UploadSnippet mocks base method.
+func (m *MockInterface) UploadSnippet(channel, threadTS, filename, content string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadSnippet", channel, threadTS, filename, content)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UploadSnippet indicates an expected call of UploadSnippet.
+func (mr *MockInterfaceMockRecorder) UploadSnippet(channel, threadTS, filename, content any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadSnippet", reflect.TypeOf((*MockInterface)(nil).UploadSnippet), channel, threadTS, filename, content)
+}
+
+// MockauthTester is a mock of authTester interface.
+type MockauthTester struct {
+	ctrl     *gomock.Controller
+	recorder *MockauthTesterMockRecorder
+	isgomock struct{}
+}
+
+// MockauthTesterMockRecorder is the mock recorder for MockauthTester.
+type MockauthTesterMockRecorder struct {
+	mock *MockauthTester
+}
+
+// NewMockauthTester creates a new mock instance.
+func NewMockauthTester(ctrl *gomock.Controller) *MockauthTester {
+	mock := &MockauthTester{ctrl: ctrl}
+	mock.recorder = &MockauthTesterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockauthTester) EXPECT() *MockauthTesterMockRecorder {
+	return m.recorder
+}
+
+// AuthTest mocks base method.
+func (m *MockauthTester) AuthTest() (*slack.AuthTestResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthTest")
+	ret0, _ := ret[0].(*slack.AuthTestResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthTest indicates an expected call of AuthTest.
+func (mr *MockauthTesterMockRecorder) AuthTest() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthTest", reflect.TypeOf((*MockauthTester)(nil).AuthTest))
+}
@@ -0,0 +1,152 @@
+package slackbot
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/slack-go/slack"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+)
+
+const (
+	// ActionElaborate is the action ID of the answer "Elaborate" button
+	ActionElaborate = "elaborate"
+	// ActionSources is the action ID of the answer "Sources" button
+	ActionSources = "sources"
+	// ActionInjectConfirm is the action ID of the inject preview "Confirm" button, its value is the pending inject ID
+	ActionInjectConfirm = "inject-confirm"
+	// ActionInjectCancel is the action ID of the inject preview "Cancel" button, its value is the pending inject ID
+	ActionInjectCancel = "inject-cancel"
+
+	// sourcesBlockID is the block ID of the context block listing the answer sources
+	sourcesBlockID = "sources"
+	// answerActionsBlockID is the block ID of the action block with the answer buttons
+	answerActionsBlockID = "answer-actions"
+	// footerBlockID is the block ID of the context block with the answer footer
+	footerBlockID = "answer-footer"
+
+	// maxSectionTextLength is the maximum text length Slack accepts in a section block
+	maxSectionTextLength = 3000
+	// maxContextElements is the maximum number of elements Slack accepts in a context block
+	maxContextElements = 10
+)
+
+// BuildAnswerBlocks renders an answer as Block Kit blocks: the answer text split into sections,
+// a context block listing the sources (if any), a context block with the footer (if any) and the action buttons
+// for the answer.
+func BuildAnswerBlocks(intro, answer string, sources []string, footer string) []slack.Block {
+	text := answer
+	if intro != "" {
+		text = intro + "\n" + answer
+	}
+
+	blocks := []slack.Block{}
+	for _, chunk := range splitText(text, maxSectionTextLength) {
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, chunk, false, false), nil, nil))
+	}
+
+	if len(sources) > 0 {
+		blocks = append(blocks, slack.NewDividerBlock())
+		elements := []slack.MixedElement{}
+		for _, source := range sources {
+			if len(elements) == maxContextElements {
+				break
+			}
+			elements = append(elements, slack.NewTextBlockObject(slack.MarkdownType, source, false, false))
+		}
+		blocks = append(blocks, slack.NewContextBlock(sourcesBlockID, elements...))
+	}
+
+	if footer != "" {
+		blocks = append(blocks, slack.NewContextBlock(footerBlockID,
+			slack.NewTextBlockObject(slack.MarkdownType, footer, false, false)))
+	}
+
+	blocks = append(blocks, slack.NewActionBlock(answerActionsBlockID,
+		slack.NewButtonBlockElement(ActionElaborate, "", slack.NewTextBlockObject(slack.PlainTextType, i18n.T("button.elaborate"), false, false)),
+		slack.NewButtonBlockElement(ActionSources, "", slack.NewTextBlockObject(slack.PlainTextType, i18n.T("button.sources"), false, false)),
+	))
+
+	return blocks
+}
+
+// BuildInjectConfirmationBlocks renders the preview of an injection with the confirm and cancel buttons,
+// the preview is truncated to fit in a single section
+func BuildInjectConfirmationBlocks(intro, preview, pendingID string) []slack.Block {
+	if utf8.RuneCountInString(preview) > maxSectionTextLength {
+		preview = string([]rune(preview)[:maxSectionTextLength-3]) + "..."
+	}
+
+	confirm := slack.NewButtonBlockElement(ActionInjectConfirm, pendingID, slack.NewTextBlockObject(slack.PlainTextType, i18n.T("button.confirm"), false, false))
+	confirm.Style = slack.StylePrimary
+	cancel := slack.NewButtonBlockElement(ActionInjectCancel, pendingID, slack.NewTextBlockObject(slack.PlainTextType, i18n.T("button.cancel"), false, false))
+	cancel.Style = slack.StyleDanger
+
+	return []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, intro, false, false), nil, nil),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.PlainTextType, preview, false, false), nil, nil),
+		slack.NewActionBlock("inject-actions", confirm, cancel),
+	}
+}
+
+// AnswerText returns the answer text rendered in the section blocks of an answer message
+func AnswerText(blocks slack.Blocks) string {
+	sections := []string{}
+	for _, block := range blocks.BlockSet {
+		section, ok := block.(*slack.SectionBlock)
+		if !ok || section.Text == nil {
+			continue
+		}
+		sections = append(sections, section.Text.Text)
+	}
+	return strings.Join(sections, "\n")
+}
+
+// IsAnswer reports whether the blocks are the blocks of an answer message, i.e. they have the answer buttons
+func IsAnswer(blocks slack.Blocks) bool {
+	for _, block := range blocks.BlockSet {
+		if action, ok := block.(*slack.ActionBlock); ok && action.BlockID == answerActionsBlockID {
+			return true
+		}
+	}
+	return false
+}
+
+// AnswerSources returns the sources listed in the context block of an answer message
+func AnswerSources(blocks slack.Blocks) []string {
+	sources := []string{}
+	for _, block := range blocks.BlockSet {
+		context, ok := block.(*slack.ContextBlock)
+		if !ok || context.BlockID != sourcesBlockID {
+			continue
+		}
+		for _, element := range context.ContextElements.Elements {
+			if text, ok := element.(*slack.TextBlockObject); ok {
+				sources = append(sources, text.Text)
+			}
+		}
+	}
+	return sources
+}
+
+// splitText splits text into chunks of at most maxLength bytes, preferring to break on new lines
+func splitText(text string, maxLength int) []string {
+	chunks := []string{}
+	for len(text) > maxLength {
+		cut := strings.LastIndex(text[:maxLength], "\n")
+		if cut <= 0 {
+			cut = maxLength
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+		}
+		chunks = append(chunks, text[:cut])
+		text = strings.TrimPrefix(text[cut:], "\n")
+	}
+	if text != "" {
+		chunks = append(chunks, text)
+	}
+	return chunks
+}
@@ -0,0 +1,151 @@
+package slackbot
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+)
+
+var _ = Describe("BuildAnswerBlocks", func() {
+	It("should render the answer and the action buttons", func() {
+		blocks := BuildAnswerBlocks("Here is the information I was able to find", "AI response", nil, "")
+		Expect(blocks).To(HaveLen(2))
+
+		section, ok := blocks[0].(*slack.SectionBlock)
+		Expect(ok).To(BeTrue())
+		Expect(section.Text.Type).To(Equal(slack.MarkdownType))
+		Expect(section.Text.Text).To(Equal("Here is the information I was able to find\nAI response"))
+
+		actions, ok := blocks[1].(*slack.ActionBlock)
+		Expect(ok).To(BeTrue())
+		Expect(actions.Elements.ElementSet).To(HaveLen(2))
+		button, ok := actions.Elements.ElementSet[0].(*slack.ButtonBlockElement)
+		Expect(ok).To(BeTrue())
+		Expect(button.ActionID).To(Equal(ActionElaborate))
+		button, ok = actions.Elements.ElementSet[1].(*slack.ButtonBlockElement)
+		Expect(ok).To(BeTrue())
+		Expect(button.ActionID).To(Equal(ActionSources))
+	})
+
+	It("should render the sources in a context block", func() {
+		blocks := BuildAnswerBlocks("", "AI response", []string{"metallb-faq.md", "metallb-api.md"}, "")
+		Expect(blocks).To(HaveLen(4))
+
+		section, ok := blocks[0].(*slack.SectionBlock)
+		Expect(ok).To(BeTrue())
+		Expect(section.Text.Text).To(Equal("AI response"))
+		Expect(blocks[1].BlockType()).To(Equal(slack.MBTDivider))
+
+		sources, ok := blocks[2].(*slack.ContextBlock)
+		Expect(ok).To(BeTrue())
+		Expect(sources.ContextElements.Elements).To(HaveLen(2))
+		Expect(blocks[3].BlockType()).To(Equal(slack.MBTAction))
+	})
+
+	It("should render the footer in a context block after the sources and before the buttons", func() {
+		blocks := BuildAnswerBlocks("", "AI response", []string{"metallb-faq.md"}, "_AI-generated, verify before acting_")
+		Expect(blocks).To(HaveLen(5))
+
+		Expect(blocks[0].BlockType()).To(Equal(slack.MBTSection))
+		Expect(blocks[1].BlockType()).To(Equal(slack.MBTDivider))
+		sources, ok := blocks[2].(*slack.ContextBlock)
+		Expect(ok).To(BeTrue())
+		Expect(sources.BlockID).To(Equal(sourcesBlockID))
+		footer, ok := blocks[3].(*slack.ContextBlock)
+		Expect(ok).To(BeTrue())
+		Expect(footer.BlockID).To(Equal(footerBlockID))
+		Expect(footer.ContextElements.Elements).To(HaveLen(1))
+		text, ok := footer.ContextElements.Elements[0].(*slack.TextBlockObject)
+		Expect(ok).To(BeTrue())
+		Expect(text.Type).To(Equal(slack.MarkdownType))
+		Expect(text.Text).To(Equal("_AI-generated, verify before acting_"))
+		Expect(blocks[4].BlockType()).To(Equal(slack.MBTAction))
+
+		// The footer isn't part of the answer elaborated, exported or corrected
+		Expect(AnswerText(slack.Blocks{BlockSet: blocks})).To(Equal("AI response"))
+		Expect(AnswerSources(slack.Blocks{BlockSet: blocks})).To(Equal([]string{"metallb-faq.md"}))
+	})
+
+	It("should split long answers into multiple sections", func() {
+		line := strings.Repeat("a", 1000)
+		answer := strings.Join([]string{line, line, line, line}, "\n")
+
+		blocks := BuildAnswerBlocks("", answer, nil, "")
+		Expect(blocks).To(HaveLen(3))
+		for _, block := range blocks[:2] {
+			section, ok := block.(*slack.SectionBlock)
+			Expect(ok).To(BeTrue())
+			Expect(len(section.Text.Text)).To(BeNumerically("<=", maxSectionTextLength))
+		}
+	})
+})
+
+var _ = Describe("AnswerText and AnswerSources", func() {
+	It("should extract the answer text and sources from the answer blocks", func() {
+		blocks := slack.Blocks{BlockSet: BuildAnswerBlocks("Intro", "AI response", []string{"metallb-faq.md"}, "")}
+		Expect(AnswerText(blocks)).To(Equal("Intro\nAI response"))
+		Expect(AnswerSources(blocks)).To(Equal([]string{"metallb-faq.md"}))
+	})
+
+	It("should return empty values for messages without answer blocks", func() {
+		Expect(AnswerText(slack.Blocks{})).To(BeEmpty())
+		Expect(AnswerSources(slack.Blocks{})).To(BeEmpty())
+	})
+})
+
+var _ = Describe("IsAnswer", func() {
+	It("should only recognize the blocks with the answer buttons", func() {
+		Expect(IsAnswer(slack.Blocks{BlockSet: BuildAnswerBlocks("Intro", "AI response", nil, "")})).To(BeTrue())
+		Expect(IsAnswer(slack.Blocks{BlockSet: BuildInjectConfirmationBlocks("Inject?", "preview", "1")})).To(BeFalse())
+		Expect(IsAnswer(slack.Blocks{})).To(BeFalse())
+	})
+})
+
+var _ = Describe("splitText", func() {
+	It("should not split short text", func() {
+		Expect(splitText("short", 10)).To(Equal([]string{"short"}))
+	})
+
+	It("should prefer breaking on new lines", func() {
+		Expect(splitText("aaaa\nbbbb\ncc", 10)).To(Equal([]string{"aaaa\nbbbb", "cc"}))
+	})
+
+	It("should hard split text without new lines", func() {
+		Expect(splitText("aaaaaaaaaaaa", 5)).To(Equal([]string{"aaaaa", "aaaaa", "aa"}))
+	})
+
+	It("should not split multi-byte characters", func() {
+		chunks := splitText("ééééé", 5)
+		Expect(chunks).To(Equal([]string{"éé", "éé", "é"}))
+	})
+})
+
+var _ = Describe("BuildInjectConfirmationBlocks", func() {
+	It("should render the preview with the confirm and cancel buttons", func() {
+		blocks := BuildInjectConfirmationBlocks("Please confirm", "injected content", "7")
+		Expect(blocks).To(HaveLen(3))
+		Expect(AnswerText(slack.Blocks{BlockSet: blocks})).To(Equal("Please confirm\ninjected content"))
+
+		actions, ok := blocks[2].(*slack.ActionBlock)
+		Expect(ok).To(BeTrue())
+		Expect(actions.Elements.ElementSet).To(HaveLen(2))
+		confirm, ok := actions.Elements.ElementSet[0].(*slack.ButtonBlockElement)
+		Expect(ok).To(BeTrue())
+		Expect(confirm.ActionID).To(Equal(ActionInjectConfirm))
+		Expect(confirm.Value).To(Equal("7"))
+		cancel, ok := actions.Elements.ElementSet[1].(*slack.ButtonBlockElement)
+		Expect(ok).To(BeTrue())
+		Expect(cancel.ActionID).To(Equal(ActionInjectCancel))
+		Expect(cancel.Value).To(Equal("7"))
+	})
+
+	It("should truncate long previews", func() {
+		blocks := BuildInjectConfirmationBlocks("Please confirm", strings.Repeat("a", maxSectionTextLength+10), "7")
+		preview, ok := blocks[1].(*slack.SectionBlock)
+		Expect(ok).To(BeTrue())
+		Expect(preview.Text.Text).To(HaveLen(maxSectionTextLength))
+		Expect(preview.Text.Text).To(HaveSuffix("..."))
+	})
+})
@@ -0,0 +1,60 @@
+package slackbot
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	// maxImages is the number of images of a message that are downloaded, the others are ignored
+	maxImages = 4
+	// maxImageSize is the size in bytes of the largest image downloaded, Slack reports it with the file
+	maxImageSize = 5 * 1024 * 1024
+)
+
+// Image is an image attached to a Slack message, e.g. a pasted screenshot
+type Image struct {
+	Name     string
+	MimeType string
+	Data     []byte
+}
+
+// ImageFiles returns the image files attached to the message
+func ImageFiles(message slack.Message) []slack.File {
+	var images []slack.File
+	for _, file := range message.Files {
+		if strings.HasPrefix(file.Mimetype, "image/") {
+			images = append(images, file)
+		}
+	}
+	return images
+}
+
+// GetImages downloads the images attached to the message, at most maxImages of at most maxImageSize bytes each
+func (b *SlackBot) GetImages(channel string, message slack.Message) ([]Image, error) {
+	var images []Image
+	for _, file := range ImageFiles(message) {
+		if len(images) == maxImages {
+			fmt.Printf("⚠️ Ignoring the images after the first %d of the message %s\n", maxImages, message.Timestamp)
+			break
+		}
+		if file.Size > maxImageSize {
+			fmt.Printf("⚠️ Ignoring the image %s of %d bytes, larger than %d bytes\n", file.Name, file.Size, maxImageSize)
+			continue
+		}
+
+		downloadURL := file.URLPrivateDownload
+		if downloadURL == "" {
+			downloadURL = file.URLPrivate
+		}
+		var data bytes.Buffer
+		if err := b.clientForChannel(channel).GetFile(downloadURL, &data); err != nil {
+			return nil, fmt.Errorf("failed to download image %s: %w", file.Name, err)
+		}
+		images = append(images, Image{Name: file.Name, MimeType: file.Mimetype, Data: data.Bytes()})
+	}
+	return images, nil
+}
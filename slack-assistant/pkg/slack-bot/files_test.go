@@ -0,0 +1,90 @@
+package slackbot
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+)
+
+var _ = Describe("Images", func() {
+	var (
+		server *httptest.Server
+		bot    *SlackBot
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			Expect(r.Header.Get("Authorization")).To(Equal("Bearer xoxb-test"))
+			//nolint:errcheck // test mock
+			_, _ = w.Write([]byte("png bytes of " + r.URL.Path))
+		}))
+		bot = &SlackBot{api: slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"))}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should only return the image files of the message", func() {
+		message := slack.Message{Msg: slack.Msg{Files: []slack.File{
+			{Name: "error.png", Mimetype: "image/png"},
+			{Name: "must-gather.tar.gz", Mimetype: "application/gzip"},
+			{Name: "node.jpeg", Mimetype: "image/jpeg"},
+		}}}
+
+		files := ImageFiles(message)
+		Expect(files).To(HaveLen(2))
+		Expect(files[0].Name).To(Equal("error.png"))
+		Expect(files[1].Name).To(Equal("node.jpeg"))
+	})
+
+	It("should return no image files for a message without files", func() {
+		Expect(ImageFiles(slack.Message{Msg: slack.Msg{Text: "How do I create a VF?"}})).To(BeEmpty())
+	})
+
+	It("should download the images of the message", func() {
+		message := slack.Message{Msg: slack.Msg{Files: []slack.File{
+			{Name: "error.png", Mimetype: "image/png", URLPrivateDownload: server.URL + "/download/error.png"},
+			{Name: "notes.txt", Mimetype: "text/plain", URLPrivateDownload: server.URL + "/download/notes.txt"},
+			{Name: "node.jpeg", Mimetype: "image/jpeg", URLPrivate: server.URL + "/private/node.jpeg"},
+		}}}
+
+		images, err := bot.GetImages("C123", message)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(images).To(Equal([]Image{
+			{Name: "error.png", MimeType: "image/png", Data: []byte("png bytes of /download/error.png")},
+			{Name: "node.jpeg", MimeType: "image/jpeg", Data: []byte("png bytes of /private/node.jpeg")},
+		}))
+	})
+
+	It("should skip the images that are too large and keep at most maxImages", func() {
+		files := []slack.File{{Name: "huge.png", Mimetype: "image/png", Size: maxImageSize + 1, URLPrivateDownload: server.URL + "/huge.png"}}
+		for range maxImages + 1 {
+			files = append(files, slack.File{Name: "small.png", Mimetype: "image/png", Size: 100, URLPrivateDownload: server.URL + "/small.png"})
+		}
+
+		images, err := bot.GetImages("C123", slack.Message{Msg: slack.Msg{Files: files}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(images).To(HaveLen(maxImages))
+		for _, image := range images {
+			Expect(image.Name).To(Equal("small.png"))
+		}
+	})
+
+	It("should return an error when an image can't be downloaded", func() {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer failing.Close()
+		message := slack.Message{Msg: slack.Msg{Files: []slack.File{
+			{Name: "error.png", Mimetype: "image/png", URLPrivateDownload: failing.URL + "/error.png"},
+		}}}
+
+		_, err := bot.GetImages("C123", message)
+		Expect(err).To(MatchError(ContainSubstring("failed to download image error.png")))
+	})
+})
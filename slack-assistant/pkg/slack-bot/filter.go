@@ -0,0 +1,48 @@
+package slackbot
+
+import (
+	"encoding/json"
+)
+
+// ignoredSubtypes are the subtypes of the messages posted by bots or by Slack itself, they never carry a command
+var ignoredSubtypes = map[string]struct{}{
+	"bot_message":       {},
+	"message_changed":   {},
+	"message_deleted":   {},
+	"message_replied":   {},
+	"channel_join":      {},
+	"channel_leave":     {},
+	"channel_topic":     {},
+	"channel_purpose":   {},
+	"channel_name":      {},
+	"channel_archive":   {},
+	"channel_unarchive": {},
+	"group_join":        {},
+	"group_leave":       {},
+	"pinned_item":       {},
+	"unpinned_item":     {},
+}
+
+// eventsAPIPayload is the part of an Events API payload read by the filter,
+// the app mention event of slack-go drops the subtype
+type eventsAPIPayload struct {
+	Event struct {
+		SubType string `json:"subtype"`
+	} `json:"event"`
+}
+
+// ignoredMention reports why the app mention must not be processed, e.g. another bot mentioning this bot could
+// start a loop of answers, the reason is empty when it is posted by a user
+func ignoredMention(payload json.RawMessage, botID string) string {
+	if botID != "" {
+		return "posted by bot " + botID
+	}
+	var event eventsAPIPayload
+	if len(payload) == 0 || json.Unmarshal(payload, &event) != nil {
+		return ""
+	}
+	if _, ignored := ignoredSubtypes[event.Event.SubType]; ignored {
+		return "with subtype " + event.Event.SubType
+	}
+	return ""
+}
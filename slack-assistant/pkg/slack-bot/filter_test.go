@@ -0,0 +1,70 @@
+package slackbot
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+)
+
+var _ = Describe("Events filter", func() {
+	var bot *SlackBot
+
+	// mentionEvent returns the socket mode event of an app mention with the raw payload Slack sent
+	mentionEvent := func(mention *slackevents.AppMentionEvent, payload string) *socketmode.Event {
+		return &socketmode.Event{
+			Type: socketmode.EventTypeEventsAPI,
+			Data: slackevents.EventsAPIEvent{
+				Type:       slackevents.CallbackEvent,
+				TeamID:     "T1",
+				InnerEvent: slackevents.EventsAPIInnerEvent{Type: "app_mention", Data: mention},
+			},
+			Request: &socketmode.Request{EnvelopeID: "envelope", Payload: json.RawMessage(payload)},
+		}
+	}
+
+	BeforeEach(func() {
+		bot = &SlackBot{
+			socketMode:        socketmode.New(slack.New("xoxb-test")),
+			appMentionChannel: make(chan *slackevents.AppMentionEvent, 1),
+		}
+	})
+
+	It("should forward the mention of a user", func() {
+		mention := &slackevents.AppMentionEvent{User: "U1", Channel: "C1", Text: "<@UBOT> answer sriov 4.16"}
+		bot.handleEvent(context.Background(), mentionEvent(mention, `{"event":{"type":"app_mention","user":"U1"}}`))
+
+		Expect(bot.appMentionChannel).To(Receive(Equal(mention)))
+	})
+
+	It("should drop a bot_message mention", func() {
+		mention := &slackevents.AppMentionEvent{Channel: "C1", Text: "<@UBOT> answer sriov 4.16"}
+		bot.handleEvent(context.Background(), mentionEvent(mention, `{"event":{"type":"app_mention","subtype":"bot_message","username":"other-bot"}}`))
+
+		Expect(bot.appMentionChannel).To(BeEmpty())
+	})
+
+	It("should drop the mention of another bot", func() {
+		mention := &slackevents.AppMentionEvent{User: "U2", BotID: "B2", Channel: "C1", Text: "<@UBOT> answer sriov 4.16"}
+		bot.handleEvent(context.Background(), mentionEvent(mention, `{"event":{"type":"app_mention","user":"U2","bot_id":"B2"}}`))
+
+		Expect(bot.appMentionChannel).To(BeEmpty())
+	})
+
+	DescribeTable("should tell why a mention is ignored",
+		func(payload, botID, reason string) {
+			Expect(ignoredMention(json.RawMessage(payload), botID)).To(Equal(reason))
+		},
+		Entry("posted by a user", `{"event":{"type":"app_mention"}}`, "", ""),
+		Entry("posted by a user in a thread broadcast", `{"event":{"subtype":"thread_broadcast"}}`, "", ""),
+		Entry("posted by a bot", `{"event":{}}`, "B2", "posted by bot B2"),
+		Entry("with the bot_message subtype", `{"event":{"subtype":"bot_message"}}`, "", "with subtype bot_message"),
+		Entry("edited", `{"event":{"subtype":"message_changed"}}`, "", "with subtype message_changed"),
+		Entry("joining the channel", `{"event":{"subtype":"channel_join"}}`, "", "with subtype channel_join"),
+		Entry("without a payload", ``, "", ""),
+	)
+})
@@ -0,0 +1,60 @@
+package slackbot
+
+import (
+	"regexp"
+	"strings"
+)
+
+// markupPattern matches the Slack markup wrapped in angle brackets, e.g. <@U123>, <#C123|general> or <https://example.com|label>
+var markupPattern = regexp.MustCompile(`<([^<>]*)>`)
+
+// entityReplacer decodes the HTML entities Slack escapes in message text
+var entityReplacer = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&")
+
+// UserResolver returns the display name of a Slack user, or an empty string when it is unknown
+type UserResolver func(userID string) string
+
+// SanitizeText turns Slack markup into plain text: mentions become @name or #channel and links are unwrapped.
+// User mentions without a label are resolved with resolveUser when it is set, otherwise the user ID is kept.
+func SanitizeText(text string, resolveUser UserResolver) string {
+	text = markupPattern.ReplaceAllStringFunc(text, func(markup string) string {
+		target, label, hasLabel := strings.Cut(markup[1:len(markup)-1], "|")
+
+		switch {
+		case strings.HasPrefix(target, "@"):
+			if hasLabel {
+				return "@" + strings.TrimPrefix(label, "@")
+			}
+			userID := target[1:]
+			if resolveUser != nil {
+				if name := resolveUser(userID); name != "" {
+					return "@" + name
+				}
+			}
+			return "@" + userID
+		case strings.HasPrefix(target, "#"):
+			if hasLabel {
+				return "#" + label
+			}
+			return target
+		case strings.HasPrefix(target, "!"):
+			// Special mentions (<!here>), user groups (<!subteam^S123|@team>) and dates (<!date^...|fallback>)
+			if hasLabel {
+				return label
+			}
+			command, _, _ := strings.Cut(target[1:], "^")
+			return "@" + command
+		case strings.HasPrefix(target, "mailto:"):
+			if hasLabel {
+				return label
+			}
+			return strings.TrimPrefix(target, "mailto:")
+		default:
+			if hasLabel && label != target {
+				return label + " (" + target + ")"
+			}
+			return target
+		}
+	})
+	return entityReplacer.Replace(text)
+}
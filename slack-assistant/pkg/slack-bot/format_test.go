@@ -0,0 +1,40 @@
+package slackbot
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SanitizeText", func() {
+	DescribeTable("should turn Slack markup into plain text",
+		func(text, expected string) {
+			Expect(SanitizeText(text, nil)).To(Equal(expected))
+		},
+		Entry("plain text", "how do I configure BGP?", "how do I configure BGP?"),
+		Entry("user mention", "<@U123> can you check?", "@U123 can you check?"),
+		Entry("user mention with a label", "<@U123|alice> can you check?", "@alice can you check?"),
+		Entry("channel mention", "see <#C123|metallb>", "see #metallb"),
+		Entry("channel mention without a label", "see <#C123>", "see #C123"),
+		Entry("special mention", "<!here> the cluster is down", "@here the cluster is down"),
+		Entry("user group mention", "<!subteam^S123|@network-team> please help", "@network-team please help"),
+		Entry("date", "since <!date^1392734382^{date}|February 18th, 2014>", "since February 18th, 2014"),
+		Entry("link", "read <https://docs.openshift.com>", "read https://docs.openshift.com"),
+		Entry("link with a label", "read <https://docs.openshift.com|the docs>", "read the docs (https://docs.openshift.com)"),
+		Entry("link labeled with itself", "read <https://docs.openshift.com|https://docs.openshift.com>", "read https://docs.openshift.com"),
+		Entry("email", "write to <mailto:team@example.com|team@example.com>", "write to team@example.com"),
+		Entry("escaped characters", "a &lt; b &amp;&amp; c &gt; d", "a < b && c > d"),
+		Entry("several markups", "<@U123> see <#C123|general> and <https://example.com|this>",
+			"@U123 see #general and this (https://example.com)"),
+	)
+
+	It("should resolve user mentions with the resolver", func() {
+		resolve := func(userID string) string {
+			if userID == "U123" {
+				return "alice"
+			}
+			return ""
+		}
+
+		Expect(SanitizeText("<@U123> and <@U456>", resolve)).To(Equal("@alice and @U456"))
+	})
+})
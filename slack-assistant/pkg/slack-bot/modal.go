@@ -0,0 +1,130 @@
+package slackbot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
+)
+
+const (
+	// ModalAnswer is the callback ID of the modal of the answer slash command
+	ModalAnswer = "answer-modal"
+	// ModalInject is the callback ID of the modal of the inject slash command
+	ModalInject = "inject-modal"
+
+	// modalProjectBlockID is the block ID of the project input of a command modal
+	modalProjectBlockID = "project"
+	// modalVersionBlockID is the block ID of the version input of a command modal
+	modalVersionBlockID = "version"
+	// modalContentBlockID is the block ID of the question or content input of a command modal
+	modalContentBlockID = "content"
+	// modalInputActionID is the action ID of the element of every command modal input
+	modalInputActionID = "input"
+
+	// maxSelectOptions is the maximum number of options Slack accepts in a static select
+	maxSelectOptions = 100
+)
+
+// modalCommands maps the callback ID of a command modal to its command
+var modalCommands = map[string]string{
+	ModalAnswer: "answer",
+	ModalInject: "inject",
+}
+
+// ModalSubmission is the command submitted with a command modal
+type ModalSubmission struct {
+	// Command is answer or inject
+	Command string
+	// Channel is the channel the slash command was used in
+	Channel string
+	Project string
+	Version string
+	// Content is the question of answer or the text of inject
+	Content string
+}
+
+// BuildCommandModal renders the modal of the answer or inject slash command used in the channel, the project is
+// picked from the projects when there are some and typed otherwise
+func BuildCommandModal(command, channel string, projects []string) (slack.ModalViewRequest, error) {
+	callbackID, contentLabel := ModalAnswer, i18n.T("modal.question")
+	switch command {
+	case "answer":
+	case "inject":
+		callbackID, contentLabel = ModalInject, i18n.T("modal.content")
+	default:
+		return slack.ModalViewRequest{}, fmt.Errorf("no modal for the %s command", command)
+	}
+
+	var projectElement slack.BlockElement = slack.NewPlainTextInputBlockElement(nil, modalInputActionID)
+	if len(projects) > 0 {
+		options := make([]*slack.OptionBlockObject, 0, min(len(projects), maxSelectOptions))
+		for _, project := range projects[:min(len(projects), maxSelectOptions)] {
+			options = append(options, slack.NewOptionBlockObject(project, plainText(project), nil))
+		}
+		projectElement = slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, nil, modalInputActionID, options...)
+	}
+	content := slack.NewPlainTextInputBlockElement(nil, modalInputActionID)
+	content.Multiline = true
+
+	return slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		CallbackID:      callbackID,
+		PrivateMetadata: channel,
+		Title:           plainText(i18n.T("modal.title." + command)),
+		Submit:          plainText(i18n.T("modal.submit")),
+		Close:           plainText(i18n.T("button.cancel")),
+		Blocks: slack.Blocks{BlockSet: []slack.Block{
+			slack.NewInputBlock(modalProjectBlockID, plainText(i18n.T("modal.project")), nil, projectElement),
+			slack.NewInputBlock(modalVersionBlockID, plainText(i18n.T("modal.version")), nil, slack.NewPlainTextInputBlockElement(nil, modalInputActionID)),
+			slack.NewInputBlock(modalContentBlockID, plainText(contentLabel), nil, content),
+		}},
+	}, nil
+}
+
+// ParseCommandModal returns the command submitted with a command modal, every input must be filled
+func ParseCommandModal(view slack.View) (ModalSubmission, error) {
+	command, ok := modalCommands[view.CallbackID]
+	if !ok {
+		return ModalSubmission{}, fmt.Errorf("unknown modal %q", view.CallbackID)
+	}
+
+	submission := ModalSubmission{Command: command, Channel: view.PrivateMetadata}
+	inputs := []struct {
+		blockID string
+		value   *string
+	}{
+		{modalProjectBlockID, &submission.Project},
+		{modalVersionBlockID, &submission.Version},
+		{modalContentBlockID, &submission.Content},
+	}
+	for _, input := range inputs {
+		*input.value = modalInput(view.State, input.blockID)
+		if *input.value == "" {
+			return ModalSubmission{}, fmt.Errorf("the %s of the %s modal is missing", input.blockID, command)
+		}
+	}
+	if submission.Channel == "" {
+		return ModalSubmission{}, fmt.Errorf("the channel of the %s modal is missing", command)
+	}
+	return submission, nil
+}
+
+// modalInput returns the typed or selected value of a modal input
+func modalInput(state *slack.ViewState, blockID string) string {
+	if state == nil {
+		return ""
+	}
+	action := state.Values[blockID][modalInputActionID]
+	if action.SelectedOption.Value != "" {
+		return action.SelectedOption.Value
+	}
+	return strings.TrimSpace(action.Value)
+}
+
+// plainText returns a plain text object, the only text type modal titles, labels and options accept
+func plainText(text string) *slack.TextBlockObject {
+	return slack.NewTextBlockObject(slack.PlainTextType, text, false, false)
+}
@@ -0,0 +1,123 @@
+package slackbot
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+)
+
+var _ = Describe("Command modals", func() {
+	// submission returns the view of a view_submission payload as sent by Slack
+	submission := func(callbackID, privateMetadata, project, version, content string) slack.View {
+		payload := `{
+			"type": "view_submission",
+			"user": {"id": "U123456"},
+			"view": {
+				"type": "modal",
+				"callback_id": "` + callbackID + `",
+				"private_metadata": "` + privateMetadata + `",
+				"state": {"values": {
+					"project": {"input": {"type": "static_select", "selected_option": {"value": "` + project + `"}}},
+					"version": {"input": {"type": "plain_text_input", "value": "` + version + `"}},
+					"content": {"input": {"type": "plain_text_input", "value": "` + content + `"}}
+				}}
+			}
+		}`
+		var callback slack.InteractionCallback
+		Expect(json.Unmarshal([]byte(payload), &callback)).To(Succeed())
+		Expect(callback.Type).To(Equal(slack.InteractionTypeViewSubmission))
+		return callback.View
+	}
+
+	It("should build the answer modal with a project dropdown", func() {
+		modal, err := BuildCommandModal("answer", "C1234567890", []string{"metallb", "sriov"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(modal.CallbackID).To(Equal(ModalAnswer))
+		Expect(modal.PrivateMetadata).To(Equal("C1234567890"))
+		Expect(modal.Title.Text).To(Equal("Ask the assistant"))
+		Expect(modal.Blocks.BlockSet).To(HaveLen(3))
+
+		project, ok := modal.Blocks.BlockSet[0].(*slack.InputBlock)
+		Expect(ok).To(BeTrue())
+		dropdown, ok := project.Element.(*slack.SelectBlockElement)
+		Expect(ok).To(BeTrue())
+		Expect(dropdown.Options).To(HaveLen(2))
+		Expect(dropdown.Options[1].Value).To(Equal("sriov"))
+
+		content, ok := modal.Blocks.BlockSet[2].(*slack.InputBlock)
+		Expect(ok).To(BeTrue())
+		Expect(content.Label.Text).To(Equal("Question"))
+		Expect(content.Element.(*slack.PlainTextInputBlockElement).Multiline).To(BeTrue())
+	})
+
+	It("should let the project be typed when no project is known", func() {
+		modal, err := BuildCommandModal("inject", "C1234567890", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(modal.CallbackID).To(Equal(ModalInject))
+		Expect(modal.Blocks.BlockSet[0].(*slack.InputBlock).Element).To(BeAssignableToTypeOf(&slack.PlainTextInputBlockElement{}))
+		Expect(modal.Blocks.BlockSet[2].(*slack.InputBlock).Label.Text).To(Equal("Content"))
+	})
+
+	It("should open the modal with the trigger ID", func() {
+		var request struct {
+			TriggerID string                 `json:"trigger_id"`
+			View      slack.ModalViewRequest `json:"view"`
+		}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			Expect(r.URL.Path).To(Equal("/views.open"))
+			Expect(json.NewDecoder(r.Body).Decode(&request)).To(Succeed())
+			w.Header().Set("Content-Type", "application/json")
+			_, err := w.Write([]byte(`{"ok": true, "view": {"id": "V123"}}`))
+			Expect(err).NotTo(HaveOccurred())
+		}))
+		defer server.Close()
+
+		modal, err := BuildCommandModal("answer", "C1234567890", nil)
+		Expect(err).NotTo(HaveOccurred())
+		bot := &SlackBot{api: slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"))}
+		Expect(bot.OpenView("U123456", "trigger-123", modal)).To(Succeed())
+		Expect(request.TriggerID).To(Equal("trigger-123"))
+		Expect(request.View.CallbackID).To(Equal(ModalAnswer))
+		Expect(request.View.PrivateMetadata).To(Equal("C1234567890"))
+	})
+
+	It("should reject a command without a modal", func() {
+		_, err := BuildCommandModal("docs", "C1234567890", nil)
+		Expect(err).To(MatchError("no modal for the docs command"))
+	})
+
+	It("should parse the submitted answer modal", func() {
+		parsed, err := ParseCommandModal(submission(ModalAnswer, "C1234567890", "sriov", " 4.16 ", "How do I create a VF?"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parsed).To(Equal(ModalSubmission{
+			Command: "answer",
+			Channel: "C1234567890",
+			Project: "sriov",
+			Version: "4.16",
+			Content: "How do I create a VF?",
+		}))
+	})
+
+	It("should parse the submitted inject modal", func() {
+		parsed, err := ParseCommandModal(submission(ModalInject, "C1234567890", "metallb", "4.18", "BGP peers need a password"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parsed.Command).To(Equal("inject"))
+		Expect(parsed.Content).To(Equal("BGP peers need a password"))
+	})
+
+	It("should reject an incomplete or unknown modal", func() {
+		_, err := ParseCommandModal(submission(ModalAnswer, "C1234567890", "sriov", "4.16", "  "))
+		Expect(err).To(MatchError("the content of the answer modal is missing"))
+
+		_, err = ParseCommandModal(submission(ModalAnswer, "", "sriov", "4.16", "How do I create a VF?"))
+		Expect(err).To(MatchError("the channel of the answer modal is missing"))
+
+		_, err = ParseCommandModal(submission("feedback-modal", "C1234567890", "sriov", "4.16", "Great"))
+		Expect(err).To(MatchError(`unknown modal "feedback-modal"`))
+	})
+})
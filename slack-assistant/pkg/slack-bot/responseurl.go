@@ -0,0 +1,25 @@
+package slackbot
+
+import (
+	"fmt"
+
+	"github.com/slack-go/slack"
+)
+
+// RespondViaResponseURL replies to a slash command through its response URL, which works in channels the bot isn't a member of.
+// Ephemeral replies are only visible to the user who ran the command, the others are posted in the channel.
+func (b *SlackBot) RespondViaResponseURL(url, text string, ephemeral bool) error {
+	responseType := slack.ResponseTypeInChannel
+	if ephemeral {
+		responseType = slack.ResponseTypeEphemeral
+	}
+
+	text = b.limitMessage(text)
+	if err := slack.PostWebhook(url, &slack.WebhookMessage{Text: text, ResponseType: responseType}); err != nil {
+		fmt.Printf("❌ Failed to respond via response URL: %v\n", err)
+		return fmt.Errorf("failed to respond via response URL: %w", err)
+	}
+
+	fmt.Printf("🔍 Responded via response URL (%s): %s\n", responseType, text)
+	return nil
+}
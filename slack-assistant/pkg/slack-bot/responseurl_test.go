@@ -0,0 +1,52 @@
+package slackbot
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+)
+
+var _ = Describe("RespondViaResponseURL", func() {
+	var (
+		server   *httptest.Server
+		received slack.WebhookMessage
+		status   int
+	)
+
+	BeforeEach(func() {
+		status = http.StatusOK
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			Expect(r.Method).To(Equal(http.MethodPost))
+			Expect(json.NewDecoder(r.Body).Decode(&received)).To(Succeed())
+			w.WriteHeader(status)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should post an in channel reply", func() {
+		bot := &SlackBot{}
+		Expect(bot.RespondViaResponseURL(server.URL, "status", false)).To(Succeed())
+		Expect(received.Text).To(Equal("status"))
+		Expect(received.ResponseType).To(Equal(slack.ResponseTypeInChannel))
+	})
+
+	It("should post an ephemeral reply", func() {
+		bot := &SlackBot{}
+		Expect(bot.RespondViaResponseURL(server.URL, "only for you", true)).To(Succeed())
+		Expect(received.ResponseType).To(Equal(slack.ResponseTypeEphemeral))
+	})
+
+	It("should return an error when the response URL rejects the reply", func() {
+		status = http.StatusNotFound
+		bot := &SlackBot{}
+		Expect(bot.RespondViaResponseURL(server.URL, "status", false)).To(MatchError(ContainSubstring("failed to respond via response URL")))
+	})
+})
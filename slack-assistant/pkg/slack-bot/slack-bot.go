@@ -3,13 +3,22 @@ package slackbot
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"math/rand/v2"
+	"net"
+	"net/http"
 	"os"
+	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
 	"github.com/slack-go/slack/socketmode"
+
+	"github.com/SchSeba/slack-ai-assistant/pkg/i18n"
 )
 
 // Interface defines the contract for Slack bot operations
@@ -18,34 +27,143 @@ type Interface interface {
 	Start(ctx context.Context)
 
 	// PostMessage posts a message to a channel
-	PostMessage(channel, threadTS, message string) error
+	PostMessage(channel, threadTS, message string, options ...MessageOption) error
+
+	// PostMessageTS posts a message to a channel and returns its timestamp
+	PostMessageTS(channel, threadTS, message string, options ...MessageOption) (string, error)
+
+	// DeleteMessage deletes a message posted by the bot
+	DeleteMessage(channel, timestamp string) error
+
+	// PinMessage pins a message to the channel
+	PinMessage(channel, timestamp string) error
+
+	// UnpinMessage unpins a message from the channel
+	UnpinMessage(channel, timestamp string) error
+
+	// UploadSnippet uploads the content as a text snippet in the thread
+	UploadSnippet(channel, threadTS, filename, content string) error
+
+	// PostBlocks posts a Block Kit message to a channel
+	PostBlocks(channel, threadTS string, blocks []slack.Block, options ...MessageOption) error
+
+	// PostEphemeral posts a message to a channel that is only visible to the given user
+	PostEphemeral(channel, userID, message string) error
+
+	// RespondViaResponseURL replies to a slash command through its response URL
+	RespondViaResponseURL(url, text string, ephemeral bool) error
+
+	// PublishHomeView publishes the App Home tab view for a user
+	PublishHomeView(userID string, view slack.HomeTabViewRequest) error
+
+	// OpenView opens a modal for the user who triggered it, e.g. with a slash command
+	OpenView(userID, triggerID string, view slack.ModalViewRequest) error
 
-	// GetConversationReplies gets replies in a conversation thread
+	// GetConversationReplies gets all the replies in a conversation thread, following every page
 	GetConversationReplies(params *slack.GetConversationRepliesParameters) ([]slack.Message, error)
 
+	// GetImages downloads the images attached to a message of the channel
+	GetImages(channel string, message slack.Message) ([]Image, error)
+
+	// GetPermalink returns the permanent link of a message
+	GetPermalink(channel, timestamp string) (string, error)
+
+	// GetUserName returns the display name of a user
+	GetUserName(userID string) (string, error)
+
 	// GetBotUser returns the bot user information
 	GetBotUser() *slack.AuthTestResponse
 }
 
+const (
+	// DefaultAuthAttempts is the number of times AuthTest is tried before giving up
+	DefaultAuthAttempts = 5
+	// DefaultAuthBackoff is the wait before the first AuthTest retry, doubled on every attempt
+	DefaultAuthBackoff = time.Second
+	// reconnectInitialBackoff is the wait before the first socket mode reconnect, doubled on every attempt
+	reconnectInitialBackoff = time.Second
+	// reconnectMaxBackoff caps the wait between socket mode reconnects
+	reconnectMaxBackoff = 2 * time.Minute
+
+	// DefaultMaxMessageLength is the maximum number of characters Slack accepts in a message text
+	DefaultMaxMessageLength = 40000
+)
+
+// MessageOption customizes how PostMessage and PostBlocks post a message
+type MessageOption int
+
+const (
+	// OptionBroadcast also shows the thread reply in the channel (reply_broadcast)
+	OptionBroadcast MessageOption = iota + 1
+)
+
+// messageOptions returns the Slack options of a message posted in the thread
+func messageOptions(threadTS string, options []MessageOption) []slack.MsgOption {
+	msgOptions := []slack.MsgOption{slack.MsgOptionTS(threadTS)}
+	for _, option := range options {
+		if option == OptionBroadcast {
+			msgOptions = append(msgOptions, slack.MsgOptionBroadcast())
+		}
+	}
+	return msgOptions
+}
+
+// AuthRetry is the retry budget of the Slack authentication at startup
+type AuthRetry struct {
+	// MaxAttempts is the number of times AuthTest is tried before giving up, it is tried at least once
+	MaxAttempts int
+	// Backoff is the wait before the first retry, doubled on every attempt and jittered so the replicas starting
+	// together don't retry together
+	Backoff time.Duration
+}
+
+// DefaultAuthRetry returns the retry budget of DefaultAuthAttempts attempts starting at DefaultAuthBackoff
+func DefaultAuthRetry() AuthRetry {
+	return AuthRetry{MaxAttempts: DefaultAuthAttempts, Backoff: DefaultAuthBackoff}
+}
+
+// authTester is the subset of the Slack client used to validate the bot credentials
+type authTester interface {
+	AuthTest() (*slack.AuthTestResponse, error)
+}
+
 type SlackBot struct {
 	api                 *slack.Client
 	socketMode          *socketmode.Client
 	botUser             *slack.AuthTestResponse
 	appMentionChannel   chan *slackevents.AppMentionEvent
 	slashCommandChannel chan *slack.SlashCommand
+	interactionChannel  chan *slack.InteractionCallback
+	appHomeChannel      chan *slackevents.AppHomeOpenedEvent
+	debug               bool
+	// authRetry is the retry budget of the authentication of the teams
+	authRetry AuthRetry
+	// maxMessageLength caps the message texts, DefaultMaxMessageLength is used when it is not set
+	maxMessageLength int
+	// triggerPrefix starts the messages of triggerChannels handled like a mention, see SetTrigger
+	triggerPrefix   string
+	triggerChannels []string
+
+	// teams holds the client of every workspace keyed by team ID, api is the default one
+	teamsMu      sync.RWMutex
+	teams        map[string]*slack.Client
+	channelTeams map[string]string
+	userTeams    map[string]string
+
+	// userNames caches the display names returned by GetUserName
+	userNamesMu sync.RWMutex
+	userNames   map[string]string
 }
 
 func NewSlackBot(slackBotToken, slackAppToken string,
 	appMentionChannel chan *slackevents.AppMentionEvent,
 	slashCommandChannel chan *slack.SlashCommand,
+	interactionChannel chan *slack.InteractionCallback,
+	appHomeChannel chan *slackevents.AppHomeOpenedEvent,
+	authRetry AuthRetry,
 	debug bool) (*SlackBot, error) {
 	// Create a new Slack API client
-	api := slack.New(
-		slackBotToken,
-		slack.OptionDebug(debug),
-		slack.OptionLog(log.New(os.Stdout, "slack-bot: ", log.Lshortfile|log.LstdFlags)),
-		slack.OptionAppLevelToken(slackAppToken),
-	)
+	api := newAPIClient(slackBotToken, slackAppToken, debug)
 
 	// Create a new Socket Mode client
 	socketMode := socketmode.New(
@@ -55,86 +173,444 @@ func NewSlackBot(slackBotToken, slackAppToken string,
 	)
 
 	// Test the connection
-	authTest, err := api.AuthTest()
+	authTest, err := authenticate(api, authRetry)
 	if err != nil {
-		log.Fatalf("❌ Failed to authenticate with Slack: %v", err)
+		return nil, fmt.Errorf("failed to authenticate with Slack: %w", err)
 	}
 
 	botUser := authTest // Store bot user info
 	fmt.Printf("✅ Connected to Slack! Bot User: %s (ID: %s)\n", authTest.User, authTest.UserID)
-	return &SlackBot{api: api, socketMode: socketMode, botUser: botUser, appMentionChannel: appMentionChannel, slashCommandChannel: slashCommandChannel}, nil
+	return &SlackBot{
+		api:                 api,
+		socketMode:          socketMode,
+		botUser:             botUser,
+		appMentionChannel:   appMentionChannel,
+		slashCommandChannel: slashCommandChannel,
+		interactionChannel:  interactionChannel,
+		appHomeChannel:      appHomeChannel,
+		debug:               debug,
+		authRetry:           authRetry,
+		teams:               map[string]*slack.Client{authTest.TeamID: api},
+	}, nil
 }
 
-// Start begins the bot's event processing loop
-//
-//nolint:gocognit // this is a long function, but it is a good place to put the event handling logic
+// newAPIClient creates a Slack API client for the bot token, the app token is only needed for socket mode
+func newAPIClient(slackBotToken, slackAppToken string, debug bool) *slack.Client {
+	options := []slack.Option{
+		slack.OptionDebug(debug),
+		slack.OptionLog(log.New(os.Stdout, "slack-bot: ", log.Lshortfile|log.LstdFlags)),
+	}
+	if slackAppToken != "" {
+		options = append(options, slack.OptionAppLevelToken(slackAppToken))
+	}
+	return slack.New(slackBotToken, options...)
+}
+
+// authenticate runs AuthTest, retrying transient failures with a jittered exponential backoff
+func authenticate(client authTester, retry AuthRetry) (*slack.AuthTestResponse, error) {
+	maxAttempts := max(retry.MaxAttempts, 1)
+	backoff := retry.Backoff
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		var authTest *slack.AuthTestResponse
+		authTest, err = client.AuthTest()
+		if err == nil {
+			return authTest, nil
+		}
+
+		if !isTransientError(err) || attempt == maxAttempts {
+			break
+		}
+
+		wait := authWait(err, jitter(backoff))
+		fmt.Printf("⚠️ Slack authentication attempt %d/%d failed: %v, retrying in %s\n", attempt, maxAttempts, err, wait)
+		time.Sleep(wait)
+		backoff *= 2
+	}
+	return nil, err
+}
+
+// jitter returns a random wait between half the backoff and the backoff, so the clients failing together spread
+// their retries while still backing off
+func jitter(backoff time.Duration) time.Duration {
+	if backoff <= 0 {
+		return 0
+	}
+	half := backoff / 2
+	return half + rand.N(backoff-half+1)
+}
+
+// authWait returns the wait before retrying the failed AuthTest, at least the Retry-After of a rate limit
+func authWait(err error, backoff time.Duration) time.Duration {
+	var rateLimitedErr *slack.RateLimitedError
+	if errors.As(err, &rateLimitedErr) {
+		return max(backoff, rateLimitedErr.RetryAfter)
+	}
+	return backoff
+}
+
+// isTransientError reports whether a Slack API error is worth retrying
+func isTransientError(err error) bool {
+	var rateLimitedErr *slack.RateLimitedError
+	if errors.As(err, &rateLimitedErr) {
+		return true
+	}
+
+	var statusCodeErr slack.StatusCodeError
+	if errors.As(err, &statusCodeErr) {
+		return statusCodeErr.Code >= http.StatusInternalServerError
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// Start begins the bot's event processing loop, it returns once the context is canceled and the loop exited
 func (b *SlackBot) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	// Handle different types of events
+	var wg sync.WaitGroup
+	wg.Add(1)
 	go func() {
-		for envelope := range b.socketMode.Events {
-			switch envelope.Type {
-			case socketmode.EventTypeConnecting:
-				fmt.Println("🔌 Connecting to Slack with Socket Mode...")
-
-			case socketmode.EventTypeConnectionError:
-				fmt.Printf("❌ Connection failed: %v\n", envelope.Data)
-
-			case socketmode.EventTypeConnected:
-				fmt.Println("✅ Connected to Slack with Socket Mode")
-			case socketmode.EventTypeHello:
-				fmt.Println("👋 Hello from Slack!")
-
-			case socketmode.EventTypeEventsAPI:
-				// Handle Events API events
-				eventsAPIEvent, ok := envelope.Data.(slackevents.EventsAPIEvent)
-				if !ok {
-					fmt.Printf("❌ Unexpected event type: %v\n", envelope.Data)
-					continue
-				}
-
-				// Acknowledge the event
-				// TODO: Maybe we should not ack the event here, but in the handleAppMentionEvent and handleSlashCommand functions
-				b.socketMode.Ack(*envelope.Request)
-				appMentionEvent, ok := eventsAPIEvent.InnerEvent.Data.(*slackevents.AppMentionEvent)
-				if !ok {
-					fmt.Printf("❌ Unexpected app mention event type: %v\n", eventsAPIEvent.InnerEvent.Data)
-					continue
-				}
-				b.appMentionChannel <- appMentionEvent
-
-			case socketmode.EventTypeSlashCommand:
-				// Handle slash commands
-				command, ok := envelope.Data.(*slack.SlashCommand)
-				if !ok {
-					fmt.Printf("❌ Unexpected slash command type: %v\n", envelope.Data)
-					continue
-				}
-				b.slashCommandChannel <- command
-
-			default:
-				fmt.Printf("🔍 Unhandled event type: %s\n", envelope.Type)
-			}
-		}
+		defer wg.Done()
+		b.handleEvents(ctx)
 	}()
 
 	fmt.Println("🤖 Slack AI Assistant Bot is running...")
-	if err := b.socketMode.RunContext(ctx); err != nil {
-		fmt.Printf("Error running socket mode: %v\n", err)
+	runWithReconnect(ctx, b.socketMode.RunContext, reconnectInitialBackoff, reconnectMaxBackoff)
+
+	// Stop the event loop and wait for it so no goroutine outlives Start
+	cancel()
+	wg.Wait()
+	fmt.Println("🛑 Slack event loop stopped")
+}
+
+// handleEvents processes socket mode events until the context is canceled or the events channel is closed
+func (b *SlackBot) handleEvents(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case envelope, ok := <-b.socketMode.Events:
+			if !ok {
+				return
+			}
+			b.handleEvent(ctx, &envelope)
+		}
 	}
 }
 
-func (b *SlackBot) PostMessage(channel, threadTS, message string) error {
-	_, _, err := b.api.PostMessage(
+// handleEvent dispatches a single socket mode event to the matching channel
+//
+//nolint:gocognit // this is a long function, but it is a good place to put the event handling logic
+func (b *SlackBot) handleEvent(ctx context.Context, envelope *socketmode.Event) {
+	switch envelope.Type {
+	case socketmode.EventTypeConnecting:
+		fmt.Println("🔌 Connecting to Slack with Socket Mode...")
+
+	case socketmode.EventTypeConnectionError:
+		fmt.Printf("❌ Connection failed: %v\n", envelope.Data)
+
+	case socketmode.EventTypeConnected:
+		fmt.Println("✅ Connected to Slack with Socket Mode")
+	case socketmode.EventTypeHello:
+		fmt.Println("👋 Hello from Slack!")
+
+	case socketmode.EventTypeEventsAPI:
+		// Handle Events API events
+		eventsAPIEvent, ok := envelope.Data.(slackevents.EventsAPIEvent)
+		if !ok {
+			fmt.Printf("❌ Unexpected event type: %v\n", envelope.Data)
+			return
+		}
+
+		// Acknowledge the event
+		// TODO: Maybe we should not ack the event here, but in the handleAppMentionEvent and handleSlashCommand functions
+		b.socketMode.Ack(*envelope.Request)
+		switch innerEvent := eventsAPIEvent.InnerEvent.Data.(type) {
+		case *slackevents.AppMentionEvent:
+			if reason := ignoredMention(envelope.Request.Payload, innerEvent.BotID); reason != "" {
+				fmt.Printf("🙈 Ignored the mention %s in channel %s\n", reason, innerEvent.Channel)
+				return
+			}
+			b.rememberTeam(eventsAPIEvent.TeamID, innerEvent.Channel, innerEvent.User)
+			forward(ctx, b.appMentionChannel, innerEvent)
+		case *slackevents.MessageEvent:
+			if mention, ok := b.triggerMention(innerEvent); ok {
+				b.rememberTeam(eventsAPIEvent.TeamID, innerEvent.Channel, innerEvent.User)
+				forward(ctx, b.appMentionChannel, mention)
+			}
+		case *slackevents.AppHomeOpenedEvent:
+			b.rememberTeam(eventsAPIEvent.TeamID, innerEvent.Channel, innerEvent.User)
+			forward(ctx, b.appHomeChannel, innerEvent)
+		default:
+			fmt.Printf("❌ Unexpected events API event type: %v\n", eventsAPIEvent.InnerEvent.Data)
+		}
+
+	case socketmode.EventTypeSlashCommand:
+		// Handle slash commands
+		command, ok := envelope.Data.(*slack.SlashCommand)
+		if !ok {
+			fmt.Printf("❌ Unexpected slash command type: %v\n", envelope.Data)
+			return
+		}
+		b.rememberTeam(command.TeamID, command.ChannelID, command.UserID)
+		forward(ctx, b.slashCommandChannel, command)
+
+	case socketmode.EventTypeInteractive:
+		// Handle interactive components (button clicks and modal submissions)
+		callback, ok := envelope.Data.(slack.InteractionCallback)
+		if !ok {
+			fmt.Printf("❌ Unexpected interaction type: %v\n", envelope.Data)
+			return
+		}
+
+		// Acknowledge the interaction right away so Slack doesn't show an error to the user,
+		// an empty acknowledgement also closes a submitted modal
+		b.socketMode.Ack(*envelope.Request)
+		channel := callback.Channel.ID
+		switch callback.Type {
+		case slack.InteractionTypeBlockActions:
+		case slack.InteractionTypeViewSubmission:
+			// A modal isn't posted in a channel, the command modals keep the channel of the slash command
+			channel = callback.View.PrivateMetadata
+		default:
+			fmt.Printf("🔍 Unhandled interaction type: %s\n", callback.Type)
+			return
+		}
+		b.rememberTeam(callback.Team.ID, channel, callback.User.ID)
+		forward(ctx, b.interactionChannel, &callback)
+
+	default:
+		fmt.Printf("🔍 Unhandled event type: %s\n", envelope.Type)
+	}
+}
+
+// forward sends the value to the channel unless the context is canceled first
+func forward[T any](ctx context.Context, channel chan T, value T) {
+	select {
+	case channel <- value:
+	case <-ctx.Done():
+		fmt.Println("🛑 Dropping event, the bot is shutting down")
+	}
+}
+
+// runWithReconnect runs the socket mode loop and reconnects with exponential backoff when the connection drops.
+// It returns when the context is canceled or the loop exits without an error.
+func runWithReconnect(ctx context.Context, run func(context.Context) error, initialBackoff, maxBackoff time.Duration) {
+	backoff := initialBackoff
+	for attempt := 1; ; attempt++ {
+		err := run(ctx)
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+
+		fmt.Printf("❌ Error running socket mode: %v\n", err)
+		fmt.Printf("🔄 Reconnecting to Slack in %s (attempt %d)...\n", backoff, attempt)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// ErrThreadGone is returned when posting to a thread or channel that was deleted
+var ErrThreadGone = errors.New("thread no longer exists")
+
+// threadGoneErrors are the Slack error codes returned when the thread or its channel was deleted
+var threadGoneErrors = map[string]struct{}{
+	"thread_not_found":  {},
+	"channel_not_found": {},
+}
+
+// postError marks the Slack errors returned for a deleted thread or channel with ErrThreadGone
+func postError(err error) error {
+	var slackErr slack.SlackErrorResponse
+	if errors.As(err, &slackErr) {
+		if _, ok := threadGoneErrors[slackErr.Err]; ok {
+			return fmt.Errorf("%w: %w", ErrThreadGone, err)
+		}
+	}
+	return err
+}
+
+// SetMaxMessageLength sets the maximum number of characters of a message text, longer messages are truncated
+func (b *SlackBot) SetMaxMessageLength(maxLength int) {
+	b.maxMessageLength = maxLength
+}
+
+// limitMessage truncates the message to the maximum message length so Slack doesn't reject it
+func (b *SlackBot) limitMessage(message string) string {
+	maxLength := b.maxMessageLength
+	if maxLength <= 0 {
+		maxLength = DefaultMaxMessageLength
+	}
+	return truncateMessage(message, maxLength)
+}
+
+// truncateMessage cuts the message to maxLength characters, ending it with the truncated marker
+func truncateMessage(message string, maxLength int) string {
+	if utf8.RuneCountInString(message) <= maxLength {
+		return message
+	}
+
+	runes := []rune(message)
+	fmt.Printf("✂️ Message of %d characters truncated to %d\n", len(runes), maxLength)
+	// The marker ends the messages cut to the maximum message length
+	truncatedMarker := i18n.T("message.truncated")
+	keep := maxLength - utf8.RuneCountInString(truncatedMarker)
+	if keep <= 0 {
+		// No room for the marker
+		return string(runes[:maxLength])
+	}
+	return string(runes[:keep]) + truncatedMarker
+}
+
+func (b *SlackBot) PostMessage(channel, threadTS, message string, options ...MessageOption) error {
+	_, err := b.PostMessageTS(channel, threadTS, message, options...)
+	return err
+}
+
+// PostMessageTS posts a message to a channel and returns its timestamp, e.g. to delete it later
+func (b *SlackBot) PostMessageTS(channel, threadTS, message string, options ...MessageOption) (string, error) {
+	message = b.limitMessage(message)
+	_, timestamp, err := b.clientForChannel(channel).PostMessage(
 		channel,
-		slack.MsgOptionText(message, false),
-		slack.MsgOptionTS(threadTS),
+		append([]slack.MsgOption{slack.MsgOptionText(message, false)}, messageOptions(threadTS, options)...)...,
 	)
 
 	fmt.Printf("🔍 Posted message to channel %s in thread %s: %s\n", channel, threadTS, message)
 	if err != nil {
 		fmt.Printf("❌ Failed to post message: %v\n", err)
-		return fmt.Errorf("failed to post message: %w", err)
+		return "", fmt.Errorf("failed to post message: %w", postError(err))
+	}
+	return timestamp, nil
+}
+
+// UploadSnippet uploads the content as a text snippet in the thread, e.g. an answer too long to read as a message,
+// the snippet type is guessed by Slack from the filename extension
+func (b *SlackBot) UploadSnippet(channel, threadTS, filename, content string) error {
+	_, err := b.clientForChannel(channel).UploadFileV2(slack.UploadFileV2Parameters{
+		Channel:         channel,
+		ThreadTimestamp: threadTS,
+		Filename:        filename,
+		Title:           filename,
+		Content:         content,
+		FileSize:        len(content),
+	})
+	if err != nil {
+		fmt.Printf("❌ Failed to upload snippet: %v\n", err)
+		return fmt.Errorf("failed to upload snippet: %w", postError(err))
 	}
+	fmt.Printf("📎 Uploaded snippet %s (%d bytes) to channel %s in thread %s\n", filename, len(content), channel, threadTS)
+	return nil
+}
+
+// DeleteMessage deletes a message posted by the bot, a message that is already deleted is not an error
+func (b *SlackBot) DeleteMessage(channel, timestamp string) error {
+	if _, _, err := b.clientForChannel(channel).DeleteMessage(channel, timestamp); err != nil {
+		var slackErr slack.SlackErrorResponse
+		if errors.As(err, &slackErr) && slackErr.Err == "message_not_found" {
+			fmt.Printf("🗑️ Message %s in channel %s was already deleted\n", timestamp, channel)
+			return nil
+		}
+		return fmt.Errorf("failed to delete message: %w", err)
+	}
+	fmt.Printf("🗑️ Deleted message %s in channel %s\n", timestamp, channel)
+	return nil
+}
+
+// PinMessage pins a message to the channel, a message that is already pinned is not an error
+func (b *SlackBot) PinMessage(channel, timestamp string) error {
+	if err := b.clientForChannel(channel).AddPin(channel, slack.NewRefToMessage(channel, timestamp)); err != nil {
+		var slackErr slack.SlackErrorResponse
+		if errors.As(err, &slackErr) && slackErr.Err == "already_pinned" {
+			fmt.Printf("📌 Message %s in channel %s was already pinned\n", timestamp, channel)
+			return nil
+		}
+		return fmt.Errorf("failed to pin message: %w", err)
+	}
+	fmt.Printf("📌 Pinned message %s in channel %s\n", timestamp, channel)
+	return nil
+}
+
+// UnpinMessage unpins a message from the channel, a message that isn't pinned is not an error
+func (b *SlackBot) UnpinMessage(channel, timestamp string) error {
+	if err := b.clientForChannel(channel).RemovePin(channel, slack.NewRefToMessage(channel, timestamp)); err != nil {
+		var slackErr slack.SlackErrorResponse
+		if errors.As(err, &slackErr) && slackErr.Err == "no_pin" {
+			fmt.Printf("📌 Message %s in channel %s was not pinned\n", timestamp, channel)
+			return nil
+		}
+		return fmt.Errorf("failed to unpin message: %w", err)
+	}
+	fmt.Printf("📌 Unpinned message %s in channel %s\n", timestamp, channel)
+	return nil
+}
+
+// PostBlocks posts a Block Kit message to a channel
+func (b *SlackBot) PostBlocks(channel, threadTS string, blocks []slack.Block, options ...MessageOption) error {
+	_, _, err := b.clientForChannel(channel).PostMessage(
+		channel,
+		append([]slack.MsgOption{slack.MsgOptionBlocks(blocks...)}, messageOptions(threadTS, options)...)...,
+	)
+
+	fmt.Printf("🔍 Posted %d block(s) to channel %s in thread %s\n", len(blocks), channel, threadTS)
+	if err != nil {
+		fmt.Printf("❌ Failed to post blocks: %v\n", err)
+		return fmt.Errorf("failed to post blocks: %w", postError(err))
+	}
+	return nil
+}
+
+// PostEphemeral posts a message to a channel that is only visible to the given user
+func (b *SlackBot) PostEphemeral(channel, userID, message string) error {
+	message = b.limitMessage(message)
+	_, err := b.clientForChannel(channel).PostEphemeral(
+		channel,
+		userID,
+		slack.MsgOptionText(message, false),
+	)
+
+	fmt.Printf("🔍 Posted ephemeral message to user %s in channel %s: %s\n", userID, channel, message)
+	if err != nil {
+		fmt.Printf("❌ Failed to post ephemeral message: %v\n", err)
+		return fmt.Errorf("failed to post ephemeral message: %w", postError(err))
+	}
+	return nil
+}
+
+// PublishHomeView publishes the App Home tab view for a user
+func (b *SlackBot) PublishHomeView(userID string, view slack.HomeTabViewRequest) error {
+	request := slack.PublishViewContextRequest{UserID: userID, View: view}
+	if _, err := b.clientForUser(userID).PublishViewContext(context.Background(), request); err != nil {
+		fmt.Printf("❌ Failed to publish home view: %v\n", err)
+		return fmt.Errorf("failed to publish home view: %w", err)
+	}
+
+	fmt.Printf("🏠 Published home view for user %s\n", userID)
+	return nil
+}
+
+// OpenView opens a modal for the user who triggered it, the trigger ID expires 3 seconds after the trigger
+func (b *SlackBot) OpenView(userID, triggerID string, view slack.ModalViewRequest) error {
+	if _, err := b.clientForUser(userID).OpenViewContext(context.Background(), triggerID, view); err != nil {
+		fmt.Printf("❌ Failed to open modal %s: %v\n", view.CallbackID, err)
+		return fmt.Errorf("failed to open modal: %w", err)
+	}
+
+	fmt.Printf("🪟 Opened modal %s for user %s\n", view.CallbackID, userID)
 	return nil
 }
 
@@ -143,8 +619,30 @@ func (b *SlackBot) GetBotUser() *slack.AuthTestResponse {
 	return b.botUser
 }
 
-// GetConversationReplies gets replies in a conversation thread
+// GetConversationReplies gets all the replies in a conversation thread, Slack returns them by page
+// so the next cursor is followed until the last page, otherwise long threads lose their newest messages
 func (b *SlackBot) GetConversationReplies(params *slack.GetConversationRepliesParameters) ([]slack.Message, error) {
-	replies, _, _, err := b.api.GetConversationReplies(params)
-	return replies, err
+	// The parameters of the caller are left untouched by the cursor
+	page := *params
+	var replies []slack.Message
+	for {
+		messages, hasMore, nextCursor, err := b.clientForChannel(page.ChannelID).GetConversationReplies(&page)
+		if err != nil {
+			return nil, err
+		}
+		replies = append(replies, messages...)
+		if !hasMore || nextCursor == "" {
+			return replies, nil
+		}
+		page.Cursor = nextCursor
+	}
+}
+
+// GetPermalink returns the permanent link of a message
+func (b *SlackBot) GetPermalink(channel, timestamp string) (string, error) {
+	permalink, err := b.clientForChannel(channel).GetPermalink(&slack.PermalinkParameters{Channel: channel, Ts: timestamp})
+	if err != nil {
+		return "", fmt.Errorf("failed to get permalink: %w", err)
+	}
+	return permalink, nil
 }
@@ -0,0 +1,631 @@
+package slackbot
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/socketmode"
+)
+
+// stubAuthTester returns the configured errors in order, then the response
+type stubAuthTester struct {
+	errs     []error
+	response *slack.AuthTestResponse
+	calls    int
+}
+
+func (s *stubAuthTester) AuthTest() (*slack.AuthTestResponse, error) {
+	s.calls++
+	if s.calls <= len(s.errs) {
+		return nil, s.errs[s.calls-1]
+	}
+	return s.response, nil
+}
+
+var _ = Describe("SlackBot", func() {
+	Describe("authenticate", func() {
+		var botUser *slack.AuthTestResponse
+
+		BeforeEach(func() {
+			botUser = &slack.AuthTestResponse{User: "slack-ai-assistant", UserID: "BOT123"}
+		})
+
+		It("should return the bot user when authentication succeeds", func() {
+			stub := &stubAuthTester{response: botUser}
+
+			authTest, err := authenticate(stub, AuthRetry{MaxAttempts: 3})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(authTest).To(Equal(botUser))
+			Expect(stub.calls).To(Equal(1))
+		})
+
+		It("should return the auth error instead of exiting", func() {
+			stub := &stubAuthTester{errs: []error{slack.SlackErrorResponse{Err: "invalid_auth"}}}
+
+			authTest, err := authenticate(stub, AuthRetry{MaxAttempts: 3})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid_auth"))
+			Expect(authTest).To(BeNil())
+			Expect(stub.calls).To(Equal(1))
+		})
+
+		It("should retry transient failures before succeeding", func() {
+			stub := &stubAuthTester{
+				errs: []error{
+					&net.OpError{Op: "dial", Err: errors.New("connection refused")},
+					slack.StatusCodeError{Code: http.StatusServiceUnavailable, Status: "503 Service Unavailable"},
+				},
+				response: botUser,
+			}
+
+			authTest, err := authenticate(stub, AuthRetry{MaxAttempts: 3})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(authTest).To(Equal(botUser))
+			Expect(stub.calls).To(Equal(3))
+		})
+
+		It("should give up after the maximum number of attempts", func() {
+			stub := &stubAuthTester{
+				errs: []error{
+					&slack.RateLimitedError{},
+					&slack.RateLimitedError{},
+					&slack.RateLimitedError{},
+				},
+				response: botUser,
+			}
+
+			_, err := authenticate(stub, AuthRetry{MaxAttempts: 2})
+			Expect(err).To(HaveOccurred())
+			Expect(stub.calls).To(Equal(2))
+		})
+
+		It("should recover from rate limits within the retry budget", func() {
+			stub := &stubAuthTester{
+				errs:     []error{&slack.RateLimitedError{}, &slack.RateLimitedError{}},
+				response: botUser,
+			}
+
+			authTest, err := authenticate(stub, AuthRetry{MaxAttempts: 3, Backoff: time.Millisecond})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(authTest).To(Equal(botUser))
+			Expect(stub.calls).To(Equal(3))
+		})
+
+		It("should try once without retry budget", func() {
+			stub := &stubAuthTester{errs: []error{&slack.RateLimitedError{}}, response: botUser}
+
+			_, err := authenticate(stub, AuthRetry{})
+			Expect(err).To(HaveOccurred())
+			Expect(stub.calls).To(Equal(1))
+		})
+	})
+
+	Describe("jitter", func() {
+		It("should wait between half the backoff and the backoff", func() {
+			waits := map[time.Duration]bool{}
+			for range 200 {
+				wait := jitter(time.Second)
+				Expect(wait).To(BeNumerically(">=", 500*time.Millisecond))
+				Expect(wait).To(BeNumerically("<=", time.Second))
+				waits[wait] = true
+			}
+			// The replicas failing together don't all retry at the same time
+			Expect(len(waits)).To(BeNumerically(">", 1))
+		})
+
+		It("should not wait without backoff", func() {
+			Expect(jitter(0)).To(BeZero())
+		})
+
+		It("should wait at least the Retry-After of a rate limit", func() {
+			Expect(authWait(&slack.RateLimitedError{RetryAfter: 3 * time.Second}, time.Second)).To(Equal(3 * time.Second))
+			Expect(authWait(&slack.RateLimitedError{RetryAfter: time.Second}, 2*time.Second)).To(Equal(2 * time.Second))
+			Expect(authWait(slack.StatusCodeError{Code: http.StatusServiceUnavailable}, time.Second)).To(Equal(time.Second))
+		})
+	})
+})
+
+var _ = Describe("runWithReconnect", func() {
+	It("should reconnect after errors until the loop succeeds", func() {
+		calls := 0
+		run := func(ctx context.Context) error {
+			calls++
+			if calls <= 2 {
+				return errors.New("websocket closed")
+			}
+			return nil
+		}
+
+		runWithReconnect(context.Background(), run, time.Millisecond, 5*time.Millisecond)
+		Expect(calls).To(Equal(3))
+	})
+
+	It("should stop reconnecting when the context is canceled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+		run := func(ctx context.Context) error {
+			calls++
+			cancel()
+			return errors.New("websocket closed")
+		}
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			runWithReconnect(ctx, run, time.Hour, time.Hour)
+		}()
+
+		Eventually(done).Should(BeClosed())
+		Expect(calls).To(Equal(1))
+	})
+
+	It("should stop waiting for the backoff when the context is canceled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		run := func(ctx context.Context) error {
+			return errors.New("websocket closed")
+		}
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			runWithReconnect(ctx, run, time.Hour, time.Hour)
+		}()
+
+		cancel()
+		Eventually(done).Should(BeClosed())
+	})
+})
+
+var _ = Describe("PostEphemeral", func() {
+	It("should post an ephemeral message to the user", func() {
+		var form url.Values
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			Expect(r.URL.Path).To(Equal("/chat.postEphemeral"))
+			Expect(r.ParseForm()).To(Succeed())
+			form = r.PostForm
+			w.Header().Set("Content-Type", "application/json")
+			_, err := w.Write([]byte(`{"ok": true, "message_ts": "1234567890.123456"}`))
+			Expect(err).NotTo(HaveOccurred())
+		}))
+		defer server.Close()
+
+		bot := &SlackBot{api: slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"))}
+		Expect(bot.PostEphemeral("C123", "U123", "only for you")).To(Succeed())
+		Expect(form.Get("channel")).To(Equal("C123"))
+		Expect(form.Get("user")).To(Equal("U123"))
+		Expect(form.Get("text")).To(Equal("only for you"))
+	})
+
+	It("should return an error when Slack rejects the message", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			//nolint:errcheck // test mock
+			_, _ = w.Write([]byte(`{"ok": false, "error": "user_not_in_channel"}`))
+		}))
+		defer server.Close()
+
+		bot := &SlackBot{api: slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"))}
+		err := bot.PostEphemeral("C123", "U123", "only for you")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("user_not_in_channel"))
+	})
+})
+
+var _ = Describe("PostMessage", func() {
+	// postTo posts a message to a fake Slack answering with the given error code
+	postTo := func(errorCode string) error {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			//nolint:errcheck // test mock
+			_, _ = w.Write([]byte(`{"ok": false, "error": "` + errorCode + `"}`))
+		}))
+		defer server.Close()
+
+		bot := &SlackBot{api: slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"))}
+		return bot.PostMessage("C123", "1234567890.123456", "answer")
+	}
+
+	DescribeTable("should report a deleted thread or channel as ErrThreadGone",
+		func(errorCode string) {
+			err := postTo(errorCode)
+			Expect(err).To(MatchError(ErrThreadGone))
+			Expect(err.Error()).To(ContainSubstring(errorCode))
+		},
+		Entry("deleted thread", "thread_not_found"),
+		Entry("deleted channel", "channel_not_found"),
+	)
+
+	It("should broadcast the reply to the channel when requested", func() {
+		var form url.Values
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			Expect(r.ParseForm()).To(Succeed())
+			form = r.PostForm
+			w.Header().Set("Content-Type", "application/json")
+			//nolint:errcheck // test mock
+			_, _ = w.Write([]byte(`{"ok": true, "channel": "C123", "ts": "1234567890.123456"}`))
+		}))
+		defer server.Close()
+
+		bot := &SlackBot{api: slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"))}
+		Expect(bot.PostMessage("C123", "1234567890.123456", "answer")).To(Succeed())
+		Expect(form.Has("reply_broadcast")).To(BeFalse())
+
+		Expect(bot.PostMessage("C123", "1234567890.123456", "answer", OptionBroadcast)).To(Succeed())
+		Expect(form.Get("reply_broadcast")).To(Equal("true"))
+		Expect(form.Get("thread_ts")).To(Equal("1234567890.123456"))
+	})
+
+	It("should not report other Slack errors as ErrThreadGone", func() {
+		err := postTo("not_in_channel")
+		Expect(err).To(HaveOccurred())
+		Expect(err).NotTo(MatchError(ErrThreadGone))
+	})
+})
+
+var _ = Describe("DeleteMessage", func() {
+	It("should return the timestamp of a posted message and delete it", func() {
+		var deleted url.Values
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			Expect(r.ParseForm()).To(Succeed())
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Path == "/chat.delete" {
+				deleted = r.PostForm
+			}
+			//nolint:errcheck // test mock
+			_, _ = w.Write([]byte(`{"ok": true, "channel": "C123", "ts": "1234567890.654321"}`))
+		}))
+		defer server.Close()
+
+		bot := &SlackBot{api: slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"))}
+		timestamp, err := bot.PostMessageTS("C123", "1234567890.123456", "Searching for answer...")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(timestamp).To(Equal("1234567890.654321"))
+
+		Expect(bot.DeleteMessage("C123", timestamp)).To(Succeed())
+		Expect(deleted.Get("channel")).To(Equal("C123"))
+		Expect(deleted.Get("ts")).To(Equal("1234567890.654321"))
+	})
+
+	// deleteFrom deletes a message from a fake Slack answering with the given error code
+	deleteFrom := func(errorCode string) error {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			//nolint:errcheck // test mock
+			_, _ = w.Write([]byte(`{"ok": false, "error": "` + errorCode + `"}`))
+		}))
+		defer server.Close()
+
+		bot := &SlackBot{api: slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"))}
+		return bot.DeleteMessage("C123", "1234567890.654321")
+	}
+
+	It("should ignore a message that was already deleted", func() {
+		Expect(deleteFrom("message_not_found")).To(Succeed())
+	})
+
+	It("should return the other Slack errors", func() {
+		err := deleteFrom("cant_delete_message")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("cant_delete_message"))
+	})
+})
+
+var _ = Describe("PinMessage and UnpinMessage", func() {
+	// pinServer returns a fake Slack recording the pin requests and answering with the given error code, none for success
+	pinServer := func(errorCode string, requests *[]string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			Expect(r.ParseForm()).To(Succeed())
+			*requests = append(*requests, r.URL.Path+" "+r.PostForm.Get("channel")+" "+r.PostForm.Get("timestamp"))
+			w.Header().Set("Content-Type", "application/json")
+			response := `{"ok": true}`
+			if errorCode != "" {
+				response = `{"ok": false, "error": "` + errorCode + `"}`
+			}
+			//nolint:errcheck // test mock
+			_, _ = w.Write([]byte(response))
+		}))
+	}
+
+	It("should pin and unpin the message", func() {
+		var requests []string
+		server := pinServer("", &requests)
+		defer server.Close()
+
+		bot := &SlackBot{api: slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"))}
+		Expect(bot.PinMessage("C123", "1234567890.654321")).To(Succeed())
+		Expect(bot.UnpinMessage("C123", "1234567890.654321")).To(Succeed())
+		Expect(requests).To(Equal([]string{
+			"/pins.add C123 1234567890.654321",
+			"/pins.remove C123 1234567890.654321",
+		}))
+	})
+
+	It("should ignore a message that is already pinned or not pinned", func() {
+		var requests []string
+		server := pinServer("already_pinned", &requests)
+		defer server.Close()
+		bot := &SlackBot{api: slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"))}
+		Expect(bot.PinMessage("C123", "1234567890.654321")).To(Succeed())
+
+		unpinned := pinServer("no_pin", &requests)
+		defer unpinned.Close()
+		bot = &SlackBot{api: slack.New("xoxb-test", slack.OptionAPIURL(unpinned.URL+"/"))}
+		Expect(bot.UnpinMessage("C123", "1234567890.654321")).To(Succeed())
+	})
+
+	It("should return the other Slack errors", func() {
+		var requests []string
+		server := pinServer("not_pinnable", &requests)
+		defer server.Close()
+
+		bot := &SlackBot{api: slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"))}
+		err := bot.PinMessage("C123", "1234567890.654321")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("not_pinnable"))
+	})
+})
+
+var _ = Describe("Message length guard", func() {
+	DescribeTable("should cut messages longer than the limit",
+		func(message string, maxLength int, expected string) {
+			Expect(truncateMessage(message, maxLength)).To(Equal(expected))
+		},
+		Entry("below the limit", "short", 20, "short"),
+		Entry("exactly at the limit", "exactly twenty chars", 20, "exactly twenty chars"),
+		Entry("over the limit", "this message is a bit too long", 20, "this me… (truncated)"),
+		Entry("multi-byte characters", "ééééééééééééééééééééé", 20, "ééééééé… (truncated)"),
+		Entry("limit shorter than the marker", "too long", 4, "too "),
+	)
+
+	It("should post a truncated message instead of failing when it is over the limit", func() {
+		var form url.Values
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			Expect(r.ParseForm()).To(Succeed())
+			form = r.PostForm
+			w.Header().Set("Content-Type", "application/json")
+			//nolint:errcheck // test mock
+			_, _ = w.Write([]byte(`{"ok": true, "channel": "C123", "ts": "1234567890.123456"}`))
+		}))
+		defer server.Close()
+
+		bot := &SlackBot{api: slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"))}
+		bot.SetMaxMessageLength(20)
+
+		Expect(bot.PostMessage("C123", "1234567890.123456", "exactly twenty chars")).To(Succeed())
+		Expect(form.Get("text")).To(Equal("exactly twenty chars"))
+
+		Expect(bot.PostMessage("C123", "1234567890.123456", "this message is a bit too long")).To(Succeed())
+		Expect(form.Get("text")).To(Equal("this me… (truncated)"))
+		Expect([]rune(form.Get("text"))).To(HaveLen(20))
+	})
+})
+
+var _ = Describe("UploadSnippet", func() {
+	// uploadServer fakes the three steps of a Slack file upload, the uploaded content and the completion form are recorded
+	uploadServer := func(completeResponse string, content *string, completed *url.Values) *httptest.Server {
+		var server *httptest.Server
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/files.getUploadURLExternal":
+				Expect(r.ParseForm()).To(Succeed())
+				Expect(r.PostForm.Get("filename")).To(Equal("answer.md"))
+				Expect(r.PostForm.Get("length")).To(Equal("13"))
+				//nolint:errcheck // test mock
+				_, _ = w.Write([]byte(`{"ok": true, "upload_url": "` + server.URL + `/upload", "file_id": "F123"}`))
+			case "/upload":
+				file, _, err := r.FormFile("file")
+				Expect(err).NotTo(HaveOccurred())
+				uploaded, err := io.ReadAll(file)
+				Expect(err).NotTo(HaveOccurred())
+				*content = string(uploaded)
+			case "/files.completeUploadExternal":
+				Expect(r.ParseForm()).To(Succeed())
+				*completed = r.PostForm
+				//nolint:errcheck // test mock
+				_, _ = w.Write([]byte(completeResponse))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		return server
+	}
+
+	It("should upload the content as a snippet in the thread", func() {
+		var (
+			content   string
+			completed url.Values
+		)
+		server := uploadServer(`{"ok": true, "files": [{"id": "F123", "title": "answer.md"}]}`, &content, &completed)
+		defer server.Close()
+
+		bot := &SlackBot{api: slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"))}
+		Expect(bot.UploadSnippet("C123", "1234567890.123456", "answer.md", "# Long answer")).To(Succeed())
+
+		Expect(content).To(Equal("# Long answer"))
+		Expect(completed.Get("channel_id")).To(Equal("C123"))
+		Expect(completed.Get("thread_ts")).To(Equal("1234567890.123456"))
+		Expect(completed.Get("files")).To(ContainSubstring(`"id":"F123"`))
+	})
+
+	It("should report a deleted thread as gone", func() {
+		var (
+			content   string
+			completed url.Values
+		)
+		server := uploadServer(`{"ok": false, "error": "thread_not_found"}`, &content, &completed)
+		defer server.Close()
+
+		bot := &SlackBot{api: slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"))}
+		err := bot.UploadSnippet("C123", "1234567890.123456", "answer.md", "# Long answer")
+		Expect(err).To(MatchError(ErrThreadGone))
+	})
+})
+
+var _ = Describe("GetPermalink", func() {
+	It("should return the permalink of the message", func() {
+		var query url.Values
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			Expect(r.URL.Path).To(Equal("/chat.getPermalink"))
+			Expect(r.ParseForm()).To(Succeed())
+			query = r.Form
+			w.Header().Set("Content-Type", "application/json")
+			_, err := w.Write([]byte(`{"ok": true, "channel": "C123", "permalink": "https://example.slack.com/archives/C123/p1234567890123456"}`))
+			Expect(err).NotTo(HaveOccurred())
+		}))
+		defer server.Close()
+
+		bot := &SlackBot{api: slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"))}
+		permalink, err := bot.GetPermalink("C123", "1234567890.123456")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(permalink).To(Equal("https://example.slack.com/archives/C123/p1234567890123456"))
+		Expect(query.Get("channel")).To(Equal("C123"))
+		Expect(query.Get("message_ts")).To(Equal("1234567890.123456"))
+	})
+
+	It("should return an error when Slack can't find the message", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			//nolint:errcheck // test mock
+			_, _ = w.Write([]byte(`{"ok": false, "error": "message_not_found"}`))
+		}))
+		defer server.Close()
+
+		bot := &SlackBot{api: slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"))}
+		_, err := bot.GetPermalink("C123", "1234567890.123456")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("message_not_found"))
+	})
+})
+
+var _ = Describe("GetConversationReplies", func() {
+	It("should follow the cursor until every page of the thread was collected", func() {
+		var cursors []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			Expect(r.URL.Path).To(Equal("/conversations.replies"))
+			Expect(r.ParseForm()).To(Succeed())
+			Expect(r.Form.Get("channel")).To(Equal("C123"))
+			Expect(r.Form.Get("ts")).To(Equal("1234567890.123456"))
+			cursors = append(cursors, r.Form.Get("cursor"))
+			w.Header().Set("Content-Type", "application/json")
+			var err error
+			switch r.Form.Get("cursor") {
+			case "":
+				_, err = w.Write([]byte(`{"ok": true, "messages": [{"text": "first"}, {"text": "second"}], "has_more": true, "response_metadata": {"next_cursor": "page2"}}`))
+			case "page2":
+				_, err = w.Write([]byte(`{"ok": true, "messages": [{"text": "third"}], "has_more": true, "response_metadata": {"next_cursor": "page3"}}`))
+			default:
+				_, err = w.Write([]byte(`{"ok": true, "messages": [{"text": "fourth"}], "has_more": false, "response_metadata": {"next_cursor": ""}}`))
+			}
+			Expect(err).NotTo(HaveOccurred())
+		}))
+		defer server.Close()
+
+		bot := &SlackBot{api: slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"))}
+		params := &slack.GetConversationRepliesParameters{ChannelID: "C123", Timestamp: "1234567890.123456", Inclusive: true}
+		replies, err := bot.GetConversationReplies(params)
+		Expect(err).NotTo(HaveOccurred())
+
+		texts := make([]string, 0, len(replies))
+		for _, reply := range replies {
+			texts = append(texts, reply.Text)
+		}
+		Expect(texts).To(Equal([]string{"first", "second", "third", "fourth"}))
+		Expect(cursors).To(Equal([]string{"", "page2", "page3"}))
+		Expect(params.Cursor).To(BeEmpty())
+	})
+
+	It("should return the error of a failing page", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			Expect(r.ParseForm()).To(Succeed())
+			w.Header().Set("Content-Type", "application/json")
+			var err error
+			if r.Form.Get("cursor") == "" {
+				_, err = w.Write([]byte(`{"ok": true, "messages": [{"text": "first"}], "has_more": true, "response_metadata": {"next_cursor": "page2"}}`))
+			} else {
+				_, err = w.Write([]byte(`{"ok": false, "error": "thread_not_found"}`))
+			}
+			Expect(err).NotTo(HaveOccurred())
+		}))
+		defer server.Close()
+
+		bot := &SlackBot{api: slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"))}
+		replies, err := bot.GetConversationReplies(&slack.GetConversationRepliesParameters{ChannelID: "C123", Timestamp: "1234567890.123456"})
+		Expect(err).To(MatchError(ContainSubstring("thread_not_found")))
+		Expect(replies).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Start", func() {
+	It("should stop the event loop when the context is canceled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		bot := &SlackBot{socketMode: &socketmode.Client{Events: make(chan socketmode.Event)}}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			bot.handleEvents(ctx)
+		}()
+
+		cancel()
+		Eventually(done).Should(BeClosed())
+	})
+
+	It("should not block on a full channel once the context is canceled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		events := make(chan socketmode.Event, 1)
+		bot := &SlackBot{
+			socketMode:          &socketmode.Client{Events: events},
+			slashCommandChannel: make(chan *slack.SlashCommand),
+		}
+		events <- socketmode.Event{Type: socketmode.EventTypeSlashCommand, Data: &slack.SlashCommand{Command: "/ask"}}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			bot.handleEvents(ctx)
+		}()
+
+		Eventually(events).Should(BeEmpty())
+		cancel()
+		Eventually(done).Should(BeClosed())
+	})
+
+	It("should return only after the event loop exited", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			//nolint:errcheck // test mock
+			_, _ = w.Write([]byte(`{"ok": false, "error": "invalid_auth"}`))
+		}))
+		defer server.Close()
+		api := slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"), slack.OptionAppLevelToken("xapp-test"))
+		bot := &SlackBot{api: api, socketMode: socketmode.New(api)}
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			bot.Start(ctx)
+		}()
+
+		Eventually(done, 5*time.Second).Should(BeClosed())
+		http.DefaultClient.CloseIdleConnections()
+	})
+})
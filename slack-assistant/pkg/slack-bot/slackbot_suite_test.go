@@ -0,0 +1,25 @@
+package slackbot
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/goleak"
+)
+
+func TestSlackBot(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "SlackBot Suite")
+}
+
+// Fail any spec that leaves goroutines running after its teardown
+var ignoreGoroutines goleak.Option
+
+var _ = BeforeEach(func() {
+	ignoreGoroutines = goleak.IgnoreCurrent()
+})
+
+var _ = AfterEach(func() {
+	goleak.VerifyNone(GinkgoT(), ignoreGoroutines)
+})
@@ -0,0 +1,74 @@
+package slackbot
+
+import (
+	"fmt"
+
+	"github.com/slack-go/slack"
+)
+
+// AddTeam registers the bot token of another workspace the app is installed in,
+// events coming from that workspace are answered with its own client. It returns the team ID of the token.
+func (b *SlackBot) AddTeam(botToken string) (string, error) {
+	return b.addTeam(newAPIClient(botToken, "", b.debug))
+}
+
+// addTeam authenticates the client and stores it under its team ID
+func (b *SlackBot) addTeam(api *slack.Client) (string, error) {
+	authTest, err := authenticate(api, b.authRetry)
+	if err != nil {
+		return "", fmt.Errorf("failed to authenticate with Slack: %w", err)
+	}
+
+	b.teamsMu.Lock()
+	defer b.teamsMu.Unlock()
+	if b.teams == nil {
+		b.teams = map[string]*slack.Client{}
+	}
+	b.teams[authTest.TeamID] = api
+	fmt.Printf("✅ Connected to Slack team %s (ID: %s) as %s\n", authTest.Team, authTest.TeamID, authTest.User)
+	return authTest.TeamID, nil
+}
+
+// rememberTeam records the team of the channel and user of an event so the replies use the client of that team
+func (b *SlackBot) rememberTeam(teamID, channel, user string) {
+	if teamID == "" {
+		return
+	}
+
+	b.teamsMu.Lock()
+	defer b.teamsMu.Unlock()
+	if b.channelTeams == nil {
+		b.channelTeams = map[string]string{}
+	}
+	if b.userTeams == nil {
+		b.userTeams = map[string]string{}
+	}
+	if channel != "" {
+		b.channelTeams[channel] = teamID
+	}
+	if user != "" {
+		b.userTeams[user] = teamID
+	}
+}
+
+// clientForChannel returns the client of the team the channel belongs to, or the default client
+func (b *SlackBot) clientForChannel(channel string) *slack.Client {
+	b.teamsMu.RLock()
+	defer b.teamsMu.RUnlock()
+	return b.teamClient(b.channelTeams[channel])
+}
+
+// clientForUser returns the client of the team the user belongs to, or the default client
+func (b *SlackBot) clientForUser(userID string) *slack.Client {
+	b.teamsMu.RLock()
+	defer b.teamsMu.RUnlock()
+	return b.teamClient(b.userTeams[userID])
+}
+
+// teamClient returns the client registered for the team, callers must hold teamsMu
+func (b *SlackBot) teamClient(teamID string) *slack.Client {
+	if api, ok := b.teams[teamID]; ok {
+		return api
+	}
+	return b.api
+}
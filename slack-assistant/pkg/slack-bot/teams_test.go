@@ -0,0 +1,88 @@
+package slackbot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/socketmode"
+)
+
+// newTeamServer fakes the Slack API of a team and counts the posted messages
+func newTeamServer(teamID string, posted *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/auth.test":
+			//nolint:errcheck // test mock
+			_, _ = w.Write([]byte(`{"ok": true, "team": "Team", "team_id": "` + teamID + `", "user": "bot", "user_id": "B1"}`))
+		case "/chat.postMessage":
+			posted.Add(1)
+			//nolint:errcheck // test mock
+			_, _ = w.Write([]byte(`{"ok": true, "channel": "C1", "ts": "1234567890.123456"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+var _ = Describe("Teams", func() {
+	var (
+		defaultPosted, otherPosted atomic.Int32
+		defaultServer, otherServer *httptest.Server
+		bot                        *SlackBot
+	)
+
+	BeforeEach(func() {
+		defaultPosted.Store(0)
+		otherPosted.Store(0)
+		defaultServer = newTeamServer("T1", &defaultPosted)
+		otherServer = newTeamServer("T2", &otherPosted)
+
+		defaultAPI := slack.New("xoxb-default", slack.OptionAPIURL(defaultServer.URL+"/"))
+		bot = &SlackBot{
+			api:                 defaultAPI,
+			teams:               map[string]*slack.Client{"T1": defaultAPI},
+			slashCommandChannel: make(chan *slack.SlashCommand, 1),
+		}
+		teamID, err := bot.addTeam(slack.New("xoxb-other", slack.OptionAPIURL(otherServer.URL+"/")))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(teamID).To(Equal("T2"))
+	})
+
+	AfterEach(func() {
+		defaultServer.Close()
+		otherServer.Close()
+		http.DefaultClient.CloseIdleConnections()
+	})
+
+	It("should reply with the client of the team the event came from", func() {
+		bot.handleEvent(context.Background(), &socketmode.Event{
+			Type: socketmode.EventTypeSlashCommand,
+			Data: &slack.SlashCommand{TeamID: "T2", ChannelID: "C2", UserID: "U2", Command: "/ask"},
+		})
+		Expect(bot.slashCommandChannel).To(HaveLen(1))
+
+		Expect(bot.PostMessage("C2", "", "hello")).To(Succeed())
+		Expect(otherPosted.Load()).To(Equal(int32(1)))
+		Expect(defaultPosted.Load()).To(BeZero())
+		Expect(bot.clientForUser("U2")).To(BeIdenticalTo(bot.teams["T2"]))
+	})
+
+	It("should use the default client for channels without a known team", func() {
+		Expect(bot.PostMessage("C1", "", "hello")).To(Succeed())
+		Expect(defaultPosted.Load()).To(Equal(int32(1)))
+		Expect(otherPosted.Load()).To(BeZero())
+	})
+
+	It("should use the default client for teams without a token", func() {
+		bot.rememberTeam("T3", "C3", "U3")
+
+		Expect(bot.clientForChannel("C3")).To(BeIdenticalTo(bot.api))
+		Expect(bot.clientForUser("U3")).To(BeIdenticalTo(bot.api))
+	})
+})
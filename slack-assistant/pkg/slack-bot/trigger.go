@@ -0,0 +1,48 @@
+package slackbot
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/slack-go/slack/slackevents"
+)
+
+// SetTrigger makes the messages starting with the prefix run a command without mentioning the bot, e.g. `?ask answer sriov 4.16`,
+// only the messages of the given channels are handled so every channel opts in
+func (b *SlackBot) SetTrigger(prefix string, channels []string) {
+	b.triggerPrefix = prefix
+	b.triggerChannels = channels
+}
+
+// triggerMention returns the message starting with the trigger prefix as a mention of the bot, so it is routed like one,
+// ok is false for the other messages, the messages of bots and the messages that already mention the bot
+func (b *SlackBot) triggerMention(event *slackevents.MessageEvent) (*slackevents.AppMentionEvent, bool) {
+	if b.triggerPrefix == "" || b.botUser == nil || !slices.Contains(b.triggerChannels, event.Channel) {
+		return nil, false
+	}
+	// Edits, deletions and the bot own answers must not trigger a command
+	if event.SubType != "" || event.BotID != "" || event.User == "" || event.User == b.botUser.UserID {
+		return nil, false
+	}
+
+	mention := fmt.Sprintf("<@%s>", b.botUser.UserID)
+	// The mention is already delivered as an app mention event
+	if strings.Contains(event.Text, mention) {
+		return nil, false
+	}
+	remainder, ok := strings.CutPrefix(strings.TrimSpace(event.Text), b.triggerPrefix)
+	if !ok || (remainder != "" && !strings.HasPrefix(remainder, " ")) {
+		return nil, false
+	}
+
+	return &slackevents.AppMentionEvent{
+		Type:            "app_mention",
+		User:            event.User,
+		Text:            mention + remainder,
+		TimeStamp:       event.TimeStamp,
+		ThreadTimeStamp: event.ThreadTimeStamp,
+		Channel:         event.Channel,
+		EventTimeStamp:  event.EventTimeStamp,
+	}, true
+}
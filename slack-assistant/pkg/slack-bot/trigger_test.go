@@ -0,0 +1,53 @@
+package slackbot
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+var _ = Describe("Trigger", func() {
+	var bot *SlackBot
+
+	BeforeEach(func() {
+		bot = &SlackBot{botUser: &slack.AuthTestResponse{UserID: "UBOT"}}
+		bot.SetTrigger("?ask", []string{"C1"})
+	})
+
+	It("should route a message starting with the prefix like a mention", func() {
+		mention, ok := bot.triggerMention(&slackevents.MessageEvent{
+			User:            "U1",
+			Channel:         "C1",
+			Text:            "?ask answer sriov 4.16",
+			TimeStamp:       "1234567890.200000",
+			ThreadTimeStamp: "1234567890.100000",
+		})
+		Expect(ok).To(BeTrue())
+		Expect(mention.Text).To(Equal("<@UBOT> answer sriov 4.16"))
+		Expect(mention.User).To(Equal("U1"))
+		Expect(mention.Channel).To(Equal("C1"))
+		Expect(mention.TimeStamp).To(Equal("1234567890.200000"))
+		Expect(mention.ThreadTimeStamp).To(Equal("1234567890.100000"))
+	})
+
+	DescribeTable("should ignore the messages that don't trigger a command",
+		func(event slackevents.MessageEvent) {
+			_, ok := bot.triggerMention(&event)
+			Expect(ok).To(BeFalse())
+		},
+		Entry("without the prefix", slackevents.MessageEvent{User: "U1", Channel: "C1", Text: "answer sriov 4.16"}),
+		Entry("with the prefix inside a word", slackevents.MessageEvent{User: "U1", Channel: "C1", Text: "?asking for a friend"}),
+		Entry("in a channel that didn't opt in", slackevents.MessageEvent{User: "U1", Channel: "C2", Text: "?ask answer sriov 4.16"}),
+		Entry("posted by a bot", slackevents.MessageEvent{BotID: "B1", Channel: "C1", Text: "?ask answer sriov 4.16"}),
+		Entry("posted by this bot", slackevents.MessageEvent{User: "UBOT", Channel: "C1", Text: "?ask answer sriov 4.16"}),
+		Entry("edited", slackevents.MessageEvent{User: "U1", Channel: "C1", SubType: "message_changed", Text: "?ask answer sriov 4.16"}),
+		Entry("already mentioning the bot", slackevents.MessageEvent{User: "U1", Channel: "C1", Text: "?ask <@UBOT> answer sriov 4.16"}),
+	)
+
+	It("should ignore every message when no prefix is set", func() {
+		bot.SetTrigger("", []string{"C1"})
+		_, ok := bot.triggerMention(&slackevents.MessageEvent{User: "U1", Channel: "C1", Text: "?ask answer sriov 4.16"})
+		Expect(ok).To(BeFalse())
+	})
+})
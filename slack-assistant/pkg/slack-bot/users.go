@@ -0,0 +1,42 @@
+package slackbot
+
+import (
+	"fmt"
+
+	"github.com/slack-go/slack"
+)
+
+// GetUserName returns the display name of a user, names are cached so every user is only looked up once
+func (b *SlackBot) GetUserName(userID string) (string, error) {
+	b.userNamesMu.RLock()
+	name, ok := b.userNames[userID]
+	b.userNamesMu.RUnlock()
+	if ok {
+		return name, nil
+	}
+
+	user, err := b.clientForUser(userID).GetUserInfo(userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user %s: %w", userID, err)
+	}
+	name = displayName(user)
+
+	b.userNamesMu.Lock()
+	defer b.userNamesMu.Unlock()
+	if b.userNames == nil {
+		b.userNames = map[string]string{}
+	}
+	b.userNames[userID] = name
+	return name, nil
+}
+
+// displayName returns the name Slack shows for the user: the display name, the real name or the user name
+func displayName(user *slack.User) string {
+	if user.Profile.DisplayName != "" {
+		return user.Profile.DisplayName
+	}
+	if user.RealName != "" {
+		return user.RealName
+	}
+	return user.Name
+}
@@ -0,0 +1,79 @@
+package slackbot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+)
+
+var _ = Describe("GetUserName", func() {
+	var (
+		lookups atomic.Int32
+		server  *httptest.Server
+		bot     *SlackBot
+	)
+
+	BeforeEach(func() {
+		lookups.Store(0)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lookups.Add(1)
+			w.Header().Set("Content-Type", "application/json")
+			//nolint:errcheck // test mock
+			_ = r.ParseForm()
+			switch r.Form.Get("user") {
+			case "U123":
+				//nolint:errcheck // test mock
+				_, _ = w.Write([]byte(`{"ok": true, "user": {"id": "U123", "name": "alice", "real_name": "Alice Smith", "profile": {"display_name": "alice.s"}}}`))
+			case "U456":
+				//nolint:errcheck // test mock
+				_, _ = w.Write([]byte(`{"ok": true, "user": {"id": "U456", "name": "bob", "real_name": "Bob Jones", "profile": {}}}`))
+			default:
+				//nolint:errcheck // test mock
+				_, _ = w.Write([]byte(`{"ok": false, "error": "user_not_found"}`))
+			}
+		}))
+		bot = &SlackBot{api: slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"))}
+	})
+
+	AfterEach(func() {
+		server.Close()
+		http.DefaultClient.CloseIdleConnections()
+	})
+
+	It("should look the user up on a cache miss", func() {
+		name, err := bot.GetUserName("U123")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(name).To(Equal("alice.s"))
+		Expect(lookups.Load()).To(Equal(int32(1)))
+	})
+
+	It("should reuse the cached name on a cache hit", func() {
+		_, err := bot.GetUserName("U123")
+		Expect(err).NotTo(HaveOccurred())
+
+		name, err := bot.GetUserName("U123")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(name).To(Equal("alice.s"))
+		Expect(lookups.Load()).To(Equal(int32(1)))
+	})
+
+	It("should fall back to the real name without a display name", func() {
+		name, err := bot.GetUserName("U456")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(name).To(Equal("Bob Jones"))
+	})
+
+	It("should not cache failed lookups", func() {
+		_, err := bot.GetUserName("U789")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("user_not_found"))
+
+		_, err = bot.GetUserName("U789")
+		Expect(err).To(HaveOccurred())
+		Expect(lookups.Load()).To(Equal(int32(2)))
+	})
+})
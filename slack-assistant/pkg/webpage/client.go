@@ -0,0 +1,72 @@
+package webpage
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// maxRedirects is the number of redirects followed before giving up, like the default client
+const maxRedirects = 10
+
+// ErrForbiddenAddress is returned for the pages served from a loopback, private, link-local, multicast or unspecified
+// address, so the bot can't be used to reach the services of its own network
+var ErrForbiddenAddress = errors.New("address not allowed")
+
+// NewClient returns a client with the timeout that only connects to public addresses. The address is checked after
+// the DNS resolution on every connection, so a redirect or a host resolving to an internal address is rejected too.
+// The proxy of the environment isn't used since it would connect on behalf of the bot
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: checkAddress,
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return ErrInvalidURL
+			}
+			return nil
+		},
+	}
+}
+
+// checkAddress rejects the connections to the addresses that aren't public, it is called with the resolved address
+func checkAddress(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+	}
+	if !publicAddress(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// publicAddress tells whether the address can be reached from the internet, IPv4 addresses mapped to IPv6 are
+// checked as IPv4
+func publicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified()
+}
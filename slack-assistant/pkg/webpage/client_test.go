@@ -0,0 +1,70 @@
+package webpage
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestPublicAddress(t *testing.T) {
+	tests := []struct {
+		addr   string
+		public bool
+	}{
+		{addr: "93.184.216.34", public: true},
+		{addr: "2606:2800:220:1:248:1893:25c8:1946", public: true},
+		{addr: "127.0.0.1"},
+		{addr: "::1"},
+		{addr: "10.0.0.1"},
+		{addr: "172.16.5.4"},
+		{addr: "192.168.1.1"},
+		{addr: "fd00::1"},
+		{addr: "169.254.169.254"},
+		{addr: "fe80::1"},
+		{addr: "224.0.0.1"},
+		{addr: "ff02::1"},
+		{addr: "0.0.0.0"},
+		{addr: "::"},
+		{addr: "::ffff:127.0.0.1"},
+		{addr: "::ffff:10.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if public := publicAddress(netip.MustParseAddr(tt.addr)); public != tt.public {
+				t.Errorf("Expected public to be %v, got %v", tt.public, public)
+			}
+		})
+	}
+}
+
+func TestNewClient_RejectsInternalAddresses(t *testing.T) {
+	server := newPageServer(t, "text/plain", "internal")
+
+	_, err := NewFetcher(NewClient(time.Second), 0).Fetch(context.Background(), server.URL)
+	if !errors.Is(err, ErrForbiddenAddress) {
+		t.Errorf("Expected the loopback server to be refused, got %v", err)
+	}
+}
+
+func TestNewClient_CheckRedirect(t *testing.T) {
+	client := NewClient(time.Second)
+	via := []*http.Request{httptest.NewRequest(http.MethodGet, "https://docs.example.com/", nil)}
+
+	if err := client.CheckRedirect(httptest.NewRequest(http.MethodGet, "https://docs.example.com/moved", nil), via); err != nil {
+		t.Errorf("Expected the redirect to be followed, got %v", err)
+	}
+	if err := client.CheckRedirect(httptest.NewRequest(http.MethodGet, "ftp://docs.example.com/moved", nil), via); !errors.Is(err, ErrInvalidURL) {
+		t.Errorf("Expected a redirect to another scheme to be refused, got %v", err)
+	}
+	for range maxRedirects {
+		via = append(via, via[0])
+	}
+	if err := client.CheckRedirect(httptest.NewRequest(http.MethodGet, "https://docs.example.com/moved", nil), via); err == nil {
+		t.Error("Expected the redirects to stop")
+	}
+}
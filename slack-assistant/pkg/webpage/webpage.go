@@ -0,0 +1,238 @@
+// Package webpage fetches web pages and extracts their readable text, e.g. to inject a documentation page.
+package webpage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// DefaultMaxSize is the largest page body fetched in bytes, larger pages are rejected instead of being cut
+const DefaultMaxSize = 2 << 20
+
+var (
+	// ErrInvalidURL is returned for the URLs that aren't http(s) URLs with a host
+	ErrInvalidURL = errors.New("not an http or https URL")
+	// ErrTooLarge is returned for the pages larger than the maximum size
+	ErrTooLarge = errors.New("page too large")
+	// ErrNoText is returned for the pages without any readable text, e.g. a page rendered by JavaScript
+	ErrNoText = errors.New("page has no text")
+)
+
+// StatusError is returned when the site answers with a status other than 200 OK
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("site returned status %d", e.StatusCode)
+}
+
+// UnsupportedTypeError is returned for the pages that aren't HTML or text, e.g. PDFs or images
+type UnsupportedTypeError struct {
+	ContentType string
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return fmt.Sprintf("unsupported content type %q", e.ContentType)
+}
+
+// htmlTypes are the content types parsed as HTML, textTypes are used as they are
+var (
+	htmlTypes = []string{"text/html", "application/xhtml+xml"}
+	textTypes = []string{"text/plain", "text/markdown"}
+)
+
+// Page is the readable content of a fetched page
+type Page struct {
+	URL   string
+	Title string
+	Text  string
+}
+
+// Fetcher fetches pages with a size limit
+type Fetcher struct {
+	client  *http.Client
+	maxSize int64
+}
+
+// NewFetcher returns a fetcher using the client, which should have a timeout, and rejecting the pages over maxSize bytes,
+// DefaultMaxSize is used when maxSize isn't positive
+func NewFetcher(client *http.Client, maxSize int64) *Fetcher {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	return &Fetcher{client: client, maxSize: maxSize}
+}
+
+// Fetch downloads the page and extracts its title and readable text, only HTML and text pages are accepted
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Page, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, ErrInvalidURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsed.String(), http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,text/plain;q=0.9")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch page: %w", err)
+	}
+	defer func() {
+		//nolint:errcheck // response body close in defer
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || (!slices.Contains(htmlTypes, mediaType) && !slices.Contains(textTypes, mediaType)) {
+		return nil, &UnsupportedTypeError{ContentType: contentType}
+	}
+	if resp.ContentLength > f.maxSize {
+		return nil, ErrTooLarge
+	}
+
+	// The server may not announce the length, one more byte than allowed tells the body is too large
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page: %w", err)
+	}
+	if int64(len(body)) > f.maxSize {
+		return nil, ErrTooLarge
+	}
+
+	reader, err := charset.NewReader(bytes.NewReader(body), contentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode page: %w", err)
+	}
+
+	page := &Page{URL: parsed.String()}
+	if slices.Contains(htmlTypes, mediaType) {
+		page.Title, page.Text, err = ExtractText(reader)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		text, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode page: %w", err)
+		}
+		page.Text = strings.TrimSpace(string(text))
+	}
+
+	if page.Text == "" {
+		return nil, ErrNoText
+	}
+	return page, nil
+}
+
+// skippedElements hold no readable text: scripts, styles and the navigation around the content
+var skippedElements = []string{"script", "style", "noscript", "template", "svg", "iframe", "head", "nav", "header", "footer", "aside", "form", "button"}
+
+// blockElements start a new line in the extracted text
+var blockElements = []string{
+	"p", "div", "br", "li", "ul", "ol", "dt", "dd", "tr", "table", "pre", "blockquote",
+	"section", "article", "main", "h1", "h2", "h3", "h4", "h5", "h6", "hr",
+}
+
+// ExtractText returns the title and the readable text of an HTML document, one line per block element
+// and per line of the pre elements, with the whitespace collapsed
+func ExtractText(r io.Reader) (title, text string, err error) {
+	tokenizer := html.NewTokenizer(r)
+	var (
+		builder strings.Builder
+		skipped []string
+		inTitle bool
+		// preDepth counts the open pre elements, their line breaks are kept
+		preDepth int
+	)
+
+	for {
+		tokenType := tokenizer.Next()
+		switch tokenType {
+		case html.ErrorToken:
+			if errors.Is(tokenizer.Err(), io.EOF) {
+				return strings.TrimSpace(title), collapseLines(builder.String()), nil
+			}
+			return "", "", fmt.Errorf("failed to parse page: %w", tokenizer.Err())
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := tokenizer.TagName()
+			tag := string(name)
+			if tag == "title" {
+				inTitle = tokenType == html.StartTagToken
+				continue
+			}
+			if slices.Contains(skippedElements, tag) && tokenType == html.StartTagToken {
+				skipped = append(skipped, tag)
+				continue
+			}
+			if tag == "pre" && tokenType == html.StartTagToken {
+				preDepth++
+			}
+			if slices.Contains(blockElements, tag) {
+				builder.WriteString("\n")
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			tag := string(name)
+			if tag == "title" {
+				inTitle = false
+				continue
+			}
+			if len(skipped) > 0 && skipped[len(skipped)-1] == tag {
+				skipped = skipped[:len(skipped)-1]
+				continue
+			}
+			if tag == "pre" && preDepth > 0 {
+				preDepth--
+			}
+			if slices.Contains(blockElements, tag) {
+				builder.WriteString("\n")
+			}
+		case html.TextToken:
+			if inTitle {
+				title += string(tokenizer.Text())
+				continue
+			}
+			if len(skipped) > 0 {
+				continue
+			}
+			// Outside pre the line breaks of the source are only whitespace
+			if preDepth > 0 {
+				builder.Write(tokenizer.Text())
+			} else {
+				builder.WriteString(strings.ReplaceAll(string(tokenizer.Text()), "\n", " "))
+			}
+		}
+	}
+}
+
+// collapseLines collapses the whitespace of every line and drops the empty lines
+func collapseLines(text string) string {
+	lines := strings.Split(text, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
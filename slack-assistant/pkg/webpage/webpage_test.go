@@ -0,0 +1,130 @@
+package webpage
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testPage = `<!DOCTYPE html>
+<html>
+<head><title>Configuring SR-IOV &amp; VFs</title><style>body { color: red; }</style></head>
+<body>
+<nav><a href="/">Home</a> <a href="/docs">Docs</a></nav>
+<main>
+<h1>Creating a VF</h1>
+<p>Create a   <code>SriovNetworkNodePolicy</code>
+to configure the VFs.</p>
+<ul><li>Set numVfs</li><li>Set the resource name</li></ul>
+<script>console.log("tracking")</script>
+</main>
+<footer>Copyright</footer>
+</body>
+</html>`
+
+// newPageServer serves the body with the content type on every path
+func newPageServer(t *testing.T, contentType, body string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		//nolint:errcheck // test server
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestExtractText(t *testing.T) {
+	title, text, err := ExtractText(strings.NewReader(testPage))
+	if err != nil {
+		t.Fatalf("ExtractText failed: %v", err)
+	}
+
+	if title != "Configuring SR-IOV & VFs" {
+		t.Errorf("Unexpected title: %q", title)
+	}
+	expected := "Creating a VF\nCreate a SriovNetworkNodePolicy to configure the VFs.\nSet numVfs\nSet the resource name"
+	if text != expected {
+		t.Errorf("Expected %q, got %q", expected, text)
+	}
+}
+
+func TestFetcher_Fetch(t *testing.T) {
+	server := newPageServer(t, "text/html; charset=utf-8", testPage)
+
+	page, err := NewFetcher(server.Client(), 0).Fetch(context.Background(), server.URL+"/sriov")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if page.URL != server.URL+"/sriov" || page.Title != "Configuring SR-IOV & VFs" || !strings.HasPrefix(page.Text, "Creating a VF\n") {
+		t.Errorf("Unexpected page: %+v", page)
+	}
+}
+
+func TestFetcher_Fetch_PlainText(t *testing.T) {
+	server := newPageServer(t, "text/plain", "  Set numVfs to 8\n")
+
+	page, err := NewFetcher(server.Client(), 0).Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if page.Title != "" || page.Text != "Set numVfs to 8" {
+		t.Errorf("Unexpected page: %+v", page)
+	}
+}
+
+func TestFetcher_Fetch_Rejected(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		check       func(err error) bool
+	}{
+		{name: "pdf", contentType: "application/pdf", body: "%PDF-1.7", check: func(err error) bool {
+			var unsupported *UnsupportedTypeError
+			return errors.As(err, &unsupported) && unsupported.ContentType == "application/pdf"
+		}},
+		{name: "missing content type", contentType: "", body: "hello", check: func(err error) bool {
+			var unsupported *UnsupportedTypeError
+			return errors.As(err, &unsupported)
+		}},
+		{name: "too large", contentType: "text/plain", body: strings.Repeat("a", 65), check: func(err error) bool {
+			return errors.Is(err, ErrTooLarge)
+		}},
+		{name: "no text", contentType: "text/html", body: "<html><body><script>render()</script></body></html>", check: func(err error) bool {
+			return errors.Is(err, ErrNoText)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newPageServer(t, tt.contentType, tt.body)
+
+			page, err := NewFetcher(server.Client(), 64).Fetch(context.Background(), server.URL)
+			if !tt.check(err) {
+				t.Errorf("Unexpected result: %+v, %v", page, err)
+			}
+		})
+	}
+}
+
+func TestFetcher_Fetch_Status(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	var statusErr *StatusError
+	if _, err := NewFetcher(server.Client(), 0).Fetch(context.Background(), server.URL); !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected a 404 status error, got %v", err)
+	}
+}
+
+func TestFetcher_Fetch_InvalidURL(t *testing.T) {
+	for _, rawURL := range []string{"ftp://docs.example.com/file", "file:///etc/passwd", "docs.example.com", "http://"} {
+		if _, err := NewFetcher(http.DefaultClient, 0).Fetch(context.Background(), rawURL); !errors.Is(err, ErrInvalidURL) {
+			t.Errorf("Expected %q to be rejected, got %v", rawURL, err)
+		}
+	}
+}